@@ -0,0 +1,29 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+)
+
+// generalAdmin runs an iadmin like operation, such as generalAdmin("Create Resource", "add", "resource", ...).
+// op names it in errors ("iRODS <op> Failed"). You must have the proper rodsadmin privileges.
+func (con *Connection) generalAdmin(op string, args ...string) error {
+	padded := make([]string, 10)
+	copy(padded, args)
+
+	return con.retry(true, func() error {
+		ga, ok := con.innerTransport(context.Background()).(generalAdminer)
+		if !ok {
+			return con.notSupported(op)
+		}
+
+		if err := ga.GeneralAdmin(padded...); err != nil {
+			return transportError(err, fmt.Sprintf("iRODS %v Failed", op))
+		}
+
+		return nil
+	})
+}
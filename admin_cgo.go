@@ -0,0 +1,50 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+// GeneralAdmin runs an iadmin like operation, see generalAdminer
+func (t *cTransport) GeneralAdmin(args ...string) error {
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	return cGeneralAdmin(ccon, args)
+}
+
+// cGeneralAdmin sends the 10 arguments of an iadmin like operation to the server
+func cGeneralAdmin(ccon *rcComm, args []string) error {
+	allocated := make([]unsafe.Pointer, 0, len(args))
+	defer func() {
+		for _, p := range allocated {
+			C.free(p)
+		}
+	}()
+
+	arg := func(i int) *C.char {
+		cArg := C.CString(args[i])
+		allocated = append(allocated, unsafe.Pointer(cArg))
+		return cArg
+	}
+
+	var errMsg *C.char
+
+	if status := C.gorods_general_admin(0, arg(0), arg(1), arg(2), arg(3), arg(4), arg(5), arg(6), arg(7), arg(8), arg(9), nil, ccon, &errMsg); status < 0 && status != catSuccessButWithNoInfo {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// scrambleWheel holds the characters the .irodsA obfuscation rotates, others are stored as is
+const scrambleWheel = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!\"#$%&'()*+,-./"
+
+// scrambleSeqs are the keys of the .irodsA obfuscation, the one used is stored in the 7th byte of the file
+var scrambleSeqs = [...]int64{
+	0xd768b678, 0xedfdaf56, 0x2420231b, 0x987098d8,
+	0xc1bdfeee, 0xf572341f, 0x478def3a, 0xa830d343,
+	0x774dfa2a, 0x6720731e, 0x346fa320, 0x6ffdf43a,
+	0x7723a320, 0xdf67d02e, 0x86ad240a, 0xe76d342e,
+}
+
+// AuthFile returns the path of the scrambled password file written by iinit: $IRODS_AUTHENTICATION_FILE when
+// it's set, ~/.irods/.irodsA otherwise.
+func AuthFile() string {
+	if path := os.Getenv("IRODS_AUTHENTICATION_FILE"); path != "" {
+		return path
+	}
+
+	return filepath.Join(homeDir(), ".irods", ".irodsA")
+}
+
+// ScramblePassword obfuscates password the way iinit does before writing it to .irodsA. The result can only be
+// read back with the same uid, use os.Getuid() to share the file with icommands.
+func ScramblePassword(password string, uid int) string {
+	return scramblePassword(password, uid, time.Now().Unix())
+}
+
+func scramblePassword(password string, uid int, mtime int64) string {
+	seqIndex := int(mtime & 0xf)
+
+	// A check character and the low 16 bits of the file's modification time come before the password
+	plain := []byte{
+		byte('S' - (seqIndex&0x7)*2),
+		byte('a' + (mtime>>4)&0xf),
+		byte('a' + mtime&0xf),
+		byte('a' + (mtime>>12)&0xf),
+		byte('a' + (mtime>>8)&0xf),
+	}
+	plain = append(plain, password...)
+
+	scrambled := rotateWheel(plain, scrambleSeqs[seqIndex], 0, uid, 1)
+
+	out := []byte{'.'}
+	out = append(out, scrambled[:5]...)
+	out = append(out, byte('e'+seqIndex))
+	out = append(out, scrambled[5:]...)
+
+	// iinit terminates the file with a null byte
+	return string(append(out, 0))
+}
+
+// UnscramblePassword returns the password obfuscated in the contents of an .irodsA file by iinit or
+// ScramblePassword, using the uid the file was written with.
+func UnscramblePassword(scrambled string, uid int) (string, error) {
+	if i := strings.IndexAny(scrambled, "\x00\r\n"); i >= 0 {
+		scrambled = scrambled[:i]
+	}
+
+	if len(scrambled) < 7 || scrambled[0] != '.' || scrambled[6] < 'e' || scrambled[6] >= 'e'+byte(len(scrambleSeqs)) {
+		return "", newError(Fatal, -1, "iRODS Unscramble Password Failed: Unrecognized .irodsA format")
+	}
+
+	seq := scrambleSeqs[scrambled[6]-'e']
+
+	// The header characters before the sequence index are skipped, along with their 5 shifts of the key
+	return string(rotateWheel([]byte(scrambled[7:]), seq, 15, uid, -1)), nil
+}
+
+// rotateWheel moves each character of in found in scrambleWheel forward (direction 1) or back (direction -1) by
+// an amount derived from seq and uid
+func rotateWheel(in []byte, seq int64, shift uint, uid int, direction int) []byte {
+	out := make([]byte, len(in))
+	wheelLen := len(scrambleWheel)
+
+	for i, c := range in {
+		offset := int((seq>>shift)&0x1f) + (uid & 0xf5f)
+
+		shift += 3
+		if shift > 28 {
+			shift = 0
+		}
+
+		pos := strings.IndexByte(scrambleWheel, c)
+		if pos < 0 {
+			out[i] = c
+			continue
+		}
+
+		pos = (pos + direction*(offset%wheelLen) + wheelLen) % wheelLen
+		out[i] = scrambleWheel[pos]
+	}
+
+	return out
+}
+
+// ReadAuthFile reads the password stored in the .irodsA file at path, or AuthFile() when path is empty
+func ReadAuthFile(path string) (string, error) {
+	if path == "" {
+		path = AuthFile()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", newError(Fatal, -1, fmt.Sprintf("iRODS Read Auth File Failed: %v", err)).wrap(err)
+	}
+
+	return UnscramblePassword(string(data), os.Getuid())
+}
+
+// WriteAuthFile scrambles password into the .irodsA file at path, or AuthFile() when path is empty, creating its
+// directory. The file is only readable by its owner, and can be used by icommands run by the same user.
+func WriteAuthFile(path string, password string) error {
+	if path == "" {
+		path = AuthFile()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Write Auth File Failed: %v", err))
+	}
+
+	if err := ioutil.WriteFile(path, []byte(ScramblePassword(password, os.Getuid())), 0600); err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Write Auth File Failed: %v", err))
+	}
+
+	return nil
+}
+
+// readAuthFile fills in the password from AuthFile when neither Password nor PAMToken is set. EnvironmentDefined
+// connections fall back to irods_authentication_file, then the default AuthFile(), and a missing default file
+// isn't an error.
+func (conOpts *ConnectionOptions) readAuthFile() error {
+	if conOpts.Password != "" || conOpts.PAMToken != "" {
+		return nil
+	}
+
+	path := conOpts.AuthFile
+
+	if path == "" {
+		if conOpts.Type != EnvironmentDefined {
+			return nil
+		}
+
+		if env, _ := LoadEnvironment(); env != nil && env.AuthenticationFile != "" {
+			path = env.AuthenticationFile
+		} else {
+			path = AuthFile()
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	password, err := ReadAuthFile(path)
+	if err != nil {
+		return err
+	}
+
+	// The file holds the temporary password of a PAM login, which is used like a PAM token
+	if conOpts.AuthType == PAMAuth {
+		conOpts.PAMToken = password
+	} else {
+		conOpts.Password = password
+	}
+
+	return nil
+}
+
+// Iinit connects like NewConnection and, once logged in, caches the credential in the .irodsA file at
+// opts.AuthFile (or AuthFile()) like the iinit icommand. With PAMAuth the temporary password returned by the
+// server is cached. UserDefined options are also written to EnvironmentFile() when it doesn't exist yet, so
+// icommands and later EnvironmentDefined connections can use them without a password.
+func Iinit(opts *ConnectionOptions) (*Connection, error) {
+	if opts.Password == "" {
+		return nil, newError(Fatal, -1, "iRODS Iinit Failed: Password is required")
+	}
+
+	con, err := NewConnection(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	password := opts.Password
+	if opts.AuthType == PAMAuth {
+		password = con.PAMToken
+	}
+
+	if err := WriteAuthFile(opts.AuthFile, password); err != nil {
+		con.Disconnect()
+		return nil, err
+	}
+
+	if opts.Type == UserDefined {
+		if _, err := os.Stat(EnvironmentFile()); os.IsNotExist(err) {
+			env := NewEnvironment(opts.Host, opts.Port, opts.Zone, opts.Username)
+			env.AuthenticationFile = opts.AuthFile
+
+			if opts.AuthType == PAMAuth {
+				env.AuthenticationScheme = "pam"
+			}
+
+			if err := env.Write(""); err != nil {
+				con.Disconnect()
+				return nil, err
+			}
+		}
+	}
+
+	return con, nil
+}
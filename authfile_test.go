@@ -0,0 +1,154 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setenv sets key for the duration of a test, the returned function restores it
+func setenv(key string, value string) func() {
+	old, set := os.LookupEnv(key)
+	os.Setenv(key, value)
+
+	return func() {
+		if set {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func TestScramblePassword(t *testing.T) {
+	// .irodsA contents written by icommands.EncodePasswordString of go-irodsclient v0.14.1, a port of the iinit
+	// obfuscation. It always uses the first sequence, and the 4 characters after the check character encode the
+	// time the file was written.
+	vectors := []struct {
+		uid       int
+		password  string
+		scrambled string
+	}{
+		{1000, "rods", ".jxy(qe\"(x)\x00"},
+		{1000, "S3cr3t pa:ss@word!", ".jxy(qecMw(M+ (u:#,@-()l3\x00"},
+		{501, "rods", ".8MNWFeQWMX\x00"},
+		{501, "S3cr3t pa:ss@word!", ".8MNWFe1!LW!Z WJ:Ra@bWXAh\x00"},
+	}
+
+	for _, v := range vectors {
+		if password, err := UnscramblePassword(v.scrambled, v.uid); err != nil || password != v.password {
+			t.Errorf("Expected %q for uid %v, got %q (%v)", v.password, v.uid, password, err)
+		}
+
+		// 1500000000 selects the first sequence too
+		if scrambled := scramblePassword(v.password, v.uid, 1500000000); scrambled[:2] != v.scrambled[:2] || scrambled[6:] != v.scrambled[6:] {
+			t.Errorf("Expected the iinit encoding %q for uid %v, got %q", v.scrambled, v.uid, scrambled)
+		}
+	}
+
+	for _, password := range []string{"a", "rods", "S3cr3t pa:ss@word!"} {
+		for _, uid := range []int{0, 501, 1000, 65534} {
+			if unscrambled, err := UnscramblePassword(ScramblePassword(password, uid), uid); err != nil || unscrambled != password {
+				t.Errorf("Expected %q back for uid %v, got %q (%v)", password, uid, unscrambled, err)
+			}
+		}
+	}
+
+	if unscrambled, _ := UnscramblePassword(ScramblePassword("rods", 1000), 1001); unscrambled == "rods" {
+		t.Error("Expected another uid not to unscramble the password")
+	}
+}
+
+func TestUnscramblePasswordInvalid(t *testing.T) {
+	for _, scrambled := range []string{"", "rods", "xabcdeexyz", ".abcdezxyz"} {
+		if _, err := UnscramblePassword(scrambled, 1000); err == nil {
+			t.Errorf("Expected %q to be rejected", scrambled)
+		}
+	}
+}
+
+func TestAuthFileReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorods-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".irods", ".irodsA")
+
+	if err := WriteAuthFile(path, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file to be private, got %v", info.Mode().Perm())
+	}
+
+	if password, err := ReadAuthFile(path); err != nil || password != "secret" {
+		t.Errorf("Expected secret, got %q (%v)", password, err)
+	}
+
+	defer setenv("IRODS_AUTHENTICATION_FILE", path)()
+
+	if AuthFile() != path {
+		t.Errorf("Expected IRODS_AUTHENTICATION_FILE to be used, got %v", AuthFile())
+	}
+
+	if _, err := ReadAuthFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
+
+func TestConnectionOptionsReadAuthFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorods-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".irodsA")
+	if err := WriteAuthFile(path, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	defer setenv("IRODS_ENVIRONMENT_FILE", filepath.Join(dir, "missing.json"))()
+	defer setenv("IRODS_AUTHENTICATION_FILE", path)()
+
+	opts := ConnectionOptions{Type: EnvironmentDefined}
+	if err := opts.readAuthFile(); err != nil || opts.Password != "secret" {
+		t.Errorf("Expected the default auth file to be read, got %q (%v)", opts.Password, err)
+	}
+
+	opts = ConnectionOptions{Type: EnvironmentDefined, AuthType: PAMAuth}
+	if err := opts.readAuthFile(); err != nil || opts.PAMToken != "secret" || opts.Password != "" {
+		t.Errorf("Expected the PAM token to be read, got %+v (%v)", opts, err)
+	}
+
+	opts = ConnectionOptions{Type: EnvironmentDefined, Password: "explicit"}
+	if err := opts.readAuthFile(); err != nil || opts.Password != "explicit" {
+		t.Errorf("Expected an explicit password to take precedence, got %q (%v)", opts.Password, err)
+	}
+
+	opts = ConnectionOptions{Type: UserDefined}
+	if err := opts.readAuthFile(); err != nil || opts.Password != "" {
+		t.Errorf("Expected UserDefined options to only read AuthFile, got %q (%v)", opts.Password, err)
+	}
+
+	opts = ConnectionOptions{Type: UserDefined, AuthFile: filepath.Join(dir, "missing")}
+	if err := opts.readAuthFile(); err == nil {
+		t.Error("Expected a missing AuthFile to fail")
+	}
+
+	os.Remove(path)
+
+	opts = ConnectionOptions{Type: EnvironmentDefined}
+	if err := opts.readAuthFile(); err != nil {
+		t.Errorf("Expected a missing default auth file to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TransferCheckpoint is the state persisted by DataObj.DownloadToResumable and Collection.PutResumable,
+// so that an interrupted transfer can pick up where it left off. Completed holds the byte ranges
+// already transferred.
+//
+// Downloads are only resumed if the data object's size, modify time and checksum are unchanged.
+// Uploads are only resumed if the local file's size and modification time are unchanged, and
+// the data object still has the same id, modify time and checksum as when the previous attempt
+// stopped. Those are recorded when an upload fails or is cancelled: after a crash they're unknown,
+// and the upload starts over.
+type TransferCheckpoint struct {
+	Upload    bool
+	Path      string
+	LocalPath string
+	Size      int64
+	PartSize  int64
+
+	ModifyTime   string
+	Checksum     string
+	DataId       string
+	LocalModTime time.Time
+
+	Completed []ByteRange
+
+	mu sync.Mutex
+}
+
+// LoadCheckpoint reads a checkpoint previously written to stateFile.
+func LoadCheckpoint(stateFile string) (*TransferCheckpoint, error) {
+	contents, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("Unable to read checkpoint %v: %v", stateFile, err)).wrap(err)
+	}
+
+	cp := new(TransferCheckpoint)
+
+	if er := json.Unmarshal(contents, cp); er != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("Unable to parse checkpoint %v: %v", stateFile, er)).wrap(er)
+	}
+
+	return cp, nil
+}
+
+// Save writes the checkpoint to stateFile. The file is replaced atomically, so a crash while saving leaves the previous state intact.
+func (cp *TransferCheckpoint) Save(stateFile string) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	return cp.save(stateFile)
+}
+
+func (cp *TransferCheckpoint) save(stateFile string) error {
+	contents, err := json.Marshal(cp)
+	if err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("Unable to save checkpoint %v: %v", stateFile, err)).wrap(err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(stateFile), filepath.Base(stateFile)+".tmp")
+	if err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("Unable to save checkpoint %v: %v", stateFile, err)).wrap(err)
+	}
+
+	if _, er := tmp.Write(contents); er != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return newError(Fatal, -1, fmt.Sprintf("Unable to save checkpoint %v: %v", stateFile, er)).wrap(er)
+	}
+
+	if er := tmp.Close(); er != nil {
+		os.Remove(tmp.Name())
+		return newError(Fatal, -1, fmt.Sprintf("Unable to save checkpoint %v: %v", stateFile, er)).wrap(er)
+	}
+
+	if er := os.Rename(tmp.Name(), stateFile); er != nil {
+		os.Remove(tmp.Name())
+		return newError(Fatal, -1, fmt.Sprintf("Unable to save checkpoint %v: %v", stateFile, er)).wrap(er)
+	}
+
+	return nil
+}
+
+// Complete marks r as transferred and saves the checkpoint to stateFile.
+func (cp *TransferCheckpoint) Complete(r ByteRange, stateFile string) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.Completed = append(cp.Completed, r)
+
+	return cp.save(stateFile)
+}
+
+// Remaining returns the byte ranges which haven't been transferred yet.
+func (cp *TransferCheckpoint) Remaining() []ByteRange {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	done := make(map[ByteRange]bool)
+	for _, r := range cp.Completed {
+		done[r] = true
+	}
+
+	remaining := make([]ByteRange, 0)
+
+	for _, r := range splitRanges(cp.Size, cp.PartSize) {
+		if !done[r] {
+			remaining = append(remaining, r)
+		}
+	}
+
+	return remaining
+}
+
+// matches checks whether a previously saved checkpoint describes the same transfer as cp
+func (cp *TransferCheckpoint) matches(prev *TransferCheckpoint) bool {
+	if prev.Upload != cp.Upload || prev.Path != cp.Path || prev.LocalPath != cp.LocalPath || prev.Size != cp.Size || prev.PartSize != cp.PartSize {
+		return false
+	}
+
+	if cp.Upload {
+		return prev.LocalModTime.Equal(cp.LocalModTime)
+	}
+
+	return prev.ModifyTime == cp.ModifyTime && prev.Checksum == cp.Checksum
+}
+
+// DownloadToResumable downloads the data object to localPath like DownloadToParallel, recording completed ranges in stateFile.
+// If stateFile holds a checkpoint for the same download, and the data object hasn't changed since, only the missing ranges are transferred.
+// The state file is removed once the download completes.
+func (obj *DataObj) DownloadToResumable(localPath string, stateFile string, topts TransferOptions) error {
+
+	info, err := obj.Stat()
+	if err != nil {
+		return err
+	}
+
+	cp := &TransferCheckpoint{
+		Path:       obj.path,
+		LocalPath:  localPath,
+		Size:       int64(info["objSize"].(int)),
+		PartSize:   topts.partSize(),
+		ModifyTime: info["modifyTime"].(string),
+		Checksum:   info["chksum"].(string),
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+
+	if prev, er := LoadCheckpoint(stateFile); er == nil && cp.matches(prev) {
+		if local, lEr := os.Stat(localPath); lEr == nil && local.Size() == cp.Size {
+			cp.Completed = prev.Completed
+		}
+	}
+
+	if len(cp.Completed) == 0 {
+		flags |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(localPath, flags, 0644)
+	if err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, err)).withPath(obj.path).wrap(err)
+	}
+	defer f.Close()
+
+	if er := f.Truncate(cp.Size); er != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, er)).withPath(obj.path).wrap(er)
+	}
+
+	if er := cp.Save(stateFile); er != nil {
+		return er
+	}
+
+	bufSize := topts.bufferSize()
+	ctx := topts.context()
+
+	if er := obj.transferRanges(cp.Remaining(), topts, false, func(w *DataObj, r ByteRange) error {
+		if err := w.downloadRange(ctx, f, r, bufSize, nil); err != nil {
+			return err
+		}
+		return cp.Complete(r, stateFile)
+	}); er != nil {
+		return er
+	}
+
+	// Make sure we didn't stitch together two different versions of the data object
+	if after, er := obj.Stat(); er != nil {
+		return er
+	} else if after["modifyTime"].(string) != cp.ModifyTime {
+		os.Remove(stateFile)
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, data object changed during transfer", obj.path)).withPath(obj.path)
+	}
+
+	if er := f.Close(); er != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, er)).withPath(obj.path).wrap(er)
+	}
+
+	if er := os.Remove(stateFile); er != nil {
+		return newError(Fatal, -1, fmt.Sprintf("Unable to remove checkpoint %v: %v", stateFile, er)).wrap(er)
+	}
+
+	// Ranges from earlier attempts weren't seen, so the whole file is checksummed
+	if topts.Verify {
+		return obj.VerifyFile(localPath)
+	}
+
+	return nil
+}
+
+// PutResumable uploads the file at localPath into the collection like PutParallel, recording completed ranges in stateFile.
+// If stateFile holds a checkpoint for the same upload, the local file hasn't changed, and the partially uploaded
+// data object is as the previous attempt left it, only the missing ranges are transferred. Otherwise the upload
+// starts over, replacing the partial data object. The state file is removed once the upload completes.
+func (col *Collection) PutResumable(localPath string, stateFile string, opts DataObjOptions, topts TransferOptions) (*DataObj, error) {
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Put DataObject Failed: %v", err)).wrap(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Put DataObject Failed: %v", err)).wrap(err)
+	}
+
+	if opts.Name == "" {
+		opts.Name = filepath.Base(localPath)
+	}
+
+	opts.Size = info.Size()
+
+	cp := &TransferCheckpoint{
+		Upload:       true,
+		Path:         col.path + "/" + opts.Name,
+		LocalPath:    localPath,
+		Size:         opts.Size,
+		PartSize:     topts.partSize(),
+		LocalModTime: info.ModTime(),
+	}
+
+	var obj *DataObj
+
+	if prev, er := LoadCheckpoint(stateFile); er == nil && cp.matches(prev) {
+		if existing, oEr := getDataObj(topts.context(), cp.Path, col.con); oEr == nil && existing.dataId == prev.DataId && prev.ModifyTime != "" {
+			if info, sEr := existing.Stat(); sEr == nil && info["modifyTime"].(string) == prev.ModifyTime && info["chksum"].(string) == prev.Checksum {
+				obj = existing
+				cp.Completed = prev.Completed
+			}
+		}
+
+		// The data object was changed since, or we can't tell: it's the partial upload, start it over
+		if obj == nil {
+			opts.Force = true
+		}
+	}
+
+	if obj == nil {
+		if obj, err = CreateDataObj(opts, col); err != nil {
+			return nil, err
+		}
+	}
+
+	cp.DataId = obj.dataId
+
+	if er := cp.Save(stateFile); er != nil {
+		return nil, er
+	}
+
+	bufSize := topts.bufferSize()
+	ctx := topts.context()
+
+	if er := obj.transferRanges(cp.Remaining(), topts, true, func(w *DataObj, r ByteRange) error {
+		if err := w.uploadRange(ctx, f, r, bufSize, nil); err != nil {
+			return err
+		}
+		return cp.Complete(r, stateFile)
+	}); er != nil {
+		// Every handle is closed by now, record the state we leave the data object in for the next attempt
+		if info, sEr := obj.Stat(); sEr == nil {
+			cp.ModifyTime = info["modifyTime"].(string)
+			cp.Checksum = info["chksum"].(string)
+			cp.Save(stateFile)
+		}
+		return nil, er
+	}
+
+	if er := os.Remove(stateFile); er != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("Unable to remove checkpoint %v: %v", stateFile, er)).wrap(er)
+	}
+
+	if opts.Verify || topts.Verify {
+		if er := obj.VerifyFile(localPath); er != nil {
+			return nil, er
+		}
+	}
+
+	if err := col.refreshContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return getDataObj(ctx, cp.Path, col.con)
+}
@@ -0,0 +1,163 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckpointResume(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "gorods-checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stateFile := filepath.Join(dir, "state.json")
+
+	cp := &TransferCheckpoint{
+		Path:       "/tempZone/home/rods/big.bam",
+		LocalPath:  "/tmp/big.bam",
+		Size:       10,
+		PartSize:   4,
+		ModifyTime: "1473969839",
+		Checksum:   "sha2:abc",
+	}
+
+	if er := cp.Save(stateFile); er != nil {
+		t.Fatal(er)
+	}
+
+	if er := cp.Complete(ByteRange{4, 4}, stateFile); er != nil {
+		t.Fatal(er)
+	}
+
+	loaded, err := LoadCheckpoint(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cp.matches(loaded) {
+		t.Errorf("Expected loaded checkpoint to match %+v, got %+v", cp, loaded)
+	}
+
+	remaining := loaded.Remaining()
+	expected := []ByteRange{{0, 4}, {8, 2}}
+
+	if len(remaining) != len(expected) {
+		t.Fatalf("Expected %v remaining ranges, got %v", expected, remaining)
+	}
+
+	for i := range expected {
+		if remaining[i] != expected[i] {
+			t.Errorf("Expected range %v, got %v", expected[i], remaining[i])
+		}
+	}
+
+	loaded.ModifyTime = "1473969900"
+
+	if cp.matches(loaded) {
+		t.Error("Expected checkpoint with a different modify time not to match")
+	}
+}
+
+func TestCheckpointUploadMatch(t *testing.T) {
+
+	now := time.Now()
+
+	cp := &TransferCheckpoint{Upload: true, Path: "/tempZone/home/rods/a", LocalPath: "a", Size: 1, PartSize: 1, LocalModTime: now}
+	prev := &TransferCheckpoint{Upload: true, Path: "/tempZone/home/rods/a", LocalPath: "a", Size: 1, PartSize: 1, LocalModTime: now.Add(time.Second)}
+
+	if cp.matches(prev) {
+		t.Error("Expected checkpoint with a different local modification time not to match")
+	}
+
+	prev.LocalModTime = now
+
+	if !cp.matches(prev) {
+		t.Error("Expected identical upload checkpoints to match")
+	}
+}
+
+func TestPutResumableRemoteChanged(t *testing.T) {
+	con := memConnection(t)
+	mem := unwrapTransport(con.transport).(*MemTransport)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "gorods-checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "big.bin")
+	stateFile := filepath.Join(dir, "big.bin.state")
+	objPath := home.Path() + "/big.bin"
+
+	if err := ioutil.WriteFile(localPath, []byte("0123456789abcdefghij"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		change   func(cp *TransferCheckpoint)
+		expected string
+	}{
+		{"unchanged", func(cp *TransferCheckpoint) {}, "XXXXXXXXXXabcdefghij"},
+		{"modified", func(cp *TransferCheckpoint) { mem.objs[objPath].modifyTime = time.Now().Add(time.Hour) }, "0123456789abcdefghij"},
+		{"checksummed", func(cp *TransferCheckpoint) { mem.Checksum(objPath) }, "0123456789abcdefghij"},
+		{"crashed", func(cp *TransferCheckpoint) { cp.ModifyTime = "" }, "0123456789abcdefghij"},
+	}
+
+	for _, test := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// The cancelled attempt creates the data object, and records the state it leaves it in
+		if _, err := home.PutResumable(localPath, stateFile, DataObjOptions{Force: true}, TransferOptions{PartSize: 10, Concurrency: 1, Context: ctx}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("%v: Expected the upload to be cancelled, got %v", test.name, err)
+		}
+
+		cp, err := LoadCheckpoint(stateFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cp.ModifyTime == "" || cp.DataId == "" {
+			t.Fatalf("%v: Expected the state of the data object to be recorded, got %+v", test.name, cp)
+		}
+
+		// Pretend the first range was uploaded, with content telling whether it's uploaded again
+		mem.objs[objPath].data = []byte("XXXXXXXXXX")
+
+		test.change(cp)
+
+		if err := cp.Complete(ByteRange{0, 10}, stateFile); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := home.PutResumable(localPath, stateFile, DataObjOptions{Force: true}, TransferOptions{PartSize: 10, Concurrency: 1}); err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+
+		if content := string(mem.objs[objPath].data); content != test.expected {
+			t.Errorf("%v: Expected the data object to hold %q, got %q", test.name, test.expected, content)
+		}
+
+		if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+			t.Errorf("%v: Expected the state file to be removed, got %v", test.name, err)
+		}
+	}
+}
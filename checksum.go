@@ -0,0 +1,477 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Checksum algorithms, named like the prefixes of iRODS checksums. MD5 checksums are stored as hex digests without a
+// prefix by iRODS, the others as "<algorithm>:" followed by the base64 digest, e.g. "sha2:qUiQTy8P...".
+const (
+	ChecksumMD5    = "md5"
+	ChecksumSHA1   = "sha1"
+	ChecksumSHA256 = "sha2"
+	ChecksumSHA512 = "sha512"
+)
+
+// checksumAlgorithms holds the hash of each algorithm, and whether its digests are base64 (otherwise hex) encoded
+var checksumAlgorithms = map[string]struct {
+	newHash func() hash.Hash
+	base64  bool
+}{
+	ChecksumMD5:    {md5.New, false},
+	ChecksumSHA1:   {sha1.New, true},
+	ChecksumSHA256: {sha256.New, true},
+	ChecksumSHA512: {sha512.New, true},
+}
+
+// checksumAlgorithm returns the algorithm named by the prefix of the iRODS checksum sum, md5 when there's none
+func checksumAlgorithm(sum string) string {
+	if i := strings.IndexByte(sum, ':'); i > 0 {
+		return sum[:i]
+	}
+
+	return ChecksumMD5
+}
+
+// ParseChecksum returns the algorithm and the raw digest of an iRODS checksum, in the "sha2:<base64>" format (or
+// sha1, sha512), as a hex md5 digest, or as "md5:<hex>".
+func ParseChecksum(sum string) (string, []byte, error) {
+	algorithm := checksumAlgorithm(sum)
+
+	alg, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return "", nil, newError(Fatal, -1, fmt.Sprintf("iRODS Parse Checksum Failed: Unsupported algorithm %q", algorithm))
+	}
+
+	encoded := strings.TrimPrefix(sum, algorithm+":")
+
+	var (
+		digest []byte
+		err    error
+	)
+
+	if alg.base64 {
+		digest, err = base64.StdEncoding.DecodeString(encoded)
+	} else {
+		digest, err = hex.DecodeString(encoded)
+	}
+
+	if err != nil {
+		return "", nil, newError(Fatal, -1, fmt.Sprintf("iRODS Parse Checksum Failed: %q: %v", sum, err)).wrap(err)
+	}
+
+	if len(digest) != alg.newHash().Size() {
+		return "", nil, newError(Fatal, -1, fmt.Sprintf("iRODS Parse Checksum Failed: %q: Expected a %v byte digest", sum, alg.newHash().Size()))
+	}
+
+	return algorithm, digest, nil
+}
+
+// FormatChecksum formats digest like iRODS stores checksums of algorithm
+func FormatChecksum(algorithm string, digest []byte) string {
+	if alg, ok := checksumAlgorithms[algorithm]; ok && alg.base64 {
+		return algorithm + ":" + base64.StdEncoding.EncodeToString(digest)
+	}
+
+	return hex.EncodeToString(digest)
+}
+
+// ChecksumsEqual reports whether two iRODS checksums have the same algorithm and digest, whatever their formats
+// (for example, a hex md5 digest equals the same digest with the "md5:" prefix). Checksums which can't be parsed
+// are compared as strings.
+func ChecksumsEqual(a string, b string) bool {
+	algA, digestA, errA := ParseChecksum(a)
+	algB, digestB, errB := ParseChecksum(b)
+
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	return algA == algB && bytes.Equal(digestA, digestB)
+}
+
+// Checksummer is an io.Writer that computes the checksums of the data written to it, so a stream can be compared
+// with iRODS checksums while it's transferred. For example, to check a download:
+//
+//	sums, _ := gorods.NewChecksummer()
+//	io.Copy(io.MultiWriter(localFile, sums), reader)
+//
+//	if remote, _ := obj.Chksum(); !sums.Matches(remote) {
+//		// ...
+//	}
+type Checksummer struct {
+	hashes map[string]hash.Hash
+}
+
+// NewChecksummer returns a Checksummer computing the checksums of algorithms, or of md5 and sha2 (the algorithms
+// iRODS uses by default) when none are given.
+func NewChecksummer(algorithms ...string) (*Checksummer, error) {
+	if len(algorithms) == 0 {
+		algorithms = []string{ChecksumMD5, ChecksumSHA256}
+	}
+
+	c := &Checksummer{hashes: make(map[string]hash.Hash)}
+
+	for _, algorithm := range algorithms {
+		alg, ok := checksumAlgorithms[algorithm]
+		if !ok {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Checksum Failed: Unsupported algorithm %q", algorithm))
+		}
+
+		c.hashes[algorithm] = alg.newHash()
+	}
+
+	return c, nil
+}
+
+// Write adds p to the checksums, it never fails
+func (c *Checksummer) Write(p []byte) (int, error) {
+	for _, h := range c.hashes {
+		h.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// Sum returns the checksum of the data written so far with algorithm, formatted like iRODS does, or an empty string
+// if the Checksummer doesn't compute it
+func (c *Checksummer) Sum(algorithm string) string {
+	h, ok := c.hashes[algorithm]
+	if !ok {
+		return ""
+	}
+
+	return FormatChecksum(algorithm, h.Sum(nil))
+}
+
+// SumLike returns the checksum of the data written so far in the same format as the iRODS checksum remote,
+// including the optional "md5:" prefix of md5 checksums, or an empty string if the Checksummer doesn't compute it
+func (c *Checksummer) SumLike(remote string) string {
+	algorithm := checksumAlgorithm(remote)
+
+	sum := c.Sum(algorithm)
+	if sum != "" && algorithm == ChecksumMD5 && strings.HasPrefix(remote, "md5:") {
+		return "md5:" + sum
+	}
+
+	return sum
+}
+
+// Matches reports whether the data written so far has the iRODS checksum sum
+func (c *Checksummer) Matches(sum string) bool {
+	local := c.SumLike(sum)
+
+	return local != "" && ChecksumsEqual(local, sum)
+}
+
+// checksumFile computes the checksums of the file at localPath with algorithms, see NewChecksummer
+func checksumFile(localPath string, algorithms ...string) (*Checksummer, error) {
+	sums, err := NewChecksummer(algorithms...)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("Unable to checksum %v: %v", localPath, err)).wrap(err)
+	}
+	defer f.Close()
+
+	if _, er := io.Copy(sums, f); er != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("Unable to checksum %v: %v", localPath, er)).wrap(er)
+	}
+
+	return sums, nil
+}
+
+// localChecksum computes the checksum of the file at localPath, in the same format as the iRODS checksum remote
+// (either "sha2:" followed by a base64 SHA-256 digest, or a hex md5 digest with an optional "md5:" prefix)
+func localChecksum(localPath string, remote string) (string, error) {
+	sums, err := checksumFile(localPath, checksumAlgorithm(remote))
+	if err != nil {
+		return "", err
+	}
+
+	return sums.SumLike(remote), nil
+}
+
+// goChecksumFile starts computing the md5 and sha2 checksums of the file at localPath in the background, so it's
+// hashed while it's uploaded by the iRODS C API. The returned function waits for the result.
+func goChecksumFile(localPath string) func() (*Checksummer, error) {
+	var (
+		sums *Checksummer
+		err  error
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		sums, err = checksumFile(localPath)
+		close(done)
+	}()
+
+	return func() (*Checksummer, error) {
+		<-done
+		return sums, err
+	}
+}
+
+// rangeChecksummer computes the checksums of a local file moved in ranges by transferRanges, in any order. Data is
+// hashed as it's transferred while it follows what was hashed so far, data transferred ahead of it is read back from
+// file once the gap is filled. A nil *rangeChecksummer ignores everything.
+type rangeChecksummer struct {
+	mu      sync.Mutex
+	file    io.ReaderAt
+	sums    *Checksummer
+	next    int64
+	pending map[int64]int64
+	err     error
+}
+
+func newRangeChecksummer(file io.ReaderAt) *rangeChecksummer {
+	sums, _ := NewChecksummer()
+
+	return &rangeChecksummer{
+		file:    file,
+		sums:    sums,
+		pending: make(map[int64]int64),
+	}
+}
+
+// add records that data was transferred at offset pos of the file
+func (rc *rangeChecksummer) add(pos int64, data []byte) {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.err != nil {
+		return
+	}
+
+	if pos != rc.next {
+		rc.pending[pos] = int64(len(data))
+		return
+	}
+
+	rc.sums.Write(data)
+	rc.next += int64(len(data))
+
+	for {
+		n, ok := rc.pending[rc.next]
+		if !ok {
+			return
+		}
+
+		delete(rc.pending, rc.next)
+
+		if _, er := io.Copy(rc.sums, io.NewSectionReader(rc.file, rc.next, n)); er != nil {
+			rc.err = er
+			return
+		}
+
+		rc.next += n
+	}
+}
+
+// result returns the checksums once size bytes were transferred
+func (rc *rangeChecksummer) result(size int64) (*Checksummer, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Verify DataObject Failed: %v", rc.err)).wrap(rc.err)
+	}
+
+	if rc.next != size {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Verify DataObject Failed: Only %v of %v bytes were checksummed", rc.next, size))
+	}
+
+	return rc.sums, nil
+}
+
+// compareChecksum compares the iRODS checksum remote of the data object at path with the checksum of the local file
+// at localPath. local holds the checksums computed while the file was transferred, if any, the file is read again
+// when it doesn't include the algorithm of remote. A difference is reported with an error matching
+// ErrChecksumMismatch.
+func compareChecksum(path string, remote string, localPath string, local *Checksummer) error {
+	if remote == "" {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Verify DataObject Failed: %v has no checksum", path)).withPath(path)
+	}
+
+	var sum string
+
+	if local != nil {
+		sum = local.SumLike(remote)
+	}
+
+	if sum == "" {
+		var err error
+		if sum, err = localChecksum(localPath, remote); err != nil {
+			return err
+		}
+	}
+
+	if !ChecksumsEqual(sum, remote) {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Verify DataObject Failed: %v, checksum %v of %v doesn't match %v", path, sum, localPath, remote)).withPath(path).wrap(ErrChecksumMismatch)
+	}
+
+	return nil
+}
+
+// verifyTransfer compares the checksums computed by sums while the size bytes of the data object were transferred
+// from or to the local file at localPath with the checksum computed by the server
+func (obj *DataObj) verifyTransfer(localPath string, size int64, sums *rangeChecksummer) error {
+	local, err := sums.result(size)
+	if err != nil {
+		return err
+	}
+
+	remote, err := obj.Chksum()
+	if err != nil {
+		return err
+	}
+
+	return compareChecksum(obj.path, remote, localPath, local)
+}
+
+// VerifyFile has the server compute the checksum of the data object, and compares it with the checksum of the
+// local file at localPath using the same algorithm. A difference is reported with an error matching
+// ErrChecksumMismatch.
+func (obj *DataObj) VerifyFile(localPath string) error {
+	remote, err := obj.Chksum()
+	if err != nil {
+		return err
+	}
+
+	return compareChecksum(obj.path, remote, localPath, nil)
+}
+
+// ReplicaChecksum is the checksum of one replica of a data object, as reported by DataObj.VerifyReplicas.
+// Checksum is the one registered in the catalog, or computed by the server while verifying the replica. Err is set
+// when the server couldn't verify the replica, and matches ErrChecksumMismatch when the replica's data doesn't match
+// its registered checksum. Mismatch is true when either the data or Checksum differ from the expected checksum.
+type ReplicaChecksum struct {
+	ReplNum    int
+	Resource   string
+	RescHier   string
+	ReplStatus int
+	Checksum   string
+	Mismatch   bool
+	Err        error
+}
+
+// ChecksumReport holds the checksum of every replica of the data object at Path, compared with Expected
+type ChecksumReport struct {
+	Path     string
+	Expected string
+	Replicas []*ReplicaChecksum
+}
+
+// Mismatches returns the replicas that don't match the expected checksum
+func (report *ChecksumReport) Mismatches() []*ReplicaChecksum {
+	response := make([]*ReplicaChecksum, 0)
+
+	for _, r := range report.Replicas {
+		if r.Mismatch {
+			response = append(response, r)
+		}
+	}
+
+	return response
+}
+
+// Err returns nil when every replica matches the expected checksum, otherwise an error matching
+// ErrChecksumMismatch that lists the replicas that don't
+func (report *ChecksumReport) Err() error {
+	mismatches := report.Mismatches()
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	replicas := make([]string, len(mismatches))
+	for i, r := range mismatches {
+		replicas[i] = fmt.Sprintf("%v (%v: %v)", r.ReplNum, r.Resource, r.Checksum)
+	}
+
+	return newError(Fatal, -1, fmt.Sprintf("iRODS Verify Replicas Failed: %v, replicas %v don't match %v", report.Path, strings.Join(replicas, ", "), report.Expected)).withPath(report.Path).wrap(ErrChecksumMismatch)
+}
+
+// VerifyReplicas has the server verify every replica of the data object against its registered checksum, and
+// compares the checksums with expected, which can use any of the formats accepted by ParseChecksum. When expected is
+// empty, the checksum of the data object (see Chksum) is used. The report lists every replica, use its Err or
+// Mismatches functions to find the replicas that don't match. On connections using a Transport other than the
+// iRODS C API or PureGo, the server can't verify the replicas' data, and only their registered checksums are
+// compared.
+func (obj *DataObj) VerifyReplicas(expected string) (*ChecksumReport, error) {
+	if expected == "" {
+		var err error
+		if expected, err = obj.Chksum(); err != nil {
+			return nil, err
+		}
+	}
+
+	repls, err := obj.Replicas()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ChecksumReport{
+		Path:     obj.path,
+		Expected: expected,
+		Replicas: make([]*ReplicaChecksum, 0),
+	}
+
+	for _, repl := range repls {
+		r := &ReplicaChecksum{
+			ReplNum:    repl.num,
+			Resource:   repl.resource,
+			RescHier:   repl.rescHier,
+			ReplStatus: repl.status,
+			Checksum:   repl.checksum,
+		}
+
+		if sum, er := obj.con.verifyReplica(obj.path, repl.num); er == nil {
+			if sum != "" {
+				r.Checksum = sum
+			}
+		} else if !errors.Is(er, ErrNotSupported) {
+			r.Err = transportError(er, fmt.Sprintf("iRODS Verify Replicas Failed: %v, replica %v", obj.path, repl.num)).withPath(obj.path)
+		}
+
+		r.Mismatch = errors.Is(r.Err, ErrChecksumMismatch) || !ChecksumsEqual(r.Checksum, expected)
+
+		report.Replicas = append(report.Replicas, r)
+	}
+
+	return report, nil
+}
+
+// verifyReplica has the server verify replica replNum of the data object at path against its registered checksum,
+// and returns it. Errors wrap ErrNotSupported when the connection's Transport doesn't implement checksumVerifier.
+func (con *Connection) verifyReplica(path string, replNum int) (string, error) {
+	cv, ok := unwrapTransport(con.transport).(checksumVerifier)
+	if !ok {
+		return "", con.notSupported("Verify Replicas")
+	}
+
+	return cv.VerifyChecksum(path, replNum)
+}
@@ -0,0 +1,45 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+// VerifyChecksum has the server verify a replica against its registered checksum, see checksumVerifier
+func (t *cTransport) VerifyChecksum(path string, replNum int) (string, error) {
+	ccon, er := t.getCcon()
+	if er != nil {
+		return "", er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	return cVerifyChecksum(ccon, path, replNum)
+}
+
+// cVerifyChecksum has the server verify replica replNum of the data object at path, and returns its checksum
+func cVerifyChecksum(ccon *rcComm, path string, replNum int) (string, error) {
+	var (
+		err       *C.char
+		chksumOut *C.char
+	)
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	if status := C.gorods_verify_checksum_dataobject(cPath, C.int(replNum), &chksumOut, ccon, &err); status != 0 {
+		return "", NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.free(unsafe.Pointer(chksumOut))
+
+	return C.GoString(chksumOut), nil
+}
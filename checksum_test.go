@@ -0,0 +1,215 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// "hello world\n" in each format
+const (
+	helloMD5    = "6f5902ac237024bdd0c176cb93063dc4"
+	helloSHA256 = "sha2:qUiQTy8PR5uPgZdpSzAYSw0u0cHNKh7A+4XSmaGSpEc="
+)
+
+// badChecksumTransport reports a checksum that never matches
+type badChecksumTransport struct {
+	Transport
+}
+
+func (t *badChecksumTransport) Checksum(path string) (string, error) {
+	return "sha2:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", nil
+}
+
+func TestParseChecksum(t *testing.T) {
+	for sum, algorithm := range map[string]string{
+		helloMD5:          ChecksumMD5,
+		"md5:" + helloMD5: ChecksumMD5,
+		helloSHA256:       ChecksumSHA256,
+	} {
+		alg, digest, err := ParseChecksum(sum)
+		if err != nil || alg != algorithm {
+			t.Errorf("Expected %v to be a %v checksum, got %v (%v)", sum, algorithm, alg, err)
+			continue
+		}
+
+		if formatted := FormatChecksum(alg, digest); !ChecksumsEqual(formatted, sum) {
+			t.Errorf("Expected %v to be formatted back, got %v", sum, formatted)
+		}
+	}
+
+	for _, sum := range []string{"", "sha2:abc", "md5:xyz", "adler32:0a1b2c3d", "sha2:" + helloMD5} {
+		if _, _, err := ParseChecksum(sum); err == nil {
+			t.Errorf("Expected %q to be rejected", sum)
+		}
+	}
+
+	if !ChecksumsEqual(helloMD5, "md5:"+helloMD5) || ChecksumsEqual(helloMD5, helloSHA256) {
+		t.Error("Expected checksums to be compared by algorithm and digest")
+	}
+}
+
+func TestChecksummer(t *testing.T) {
+	sums, err := NewChecksummer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sums.Write([]byte("hello "))
+	sums.Write([]byte("world\n"))
+
+	if sums.Sum(ChecksumMD5) != helloMD5 || sums.Sum(ChecksumSHA256) != helloSHA256 || sums.Sum(ChecksumSHA512) != "" {
+		t.Errorf("Unexpected checksums %v, %v", sums.Sum(ChecksumMD5), sums.Sum(ChecksumSHA256))
+	}
+
+	if !sums.Matches("md5:"+helloMD5) || !sums.Matches(helloSHA256) || sums.Matches(helloSHA256[:10]) {
+		t.Error("Expected the checksums to match in any format")
+	}
+
+	if _, err := NewChecksummer("crc32"); err == nil {
+		t.Error("Expected an unsupported algorithm to be rejected")
+	}
+}
+
+func TestRangeChecksummer(t *testing.T) {
+	data := []byte("hello world\n")
+
+	// Ranges arrive out of order, the early ones are read back from the file
+	sums := newRangeChecksummer(bytes.NewReader(data))
+	sums.add(8, data[8:])
+	sums.add(4, data[4:8])
+	sums.add(0, data[:4])
+
+	if local, err := sums.result(int64(len(data))); err != nil || !local.Matches(helloSHA256) {
+		t.Errorf("Expected the checksum of the whole file, got %v", err)
+	}
+
+	sums = newRangeChecksummer(bytes.NewReader(data))
+	sums.add(4, data[4:])
+
+	if _, err := sums.result(int64(len(data))); err == nil {
+		t.Error("Expected a missing range to fail")
+	}
+}
+
+func TestPutVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorods-checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "hello.txt")
+	if err := ioutil.WriteFile(localPath, []byte("hello world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.Put(localPath, DataObjOptions{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !obj.Verify(helloSHA256) || obj.Verify(helloMD5) {
+		t.Error("Expected Verify to compare the sha2 checksum")
+	}
+
+	if _, err := home.PutParallel(localPath, DataObjOptions{Name: "parallel.txt"}, TransferOptions{PartSize: 5, Concurrency: 2, Verify: true}); err != nil {
+		t.Error(err)
+	}
+
+	downloaded := filepath.Join(dir, "downloaded.txt")
+	if err := obj.DownloadToParallel(downloaded, TransferOptions{PartSize: 5, Concurrency: 3, Verify: true}); err != nil {
+		t.Error(err)
+	}
+
+	con.transport = &badChecksumTransport{con.transport}
+
+	if _, err := home.Put(localPath, DataObjOptions{Name: "bad.txt", Verify: true}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected the upload to fail verification, got %v", err)
+	}
+
+	if err := obj.VerifyFile(downloaded); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected the download to fail verification, got %v", err)
+	}
+}
+
+func TestVerifyReplicas(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.CreateDataObj(DataObjOptions{Name: "hello.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := obj.Write([]byte("hello world\n")); err != nil {
+		t.Fatal(err)
+	}
+	obj.Close()
+
+	report, err := obj.VerifyReplicas("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Replicas) != 1 || report.Expected != helloSHA256 || report.Err() != nil {
+		t.Errorf("Expected a single matching replica, got %+v (%v)", report, report.Err())
+	}
+
+	if report, err = obj.VerifyReplicas(helloMD5); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Mismatches()) != 1 || !errors.Is(report.Err(), ErrChecksumMismatch) {
+		t.Errorf("Expected the replica not to match another checksum, got %v", report.Err())
+	}
+}
+
+// verifyingTransport has replica 0 fail verification on the server, with the checksum it computed
+type verifyingTransport struct {
+	Transport
+	verified []int
+}
+
+func (t *verifyingTransport) VerifyChecksum(path string, replNum int) (string, error) {
+	t.verified = append(t.verified, replNum)
+	return "sha2:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", nil
+}
+
+func TestVerifyReplicasTransport(t *testing.T) {
+	con, obj := memDataObj(t, "hello.txt", []byte("hello world\n"))
+	obj.Close()
+
+	verifying := &verifyingTransport{Transport: con.transport}
+	con.transport = verifying
+
+	report, err := obj.VerifyReplicas("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(verifying.verified) != 1 || verifying.verified[0] != 0 {
+		t.Errorf("Expected the Transport to verify replica 0, got %v", verifying.verified)
+	}
+
+	if len(report.Mismatches()) != 1 {
+		t.Errorf("Expected the checksum computed by the server not to match, got %+v", report.Replicas[0])
+	}
+}
@@ -3,10 +3,8 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
 	"fmt"
 	// "io/ioutil"
 	// "path/filepath"
@@ -16,55 +14,70 @@ import (
 	// "unsafe"
 )
 
-// Client structs are used to store connection options, and instatiate connections with those options
+// Client structs are used to store connection options, and instatiate connections with those options.
+// Connections are checked out of, and returned to, a *ConnectionPool so that each operation doesn't
+// require a new iRODS connection and login.
 type Client struct {
 	Options    *ConnectionOptions
 	ConnectErr error
+	Pool       *ConnectionPool
 }
 
-// OpenCollection will create a new connection using the previously configured iRODS client. It will execute the handler,
-// and close *Collection and *Collection automatically when your handler finishes execution.
+// OpenCollection will check out a connection from the client's pool. It will execute the handler,
+// and close *Collection automatically when your handler finishes execution. The connection is then returned to the pool.
 // Operations on a single connection are queued when shared between goroutines (iRODS C API
 // doesn't support concurrent operations on a single connection), so be sure to open up new connections
 // for long-running operations to prevent blocking between goroutines.
 func (cli *Client) OpenCollection(opts CollectionOptions, handler func(*Collection, *Connection)) error {
+	return cli.OpenCollectionContext(context.Background(), opts, handler)
+}
+
+// OpenCollectionContext is like OpenCollection, but gives up waiting for a connection from the pool, or for a session
+// while opening the collection, once ctx is done. ctx isn't passed to the handler.
+func (cli *Client) OpenCollectionContext(ctx context.Context, opts CollectionOptions, handler func(*Collection, *Connection)) error {
 	if cli.ConnectErr == nil {
-		if con, err := NewConnection(cli.Options); err == nil {
-			col, colEr := con.Collection(opts)
+		if con, err := cli.Pool.GetContext(ctx); err == nil {
+			col, colEr := con.collectionContext(ctx, opts)
 
 			if colEr != nil {
+				cli.Pool.Put(con)
 				return newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", colEr))
 			}
 
 			handler(col, con)
 
 			if er := col.Close(); er != nil {
-				return er
-			}
-			if er := con.Disconnect(); er != nil {
+				cli.Pool.Put(con)
 				return er
 			}
 
-			return nil
+			return cli.Pool.Put(con)
 		} else {
-			return newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", err))
+			return err
 		}
 	}
 
 	return newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", cli.ConnectErr))
 }
 
-// OpenDataObject will create a new connection using the previously configured iRODS client. It will execute the handler,
-// and close *DataObj and *Collection automatically when your handler finishes execution.
+// OpenDataObject will check out a connection from the client's pool. It will execute the handler,
+// and close *DataObj and *Collection automatically when your handler finishes execution. The connection is then returned to the pool.
 // Operations on a single connection are queued when shared between goroutines (iRODS C API
 // doesn't support concurrent operations on a single connection), so be sure to open up new connections
 // for long-running operations to prevent blocking between goroutines.
 func (cli *Client) OpenDataObject(path string, handler func(*DataObj, *Connection)) error {
+	return cli.OpenDataObjectContext(context.Background(), path, handler)
+}
+
+// OpenDataObjectContext is like OpenDataObject, but gives up waiting for a connection from the pool, or for a session
+// while opening the data object, once ctx is done. ctx isn't passed to the handler.
+func (cli *Client) OpenDataObjectContext(ctx context.Context, path string, handler func(*DataObj, *Connection)) error {
 	if cli.ConnectErr == nil {
-		if con, err := NewConnection(cli.Options); err == nil {
+		if con, err := cli.Pool.GetContext(ctx); err == nil {
 
-			obj, objEr := con.DataObject(path)
+			obj, objEr := con.dataObjectContext(ctx, path)
 			if objEr != nil {
+				cli.Pool.Put(con)
 				return objEr
 			}
 
@@ -72,41 +85,39 @@ func (cli *Client) OpenDataObject(path string, handler func(*DataObj, *Connectio
 
 			if obj.col != nil {
 				if er := obj.col.Close(); er != nil {
+					cli.Pool.Put(con)
 					return er
 				}
 			}
 
-			if er := con.Disconnect(); er != nil {
-				return er
-			}
-
-			return nil
+			return cli.Pool.Put(con)
 		} else {
-			return newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", err))
+			return err
 		}
 	}
 
 	return newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", cli.ConnectErr))
 }
 
-// OpenConnection will create a new connection using the previously configured iRODS client. It will execute the handler,
-// and close *Collection automatically when your handler finishes execution.
+// OpenConnection will check out a connection from the client's pool. It will execute the handler,
+// and return the connection to the pool when your handler finishes execution.
 // Operations on a single connection are queued when shared between goroutines (iRODS C API
 // doesn't support concurrent operations on a single connection), so be sure to open up new connections
 // for long-running operations to prevent blocking between goroutines.
 func (cli *Client) OpenConnection(handler func(*Connection)) error {
+	return cli.OpenConnectionContext(context.Background(), handler)
+}
+
+// OpenConnectionContext is like OpenConnection, but gives up waiting for a connection from the pool once ctx is done
+func (cli *Client) OpenConnectionContext(ctx context.Context, handler func(*Connection)) error {
 	if cli.ConnectErr == nil {
-		if con, err := NewConnection(cli.Options); err == nil {
+		if con, err := cli.Pool.GetContext(ctx); err == nil {
 
 			handler(con)
 
-			if er := con.Disconnect(); er != nil {
-				return er
-			}
-
-			return nil
+			return cli.Pool.Put(con)
 		} else {
-			return newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", err))
+			return err
 		}
 	}
 
@@ -118,16 +129,26 @@ func (cli *Client) OpenConnection(handler func(*Connection)) error {
 // When EnvironmentDefined is specified, the options stored in ~/.irods/irods_environment.json will be used.
 // When UserDefined is specified you must also pass Host, Port, Username, and Zone. Password
 // should be set unless using an anonymous user account with tickets.
+// The client's connection pool is configured with DefaultPoolOptions, use NewWithPool to change them.
 func New(opts ConnectionOptions) (*Client, error) {
+	return NewWithPool(opts, DefaultPoolOptions)
+}
+
+// NewWithPool is the same as New, except the connection pool is configured using poolOpts.
+// The test connection is kept in the pool for later use.
+func NewWithPool(opts ConnectionOptions, poolOpts PoolOptions) (*Client, error) {
 	cli := new(Client)
 
 	cli.Options = &opts
+	cli.Pool = NewConnectionPool(cli.Options, poolOpts)
 
-	if con, err := NewConnection(cli.Options); err != nil {
+	if con, err := cli.Pool.Get(); err != nil {
 		cli.ConnectErr = err
+		cli.Pool.Close()
 		return nil, err
 	} else {
-		if er := con.Disconnect(); er != nil {
+		if er := cli.Pool.Put(con); er != nil {
+			cli.Pool.Close()
 			return nil, er
 		}
 	}
@@ -135,6 +156,13 @@ func New(opts ConnectionOptions) (*Client, error) {
 	return cli, nil
 }
 
+// Close disconnects all idle connections held by the client's pool.
+func (cli *Client) Close() error {
+	return cli.Pool.Close()
+}
+
+// DisplayMemInfo prints the memory allocation statistics of the process, as reported by mallinfo. It prints nothing in
+// builds without cgo.
 func (cli *Client) DisplayMemInfo() {
-	C.display_mallinfo()
+	cDisplayMemInfo()
 }
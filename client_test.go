@@ -24,3 +24,21 @@ func TestClientConnection(t *testing.T) {
 		t.Fatal(oconErr)
 	}
 }
+
+func TestClientMemTransport(t *testing.T) {
+	client := memClient(t)
+
+	oconErr := client.OpenConnection(func(con *Connection) {
+		if _, ok := con.Transport().(*MemTransport); !ok {
+			t.Errorf("Expected the connection to use the MemTransport, got %T", con.Transport())
+		}
+
+		if typ, err := con.PathType("/tempZone/home/rods/hello.txt"); err != nil || typ != DataObjType {
+			t.Errorf("Expected hello.txt to be a data object, got %v (%v)", typ, err)
+		}
+	})
+
+	if oconErr != nil {
+		t.Fatal(oconErr)
+	}
+}
@@ -3,10 +3,8 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -14,7 +12,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unsafe"
 )
 
 // Collection structs contain information about single collections in an iRODS zone.
@@ -41,13 +38,14 @@ type Collection struct {
 	createTime time.Time
 	modifyTime time.Time
 
-	opened     bool
-	cColHandle C.collHandle_t
+	opened bool
 }
 
 // CollectionOptions stores options relating to collection initialization.
 // Path is the full path of the collection you're requesting.
 // Recursive if set to true will load sub collections into memory, until the end of the collection "tree" is found.
+// GetRepls lists every replica of the data objects as a separate entry, use DataObj.Replicas to inspect the replicas
+// of a single data object instead.
 type CollectionOptions struct {
 	Path      string
 	Recursive bool
@@ -80,8 +78,8 @@ func (obj *Collection) String() string {
 	return str
 }
 
-// initCollection initializes collection from *C.collEnt_t. This is used internally in the gorods package.
-func initCollection(data *C.collEnt_t, acol *Collection) (*Collection, error) {
+// initCollection initializes collection from a *TransportEntry. This is used internally in the gorods package.
+func initCollection(ctx context.Context, data *TransportEntry, acol *Collection) (*Collection, error) {
 
 	col := new(Collection)
 
@@ -89,19 +87,19 @@ func initCollection(data *C.collEnt_t, acol *Collection) (*Collection, error) {
 	col.typ = CollectionType
 	col.col = acol
 	col.con = col.col.con
-	col.path = C.GoString(data.collName)
+	col.path = data.Path
 	col.options = acol.options
 	col.recursive = acol.recursive
 	col.trimRepls = acol.trimRepls
 	col.parent = acol
 
-	col.ownerName = C.GoString(data.ownerName)
-	col.createTime = cTimeToTime(data.createTime)
-	col.modifyTime = cTimeToTime(data.modifyTime)
+	col.ownerName = data.OwnerName
+	col.createTime = data.CreateTime
+	col.modifyTime = data.ModifyTime
 
 	col.name = filepath.Base(col.path)
 
-	if usrs, err := col.con.Users(); err != nil {
+	if usrs, err := col.con.usersContext(ctx); err != nil {
 		return nil, err
 	} else {
 		if u := usrs.FindByName(col.ownerName, col.con); u != nil {
@@ -113,7 +111,7 @@ func initCollection(data *C.collEnt_t, acol *Collection) (*Collection, error) {
 
 	if col.recursive {
 
-		if er := col.init(); er != nil {
+		if er := col.initContext(ctx); er != nil {
 			return nil, er
 		}
 	}
@@ -139,57 +137,35 @@ func initCollection(data *C.collEnt_t, acol *Collection) (*Collection, error) {
 //
 // "modifyTime"
 func (col *Collection) Stat() (map[string]interface{}, error) {
+	return col.statContext(context.Background())
+}
 
-	var (
-		err        *C.char
-		statResult *C.rodsObjStat_t
-	)
-
-	path := C.CString(col.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	ccon := col.con.GetCcon()
-	defer col.con.ReturnCcon(ccon)
-
-	if status := C.gorods_stat_dataobject(path, &statResult, ccon, &err); status != 0 {
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Stat Failed: %v, %v", col.path, C.GoString(err)))
+// statContext is like Stat, but gives up waiting for a session once ctx is done
+func (col *Collection) statContext(ctx context.Context) (map[string]interface{}, error) {
+	entry, er := col.con.transportContext(ctx).Stat(col.path)
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("iRODS Stat Failed: %v", col.path)).withPath(col.path)
 	}
 
-	result := make(map[string]interface{})
-
-	result["objSize"] = int(statResult.objSize)
-	result["dataMode"] = int(statResult.dataMode)
-
-	result["dataId"] = C.GoString(&statResult.dataId[0])
-	result["chksum"] = C.GoString(&statResult.chksum[0])
-	result["ownerName"] = C.GoString(&statResult.ownerName[0])
-	result["ownerZone"] = C.GoString(&statResult.ownerZone[0])
-	result["createTime"] = C.GoString(&statResult.createTime[0])
-	result["modifyTime"] = C.GoString(&statResult.modifyTime[0])
-	//result["rescHier"] = C.GoString(&statResult.rescHier[0])
-
-	C.freeRodsObjStat(statResult)
-
-	return result, nil
+	return statToMap(entry), nil
 }
 
 // getCollection initializes specified collection located at startPath using gorods.connection.
 // Could be considered alias of Connection.collection()
-func getCollection(opts CollectionOptions, con *Connection) (*Collection, error) {
+func getCollection(ctx context.Context, opts CollectionOptions, con *Connection) (*Collection, error) {
 
 	col := new(Collection)
 
 	col.options = &opts
 	col.con = con
 
-	return setupCollection(col)
+	return setupCollection(ctx, col)
 
 }
 
 // getCollectionOpts initializes specified collection located at startPath using gorods.connection.
 // Could be considered alias of Connection.collection()
-func getCollectionOpts(opts CollectionOptions, readOpts CollectionReadOpts, con *Connection) (*Collection, error) {
+func getCollectionOpts(ctx context.Context, opts CollectionOptions, readOpts CollectionReadOpts, con *Connection) (*Collection, error) {
 
 	col := new(Collection)
 
@@ -198,10 +174,10 @@ func getCollectionOpts(opts CollectionOptions, readOpts CollectionReadOpts, con
 
 	col.readOpts = &readOpts
 
-	return setupCollection(col)
+	return setupCollection(ctx, col)
 }
 
-func setupCollection(col *Collection) (*Collection, error) {
+func setupCollection(ctx context.Context, col *Collection) (*Collection, error) {
 	col.opened = false
 	col.typ = CollectionType
 
@@ -211,7 +187,7 @@ func setupCollection(col *Collection) (*Collection, error) {
 	col.trimRepls = !col.options.GetRepls
 
 	if col.recursive {
-		if er := col.init(); er != nil {
+		if er := col.initContext(ctx); er != nil {
 			return nil, er
 		}
 	} else {
@@ -220,18 +196,13 @@ func setupCollection(col *Collection) (*Collection, error) {
 		}
 	}
 
-	if info, err := col.Stat(); err == nil {
+	if info, err := col.statContext(ctx); err == nil {
 		col.ownerName = info["ownerName"].(string)
 
-		cCreateTime := C.CString(info["createTime"].(string))
-		defer C.free(unsafe.Pointer(cCreateTime))
-		cModifyTime := C.CString(info["modifyTime"].(string))
-		defer C.free(unsafe.Pointer(cModifyTime))
-
-		col.createTime = cTimeToTime(cCreateTime)
-		col.modifyTime = cTimeToTime(cModifyTime)
+		col.createTime = timeStringToTime(info["createTime"].(string))
+		col.modifyTime = timeStringToTime(info["modifyTime"].(string))
 
-		if usrs, err := col.con.Users(); err != nil {
+		if usrs, err := col.con.usersContext(ctx); err != nil {
 			return nil, err
 		} else {
 			if u := usrs.FindByName(col.ownerName, col.con); u != nil {
@@ -249,29 +220,22 @@ func setupCollection(col *Collection) (*Collection, error) {
 
 // CreateCollection creates a collection in the specified collection using provided options. Returns the newly created collection object.
 func CreateCollection(name string, coll *Collection) (*Collection, error) {
+	return createCollectionContext(context.Background(), name, coll)
+}
 
-	var (
-		errMsg *C.char
-	)
+// createCollectionContext is like CreateCollection, but gives up waiting for a session once ctx is done
+func createCollectionContext(ctx context.Context, name string, coll *Collection) (*Collection, error) {
 
 	newColPath := coll.path + "/" + name
-	path := C.CString(newColPath)
-
-	defer C.free(unsafe.Pointer(path))
 
-	ccon := coll.con.GetCcon()
-
-	if status := C.gorods_create_collection(path, ccon, &errMsg); status != 0 {
-		coll.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Create Collection Failed: %v, Does the collection already exist?", C.GoString(errMsg)))
+	if er := coll.con.transportContext(ctx).CreateCollection(newColPath); er != nil {
+		return nil, transportError(er, "iRODS Create Collection Failed").withPath(newColPath)
 	}
 
-	coll.con.ReturnCcon(ccon)
-
 	//coll.Refresh()
 	//newCol := coll.Cd(name)
 
-	return coll.Con().Collection(CollectionOptions{
+	return coll.Con().collectionContext(ctx, CollectionOptions{
 		Path: newColPath,
 	})
 
@@ -279,6 +243,11 @@ func CreateCollection(name string, coll *Collection) (*Collection, error) {
 
 // init opens and reads collection information from iRODS if it hasn't been init'd already
 func (col *Collection) init() error {
+	return col.initContext(context.Background())
+}
+
+// initContext is like init, but gives up waiting for a session once ctx is done
+func (col *Collection) initContext(ctx context.Context) error {
 
 	if !col.hasInit {
 		if err := col.Open(); err != nil {
@@ -286,7 +255,7 @@ func (col *Collection) init() error {
 		}
 
 		//if col.readOpts == nil {
-		if err := col.ReadCollection(); err != nil {
+		if err := col.readCollectionContext(ctx); err != nil {
 			return err
 		}
 		// } else {
@@ -304,7 +273,12 @@ func (col *Collection) init() error {
 
 // Collections returns only the IRodsObjs that represent collections
 func (col *Collection) Collections() (response IRodsObjs, err error) {
-	if err = col.init(); err != nil {
+	return col.collectionsContext(context.Background())
+}
+
+// collectionsContext is like Collections, but gives up waiting for a session once ctx is done
+func (col *Collection) collectionsContext(ctx context.Context) (response IRodsObjs, err error) {
+	if err = col.initContext(ctx); err != nil {
 		return
 	}
 
@@ -319,7 +293,12 @@ func (col *Collection) Collections() (response IRodsObjs, err error) {
 
 // DataObjs returns only the data objects contained within the collection
 func (col *Collection) DataObjs() (response IRodsObjs, err error) {
-	if err = col.init(); err != nil {
+	return col.dataObjsContext(context.Background())
+}
+
+// dataObjsContext is like DataObjs, but gives up waiting for a session once ctx is done
+func (col *Collection) dataObjsContext(ctx context.Context) (response IRodsObjs, err error) {
+	if err = col.initContext(ctx); err != nil {
 		return
 	}
 
@@ -375,7 +354,12 @@ func (col *Collection) EachCollection(ittr func(obj *Collection)) error {
 
 // All returns generic interface slice containing both data objects and collections combined
 func (col *Collection) All() (IRodsObjs, error) {
-	if err := col.init(); err != nil {
+	return col.allContext(context.Background())
+}
+
+// allContext is like All, but gives up waiting for a session once ctx is done
+func (col *Collection) allContext(ctx context.Context) (IRodsObjs, error) {
+	if err := col.initContext(ctx); err != nil {
 		return col.dataObjects, err
 	}
 
@@ -417,27 +401,12 @@ func (col *Collection) SetInheritance(inherits bool, recursive bool) error {
 
 // Inheritance returns true or false, depending on the collection's inheritance setting
 func (col *Collection) Inheritance() (bool, error) {
-
-	var (
-		enabled C.int
-		err     *C.char
-	)
-
-	collName := C.CString(col.path)
-	defer C.free(unsafe.Pointer(collName))
-
-	ccon := col.con.GetCcon()
-	defer col.con.ReturnCcon(ccon)
-
-	if status := C.gorods_get_collection_inheritance(ccon, collName, &enabled, &err); status != 0 {
-		return false, newError(Fatal, status, fmt.Sprintf("iRODS Get Collection Inheritance Failed: %v", C.GoString(err)))
+	enabled, er := col.con.transport.Inheritance(col.path)
+	if er != nil {
+		return false, transportError(er, "iRODS Get Collection Inheritance Failed").withPath(col.path)
 	}
 
-	if int(enabled) > 0 {
-		return true, nil
-	}
-
-	return false, nil
+	return enabled, nil
 }
 
 // GrantAccess will add permissions (ACL) to the collection
@@ -457,34 +426,17 @@ func (col *Collection) Chmod(userOrGroup string, accessLevel int, recursive bool
 // designers#tempZone:read object]
 func (col *Collection) ACL() (ACLs, error) {
 
-	var (
-		result   C.goRodsACLResult_t
-		err      *C.char
-		zoneHint *C.char
-		collName *C.char
-	)
-
-	zone, zErr := col.con.LocalZone()
+	zone, zErr := col.con.pathZone(col.path)
 	if zErr != nil {
 		return nil, zErr
-	} else {
-		zoneHint = C.CString(zone.Name())
 	}
 
-	collName = C.CString(col.path)
-	defer C.free(unsafe.Pointer(collName))
-	defer C.free(unsafe.Pointer(zoneHint))
-
-	ccon := col.con.GetCcon()
-
-	if status := C.gorods_get_collection_acl(ccon, collName, &result, zoneHint, &err); status != 0 {
-		col.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Collection ACL Failed: %v", C.GoString(err)))
+	acls, er := col.con.transport.CollectionACL(col.path, zone)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Collection ACL Failed").withPath(col.path)
 	}
 
-	col.con.ReturnCcon(ccon)
-
-	return aclSliceToResponse(&result, col.con)
+	return aclsToResponse(acls, col.con)
 }
 
 // Size returns the total size in bytes of all contained data objects and collections, recursively
@@ -620,30 +572,13 @@ func (col *Collection) Trash(recursive bool) error {
 
 // Rm is equivalent to irm {-r} {-f}
 func (col *Collection) Rm(recursive bool, force bool) error {
-	var errMsg *C.char
-
-	path := C.CString(col.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	var (
-		cForce     C.int
-		cRecursive C.int
-	)
-
-	if force {
-		cForce = C.int(1)
-	}
-
-	if recursive {
-		cRecursive = C.int(1)
-	}
-
-	ccon := col.con.GetCcon()
-	defer col.con.ReturnCcon(ccon)
+	return col.rmContext(context.Background(), recursive, force)
+}
 
-	if status := C.gorods_rm(path, C.int(1), cRecursive, cForce, C.int(0), ccon, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Rm Collection Failed: %v", C.GoString(errMsg)))
+// rmContext is like Rm, but gives up waiting for a session once ctx is done
+func (col *Collection) rmContext(ctx context.Context, recursive bool, force bool) error {
+	if er := col.con.transportContext(ctx).Remove(col.path, true, recursive, force, false); er != nil {
+		return transportError(er, "iRODS Rm Collection Failed").withPath(col.path)
 	}
 
 	return nil
@@ -651,17 +586,13 @@ func (col *Collection) Rm(recursive bool, force bool) error {
 
 // RmTrash is used (sometimes internally) by GoRODS to delete items in the trash permanently. The collection's path should be in the trash collection.
 func (col *Collection) RmTrash() error {
-	var errMsg *C.char
-
-	path := C.CString(col.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	ccon := col.con.GetCcon()
-	defer col.con.ReturnCcon(ccon)
+	return col.rmTrashContext(context.Background())
+}
 
-	if status := C.gorods_rm(path, C.int(1), C.int(1), C.int(1), C.int(1), ccon, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS RmTrash Collection Failed: %v", C.GoString(errMsg)))
+// rmTrashContext is like RmTrash, but gives up waiting for a session once ctx is done
+func (col *Collection) rmTrashContext(ctx context.Context) error {
+	if er := col.con.transportContext(ctx).Remove(col.path, true, true, true, true); er != nil {
+		return transportError(er, "iRODS RmTrash Collection Failed").withPath(col.path)
 	}
 
 	return nil
@@ -679,12 +610,17 @@ func (col *Collection) Attribute(attr string) (Metas, error) {
 
 // Meta returns collection of all metadata AVU triples for Collection
 func (col *Collection) Meta() (*MetaCollection, error) {
-	if er := col.init(); er != nil {
+	return col.metaContext(context.Background())
+}
+
+// metaContext is like Meta, but gives up waiting for a session once ctx is done
+func (col *Collection) metaContext(ctx context.Context) (*MetaCollection, error) {
+	if er := col.initContext(ctx); er != nil {
 		return nil, er
 	}
 
 	if col.metaCol == nil {
-		if mc, err := newMetaCollection(col); err == nil {
+		if mc, err := newMetaCollection(ctx, col); err == nil {
 			col.metaCol = mc
 		} else {
 			return nil, err
@@ -718,14 +654,19 @@ func (col *Collection) DeleteMeta(attr string) (*MetaCollection, error) {
 
 // DownloadTo recursively downloads all data objects and collections contained within the collection, into the path specified
 func (col *Collection) DownloadTo(localPath string) error {
+	return col.downloadToContext(context.Background(), localPath)
+}
+
+// downloadToContext is like DownloadTo, but gives up waiting for a session once ctx is done
+func (col *Collection) downloadToContext(ctx context.Context, localPath string) error {
 
 	if dir, err := os.Stat(localPath); err == nil && dir.IsDir() {
 		if localPath[len(localPath)-1] != '/' {
 			localPath += "/"
 		}
-		if objs, er := col.DataObjs(); er == nil {
+		if objs, er := col.dataObjsContext(ctx); er == nil {
 			for _, obj := range objs {
-				if e := obj.DownloadTo(localPath + obj.Name()); e != nil {
+				if e := obj.(*DataObj).DownloadToContext(ctx, localPath+obj.Name()); e != nil {
 					return e
 				}
 			}
@@ -733,16 +674,16 @@ func (col *Collection) DownloadTo(localPath string) error {
 			return er
 		}
 
-		if cols, er := col.Collections(); er == nil {
+		if cols, er := col.collectionsContext(ctx); er == nil {
 			for _, col := range cols {
 
 				newDir := localPath + col.Name()
 
-				if e := os.Mkdir(newDir, 0777); e != nil {
+				if e := os.MkdirAll(newDir, 0777); e != nil {
 					return e
 				}
 
-				if e := col.DownloadTo(newDir); e != nil {
+				if e := col.(*Collection).downloadToContext(ctx, newDir); e != nil {
 					return e
 				}
 			}
@@ -757,59 +698,23 @@ func (col *Collection) DownloadTo(localPath string) error {
 	return nil
 }
 
-// Open connects to iRODS and sets the handle for Collection.
-// Usually called by Collection.init()
+// Open marks the Collection as opened. The contents are read from the Transport in a single call by ReadCollection,
+// so no handle is kept open between the two. Usually called by Collection.init()
 func (col *Collection) Open() error {
-	if !col.opened {
-		var (
-			errMsg     *C.char
-			cTrimRepls C.int
-		)
-
-		path := C.CString(col.path)
-
-		if col.trimRepls {
-			cTrimRepls = C.int(1)
-		} else {
-			cTrimRepls = C.int(0)
-		}
-
-		defer C.free(unsafe.Pointer(path))
-
-		ccon := col.con.GetCcon()
-		defer col.con.ReturnCcon(ccon)
-
-		if status := C.gorods_open_collection(path, cTrimRepls, &col.cColHandle, ccon, &errMsg); status != 0 {
-			return newError(Fatal, status, fmt.Sprintf("iRODS Open Collection Failed: %v, %v", col.path, C.GoString(errMsg)))
-		}
-
-		col.opened = true
-	}
+	col.opened = true
 
 	return nil
 }
 
-// Close closes the Collection connection and resets the handle
+// Close closes the data objects contained in the Collection, and marks it as closed
 func (col *Collection) Close() error {
-	var errMsg *C.char
-
 	for _, c := range col.dataObjects {
 		if err := c.Close(); err != nil {
 			return err
 		}
 	}
 
-	if col.opened {
-
-		ccon := col.con.GetCcon()
-		defer col.con.ReturnCcon(ccon)
-
-		if status := C.gorods_close_collection(&col.cColHandle, &errMsg); status != 0 {
-			return newError(Fatal, status, fmt.Sprintf("iRODS Close Collection Failed: %v, %v", col.path, C.GoString(errMsg)))
-		}
-
-		col.opened = false
-	}
+	col.opened = false
 
 	return nil
 }
@@ -817,6 +722,11 @@ func (col *Collection) Close() error {
 // CopyTo copies all collections and data objects contained withing the collection to the specified collection.
 // Accepts string or *Collection types.
 func (col *Collection) CopyTo(iRODSCollection interface{}) error {
+	return col.copyToContext(context.Background(), iRODSCollection)
+}
+
+// copyToContext is like CopyTo, but gives up waiting for a session once ctx is done
+func (col *Collection) copyToContext(ctx context.Context, iRODSCollection interface{}) error {
 
 	// Get reference to destination collection (just like MoveTo)
 	var (
@@ -849,7 +759,7 @@ func (col *Collection) CopyTo(iRODSCollection interface{}) error {
 	var colEr error
 
 	// load destination collection into memory
-	if destinationCollection, colEr = col.con.Collection(CollectionOptions{
+	if destinationCollection, colEr = col.con.collectionContext(ctx, CollectionOptions{
 		Path:      destinationCollectionString,
 		Recursive: false,
 	}); colEr != nil {
@@ -857,12 +767,12 @@ func (col *Collection) CopyTo(iRODSCollection interface{}) error {
 	}
 
 	// Create collection with same name in destination as sub-collection
-	if newCol, err := destinationCollection.CreateSubCollection(col.name); err == nil {
+	if newCol, err := createCollectionContext(ctx, col.name, destinationCollection); err == nil {
 
 		// loop through data objects, copy each to new sub-collection
-		if objs, er := col.DataObjs(); er == nil {
+		if objs, er := col.dataObjsContext(ctx); er == nil {
 			for _, obj := range objs {
-				if e := obj.CopyTo(newCol); e != nil {
+				if e := obj.(*DataObj).copyToContext(ctx, newCol); e != nil {
 					return e
 				}
 			}
@@ -871,9 +781,9 @@ func (col *Collection) CopyTo(iRODSCollection interface{}) error {
 		}
 
 		// Loop through collections -> run recursive copyTo util?
-		if cols, er := col.Collections(); er == nil {
+		if cols, er := col.collectionsContext(ctx); er == nil {
 			for _, aCol := range cols {
-				if er := aCol.CopyTo(newCol); er != nil {
+				if er := aCol.(*Collection).copyToContext(ctx, newCol); er != nil {
 					return er
 				}
 			}
@@ -881,7 +791,7 @@ func (col *Collection) CopyTo(iRODSCollection interface{}) error {
 			return er
 		}
 
-		newCol.Refresh() // <- is this required?
+		newCol.refreshContext(ctx) // <- is this required?
 
 	} else {
 		return err
@@ -1039,9 +949,13 @@ func (col *Collection) Backup(targetResource interface{}, opts DataObjOptions) e
 
 // MoveTo moves the collection to the specified collection. Supports Collection struct or string as input. Also refreshes the source and destination collections automatically to maintain correct state. Returns error.
 func (col *Collection) MoveTo(iRODSCollection interface{}) error {
+	return col.moveToContext(context.Background(), iRODSCollection)
+}
+
+// moveToContext is like MoveTo, but gives up waiting for a session once ctx is done
+func (col *Collection) moveToContext(ctx context.Context, iRODSCollection interface{}) error {
 
 	var (
-		err                         *C.char
 		destination                 string
 		destinationCollectionString string
 		destinationCollection       *Collection
@@ -1068,21 +982,10 @@ func (col *Collection) MoveTo(iRODSCollection interface{}) error {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Move Collection Failed, unknown variable type passed as collection"))
 	}
 
-	path := C.CString(col.path)
-	dest := C.CString(destination)
-
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(dest))
-
-	ccon := col.con.GetCcon()
-
-	if status := C.gorods_move_dataobject(path, dest, C.RENAME_COLL, ccon, &err); status != 0 {
-		col.con.ReturnCcon(ccon)
-		return newError(Fatal, status, fmt.Sprintf("iRODS Move Collection Failed: %v, D:%v, %v", col.path, destination, C.GoString(err)))
+	if er := col.con.transportContext(ctx).Move(col.path, destination, true); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Move Collection Failed: %v, D:%v", col.path, destination)).withPath(col.path)
 	}
 
-	col.con.ReturnCcon(ccon)
-
 	// Reload source collection, we are now detached... buggy?
 	//col.parent.Refresh()
 
@@ -1092,7 +995,7 @@ func (col *Collection) MoveTo(iRODSCollection interface{}) error {
 		var colEr error
 
 		// Can't find, load collection into memory
-		destinationCollection, colEr = col.con.Collection(CollectionOptions{
+		destinationCollection, colEr = col.con.collectionContext(ctx, CollectionOptions{
 			Path:      destinationCollectionString,
 			Recursive: false,
 		})
@@ -1105,7 +1008,7 @@ func (col *Collection) MoveTo(iRODSCollection interface{}) error {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Move Collection Failed, unknown variable type passed as collection"))
 	}
 
-	destinationCollection.Refresh()
+	destinationCollection.refreshContext(ctx)
 
 	// Reassign obj.col to destination collection
 	col.parent = destinationCollection
@@ -1118,27 +1021,20 @@ func (col *Collection) MoveTo(iRODSCollection interface{}) error {
 
 // Rename is equivalent to the Linux mv command except that the collection must stay within it's current collection (directory), returns error.
 func (col *Collection) Rename(newFileName string) error {
+	return col.renameContext(context.Background(), newFileName)
+}
+
+// renameContext is like Rename, but gives up waiting for a session once ctx is done
+func (col *Collection) renameContext(ctx context.Context, newFileName string) error {
 
 	if strings.Contains(newFileName, "/") {
 		return newError(Fatal, -1, fmt.Sprintf("Can't Rename DataObject, path detected in: %v", newFileName))
 	}
 
-	var err *C.char
-
-	source := col.path
 	destination := path.Dir(col.path) + "/" + newFileName
 
-	s := C.CString(source)
-	d := C.CString(destination)
-
-	defer C.free(unsafe.Pointer(s))
-	defer C.free(unsafe.Pointer(d))
-
-	ccon := col.con.GetCcon()
-	defer col.con.ReturnCcon(ccon)
-
-	if status := C.gorods_move_dataobject(s, d, C.RENAME_COLL, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Rename Collection Failed: %v, %v", col.path, C.GoString(err)))
+	if er := col.con.transportContext(ctx).Move(col.path, destination, true); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Rename Collection Failed: %v", col.path)).withPath(col.path)
 	}
 
 	col.name = newFileName
@@ -1151,7 +1047,12 @@ func (col *Collection) Rename(newFileName string) error {
 
 // Refresh is an alias of ReadCollection()
 func (col *Collection) Refresh() error {
-	return col.ReadCollection()
+	return col.refreshContext(context.Background())
+}
+
+// refreshContext is like Refresh, but gives up waiting for a session once ctx is done
+func (col *Collection) refreshContext(ctx context.Context) error {
+	return col.readCollectionContext(ctx)
 }
 
 type CollectionReadOpts struct {
@@ -1176,63 +1077,29 @@ func (col *Collection) ReadCollectionOpts(opts CollectionReadOpts) (CollectionRe
 		return errInfo, er
 	}
 
-	var colTotal, objTotal, colCnt, objCnt int
-	var info CollectionReadInfo
-	var cOpts C.goRodsQueryOpts_t
-
-	cOpts.limit = C.int(opts.Limit)
-	cOpts.offset = C.int(opts.Offset)
-
-	var colEnt C.collEnt_t
+	page, er := col.con.transport.ListPage(col.path, col.trimRepls, opts.Offset, opts.Limit)
+	if er != nil {
+		return errInfo, transportError(er, fmt.Sprintf("iRODS Open Collection Failed: %v", col.path)).withPath(col.path)
+	}
 
 	col.dataObjects = make([]IRodsObj, 0)
 
-	ccon := col.con.GetCcon()
-	for int(C.gorods_rclReadCollectionCols(ccon, &col.cColHandle, &colEnt, cOpts)) >= 0 {
-		if newCol, er := initCollection(&colEnt, col); er == nil {
+	for _, entry := range page.Collections {
+		if newCol, er := initCollection(context.Background(), entry, col); er == nil {
 			col.add(newCol)
 		} else {
 			return errInfo, er
 		}
 	}
 
-	colTotal = int(col.cColHandle.collSqlResult.totalRowCount)
-	colCnt = int(col.cColHandle.collSqlResult.rowCnt)
-
-	C.clearCollSqlResult(&col.cColHandle.collSqlResult)
-
-	newLimit := opts.Limit - colCnt
-	newOffset := opts.Offset
-
-	if colCnt == 0 && colTotal > 0 {
-		newOffset = opts.Offset - colTotal
+	for _, entry := range page.DataObjs {
+		col.add(initDataObj(entry, col, col.con))
 	}
 
-	if newLimit == 0 {
-		// We're done, don't grab any objects
-		info = CollectionReadInfo{colCnt, objCnt, (colCnt + objCnt), colTotal, objTotal, (colTotal + objTotal)}
-		col.readInfo = &info
-
-		col.con.ReturnCcon(ccon)
-
-		return info, col.Close()
-	} else {
-		cOpts.limit = C.int(newLimit)
-		cOpts.offset = C.int(newOffset)
-	}
+	colCnt := len(page.Collections)
+	objCnt := len(page.DataObjs)
 
-	for int(C.gorods_rclReadCollectionObjs(ccon, &col.cColHandle, &colEnt, cOpts)) >= 0 {
-		col.add(initDataObj(&colEnt, col, col.con))
-	}
-
-	objTotal = int(col.cColHandle.dataObjSqlResult.totalRowCount)
-	objCnt = int(col.cColHandle.dataObjSqlResult.rowCnt)
-
-	C.clearDataObjSqlResult(&col.cColHandle.dataObjSqlResult)
-
-	col.con.ReturnCcon(ccon)
-
-	info = CollectionReadInfo{colCnt, objCnt, (colCnt + objCnt), colTotal, objTotal, (colTotal + objTotal)}
+	info := CollectionReadInfo{colCnt, objCnt, (colCnt + objCnt), page.ColTotal, page.ObjTotal, (page.ColTotal + page.ObjTotal)}
 	col.readInfo = &info
 
 	return info, col.Close()
@@ -1245,6 +1112,11 @@ func (col *Collection) ReadInfo() *CollectionReadInfo {
 
 // ReadCollection reads data (overwrites) into col.dataObjects field.
 func (col *Collection) ReadCollection() error {
+	return col.readCollectionContext(context.Background())
+}
+
+// readCollectionContext is like ReadCollection, but gives up waiting for a session once ctx is done
+func (col *Collection) readCollectionContext(ctx context.Context) error {
 
 	if er := col.Open(); er != nil {
 		return er
@@ -1253,8 +1125,6 @@ func (col *Collection) ReadCollection() error {
 	var colTotal, objTotal, colCnt, objCnt, limit, offset int
 	var info CollectionReadInfo
 
-	var colEnt C.collEnt_t
-
 	col.dataObjects = make([]IRodsObj, 0)
 
 	if col.readOpts != nil {
@@ -1265,43 +1135,42 @@ func (col *Collection) ReadCollection() error {
 		offset = -1
 	}
 
-	ccon := col.con.GetCcon()
+	entries, er := col.con.transportContext(ctx).List(col.path, col.trimRepls)
+	if er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Open Collection Failed: %v", col.path)).withPath(col.path)
+	}
 
-	col.cColHandle.genQueryInp.options = C.RETURN_TOTAL_ROW_COUNT
+	for _, entry := range entries {
+		if entry.Type == CollectionType {
+			colTotal++
+		} else {
+			objTotal++
+		}
+	}
 
 	itrInx := 0
 	addCnt := 0
 
-	for int(C.rclReadCollection(ccon, &col.cColHandle, &colEnt)) >= 0 {
+	for _, entry := range entries {
 
 		var theObj IRodsObj
 
-		isCollection := (colEnt.objType != C.DATA_OBJ_T)
+		isCollection := (entry.Type == CollectionType)
 
 		if isCollection {
-			if newCol, er := initCollection(&colEnt, col); er == nil {
+			if newCol, er := initCollection(ctx, entry, col); er == nil {
 				theObj = newCol
 			} else {
 				return er
 			}
 		} else {
-			theObj = initDataObj(&colEnt, col, col.con)
+			theObj = initDataObj(entry, col, col.con)
 		}
 
 		if col.readOpts != nil && col.readOpts.Filter != nil {
-			col.con.ReturnCcon(ccon)
 			if !col.readOpts.Filter(theObj) {
-				ccon = col.con.GetCcon()
 				continue
-			} else {
-				ccon = col.con.GetCcon()
 			}
-
-		}
-
-		if colTotal == 0 && objTotal == 0 {
-			colTotal = int(col.cColHandle.collSqlResult.totalRowCount)
-			objTotal = int(col.cColHandle.dataObjSqlResult.totalRowCount)
 		}
 
 		if offset != -1 {
@@ -1330,8 +1199,6 @@ func (col *Collection) ReadCollection() error {
 
 	}
 
-	col.con.ReturnCcon(ccon)
-
 	info = CollectionReadInfo{colCnt, objCnt, (colCnt + objCnt), colTotal, objTotal, (colTotal + objTotal)}
 	col.readInfo = &info
 
@@ -1340,57 +1207,27 @@ func (col *Collection) ReadCollection() error {
 
 // Put reads the entire file from localPath and adds it the collection, using the options specified.
 func (col *Collection) Put(localPath string, opts DataObjOptions) (*DataObj, error) {
+	return col.putContext(context.Background(), localPath, opts)
+}
 
-	var (
-		errMsg   *C.char
-		force    int
-		resource *C.char
-	)
-
-	if opts.Force {
-		force = 1
-	} else {
-		force = 0
-	}
-
-	if opts.Resource != nil {
-		switch opts.Resource.(type) {
-		case string:
-			resource = C.CString(opts.Resource.(string))
-		case *Resource:
-			r := opts.Resource.(*Resource)
-			resource = C.CString(r.Name())
-		default:
-			return nil, newError(Fatal, -1, fmt.Sprintf("Wrong variable type passed in Resource field"))
-		}
-	} else {
-		resource = C.CString("")
-	}
+// putContext is like Put, but gives up waiting for a session once ctx is done
+func (col *Collection) putContext(ctx context.Context, localPath string, opts DataObjOptions) (*DataObj, error) {
 
 	if opts.Name == "" {
 		opts.Name = filepath.Base(localPath)
 	}
 
-	path := C.CString(col.path + "/" + opts.Name)
-	cLocalPath := C.CString(localPath)
-
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(resource))
-	defer C.free(unsafe.Pointer(cLocalPath))
-
-	ccon := col.con.GetCcon()
+	path := col.path + "/" + opts.Name
 
-	if status := C.gorods_put_dataobject(cLocalPath, path, C.rodsLong_t(opts.Size), C.int(opts.Mode), C.int(force), resource, ccon, &errMsg); status != 0 {
-		col.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Put DataObject Failed: %v, Does the file already exist?", C.GoString(errMsg)))
+	if err := putDataObj(ctx, localPath, path, opts, col.con); err != nil {
+		return nil, err
 	}
-	col.con.ReturnCcon(ccon)
 
-	if err := col.Refresh(); err != nil {
+	if err := col.refreshContext(ctx); err != nil {
 		return nil, err
 	}
 
-	if do, err := getDataObj(C.GoString(path), col.con); err != nil {
+	if do, err := getDataObj(ctx, path, col.con); err != nil {
 		return nil, err
 	} else {
 		return do, nil
@@ -1398,6 +1235,41 @@ func (col *Collection) Put(localPath string, opts DataObjOptions) (*DataObj, err
 
 }
 
+// putDataObj uploads the file at localPath to the iRODS path specified, without reading anything back
+// but its checksum when opts.Verify is set
+func putDataObj(ctx context.Context, localPath string, path string, opts DataObjOptions, con *Connection) error {
+
+	resource, er := resourceName(opts.Resource)
+	if er != nil {
+		return er
+	}
+
+	var localSum func() (*Checksummer, error)
+	if opts.Verify {
+		localSum = goChecksumFile(localPath)
+	}
+
+	if er := con.transportContext(ctx).Put(localPath, path, opts.Size, opts.Mode, opts.Force, resource); er != nil {
+		return transportError(er, "iRODS Put DataObject Failed").withPath(path)
+	}
+
+	if opts.Verify {
+		local, er := localSum()
+		if er != nil {
+			return er
+		}
+
+		remote, er := con.transportContext(ctx).Checksum(path)
+		if er != nil {
+			return transportError(er, fmt.Sprintf("iRODS Chksum DataObject Failed: %v", path)).withPath(path)
+		}
+
+		return compareChecksum(path, remote, localPath, local)
+	}
+
+	return nil
+}
+
 // CreateDataObj creates a data object within the collection using the options specified
 func (col *Collection) CreateDataObj(opts DataObjOptions) (*DataObj, error) {
 	return CreateDataObj(opts, col)
@@ -1405,7 +1277,7 @@ func (col *Collection) CreateDataObj(opts DataObjOptions) (*DataObj, error) {
 
 // CreateSubCollection creates a collection within the collection using the options specified
 func (col *Collection) CreateSubCollection(name string) (*Collection, error) {
-	return CreateCollection(name, col)
+	return createCollectionContext(context.Background(), name, col)
 }
 
 func (col *Collection) add(dataObj IRodsObj) *Collection {
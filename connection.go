@@ -2,30 +2,25 @@
  *** For more information please refer to the LICENSE.md file                                   ***/
 
 // Package gorods is a Golang binding for the iRODS C API (iRODS client library).
-// GoRods uses cgo to call iRODS client functions.
+// GoRods uses cgo to call iRODS client functions. When built without cgo (CGO_ENABLED=0), only PureGo connections
+// and custom Transports are available.
 package gorods
 
-// #cgo CFLAGS: -ggdb -I/usr/include/irods
-// #cgo LDFLAGS: -Wl,-rpath,"/opt/irods-externals/boost1.60.0-0/lib" -Wl,-rpath,"/opt/irods-externals/clang3.8-0/lib" -L/opt/irods-externals/clang3.8-0/lib -L/opt/irods-externals/boost1.60.0-0/lib /opt/irods-externals/boost1.60.0-0/lib/libboost_system.a /opt/irods-externals/boost1.60.0-0/lib/libboost_chrono.a /opt/irods-externals/jansson2.7-0/lib/libjansson.a -lirods_common -lirods_client -lc++ -lc++abi -lboost_regex -lboost_program_options -lboost_thread -lboost_filesystem -lz -lssl -lcrypto -ldl -lpthread -lm -lrt -lstdc++ -rdynamic -Wno-write-strings -DBOOST_SYSTEM_NO_DEPRECATED
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"unsafe"
 )
 
 // EnvironmentDefined and UserDefined constants are used when calling
 // gorods.New(ConnectionOptions{ Type: ... })
 // When EnvironmentDefined is specified, the options stored in ~/.irods/irods_environment.json will be used.
 // When UserDefined is specified you must also pass Host, Port, Username, and Zone.
-// Password should be set regardless.
+// Password should be set, unless it was cached in an .irodsA file by iinit or Iinit (see ConnectionOptions.AuthFile).
 const (
 	EnvironmentDefined = iota
 	UserDefined
@@ -230,11 +225,7 @@ func findRecursiveHelper(objs IRodsObjs, path string) IRodsObj {
 }
 
 func chmod(obj IRodsObj, user string, accessLevel int, recursive bool, includeZone bool) error {
-	var (
-		err        *C.char
-		cRecursive C.int
-		zone       string
-	)
+	var zone string
 
 	if accessLevel != Null && accessLevel != Read && accessLevel != Write && accessLevel != Own && accessLevel != Inherit && accessLevel != NoInherit {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Chmod DataObject Failed: accessLevel must be Null | Read | Write | Own"))
@@ -248,26 +239,8 @@ func chmod(obj IRodsObj, user string, accessLevel int, recursive bool, includeZo
 		}
 	}
 
-	cUser := C.CString(user)
-	cPath := C.CString(obj.Path())
-	cZone := C.CString(zone)
-	cAccessLevel := C.CString(getTypeString(accessLevel))
-	defer C.free(unsafe.Pointer(cUser))
-	defer C.free(unsafe.Pointer(cPath))
-	defer C.free(unsafe.Pointer(cZone))
-	defer C.free(unsafe.Pointer(cAccessLevel))
-
-	if recursive {
-		cRecursive = C.int(1)
-	} else {
-		cRecursive = C.int(0)
-	}
-
-	ccon := obj.Con().GetCcon()
-	defer obj.Con().ReturnCcon(ccon)
-
-	if status := C.gorods_chmod(ccon, cPath, cZone, cUser, cAccessLevel, cRecursive, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Chmod DataObject Failed: %v", C.GoString(err)))
+	if er := obj.Con().transport.Chmod(obj.Path(), zone, user, getTypeString(accessLevel), recursive); er != nil {
+		return transportError(er, "iRODS Chmod DataObject Failed").withPath(obj.Path())
 	}
 
 	return nil
@@ -288,6 +261,48 @@ type ConnectionOptions struct {
 	Ticket        string
 	FastInit      bool
 	Threads       int
+
+	// AuthFile is a scrambled password file written by iinit or Iinit. When neither Password nor PAMToken is set,
+	// the password is read from it. EnvironmentDefined connections read irods_authentication_file, or AuthFile(),
+	// when it's empty.
+	AuthFile string
+
+	// ClientServerPolicy requests SSL negotiation with the server: CSNegRequire, CSNegRefuse or CSNegDontCare.
+	// When it's empty, irods_environment.json (or the iRODS default, plain TCP) decides. The SSL and encryption
+	// options below override irods_environment.json for both UserDefined and EnvironmentDefined connections.
+	ClientServerPolicy string
+	// SSLCACertificateFile is a PEM bundle used to verify the server's certificate
+	SSLCACertificateFile string
+	// SSLVerifyServer is SSLVerifyHostname, SSLVerifyCert or SSLVerifyNone
+	SSLVerifyServer string
+	// Encryption parameters sent to the server once SSL is negotiated, zero values keep the defaults
+	EncryptionAlgorithm     string
+	EncryptionKeySize       int
+	EncryptionSaltSize      int
+	EncryptionNumHashRounds int
+
+	// Transport replaces the iRODS C API used to talk to the server, see Transport and MemTransport.
+	// Host, Port, Password and the authentication options are ignored when it's set.
+	Transport Transport
+
+	// PureGo talks to the server with the pure-Go irodsproto client instead of the iRODS C API. It only supports
+	// native authentication, EnvironmentDefined options are read with LoadEnvironment. Operations that still
+	// require the C API return ErrNotSupported.
+	PureGo bool
+
+	// Timeout bounds establishing the TCP connection, and each exchange with the server, of PureGo connections.
+	// A server that stops answering then fails the operation instead of blocking it forever. 0 means no timeout.
+	Timeout time.Duration
+
+	// Sessions is the number of iRODS sessions (rcComm_t handles) opened by connections using the iRODS C API,
+	// 1 when it's 0. Operations from different goroutines run concurrently on the free sessions, instead of waiting
+	// for each other, while data object handles stay on the session that opened them. Each session counts as an
+	// iRODS connection on the server.
+	Sessions int
+
+	// Retry enables automatic reconnection and retries when operations fail with a transient error, such as the
+	// server agent restarting or the socket dropping. See RetryPolicy. When it's nil failures are returned as is.
+	Retry *RetryPolicy
 }
 
 func (conOpts *ConnectionOptions) String() string {
@@ -296,13 +311,18 @@ func (conOpts *ConnectionOptions) String() string {
 
 // Connection structs hold information about the iRODS iCAT server, and the user who's connecting. It also contains a cache of opened Collections and DataObjs
 type Connection struct {
-	ccon       *C.rcComm_t
-	cconBuffer chan *C.rcComm_t
+	ccon       *rcComm
+	cconBuffer chan *rcComm
+	sessions   []*session
+	transport  Transport
 	users      Users
 	groups     Groups
 	zones      Zones
 	resources  Resources
 
+	// allSessions serializes eachSession
+	allSessions sync.Mutex
+
 	PAMToken   string
 	Connected  bool
 	Init       bool
@@ -329,29 +349,26 @@ func NewConnection(opts *ConnectionOptions) (*Connection, error) {
 }
 
 func (con *Connection) UserInfo() (map[string]string, error) {
+	return con.userInfoContext(context.Background())
+}
 
-	var cUsrInfo C.userInfo_t
-	var cErr *C.char
-
-	cName := C.CString(con.Options.Username)
-	defer C.free(unsafe.Pointer(cName))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
+// userInfoContext is like UserInfo, but gives up waiting for a session once ctx is done
+func (con *Connection) userInfoContext(ctx context.Context) (map[string]string, error) {
 
-	if status := C.gorods_iuserinfo(ccon, cName, &cUsrInfo, &cErr); status < 0 {
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS gorods_iuserinfo Failed: %v", C.GoString(cErr)))
+	info, er := con.transportContext(ctx).UserInfo(con.Options.Username)
+	if er != nil {
+		return nil, transportError(er, "iRODS gorods_iuserinfo Failed")
 	}
 
 	response := make(map[string]string)
 
-	response["username"] = C.GoString(&cUsrInfo.userName[0])
-	response["zone"] = C.GoString(&cUsrInfo.rodsZone[0])
-	response["type"] = C.GoString(&cUsrInfo.userType[0])
-	response["info"] = C.GoString(&cUsrInfo.userOtherInfo.userInfo[0])
-	response["comments"] = C.GoString(&cUsrInfo.userOtherInfo.userComments[0])
-	response["create"] = C.GoString(&cUsrInfo.userOtherInfo.userCreate[0])
-	response["modify"] = C.GoString(&cUsrInfo.userOtherInfo.userModify[0])
+	response["username"] = info["user_name"]
+	response["zone"] = info["zone_name"]
+	response["type"] = info["user_type_name"]
+	response["info"] = info["user_info"]
+	response["comments"] = info["r_comment"]
+	response["create"] = info["create_ts"]
+	response["modify"] = info["modify_ts"]
 
 	return response, nil
 }
@@ -365,187 +382,73 @@ func (con *Connection) InitCon() error {
 		// Should the con.Options.PAMToken be reset here?
 	}
 
-	var (
-		status    C.int
-		errMsg    *C.char
-		ipassword *C.char
-		opassword *C.char
-	)
-
-	// Are we passing env values?
-	if con.Options.Type == UserDefined {
-		host := C.CString(con.Options.Host)
-		port := C.int(con.Options.Port)
-		username := C.CString(con.Options.Username)
-		zone := C.CString(con.Options.Zone)
-
-		defer C.free(unsafe.Pointer(host))
-		defer C.free(unsafe.Pointer(username))
-		defer C.free(unsafe.Pointer(zone))
-
-		// BUG(jjacquay712): iRODS C API code outputs errors messages, need to implement connect wrapper (gorods_connect_env) from a lower level to suppress this output
-		// https://github.com/irods/irods/blob/master/iRODS/lib/core/src/rcConnect.cpp#L109
-		if status = C.gorods_connect_env(&con.ccon, host, port, username, zone, &errMsg); status != 0 {
-			return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: %v", C.GoString(errMsg)))
-		}
-	} else {
-
-		var cHost, cUsername, cZone *C.char
-		var cPort C.int
-
-		if status = C.gorods_connect(&con.ccon, &cHost, &cPort, &cUsername, &cZone, &errMsg); status != 0 {
-			return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: %v", C.GoString(errMsg)))
-		}
-
-		con.Options.Host = C.GoString(cHost)
-		con.Options.Port = int(cPort)
-		con.Options.Username = C.GoString(cUsername)
-		con.Options.Zone = C.GoString(cZone)
+	if err := con.connect(); err != nil {
+		return err
 	}
 
-	con.cconBuffer = make(chan *C.rcComm_t, 1)
-	con.cconBuffer <- con.ccon
-
-	con.Connected = true
+	if con.Options.Retry != nil {
+		con.transport = newRetryTransport(con, con.transport, *con.Options.Retry)
+	}
 
-	ipassword = C.CString(con.Options.Password)
-	defer C.free(unsafe.Pointer(ipassword))
+	return nil
+}
 
-	if con.Options.AuthType == 0 {
-		con.Options.AuthType = PasswordAuth // Options: PasswordAuth PAMAuth
+// connect opens a session with the server and logs in, using the Transport, the PureGo client or the iRODS C API
+func (con *Connection) connect() error {
+	if con.Options.Transport != nil {
+		return con.initTransport(con.Options.Transport)
 	}
 
-	if con.Options.PAMPassExpire == 0 {
-		con.Options.PAMPassExpire = 1 // Default expiration: 1 hour
+	if er := con.Options.validateSSL(); er != nil {
+		return er
 	}
 
-	var (
-		pamPassFile *os.File
-		pamFileErr  error
-		size        int64
-	)
-
-	if con.Options.AuthType == PAMAuth {
-
-		// Was a PAM token passed?
-		if con.Options.PAMToken != "" {
-
-			// Use it, pass directly to clientLoginWithPassword
-			opassword = C.CString(con.Options.PAMToken)
-			defer C.free(unsafe.Pointer(opassword))
-
-		} else if con.Options.PAMPassFile == "" { // Continue with auth using .Password (ipassword) option, Check to see if PAMPassFile option is not set
-
-			// It's not, fetch password and just keep in memory
-			if status = C.gorods_clientLoginPam(con.ccon, ipassword, C.int(con.Options.PAMPassExpire), &opassword, &errMsg); status != 0 {
-				return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: clientLoginPam error, invalid password?"))
-			}
-
-			defer C.free(unsafe.Pointer(opassword))
-
-		} else { // There is a PAM file path set, save password to FS for subsequent use
-
-			// Does the file/dir exist?
-			if finfo, err := os.Stat(con.Options.PAMPassFile); err == nil {
-				if !finfo.IsDir() {
-					// Open file here
-					pamPassFile, pamFileErr = os.OpenFile(con.Options.PAMPassFile, os.O_RDWR, 0666)
-					if pamFileErr != nil {
-						return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Problem opening PAMPassFile at %v", con.Options.PAMPassFile))
-					}
-
-					size = finfo.Size()
-
-				} else {
-					return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: PAMPassFile is a directory durp"))
-				}
-			} else {
-				// Create file here
-				pamPassFile, pamFileErr = os.Create(con.Options.PAMPassFile)
-				if pamFileErr != nil {
-					return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Problem creating PAMPassFile at %v", con.Options.PAMPassFile))
-				}
-
-			}
+	if er := con.Options.readAuthFile(); er != nil {
+		return er
+	}
 
-			// Is this an old password file?
-			if size > 0 {
-
-				fileBtz := make([]byte, size)
-				if _, er := pamPassFile.Read(fileBtz); er != nil {
-					return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Problem reading PAMPassFile at %v", con.Options.PAMPassFile))
-				}
-
-				fileStr := string(fileBtz)
-				fileSplit := strings.Split(fileStr, ":")
-				unixTimeStamp, _ := strconv.Atoi(fileSplit[0])
-				pamPassword := fileSplit[1]
-
-				now := int(time.Now().Unix())
-
-				// Check to see if the password has expired
-				if (unixTimeStamp + (con.Options.PAMPassExpire * 60)) <= now {
-					// we're expired, refresh
-					opassword, pamFileErr = con.fetchAndWritePAMPass(pamPassFile, ipassword)
-					if pamFileErr != nil {
-						return pamFileErr
-					}
-				} else {
-					// It's still good, use it
-					opassword = C.CString(pamPassword)
-				}
-				defer C.free(unsafe.Pointer(opassword))
+	if con.Options.PureGo {
+		t, er := newProtoTransport(con.Options)
+		if er != nil {
+			return transportError(er, "iRODS Connect Failed")
+		}
 
-			} else {
+		return con.initTransport(t)
+	}
 
-				// Nope, it's new. Write to the file
-				opassword, pamFileErr = con.fetchAndWritePAMPass(pamPassFile, ipassword)
-				if pamFileErr != nil {
-					return pamFileErr
-				}
+	if err := con.connectC(); err != nil {
+		return err
+	}
 
-				defer C.free(unsafe.Pointer(opassword))
-			}
+	if con.Options.Ticket != "" {
+		if err := con.SetTicket(con.Options.Ticket); err != nil {
+			return err
 		}
-	} else if con.Options.AuthType == PasswordAuth {
-		opassword = ipassword
 	}
 
-	if status = C.clientLoginWithPassword(con.ccon, opassword); status != 0 {
-
-		// if status == C.CAT_PASSWORD_EXPIRED {
-		// 	fmt.Printf("expired:%v\n", pamPassFile.Name())
-		// }
-
-		if con.Options.AuthType == PAMAuth {
-
-			// TODO
-			// If PAM token is set, and error is password expires, reset token, reinit?
+	if !con.Options.FastInit {
+		if err := con.init(); err != nil {
+			return err
+		}
+	}
 
-			if pamPassFile != nil {
+	return nil
+}
 
-				// Failure, clear out file for another try.
-				if er := pamPassFile.Truncate(int64(0)); er != nil {
-					return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: Unable to truncate PAMPassFile: %v", er))
-				}
+// initTransport sets up a connection using t (ConnectionOptions.Transport, or the PureGo client) instead of the iRODS C API
+func (con *Connection) initTransport(t Transport) error {
+	con.transport = t
+	con.Connected = true
 
-				return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: clientLoginWithPassword error, expired password?"))
-			}
+	if con.Options.Zone == "" {
+		zone, er := con.transport.LocalZone()
+		if er != nil {
+			return transportError(er, "iRODS Connect Failed")
 		}
 
-		return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: clientLoginWithPassword error, invalid password?"))
+		con.Options.Zone = zone
 	}
 
-	if con.Options.AuthType == PAMAuth {
-		con.PAMToken = C.GoString(opassword)
-	}
-
-	if status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: %v", C.GoString(errMsg)))
-	}
-
-	con.SetThreads(con.Options.Threads)
-
 	if con.Options.Ticket != "" {
 		if err := con.SetTicket(con.Options.Ticket); err != nil {
 			return err
@@ -561,74 +464,112 @@ func (con *Connection) InitCon() error {
 	return nil
 }
 
-// fetchAndWritePAMPass attempts to authenticate with the iCAT server using PAM.
-// If PAM authentication is successful, it writes the returned PAM authentication token to a file for subsequent use in connections.
-func (con *Connection) fetchAndWritePAMPass(pamPassFile *os.File, ipassword *C.char) (*C.char, error) {
+// getCcon checks out the connection handle for use in all iRODS operations. Basically a mutex for connections.
+// Other goroutines calling this function will block until the handle is returned with con.returnCcon, by the goroutine using it.
+// This prevents errors in the net code since concurrent API calls aren't supported over a single iRODS connection.
+// When ConnectionOptions.Sessions is more than 1, it returns whichever session is free first.
+// It returns an error wrapping ErrNotSupported when the connection uses another Transport than the iRODS C API.
+func (con *Connection) getCcon() (*rcComm, error) {
+	return con.getCconContext(context.Background())
+}
 
-	var (
-		opassword *C.char
-		errMsg    *C.char
-	)
+// getCconContext is like getCcon, but gives up waiting for the connection handle when ctx is cancelled or its deadline passes.
+func (con *Connection) getCconContext(ctx context.Context) (*rcComm, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	if status := C.gorods_clientLoginPam(con.ccon, ipassword, C.int(con.Options.PAMPassExpire), &opassword, &errMsg); status != 0 {
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Connect Failed: clientLoginPam error, invalid password?"))
+	if err := con.requireCcon("getCcon"); err != nil {
+		return nil, err
 	}
 
-	if er := pamPassFile.Truncate(0); er != nil {
-		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Unable to write new password to PAMPassFile"))
+	select {
+	case ccon := <-con.cconBuffer:
+		// A data object handle of the session may still be in use
+		if err := con.lockSession(ctx, ccon); err != nil {
+			con.cconBuffer <- ccon
+			return nil, err
+		}
+
+		return ccon, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+}
+
+// Transport returns the Transport the connection talks to iRODS through, whether it's the iRODS C API, the pure-Go
+// client or ConnectionOptions.Transport. Calls are retried when ConnectionOptions.Retry is set.
+func (con *Connection) Transport() Transport {
+	return con.transport
+}
 
-	pamPassFormat := strconv.Itoa(int(time.Now().Unix())) + ":" + C.GoString(opassword)
+// transportContext returns the Transport of the connection bound to ctx, so waiting for a session used by another
+// goroutine gives up once ctx is done
+func (con *Connection) transportContext(ctx context.Context) Transport {
+	return bindTransport(ctx, con.transport)
+}
 
-	if _, er := pamPassFile.WriteString(pamPassFormat); er != nil {
-		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Unable to write new password to PAMPassFile"))
+// hasCcon reports whether the connection talks to iRODS through the C API, rather than another Transport
+func (con *Connection) hasCcon() bool {
+	return con.cconBuffer != nil
+}
+
+// requireCcon returns an error wrapping ErrNotSupported when op needs the iRODS C API, but the connection uses another Transport
+func (con *Connection) requireCcon(op string) error {
+	if !con.hasCcon() {
+		return con.notSupported(op)
 	}
 
-	return opassword, nil
+	return nil
+}
+
+// notSupported returns an error wrapping ErrNotSupported, for op which the Transport of the connection can't run
+func (con *Connection) notSupported(op string) error {
+	return newError(Fatal, -1, fmt.Sprintf("iRODS %v Failed: not supported by %T", op, unwrapTransport(con.transport))).wrap(ErrNotSupported)
 }
 
-// GetCcon checks out the connection handle for use in all iRODS operations. Basically a mutex for connections.
-// Other goroutines calling this function will block until the handle is returned with con.ReturnCcon, by the goroutine using it.
-// This prevents errors in the net code since concurrent API calls aren't supported over a single iRODS connection.
-func (con *Connection) GetCcon() *C.rcComm_t {
-	return <-con.cconBuffer
+// innerTransport returns the Transport behind the RetryPolicy of the connection, bound to ctx. The optional
+// interfaces of a Transport (genQuerier, generalAdminer...) are looked up on it, within con.retry.
+func (con *Connection) innerTransport(ctx context.Context) Transport {
+	return bindTransport(ctx, unwrapTransport(con.transport))
 }
 
-// ReturnCcon returns the connection handle for use in other threads. Unlocks the mutex.
-func (con *Connection) ReturnCcon(ccon *C.rcComm_t) {
+// returnCcon returns the connection handle for use in other threads. Unlocks the mutex.
+func (con *Connection) returnCcon(ccon *rcComm) {
+	con.unlockSession(ccon)
 	con.cconBuffer <- ccon
 }
 
 // SetTicket is equivalent to using the -t flag with icommands
 func (con *Connection) SetTicket(t string) error {
-	var (
-		status C.int
-		errMsg *C.char
-	)
-
 	con.Options.Ticket = t
 
-	ticket := C.CString(t)
-	defer C.free(unsafe.Pointer(ticket))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status = C.gorods_set_session_ticket(ccon, ticket, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Set Ticket Failed: %v", C.GoString(errMsg)))
+	if err := con.requireCcon("Set Ticket"); err != nil {
+		return err
 	}
 
-	return nil
+	return con.eachSession(func(ccon *rcComm) error {
+		if er := cSetTicket(ccon, t); er != nil {
+			return transportError(er, "iRODS Set Ticket Failed")
+		}
+
+		return nil
+	})
 }
 
 // EmptyTrash
 func (con *Connection) EmptyTrash() error {
+	return con.emptyTrashContext(context.Background())
+}
+
+// emptyTrashContext is like EmptyTrash, but gives up waiting for a session once ctx is done
+func (con *Connection) emptyTrashContext(ctx context.Context) error {
 	var (
 		user string
 		zone string
 	)
 
-	if z, zErr := con.LocalZone(); zErr == nil {
+	if z, zErr := con.localZoneContext(ctx); zErr == nil {
 		zone = z.Name()
 	} else {
 		return zErr
@@ -642,20 +583,40 @@ func (con *Connection) EmptyTrash() error {
 
 	trashColPath := "/" + zone + "/trash/home/" + user
 
-	if trashCol, cErr := con.Collection(CollectionOptions{
+	if trashCol, cErr := con.collectionContext(ctx, CollectionOptions{
 		Path:      trashColPath,
 		Recursive: false,
 		GetRepls:  false,
 		SkipCache: true,
 	}); cErr == nil {
-		return trashCol.Each(func(obj IRodsObj) error {
-			return obj.RmTrash()
-		})
+		objs, er := trashCol.allContext(ctx)
+		if er != nil {
+			return er
+		}
+
+		for _, obj := range objs {
+			if er := rmTrashContext(ctx, obj); er != nil {
+				return er
+			}
+		}
+
+		return nil
 	} else {
 		return cErr
 	}
+}
 
-	return nil
+// rmTrashContext permanently removes obj, a data object or collection found in the trash, giving up waiting for a
+// session once ctx is done
+func rmTrashContext(ctx context.Context, obj IRodsObj) error {
+	switch o := obj.(type) {
+	case *Collection:
+		return o.rmTrashContext(ctx)
+	case *DataObj:
+		return o.rmTrashContext(ctx)
+	}
+
+	return obj.RmTrash()
 }
 
 type RegOptions struct {
@@ -669,69 +630,40 @@ type RegOptions struct {
 
 // RegPhysObj is equivalent to the ireg icommand
 func (con *Connection) RegPhysObj(opts RegOptions) error {
-	var (
-		cPhysPath     *C.char
-		cRodsPath     *C.char
-		cForce        C.int
-		cCollection   C.int
-		cReplica      C.int
-		cResourceName *C.char
-		cExcludeFiles *C.char
-	)
-
 	if opts.PhysicalFilePath == "" || opts.RodsPath == "" {
 		return newError(Fatal, -1, fmt.Sprintf("opts.PhysicalFilePath or opts.RodsPath not set"))
 	}
 
-	if physFile, err := os.Stat(opts.PhysicalFilePath); err == nil {
-		if physFile.Mode().IsDir() {
-			cCollection = C.int(1)
-		} else {
-			cCollection = C.int(0)
-		}
-	} else {
-		return newError(Fatal, -1, fmt.Sprintf("opts.PhysicalFilePath doesn't exist or we don't have the correct permissions to access"))
+	if err := con.requireCcon("RegPhysObj"); err != nil {
+		return err
 	}
 
-	cPhysPath = C.CString(opts.PhysicalFilePath)
-	cRodsPath = C.CString(opts.RodsPath)
-	cExcludeFiles = C.CString(opts.ExcludeFiles)
-
-	if opts.Resource != nil {
-		switch v := opts.Resource.(type) {
-		case string:
-			cResourceName = C.CString(v)
-		case *Resource:
-			cResourceName = C.CString(v.Name())
-		default:
-			return newError(Fatal, -1, fmt.Sprintf("opts.Resource type unexpected"))
-		}
+	physFile, err := os.Stat(opts.PhysicalFilePath)
+	if err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("opts.PhysicalFilePath doesn't exist or we don't have the correct permissions to access"))
 	}
 
-	defer func() {
-		C.free(unsafe.Pointer(cPhysPath))
-		C.free(unsafe.Pointer(cRodsPath))
-		C.free(unsafe.Pointer(cExcludeFiles))
-		C.free(unsafe.Pointer(cResourceName))
-	}()
+	var resourceStr string
 
-	if opts.Force {
-		cForce = C.int(1)
-	} else {
-		cForce = C.int(0)
+	switch v := opts.Resource.(type) {
+	case nil:
+	case string:
+		resourceStr = v
+	case *Resource:
+		resourceStr = v.Name()
+	default:
+		return newError(Fatal, -1, fmt.Sprintf("opts.Resource type unexpected"))
 	}
 
-	if opts.Replica {
-		cReplica = C.int(1)
-	} else {
-		cReplica = C.int(0)
+	ccon, er := con.getCcon()
+	if er != nil {
+		return er
 	}
 
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
+	defer con.returnCcon(ccon)
 
-	if status := C.gorods_phys_path_reg(ccon, cPhysPath, cRodsPath, cForce, cCollection, cReplica, cResourceName, cExcludeFiles); status < 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS rcDisconnect Failed"))
+	if er := cRegPhysObj(ccon, opts, physFile.Mode().IsDir(), resourceStr); er != nil {
+		return transportError(er, "iRODS RegPhysObj Failed").withPath(opts.RodsPath)
 	}
 
 	return nil
@@ -741,22 +673,47 @@ func (con *Connection) RegPhysObj(opts RegOptions) error {
 func (con *Connection) Disconnect() error {
 
 	if con.Connected {
-		for _, obj := range con.OpenedObjs {
-			if er := obj.Close(); er != nil {
-				return er
-			}
+		if er := con.closeOpenedObjs(); er != nil {
+			return er
 		}
 
-		//con.OpenedObjs = make(IRodsObjs, 0)
+		if er := con.transport.Disconnect(); er != nil {
+			return transportError(er, "iRODS rcDisconnect Failed")
+		}
+
+		con.Connected = false
+	}
 
-		ccon := con.GetCcon()
-		defer con.ReturnCcon(ccon)
+	return nil
+}
 
-		if status := C.rcDisconnect(ccon); status < 0 {
-			return newError(Fatal, status, fmt.Sprintf("iRODS rcDisconnect Failed"))
+// closeOpenedObjs closes and forgets the collections and data objects opened on the connection.
+// Used when a connection is disconnected or returned to a *ConnectionPool.
+func (con *Connection) closeOpenedObjs() error {
+	for _, obj := range con.OpenedObjs {
+		if er := obj.Close(); er != nil {
+			return er
 		}
+	}
 
-		con.Connected = false
+	con.OpenedObjs = make(IRodsObjs, 0)
+
+	return nil
+}
+
+// Ping performs a cheap round-trip to the iRODS server to verify the connection is still usable.
+func (con *Connection) Ping() error {
+	return con.pingContext(context.Background())
+}
+
+// pingContext is like Ping, but gives up waiting for a session once ctx is done
+func (con *Connection) pingContext(ctx context.Context) error {
+	if !con.Connected {
+		return newError(Fatal, -1, "iRODS Ping Failed: not connected")
+	}
+
+	if er := con.transportContext(ctx).Ping(); er != nil {
+		return transportError(er, "iRODS Ping Failed")
 	}
 
 	return nil
@@ -765,30 +722,25 @@ func (con *Connection) Disconnect() error {
 // String provides connection status and options provided during initialization (gorods.New)
 func (obj *Connection) String() string {
 
-	if obj.Options.Type == UserDefined {
+	if obj.Options.Type == UserDefined || !obj.hasCcon() {
 		return fmt.Sprintf("Host: %v@%v:%v/%v, Connected: %v\n", obj.Options.Username, obj.Options.Host, obj.Options.Port, obj.Options.Zone, obj.Connected)
 	}
 
-	var (
-		username *C.char
-		host     *C.char
-		port     C.int
-		zone     *C.char
-	)
-
-	defer C.free(unsafe.Pointer(username))
-	defer C.free(unsafe.Pointer(host))
-	defer C.free(unsafe.Pointer(zone))
-
-	if status := C.irods_env(&username, &host, &port, &zone); status != 0 {
-		panic(newError(Fatal, status, fmt.Sprintf("iRODS getEnv Failed")))
+	username, host, port, zone, err := cIRODSEnv()
+	if err != nil {
+		panic(transportError(err, "iRODS getEnv Failed"))
 	}
 
-	return fmt.Sprintf("Host: %v@%v:%v/%v, Connected: %v\n", C.GoString(username), C.GoString(host), int(port), C.GoString(zone), obj.Connected)
+	return fmt.Sprintf("Host: %v@%v:%v/%v, Connected: %v\n", username, host, port, zone, obj.Connected)
 }
 
 // Collection initializes and returns an existing iRODS collection using the specified path
 func (con *Connection) Collection(opts CollectionOptions) (*Collection, error) {
+	return con.collectionContext(context.Background(), opts)
+}
+
+// collectionContext is like Collection, but gives up waiting for a session once ctx is done
+func (con *Connection) collectionContext(ctx context.Context, opts CollectionOptions) (*Collection, error) {
 
 	startPath := opts.Path
 	recursive := opts.Recursive
@@ -798,7 +750,7 @@ func (con *Connection) Collection(opts CollectionOptions) (*Collection, error) {
 		//if collection := con.OpenedObjs.FindRecursive(startPath); true {
 
 		// Load collection, no cache found
-		if col, err := getCollection(opts, con); err == nil {
+		if col, err := getCollection(ctx, opts, con); err == nil {
 			con.OpenedObjs = append(con.OpenedObjs, col)
 
 			return col, nil
@@ -812,7 +764,7 @@ func (con *Connection) Collection(opts CollectionOptions) (*Collection, error) {
 		if recursive {
 			col.recursive = true
 
-			if er := col.init(); er != nil {
+			if er := col.initContext(ctx); er != nil {
 				return nil, er
 			}
 		}
@@ -824,7 +776,7 @@ func (con *Connection) Collection(opts CollectionOptions) (*Collection, error) {
 
 // CollectionOpts initializes and returns an existing iRODS collection using the specified path
 func (con *Connection) CollectionOpts(opts CollectionOptions, readOpts CollectionReadOpts) (*Collection, error) {
-	if col, err := getCollectionOpts(opts, readOpts, con); err == nil {
+	if col, err := getCollectionOpts(context.Background(), opts, readOpts, con); err == nil {
 		return col, nil
 	} else {
 		return nil, err
@@ -833,113 +785,77 @@ func (con *Connection) CollectionOpts(opts CollectionOptions, readOpts Collectio
 
 // PathType returns DataObjType, CollectionType, or -1 (error) for the iRODS path specified
 func (con *Connection) PathType(p string) (int, error) {
-	var (
-		err        *C.char
-		statResult *C.rodsObjStat_t
-	)
-
-	path := C.CString(p)
-	defer C.free(unsafe.Pointer(path))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-	defer C.freeRodsObjStat(statResult)
+	return con.pathTypeContext(context.Background(), p)
+}
 
-	if status := C.gorods_stat_dataobject(path, &statResult, ccon, &err); status != 0 {
-		return -1, newError(Fatal, status, fmt.Sprintf("iRODS Stat Failed: %v, %v", p, C.GoString(err)))
+// pathTypeContext is like PathType, but gives up waiting for a session once ctx is done
+func (con *Connection) pathTypeContext(ctx context.Context, p string) (int, error) {
+	entry, er := con.transportContext(ctx).Stat(p)
+	if er != nil {
+		return -1, transportError(er, fmt.Sprintf("iRODS Stat Failed: %v", p)).withPath(p)
 	}
 
-	if statResult.objType == C.DATA_OBJ_T {
-		return DataObjType, nil
-	} else if statResult.objType == C.COLL_OBJ_T {
-		return CollectionType, nil
+	if entry.Type == DataObjType || entry.Type == CollectionType {
+		return entry.Type, nil
 	}
 
 	return -1, newError(Fatal, -1, "Unknown type")
 
 }
 
-// SetThreads changes the ccon.transStat.numThreads value. Not sure if it does anything.
+// SetThreads changes the ccon.transStat.numThreads value, which is passed to the server as the number of threads
+// it may use for put, open and replication requests. For client side parallel transfers see TransferOptions.
+// It has no effect when the connection uses another Transport than the iRODS C API.
 func (con *Connection) SetThreads(num int) {
-	con.ccon.transStat.numThreads = C.int(num)
+	for _, s := range con.sessions {
+		cSetThreads(s.ccon, num)
+	}
 }
 
 // Threads returns ccon.transStat.numThreads
 func (con *Connection) Threads() int {
-	return int(con.ccon.transStat.numThreads)
+	if con.ccon == nil {
+		return 0
+	}
+	return cThreads(con.ccon)
 }
 
 // IQuestSQL executes a specific query on the iCAT server and returns a multi-dimensional string slice of results.
 // Equivalent to: "iquest --sql {specificQuery} {queryArgs}..."
 func (con *Connection) IQuestSQL(specificQuery string, queryArgs ...string) ([][]string, error) {
-	var (
-		result C.goRodsGenQueryResult_t
-		err    *C.char
-	)
+	return con.iquestSQLContext(context.Background(), specificQuery, queryArgs...)
+}
 
-	result.rowSize = C.int(0)
-	result.attrSize = C.int(0)
+// iquestSQLContext is like IQuestSQL, but gives up waiting for a session once ctx is done
+func (con *Connection) iquestSQLContext(ctx context.Context, specificQuery string, queryArgs ...string) ([][]string, error) {
+	if er := con.requireCcon("IQuestSQL"); er != nil {
+		return nil, er
+	}
 
-	z, zErr := con.LocalZone()
+	z, zErr := con.localZoneContext(ctx)
 	if zErr != nil {
 		return nil, zErr
 	}
 
-	cQueryString := C.CString(specificQuery)
-	cZoneName := C.CString(z.Name())
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cQueryString))
-
-	queryArgsLen := len(queryArgs)
-	cQueryArgs := make([]*C.char, queryArgsLen)
-
-	for i := range queryArgs {
-		qa := C.CString(queryArgs[i])
-		cQueryArgs[i] = qa
-		defer C.free(unsafe.Pointer(qa))
-	}
-
-	// Solve index out of range issue for 0 args
-	if queryArgsLen == 0 {
-		blankStr := C.CString("")
-		defer C.free(unsafe.Pointer(blankStr))
+	var response [][]string
 
-		cQueryArgs = append(cQueryArgs, blankStr)
-	}
-
-	ccon := con.GetCcon()
-
-	if status := C.gorods_exec_specific_query(ccon, cQueryString, (**C.char)(unsafe.Pointer(&cQueryArgs[0])), C.int(queryArgsLen), cZoneName, &result, &err); status != 0 {
-		con.ReturnCcon(ccon)
-		if status == C.CAT_NO_ROWS_FOUND {
-			return make([][]string, 0), nil
-		} else {
-			return nil, newError(Fatal, status, fmt.Sprintf("iRODS iquest Failed: %v", C.GoString(err)))
+	er := con.retryContext(ctx, false, func() (er error) {
+		ccon, er := con.getCconContext(ctx)
+		if er != nil {
+			return
 		}
-	}
-
-	con.ReturnCcon(ccon)
-	defer C.gorods_free_gen_query_result(&result)
-
-	unsafeRows := unsafe.Pointer(result.result)
-	rowsLen := int(result.rowSize)
-	attrLen := int(result.attrSize)
-
-	response := make([][]string, 0)
-
-	// Convert C array to slice
-	rowSlice := (*[1 << 30]**C.char)(unsafeRows)[:rowsLen:rowsLen]
 
-	for _, val := range rowSlice {
+		defer con.returnCcon(ccon)
 
-		row := make([]string, attrLen)
-		attrSlice := (*[1 << 30]*C.char)(unsafe.Pointer(val))[:attrLen:attrLen]
+		response, er = cSpecificQuery(ccon, specificQuery, queryArgs, z.Name())
+		return
+	})
 
-		for i, attr := range attrSlice {
-			row[i] = C.GoString(attr)
+	if er != nil {
+		if errors.Is(er, ErrNoRowsFound) {
+			return make([][]string, 0), nil
 		}
-
-		response = append(response, row)
+		return nil, transportError(er, "iRODS iquest Failed")
 	}
 
 	return response, nil
@@ -948,70 +864,87 @@ func (con *Connection) IQuestSQL(specificQuery string, queryArgs ...string) ([][
 // IQuest accepts a SQL query fragment, returns results in slice of maps
 // If upperCase is true, all records will be matched using their uppercase representation.
 func (con *Connection) IQuest(query string, upperCase bool) ([]map[string]string, error) {
-	var (
-		result C.goRodsHashResult_t
-		err    *C.char
-		upper  int
-	)
+	return con.iquestContext(context.Background(), query, upperCase)
+}
 
-	result.size = C.int(0)
+// iquestContext is like IQuest, but gives up waiting for a session once ctx is done
+func (con *Connection) iquestContext(ctx context.Context, query string, upperCase bool) ([]map[string]string, error) {
+	if er := con.requireCcon("IQuest"); er != nil {
+		return nil, er
+	}
 
-	z, zErr := con.LocalZone()
+	z, zErr := con.localZoneContext(ctx)
 	if zErr != nil {
 		return nil, zErr
 	}
 
-	if upperCase {
-		upper = 1
-	}
+	var response []map[string]string
+
+	er := con.retryContext(ctx, false, func() (er error) {
+		ccon, er := con.getCconContext(ctx)
+		if er != nil {
+			return
+		}
 
-	cQueryString := C.CString(query)
-	cZoneName := C.CString(z.Name())
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cQueryString))
+		defer con.returnCcon(ccon)
 
-	ccon := con.GetCcon()
+		response, er = cIQuest(ccon, query, upperCase, z.Name())
+		return
+	})
 
-	if status := C.gorods_iquest_general(ccon, cQueryString, C.int(0), C.int(upper), cZoneName, &result, &err); status != 0 {
-		con.ReturnCcon(ccon)
-		if status == C.CAT_NO_ROWS_FOUND {
+	if er != nil {
+		if errors.Is(er, ErrNoRowsFound) {
 			return make([]map[string]string, 0), nil
-		} else {
-			return nil, newError(Fatal, status, fmt.Sprintf("iRODS iquest Failed: %v", C.GoString(err)))
 		}
+		return nil, transportError(er, "iRODS iquest Failed")
 	}
 
-	con.ReturnCcon(ccon)
-	defer C.gorods_free_map_result(&result)
+	return response, nil
+}
 
-	unsafeKeyArr := unsafe.Pointer(result.hashKeys)
-	keyArrLen := int(result.keySize)
+// Query validates and runs a GenQuery built with NewQuery, returning the rows in the order of the selected columns.
+// Queries are sent to the local zone, unless a zone was set using Query.Zone.
+func (con *Connection) Query(q *Query) (*QueryResult, error) {
+	return con.queryContext(context.Background(), q)
+}
 
-	unsafeValArr := unsafe.Pointer(result.hashValues)
-	valArrLen := int(result.size) * keyArrLen
+// queryContext is like Query, but gives up waiting for a session once ctx is done
+func (con *Connection) queryContext(ctx context.Context, q *Query) (*QueryResult, error) {
+	if er := q.Validate(); er != nil {
+		return nil, er
+	}
 
-	response := make([]map[string]string, int(result.size))
+	zone, zErr := con.queryZone(q)
+	if zErr != nil {
+		return nil, zErr
+	}
 
-	// Convert C array to slice
-	keySlice := (*[1 << 30]*C.char)(unsafeKeyArr)[:keyArrLen:keyArrLen]
-	valSlice := (*[1 << 30]*C.char)(unsafeValArr)[:valArrLen:valArrLen]
+	var response *QueryResult
 
-	for n, val := range valSlice {
-		mapInx := n / keyArrLen
+	er := con.retryContext(ctx, false, func() (er error) {
+		response, er = con.genQuery(ctx, q, zone)
+		return
+	})
+	if er != nil {
+		return nil, er
+	}
 
-		var key string
+	return response, nil
+}
 
-		if n == 0 {
-			key = C.GoString(keySlice[0])
-		} else {
-			key = C.GoString(keySlice[int(math.Mod(float64(n), float64(keyArrLen)))])
-		}
+// genQuery runs q in zone, when the Transport of the connection implements genQuerier
+func (con *Connection) genQuery(ctx context.Context, q *Query, zone string) (*QueryResult, error) {
+	gq, ok := con.innerTransport(ctx).(genQuerier)
+	if !ok {
+		return nil, con.notSupported("Query")
+	}
 
-		if response[mapInx] == nil {
-			response[mapInx] = make(map[string]string)
+	response, er := gq.GenQuery(q, zone)
+	if er != nil {
+		if cErr := ctx.Err(); cErr != nil {
+			return nil, cErr
 		}
-
-		response[mapInx][key] = C.GoString(val)
+		return nil, transportError(er, fmt.Sprintf("iRODS Query Failed: %v", q))
 	}
 
 	return response, nil
@@ -1019,75 +952,87 @@ func (con *Connection) IQuest(query string, upperCase bool) ([]map[string]string
 
 // DataObject directly returns a specific DataObj without the need to traverse collections. Must pass full path of data object.
 func (con *Connection) DataObject(dataObjPath string) (dataobj *DataObj, err error) {
+	return con.dataObjectContext(context.Background(), dataObjPath)
+}
+
+// dataObjectContext is like DataObject, but gives up waiting for a session once ctx is done
+func (con *Connection) dataObjectContext(ctx context.Context, dataObjPath string) (dataobj *DataObj, err error) {
 	// We use the caching mechanism from Collection()
-	dataobj, err = getDataObj(dataObjPath, con)
+	dataobj, err = getDataObj(ctx, dataObjPath, con)
 
 	return
 }
 
 // QueryMeta queries both data objects and collections for matching metadata. Returns IRodsObjs.
 func (con *Connection) QueryMeta(qString string) (response IRodsObjs, err error) {
+	return con.queryMetaContext(context.Background(), qString)
+}
 
-	var errMsg *C.char
-	var query *C.char = C.CString(qString)
-	var colresult C.goRodsPathResult_t
-	var dresult C.goRodsPathResult_t
-	var ccon *C.rcComm_t
-
-	defer C.free(unsafe.Pointer(query))
-	defer C.freeGoRodsPathResult(&colresult)
-	defer C.freeGoRodsPathResult(&dresult)
+// queryMetaContext is like QueryMeta, but gives up waiting for a session once ctx is done
+func (con *Connection) queryMetaContext(ctx context.Context, qString string) (response IRodsObjs, err error) {
 
-	ccon = con.GetCcon()
-	if status := C.gorods_query_collection(ccon, query, &colresult, &errMsg); status != 0 {
-		con.ReturnCcon(ccon)
-		err = newError(Fatal, status, fmt.Sprintf(C.GoString(errMsg)))
+	if err = con.requireCcon("QueryMeta"); err != nil {
 		return
 	}
-	con.ReturnCcon(ccon)
 
-	size := int(colresult.size)
+	var colPaths, objPaths []string
 
-	if size > 0 {
-		slice := (*[1 << 30]*C.char)(unsafe.Pointer(colresult.pathArr))[:size:size]
+	er := con.retryContext(ctx, false, func() (er error) {
+		ccon, er := con.getCconContext(ctx)
+		if er != nil {
+			return
+		}
 
-		for _, colString := range slice {
+		defer con.returnCcon(ccon)
 
-			opts := CollectionOptions{
-				Path:      C.GoString(colString),
-				Recursive: false,
-			}
+		colPaths, er = cQueryMetaCollections(ccon, qString)
+		return
+	})
 
-			if c, er := con.Collection(opts); er == nil {
-				response = append(response, c)
-			} else {
-				err = er
-				return
-			}
-		}
+	if er != nil {
+		err = transportError(er, "iRODS QueryMeta Failed")
+		return
 	}
 
-	ccon = con.GetCcon()
-	if status := C.gorods_query_dataobj(ccon, query, &dresult, &errMsg); status != 0 {
-		con.ReturnCcon(ccon)
-		err = newError(Fatal, status, fmt.Sprintf(C.GoString(errMsg)))
-		return
+	for _, colPath := range colPaths {
+
+		opts := CollectionOptions{
+			Path:      colPath,
+			Recursive: false,
+		}
+
+		if c, er := con.collectionContext(ctx, opts); er == nil {
+			response = append(response, c)
+		} else {
+			err = er
+			return
+		}
 	}
-	con.ReturnCcon(ccon)
 
-	size = int(dresult.size)
+	er = con.retryContext(ctx, false, func() (er error) {
+		ccon, er := con.getCconContext(ctx)
+		if er != nil {
+			return
+		}
 
-	if size > 0 {
-		slice := (*[1 << 30]*C.char)(unsafe.Pointer(dresult.pathArr))[:size:size]
+		defer con.returnCcon(ccon)
 
-		for _, colString := range slice {
+		objPaths, er = cQueryMetaDataObjs(ccon, qString)
+		return
+	})
 
-			if c, er := con.DataObject(C.GoString(colString)); er == nil {
-				response = append(response, c)
-			} else {
-				err = er
-				return
-			}
+	if er != nil {
+		err = transportError(er, "iRODS QueryMeta Failed")
+		return
+	}
+
+	for _, objPath := range objPaths {
+
+		if c, er := con.dataObjectContext(ctx, objPath); er == nil {
+			response = append(response, c)
+		} else {
+			err = er
+			return
 		}
 	}
 
@@ -1095,33 +1040,45 @@ func (con *Connection) QueryMeta(qString string) (response IRodsObjs, err error)
 }
 
 func (con *Connection) init() error {
+	return con.initContext(context.Background())
+}
+
+// initContext is like init, but gives up waiting for a session once ctx is done. The caches are filled again by the
+// next call when it fails.
+func (con *Connection) initContext(ctx context.Context) (err error) {
 	if !con.Init {
 		con.Init = true
 
-		info, iErr := con.UserInfo()
+		defer func() {
+			if err != nil {
+				con.Init = false
+			}
+		}()
+
+		info, iErr := con.userInfoContext(ctx)
 		if iErr != nil {
 			return iErr
 		}
 
 		// user must be rodsadmin
 		if info["type"] == "rodsadmin" {
-			if err := con.RefreshZones(); err != nil {
+			if err := con.refreshZonesContext(ctx); err != nil {
 				return err
 			}
 		}
 
-		if err := con.RefreshResources(); err != nil {
+		if err := con.refreshResourcesContext(ctx); err != nil {
 			return err
 		}
 
 		// user must be rodsadmin
 		if info["type"] == "rodsadmin" {
-			if err := con.RefreshUsers(); err != nil {
+			if err := con.refreshUsersContext(ctx); err != nil {
 				return err
 			}
 		}
 
-		if err := con.RefreshGroups(); err != nil {
+		if err := con.refreshGroupsContext(ctx); err != nil {
 			return err
 		}
 
@@ -1133,7 +1090,12 @@ func (con *Connection) init() error {
 // Groups returns a slice of all *Group in the iCAT.
 // You must have the proper groupadmin or rodsadmin privileges to use this function.
 func (con *Connection) Groups() (Groups, error) {
-	if err := con.init(); err != nil {
+	return con.groupsContext(context.Background())
+}
+
+// groupsContext is like Groups, but gives up waiting for a session once ctx is done
+func (con *Connection) groupsContext(ctx context.Context) (Groups, error) {
+	if err := con.initContext(ctx); err != nil {
 		return nil, err
 	}
 	return con.groups, nil
@@ -1142,7 +1104,12 @@ func (con *Connection) Groups() (Groups, error) {
 // Users returns a slice of all *User in the iCAT.
 // You must have the proper rodsadmin privileges to use this function.
 func (con *Connection) Users() (Users, error) {
-	if err := con.init(); err != nil {
+	return con.usersContext(context.Background())
+}
+
+// usersContext is like Users, but gives up waiting for a session once ctx is done
+func (con *Connection) usersContext(ctx context.Context) (Users, error) {
+	if err := con.initContext(ctx); err != nil {
 		return nil, err
 	}
 	return con.users, nil
@@ -1151,7 +1118,12 @@ func (con *Connection) Users() (Users, error) {
 // Zones returns a slice of all *Zone in the iCAT.
 // You must have the proper rodsadmin privileges to use this function.
 func (con *Connection) Zones() (Zones, error) {
-	if err := con.init(); err != nil {
+	return con.zonesContext(context.Background())
+}
+
+// zonesContext is like Zones, but gives up waiting for a session once ctx is done
+func (con *Connection) zonesContext(ctx context.Context) (Zones, error) {
+	if err := con.initContext(ctx); err != nil {
 		return nil, err
 	}
 	return con.zones, nil
@@ -1160,7 +1132,12 @@ func (con *Connection) Zones() (Zones, error) {
 // Resources returns a slice of all *Resource in the iCAT.
 // You must have the proper rodsadmin privileges to use this function.
 func (con *Connection) Resources() (Resources, error) {
-	if err := con.init(); err != nil {
+	return con.resourcesContext(context.Background())
+}
+
+// resourcesContext is like Resources, but gives up waiting for a session once ctx is done
+func (con *Connection) resourcesContext(ctx context.Context) (Resources, error) {
+	if err := con.initContext(ctx); err != nil {
 		return nil, err
 	}
 	return con.resources, nil
@@ -1169,19 +1146,24 @@ func (con *Connection) Resources() (Resources, error) {
 // CreateGroup creates a group within the local zone.
 // You must have the proper groupadmin or rodsadmin privileges to use this function.
 func (con *Connection) CreateGroup(name string) (*Group, error) {
+	return con.createGroupContext(context.Background(), name)
+}
+
+// createGroupContext is like CreateGroup, but gives up waiting for a session once ctx is done
+func (con *Connection) createGroupContext(ctx context.Context, name string) (*Group, error) {
 
-	if z, err := con.LocalZone(); err != nil {
+	if z, err := con.localZoneContext(ctx); err != nil {
 		return nil, err
 	} else {
-		if err := createGroup(name, z, con); err != nil {
+		if err := createGroup(ctx, name, z, con); err != nil {
 			return nil, err
 		}
 
-		if err := con.RefreshGroups(); err != nil {
+		if err := con.refreshGroupsContext(ctx); err != nil {
 			return nil, err
 		}
 
-		if grps, err := con.Groups(); err != nil {
+		if grps, err := con.groupsContext(ctx); err != nil {
 			return nil, err
 		} else {
 			if grp := grps.FindByName(name, con); grp != nil {
@@ -1198,19 +1180,24 @@ func (con *Connection) CreateGroup(name string) (*Group, error) {
 // CreateUser creates an iRODS user within the local zone.
 // You must have the proper rodsadmin privileges to use this function.
 func (con *Connection) CreateUser(name string, typ int) (*User, error) {
+	return con.createUserContext(context.Background(), name, typ)
+}
+
+// createUserContext is like CreateUser, but gives up waiting for a session once ctx is done
+func (con *Connection) createUserContext(ctx context.Context, name string, typ int) (*User, error) {
 
-	if z, err := con.LocalZone(); err != nil {
+	if z, err := con.localZoneContext(ctx); err != nil {
 		return nil, err
 	} else {
-		if err := createUser(name, z.Name(), typ, con); err != nil {
+		if err := createUser(ctx, name, z.Name(), typ, con); err != nil {
 			return nil, err
 		}
 
-		if err := con.RefreshUsers(); err != nil {
+		if err := con.refreshUsersContext(ctx); err != nil {
 			return nil, err
 		}
 
-		if usrs, err := con.Users(); err != nil {
+		if usrs, err := con.usersContext(ctx); err != nil {
 			return nil, err
 		} else {
 			if usr := usrs.FindByName(name, con); usr != nil {
@@ -1225,7 +1212,12 @@ func (con *Connection) CreateUser(name string, typ int) (*User, error) {
 
 // RefreshResources updates the slice returned by con.Resources() with fresh data from the iCAT server.
 func (con *Connection) RefreshResources() error {
-	if resources, err := con.FetchResources(); err != nil {
+	return con.refreshResourcesContext(context.Background())
+}
+
+// refreshResourcesContext is like RefreshResources, but gives up waiting for a session once ctx is done
+func (con *Connection) refreshResourcesContext(ctx context.Context) error {
+	if resources, err := con.fetchResourcesContext(ctx); err != nil {
 		return err
 	} else {
 		con.resources = resources
@@ -1236,7 +1228,12 @@ func (con *Connection) RefreshResources() error {
 
 // RefreshUsers updates the slice returned by con.Users() with fresh data from the iCAT server.
 func (con *Connection) RefreshUsers() error {
-	if users, err := con.FetchUsers(); err != nil {
+	return con.refreshUsersContext(context.Background())
+}
+
+// refreshUsersContext is like RefreshUsers, but gives up waiting for a session once ctx is done
+func (con *Connection) refreshUsersContext(ctx context.Context) error {
+	if users, err := con.fetchUsersContext(ctx); err != nil {
 		return err
 	} else {
 		con.users = users
@@ -1247,7 +1244,12 @@ func (con *Connection) RefreshUsers() error {
 
 // RefreshZones updates the slice returned by con.Zones() with fresh data from the iCAT server.
 func (con *Connection) RefreshZones() error {
-	if zones, err := con.FetchZones(); err != nil {
+	return con.refreshZonesContext(context.Background())
+}
+
+// refreshZonesContext is like RefreshZones, but gives up waiting for a session once ctx is done
+func (con *Connection) refreshZonesContext(ctx context.Context) error {
+	if zones, err := con.fetchZonesContext(ctx); err != nil {
 		return err
 	} else {
 		con.zones = zones
@@ -1258,7 +1260,12 @@ func (con *Connection) RefreshZones() error {
 
 // RefreshGroups updates the slice returned by con.Groups() with fresh data from the iCAT server.
 func (con *Connection) RefreshGroups() error {
-	if groups, err := con.FetchGroups(); err != nil {
+	return con.refreshGroupsContext(context.Background())
+}
+
+// refreshGroupsContext is like RefreshGroups, but gives up waiting for a session once ctx is done
+func (con *Connection) refreshGroupsContext(ctx context.Context) error {
+	if groups, err := con.fetchGroupsContext(ctx); err != nil {
 		return err
 	} else {
 		con.groups = groups
@@ -1269,35 +1276,21 @@ func (con *Connection) RefreshGroups() error {
 
 // FetchGroups returns a slice of *Group, fresh from the iCAT server.
 func (con *Connection) FetchGroups() (Groups, error) {
+	return con.fetchGroupsContext(context.Background())
+}
 
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
-
-	ccon := con.GetCcon()
+// fetchGroupsContext is like FetchGroups, but gives up waiting for a session once ctx is done
+func (con *Connection) fetchGroupsContext(ctx context.Context) (Groups, error) {
 
-	if status := C.gorods_get_groups(ccon, &result, &err); status != 0 {
-		con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Groups Failed: %v", C.GoString(err)))
+	groupNames, er := con.transportContext(ctx).Groups()
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Groups Failed")
 	}
 
-	con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
 	response := make(Groups, 0)
 
-	for _, groupName := range slice {
-		if grp, er := initGroup(C.GoString(groupName), con); er == nil {
+	for _, groupName := range groupNames {
+		if grp, er := initGroup(groupName, con); er == nil {
 			response = append(response, grp)
 		} else {
 			return nil, er
@@ -1311,60 +1304,41 @@ func (con *Connection) FetchGroups() (Groups, error) {
 
 // FetchUsers returns a slice of *User, fresh from the iCAT server.
 func (con *Connection) FetchUsers() (Users, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
+	return con.fetchUsersContext(context.Background())
+}
 
-	ccon := con.GetCcon()
+// fetchUsersContext is like FetchUsers, but gives up waiting for a session once ctx is done
+func (con *Connection) fetchUsersContext(ctx context.Context) (Users, error) {
 
-	if status := C.gorods_get_users(ccon, &result, &err); status != 0 {
-		con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Users Failed: %v", C.GoString(err)))
+	userNames, er := con.transportContext(ctx).Users()
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Users Failed")
 	}
 
-	con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
 	response := make(Users, 0)
 
-	for _, userNames := range slice {
+	for _, name := range userNames {
 
-		nameZone := strings.Split(strings.Trim(C.GoString(userNames), " \n"), "\n")
+		split := strings.Split(name, "#")
 
-		for _, name := range nameZone {
-
-			split := strings.Split(name, "#")
-
-			user := split[0]
-			zonename := split[1]
-			var zone *Zone
-
-			if zones, err := con.Zones(); err != nil {
-				return nil, err
-			} else {
-				if zne := zones.FindByName(zonename, con); zne != nil {
-					zone = zne
-				} else {
-					return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Fetch Users Failed: Unable to locate zone in cache"))
-				}
-			}
+		user := split[0]
+		zonename := split[1]
+		var zone *Zone
 
-			if usr, err := initUser(user, zone, con); err == nil {
-				response = append(response, usr)
+		if zones, err := con.zonesContext(ctx); err != nil {
+			return nil, err
+		} else {
+			if zne := zones.FindByName(zonename, con); zne != nil {
+				zone = zne
 			} else {
-				return nil, err
+				return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Fetch Users Failed: Unable to locate zone in cache"))
 			}
+		}
 
+		if usr, err := initUser(user, zone, con); err == nil {
+			response = append(response, usr)
+		} else {
+			return nil, err
 		}
 
 	}
@@ -1374,35 +1348,20 @@ func (con *Connection) FetchUsers() (Users, error) {
 
 // FetchResources returns a slice of *Resource, fresh from the iCAT server.
 func (con *Connection) FetchResources() (Resources, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
+	return con.fetchResourcesContext(context.Background())
+}
 
-	ccon := con.GetCcon()
+// fetchResourcesContext is like FetchResources, but gives up waiting for a session once ctx is done
+func (con *Connection) fetchResourcesContext(ctx context.Context) (Resources, error) {
 
-	if status := C.gorods_get_resources_new(ccon, &result, &err); status != 0 {
-		con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Resources Failed: %v", C.GoString(err)))
+	resourceNames, er := con.transportContext(ctx).Resources()
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Resources Failed")
 	}
 
-	con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
 	response := make(Resources, 0)
 
-	for _, cResourceName := range slice {
-
-		name := C.GoString(cResourceName)
+	for _, name := range resourceNames {
 
 		if resc, err := initResource(name, con); err == nil {
 			response = append(response, resc)
@@ -1417,44 +1376,25 @@ func (con *Connection) FetchResources() (Resources, error) {
 
 // FetchZones returns a slice of *Zone, fresh from the iCAT server.
 func (con *Connection) FetchZones() (Zones, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
+	return con.fetchZonesContext(context.Background())
+}
 
-	ccon := con.GetCcon()
+// fetchZonesContext is like FetchZones, but gives up waiting for a session once ctx is done
+func (con *Connection) fetchZonesContext(ctx context.Context) (Zones, error) {
 
-	if status := C.gorods_get_zones(ccon, &result, &err); status != 0 {
-		con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Zones Failed: %v", C.GoString(err)))
+	zoneNames, er := con.transportContext(ctx).Zones()
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Zones Failed")
 	}
 
-	con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
 	response := make(Zones, 0)
 
-	for _, cZoneName := range slice {
-
-		zoneNames := strings.Split(strings.Trim(C.GoString(cZoneName), " \n"), "\n")
-
-		for _, name := range zoneNames {
-
-			if zne, err := initZone(name, con); err == nil {
-				response = append(response, zne)
-			} else {
-				return nil, err
-			}
+	for _, name := range zoneNames {
 
+		if zne, err := initZone(name, con); err == nil {
+			response = append(response, zne)
+		} else {
+			return nil, err
 		}
 
 	}
@@ -1464,28 +1404,24 @@ func (con *Connection) FetchZones() (Zones, error) {
 
 // LocalZone returns the *Zone. First it checks the ConnectionOptions.Zone and uses that, otherwise it pulls it fresh from the iCAT server.
 func (con *Connection) LocalZone() (*Zone, error) {
+	return con.localZoneContext(context.Background())
+}
 
-	var (
-		cZoneName *C.char
-		err       *C.char
-	)
+// localZoneContext is like LocalZone, but gives up waiting for a session once ctx is done
+func (con *Connection) localZoneContext(ctx context.Context) (*Zone, error) {
 
-	if con.Options.Zone == "" {
-		ccon := con.GetCcon()
-		if status := C.gorods_get_local_zone(ccon, &cZoneName, &err); status != 0 {
-			con.ReturnCcon(ccon)
-			return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Local Zone Failed: %v", C.GoString(err)))
-		}
-		con.ReturnCcon(ccon)
-	} else {
-		cZoneName = C.CString(con.Options.Zone)
-	}
+	zoneName := con.Options.Zone
 
-	defer C.free(unsafe.Pointer(cZoneName))
+	if zoneName == "" {
+		name, er := con.transportContext(ctx).LocalZone()
+		if er != nil {
+			return nil, transportError(er, "iRODS Get Local Zone Failed")
+		}
 
-	zoneName := strings.Trim(C.GoString(cZoneName), " \n")
+		zoneName = name
+	}
 
-	if znes, err := con.Zones(); err != nil {
+	if znes, err := con.zonesContext(ctx); err != nil {
 		return nil, err
 	} else {
 		if zne := znes.FindByName(zoneName, con); zne == nil {
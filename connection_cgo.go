@@ -0,0 +1,567 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, University of Florida Research Foundation, Inc. and The BioTeam, Inc.  ***
+ *** For more information please refer to the LICENSE.md file                                   ***/
+
+package gorods
+
+// #cgo CFLAGS: -ggdb -I/usr/include/irods
+// #cgo LDFLAGS: -Wl,-rpath,"/opt/irods-externals/boost1.60.0-0/lib" -Wl,-rpath,"/opt/irods-externals/clang3.8-0/lib" -L/opt/irods-externals/clang3.8-0/lib -L/opt/irods-externals/boost1.60.0-0/lib /opt/irods-externals/boost1.60.0-0/lib/libboost_system.a /opt/irods-externals/boost1.60.0-0/lib/libboost_chrono.a /opt/irods-externals/jansson2.7-0/lib/libjansson.a -lirods_common -lirods_client -lc++ -lc++abi -lboost_regex -lboost_program_options -lboost_thread -lboost_filesystem -lz -lssl -lcrypto -ldl -lpthread -lm -lrt -lstdc++ -rdynamic -Wno-write-strings -DBOOST_SYSTEM_NO_DEPRECATED
+// #include "wrapper.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// rcComm is the connection handle of the iRODS C API, and genQueryInp the state of a GenQuery paged through by a
+// QueryCursor. Builds without cgo replace them with empty structs, see nocgo.go.
+type (
+	rcComm      = C.rcComm_t
+	genQueryInp = C.genQueryInp_t
+)
+
+// connectC connects and logs in with the iRODS C API, then opens the other sessions
+func (con *Connection) connectC() error {
+	var (
+		status    C.int
+		errMsg    *C.char
+		ipassword *C.char
+		opassword *C.char
+	)
+
+	// Are we passing env values?
+	if con.Options.Type == UserDefined {
+		host := C.CString(con.Options.Host)
+		port := C.int(con.Options.Port)
+		username := C.CString(con.Options.Username)
+		zone := C.CString(con.Options.Zone)
+
+		defer C.free(unsafe.Pointer(host))
+		defer C.free(unsafe.Pointer(username))
+		defer C.free(unsafe.Pointer(zone))
+
+		// BUG(jjacquay712): iRODS C API code outputs errors messages, need to implement connect wrapper (gorods_connect_env) from a lower level to suppress this output
+		// https://github.com/irods/irods/blob/master/iRODS/lib/core/src/rcConnect.cpp#L109
+		withSSLEnv(con.Options, func() {
+			status = C.gorods_connect_env(&con.ccon, host, port, username, zone, &errMsg)
+		})
+
+		if status != 0 {
+			return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: %v", C.GoString(errMsg)))
+		}
+	} else {
+
+		var cHost, cUsername, cZone *C.char
+		var cPort C.int
+
+		withSSLEnv(con.Options, func() {
+			status = C.gorods_connect(&con.ccon, &cHost, &cPort, &cUsername, &cZone, &errMsg)
+		})
+
+		if status != 0 {
+			return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: %v", C.GoString(errMsg)))
+		}
+
+		con.Options.Host = C.GoString(cHost)
+		con.Options.Port = int(cPort)
+		con.Options.Username = C.GoString(cUsername)
+		con.Options.Zone = C.GoString(cZone)
+	}
+
+	con.cconBuffer = make(chan *C.rcComm_t, con.Options.sessionCount())
+	con.sessions = []*session{newSession(con.ccon)}
+	con.cconBuffer <- con.ccon
+
+	con.transport = &cTransport{con: con}
+	con.Connected = true
+
+	ipassword = C.CString(con.Options.Password)
+	defer C.free(unsafe.Pointer(ipassword))
+
+	if con.Options.AuthType == 0 {
+		con.Options.AuthType = PasswordAuth // Options: PasswordAuth PAMAuth
+	}
+
+	if con.Options.PAMPassExpire == 0 {
+		con.Options.PAMPassExpire = 1 // Default expiration: 1 hour
+	}
+
+	var (
+		pamPassFile *os.File
+		pamFileErr  error
+		size        int64
+	)
+
+	if con.Options.AuthType == PAMAuth {
+
+		// Was a PAM token passed?
+		if con.Options.PAMToken != "" {
+
+			// Use it, pass directly to clientLoginWithPassword
+			opassword = C.CString(con.Options.PAMToken)
+			defer C.free(unsafe.Pointer(opassword))
+
+		} else if con.Options.PAMPassFile == "" { // Continue with auth using .Password (ipassword) option, Check to see if PAMPassFile option is not set
+
+			// It's not, fetch password and just keep in memory
+			if status = C.gorods_clientLoginPam(con.ccon, ipassword, C.int(con.Options.PAMPassExpire), &opassword, &errMsg); status != 0 {
+				return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: clientLoginPam error, invalid password?"))
+			}
+
+			defer C.free(unsafe.Pointer(opassword))
+
+		} else { // There is a PAM file path set, save password to FS for subsequent use
+
+			// Does the file/dir exist?
+			if finfo, err := os.Stat(con.Options.PAMPassFile); err == nil {
+				if !finfo.IsDir() {
+					// Open file here
+					pamPassFile, pamFileErr = os.OpenFile(con.Options.PAMPassFile, os.O_RDWR, 0666)
+					if pamFileErr != nil {
+						return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Problem opening PAMPassFile at %v", con.Options.PAMPassFile))
+					}
+
+					size = finfo.Size()
+
+				} else {
+					return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: PAMPassFile is a directory durp"))
+				}
+			} else {
+				// Create file here
+				pamPassFile, pamFileErr = os.Create(con.Options.PAMPassFile)
+				if pamFileErr != nil {
+					return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Problem creating PAMPassFile at %v", con.Options.PAMPassFile))
+				}
+
+			}
+
+			// Is this an old password file?
+			if size > 0 {
+
+				fileBtz := make([]byte, size)
+				if _, er := pamPassFile.Read(fileBtz); er != nil {
+					return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Problem reading PAMPassFile at %v", con.Options.PAMPassFile))
+				}
+
+				fileStr := string(fileBtz)
+				fileSplit := strings.Split(fileStr, ":")
+				unixTimeStamp, _ := strconv.Atoi(fileSplit[0])
+				pamPassword := fileSplit[1]
+
+				now := int(time.Now().Unix())
+
+				// Check to see if the password has expired
+				if (unixTimeStamp + (con.Options.PAMPassExpire * 60)) <= now {
+					// we're expired, refresh
+					opassword, pamFileErr = con.fetchAndWritePAMPass(pamPassFile, ipassword)
+					if pamFileErr != nil {
+						return pamFileErr
+					}
+				} else {
+					// It's still good, use it
+					opassword = C.CString(pamPassword)
+				}
+				defer C.free(unsafe.Pointer(opassword))
+
+			} else {
+
+				// Nope, it's new. Write to the file
+				opassword, pamFileErr = con.fetchAndWritePAMPass(pamPassFile, ipassword)
+				if pamFileErr != nil {
+					return pamFileErr
+				}
+
+				defer C.free(unsafe.Pointer(opassword))
+			}
+		}
+	} else if con.Options.AuthType == PasswordAuth {
+		opassword = ipassword
+	}
+
+	if status = C.clientLoginWithPassword(con.ccon, opassword); status != 0 {
+
+		// if status == C.CAT_PASSWORD_EXPIRED {
+		// 	fmt.Printf("expired:%v\n", pamPassFile.Name())
+		// }
+
+		if con.Options.AuthType == PAMAuth {
+
+			// TODO
+			// If PAM token is set, and error is password expires, reset token, reinit?
+
+			if pamPassFile != nil {
+
+				// Failure, clear out file for another try.
+				if er := pamPassFile.Truncate(int64(0)); er != nil {
+					return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: Unable to truncate PAMPassFile: %v", er)).wrap(er)
+				}
+
+				return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: clientLoginWithPassword error, expired password?"))
+			}
+		}
+
+		return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: clientLoginWithPassword error, invalid password?"))
+	}
+
+	if con.Options.AuthType == PAMAuth {
+		con.PAMToken = C.GoString(opassword)
+	}
+
+	if status != 0 {
+		return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: %v", C.GoString(errMsg)))
+	}
+
+	if err := con.openSessions(opassword); err != nil {
+		return err
+	}
+
+	con.SetThreads(con.Options.Threads)
+
+	return nil
+}
+
+// fetchAndWritePAMPass attempts to authenticate with the iCAT server using PAM.
+// If PAM authentication is successful, it writes the returned PAM authentication token to a file for subsequent use in connections.
+func (con *Connection) fetchAndWritePAMPass(pamPassFile *os.File, ipassword *C.char) (*C.char, error) {
+
+	var (
+		opassword *C.char
+		errMsg    *C.char
+	)
+
+	if status := C.gorods_clientLoginPam(con.ccon, ipassword, C.int(con.Options.PAMPassExpire), &opassword, &errMsg); status != 0 {
+		return nil, newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: clientLoginPam error, invalid password?"))
+	}
+
+	if er := pamPassFile.Truncate(0); er != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Unable to write new password to PAMPassFile"))
+	}
+
+	pamPassFormat := strconv.Itoa(int(time.Now().Unix())) + ":" + C.GoString(opassword)
+
+	if _, er := pamPassFile.WriteString(pamPassFormat); er != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Unable to write new password to PAMPassFile"))
+	}
+
+	return opassword, nil
+}
+
+// GetCcon checks out the connection handle for use in all iRODS operations. Basically a mutex for connections.
+// Other goroutines calling this function will block until the handle is returned with con.ReturnCcon, by the goroutine using it.
+// It returns an error wrapping ErrNotSupported when the connection uses another Transport than the iRODS C API.
+//
+// Deprecated: the handle is an implementation detail of the iRODS C API Transport, use the methods of Connection instead.
+func (con *Connection) GetCcon() (*C.rcComm_t, error) {
+	return con.getCcon()
+}
+
+// ReturnCcon returns the connection handle for use in other threads. Unlocks the mutex.
+//
+// Deprecated: see GetCcon.
+func (con *Connection) ReturnCcon(ccon *C.rcComm_t) {
+	con.returnCcon(ccon)
+}
+
+// cSetTicket sets the ticket used by the session ccon
+func cSetTicket(ccon *rcComm, t string) error {
+	var errMsg *C.char
+
+	ticket := C.CString(t)
+	defer C.free(unsafe.Pointer(ticket))
+
+	if status := C.gorods_set_session_ticket(ccon, ticket, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+// cRegPhysObj registers the file or directory opts.PhysicalFilePath, like ireg
+func cRegPhysObj(ccon *rcComm, opts RegOptions, collection bool, resourceName string) error {
+	cPhysPath := C.CString(opts.PhysicalFilePath)
+	cRodsPath := C.CString(opts.RodsPath)
+	cExcludeFiles := C.CString(opts.ExcludeFiles)
+	cResourceName := C.CString(resourceName)
+
+	defer func() {
+		C.free(unsafe.Pointer(cPhysPath))
+		C.free(unsafe.Pointer(cRodsPath))
+		C.free(unsafe.Pointer(cExcludeFiles))
+		C.free(unsafe.Pointer(cResourceName))
+	}()
+
+	if status := C.gorods_phys_path_reg(ccon, cPhysPath, cRodsPath, cBool(opts.Force), cBool(collection), cBool(opts.Replica), cResourceName, cExcludeFiles); status < 0 {
+		return NewTransportError(int(status), "rcPhyPathReg")
+	}
+
+	return nil
+}
+
+// cIRODSEnv returns the connection settings of the iRODS environment (irods_environment.json)
+func cIRODSEnv() (string, string, int, string, error) {
+	var (
+		username *C.char
+		host     *C.char
+		port     C.int
+		zone     *C.char
+	)
+
+	defer C.free(unsafe.Pointer(username))
+	defer C.free(unsafe.Pointer(host))
+	defer C.free(unsafe.Pointer(zone))
+
+	if status := C.irods_env(&username, &host, &port, &zone); status != 0 {
+		return "", "", 0, "", NewTransportError(int(status), "getRodsEnv")
+	}
+
+	return C.GoString(username), C.GoString(host), int(port), C.GoString(zone), nil
+}
+
+// cSetThreads sets ccon.transStat.numThreads
+func cSetThreads(ccon *rcComm, num int) {
+	ccon.transStat.numThreads = C.int(num)
+}
+
+// cThreads returns ccon.transStat.numThreads
+func cThreads(ccon *rcComm) int {
+	return int(ccon.transStat.numThreads)
+}
+
+// cSpecificQuery runs a specific query, like iquest --sql
+func cSpecificQuery(ccon *rcComm, specificQuery string, queryArgs []string, zone string) ([][]string, error) {
+	var (
+		result C.goRodsGenQueryResult_t
+		err    *C.char
+	)
+
+	result.rowSize = C.int(0)
+	result.attrSize = C.int(0)
+
+	cQueryString := C.CString(specificQuery)
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cQueryString))
+
+	queryArgsLen := len(queryArgs)
+	cQueryArgs := make([]*C.char, queryArgsLen)
+
+	for i := range queryArgs {
+		qa := C.CString(queryArgs[i])
+		cQueryArgs[i] = qa
+		defer C.free(unsafe.Pointer(qa))
+	}
+
+	// Solve index out of range issue for 0 args
+	if queryArgsLen == 0 {
+		blankStr := C.CString("")
+		defer C.free(unsafe.Pointer(blankStr))
+
+		cQueryArgs = append(cQueryArgs, blankStr)
+	}
+
+	if status := C.gorods_exec_specific_query(ccon, cQueryString, (**C.char)(unsafe.Pointer(&cQueryArgs[0])), C.int(queryArgsLen), cZoneName, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.gorods_free_gen_query_result(&result)
+
+	return genQueryRows(&result), nil
+}
+
+// cIQuest runs a GenQuery written like iquest's, returning a map of column names to values for each row
+func cIQuest(ccon *rcComm, query string, upperCase bool, zone string) ([]map[string]string, error) {
+	var (
+		result C.goRodsHashResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	cQueryString := C.CString(query)
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cQueryString))
+
+	if status := C.gorods_iquest_general(ccon, cQueryString, C.int(0), cBool(upperCase), cZoneName, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.gorods_free_map_result(&result)
+
+	unsafeKeyArr := unsafe.Pointer(result.hashKeys)
+	keyArrLen := int(result.keySize)
+
+	unsafeValArr := unsafe.Pointer(result.hashValues)
+	valArrLen := int(result.size) * keyArrLen
+
+	response := make([]map[string]string, int(result.size))
+
+	// Convert C array to slice
+	keySlice := (*[1 << 30]*C.char)(unsafeKeyArr)[:keyArrLen:keyArrLen]
+	valSlice := (*[1 << 30]*C.char)(unsafeValArr)[:valArrLen:valArrLen]
+
+	for n, val := range valSlice {
+		mapInx := n / keyArrLen
+
+		var key string
+
+		if n == 0 {
+			key = C.GoString(keySlice[0])
+		} else {
+			key = C.GoString(keySlice[int(math.Mod(float64(n), float64(keyArrLen)))])
+		}
+
+		if response[mapInx] == nil {
+			response[mapInx] = make(map[string]string)
+		}
+
+		response[mapInx][key] = C.GoString(val)
+	}
+
+	return response, nil
+}
+
+// GenQuery runs a validated *Query with the iRODS C API, see genQuerier
+func (t *cTransport) GenQuery(q *Query, zone string) (*QueryResult, error) {
+	response := &QueryResult{
+		Columns: q.Columns,
+		Rows:    make([][]string, 0),
+	}
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	rows, er := cGenQuery(ccon, q, zone)
+	t.con.returnCcon(ccon)
+
+	if er != nil {
+		if errors.Is(er, ErrNoRowsFound) {
+			return response, nil
+		}
+		return nil, er
+	}
+
+	if q.RowLimit > 0 && len(rows) > q.RowLimit {
+		rows = rows[:q.RowLimit]
+	}
+
+	response.Rows = append(response.Rows, rows...)
+
+	return response, nil
+}
+
+// cGenQuery runs q in zone, and returns the rows in the order of the selected columns
+func cGenQuery(ccon *rcComm, q *Query, zone string) ([][]string, error) {
+	var (
+		result C.goRodsGenQueryResult_t
+		err    *C.char
+	)
+
+	result.rowSize = C.int(0)
+	result.attrSize = C.int(0)
+
+	cQueryString := C.CString(q.String())
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cQueryString))
+
+	defer C.gorods_free_gen_query_result(&result)
+
+	if status := C.gorods_gen_query(ccon, cQueryString, cBool(q.NoDistinct), cBool(q.UpperCase), cZoneName, C.int(q.RowOffset), C.int(q.RowLimit), &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return genQueryRows(&result), nil
+}
+
+// genQueryRows converts the rows of a goRodsGenQueryResult_t to string slices
+func genQueryRows(result *C.goRodsGenQueryResult_t) [][]string {
+	rowsLen := int(result.rowSize)
+	attrLen := int(result.attrSize)
+
+	response := make([][]string, 0, rowsLen)
+
+	if rowsLen == 0 {
+		return response
+	}
+
+	// Convert C array to slice
+	rowSlice := (*[1 << 30]**C.char)(unsafe.Pointer(result.result))[:rowsLen:rowsLen]
+
+	for _, row := range rowSlice {
+		cols := (*[1 << 30]*C.char)(unsafe.Pointer(row))[:attrLen:attrLen]
+
+		values := make([]string, attrLen)
+		for i, val := range cols {
+			values[i] = C.GoString(val)
+		}
+
+		response = append(response, values)
+	}
+
+	return response
+}
+
+// cQueryMetaCollections returns the paths of the collections matching the metadata query qString
+func cQueryMetaCollections(ccon *rcComm, qString string) ([]string, error) {
+	var (
+		errMsg *C.char
+		result C.goRodsPathResult_t
+	)
+
+	query := C.CString(qString)
+	defer C.free(unsafe.Pointer(query))
+	defer C.freeGoRodsPathResult(&result)
+
+	if status := C.gorods_query_collection(ccon, query, &result, &errMsg); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return pathResult(&result), nil
+}
+
+// cQueryMetaDataObjs returns the paths of the data objects matching the metadata query qString
+func cQueryMetaDataObjs(ccon *rcComm, qString string) ([]string, error) {
+	var (
+		errMsg *C.char
+		result C.goRodsPathResult_t
+	)
+
+	query := C.CString(qString)
+	defer C.free(unsafe.Pointer(query))
+	defer C.freeGoRodsPathResult(&result)
+
+	if status := C.gorods_query_dataobj(ccon, query, &result, &errMsg); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return pathResult(&result), nil
+}
+
+// pathResult converts a goRodsPathResult_t to a string slice
+func pathResult(result *C.goRodsPathResult_t) []string {
+	size := int(result.size)
+
+	response := make([]string, 0, size)
+
+	if size > 0 {
+		slice := (*[1 << 30]*C.char)(unsafe.Pointer(result.pathArr))[:size:size]
+
+		for _, path := range slice {
+			response = append(response, C.GoString(path))
+		}
+	}
+
+	return response
+}
@@ -6,6 +6,7 @@ package gorods
 import (
 	"flag"
 	"fmt"
+	"os"
 	"testing"
 )
 
@@ -23,7 +24,10 @@ func init() {
 	flag.StringVar(&testCreds.Password, "irods.password", "testpassword", "Password to use in connection to iRODS server, e.g. testpassword")
 
 	flag.BoolVar(&shouldTestPAM, "irods.testpam", false, "Should try to connect with PAM")
+}
 
+// TestMain parses the irods.* flags, which go test only allows once its own flags are registered
+func TestMain(m *testing.M) {
 	flag.Parse()
 
 	if shouldTestPAM {
@@ -31,6 +35,8 @@ func init() {
 	}
 
 	fmt.Printf("Setup testing with params: %v\n", testCreds.String())
+
+	os.Exit(m.Run())
 }
 
 func TestUserDefinedConnection(t *testing.T) {
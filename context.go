@@ -0,0 +1,412 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+)
+
+// runContext runs fn, unless ctx is already done. fn passes ctx down to the Transport, so each call it makes gives
+// up waiting for a session that's busy with another goroutine once ctx is done. The iRODS C API can't interrupt a
+// call though: a call that was sent to the server runs to completion, and an operation that may have changed iRODS
+// is never reported as cancelled. The methods moving data in chunks (ReadChunkContext, WriteBytesContext,
+// TransferOptions.Context, QueryCursor.NextContext) also stop between chunks.
+func runContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// CollectionContext is like Collection, giving up once ctx is done while waiting for a busy session. See runContext for the caveats.
+func (con *Connection) CollectionContext(ctx context.Context, opts CollectionOptions) (col *Collection, err error) {
+	err = runContext(ctx, func() (er error) {
+		col, er = con.collectionContext(ctx, opts)
+		return
+	})
+	return
+}
+
+// DataObjectContext is like DataObject, giving up once ctx is done while waiting for a busy session
+func (con *Connection) DataObjectContext(ctx context.Context, dataObjPath string) (obj *DataObj, err error) {
+	err = runContext(ctx, func() (er error) {
+		obj, er = con.dataObjectContext(ctx, dataObjPath)
+		return
+	})
+	return
+}
+
+// PathTypeContext is like PathType, giving up once ctx is done while waiting for a busy session
+func (con *Connection) PathTypeContext(ctx context.Context, p string) (typ int, err error) {
+	err = runContext(ctx, func() (er error) {
+		typ, er = con.pathTypeContext(ctx, p)
+		return
+	})
+	return
+}
+
+// QueryContext is like Query, giving up once ctx is done while waiting for a busy session. Use QueryCursor with
+// QueryCursor.NextContext to stop between pages of a large result set.
+func (con *Connection) QueryContext(ctx context.Context, q *Query) (res *QueryResult, err error) {
+	err = runContext(ctx, func() (er error) {
+		res, er = con.queryContext(ctx, q)
+		return
+	})
+	return
+}
+
+// IQuestContext is like IQuest, giving up once ctx is done while waiting for a busy session
+func (con *Connection) IQuestContext(ctx context.Context, query string, upperCase bool) (res []map[string]string, err error) {
+	err = runContext(ctx, func() (er error) {
+		res, er = con.iquestContext(ctx, query, upperCase)
+		return
+	})
+	return
+}
+
+// IQuestSQLContext is like IQuestSQL, giving up once ctx is done while waiting for a busy session
+func (con *Connection) IQuestSQLContext(ctx context.Context, specificQuery string, queryArgs ...string) (res [][]string, err error) {
+	err = runContext(ctx, func() (er error) {
+		res, er = con.iquestSQLContext(ctx, specificQuery, queryArgs...)
+		return
+	})
+	return
+}
+
+// QueryMetaContext is like QueryMeta, giving up once ctx is done while waiting for a busy session
+func (con *Connection) QueryMetaContext(ctx context.Context, qString string) (res IRodsObjs, err error) {
+	err = runContext(ctx, func() (er error) {
+		res, er = con.queryMetaContext(ctx, qString)
+		return
+	})
+	return
+}
+
+// EmptyTrashContext is like EmptyTrash, giving up once ctx is done while waiting for a busy session
+func (con *Connection) EmptyTrashContext(ctx context.Context) error {
+	return runContext(ctx, func() error {
+		return con.emptyTrashContext(ctx)
+	})
+}
+
+// PingContext is like Ping, giving up once ctx is done while waiting for a busy session
+func (con *Connection) PingContext(ctx context.Context) error {
+	return runContext(ctx, func() error {
+		return con.pingContext(ctx)
+	})
+}
+
+// UsersContext is like Users, giving up once ctx is done while waiting for a busy session
+func (con *Connection) UsersContext(ctx context.Context) (usrs Users, err error) {
+	err = runContext(ctx, func() (er error) {
+		usrs, er = con.usersContext(ctx)
+		return
+	})
+	return
+}
+
+// GroupsContext is like Groups, giving up once ctx is done while waiting for a busy session
+func (con *Connection) GroupsContext(ctx context.Context) (grps Groups, err error) {
+	err = runContext(ctx, func() (er error) {
+		grps, er = con.groupsContext(ctx)
+		return
+	})
+	return
+}
+
+// ZonesContext is like Zones, giving up once ctx is done while waiting for a busy session
+func (con *Connection) ZonesContext(ctx context.Context) (zones Zones, err error) {
+	err = runContext(ctx, func() (er error) {
+		zones, er = con.zonesContext(ctx)
+		return
+	})
+	return
+}
+
+// ResourcesContext is like Resources, giving up once ctx is done while waiting for a busy session
+func (con *Connection) ResourcesContext(ctx context.Context) (rescs Resources, err error) {
+	err = runContext(ctx, func() (er error) {
+		rescs, er = con.resourcesContext(ctx)
+		return
+	})
+	return
+}
+
+// CreateUserContext is like CreateUser, giving up once ctx is done while waiting for a busy session
+func (con *Connection) CreateUserContext(ctx context.Context, name string, typ int) (usr *User, err error) {
+	err = runContext(ctx, func() (er error) {
+		usr, er = con.createUserContext(ctx, name, typ)
+		return
+	})
+	return
+}
+
+// CreateGroupContext is like CreateGroup, giving up once ctx is done while waiting for a busy session
+func (con *Connection) CreateGroupContext(ctx context.Context, name string) (grp *Group, err error) {
+	err = runContext(ctx, func() (er error) {
+		grp, er = con.createGroupContext(ctx, name)
+		return
+	})
+	return
+}
+
+// AllContext is like All, giving up once ctx is done while waiting for a busy session
+func (col *Collection) AllContext(ctx context.Context) (objs IRodsObjs, err error) {
+	err = runContext(ctx, func() (er error) {
+		objs, er = col.allContext(ctx)
+		return
+	})
+	return
+}
+
+// RefreshContext is like Refresh, giving up once ctx is done while waiting for a busy session
+func (col *Collection) RefreshContext(ctx context.Context) error {
+	return runContext(ctx, func() error {
+		return col.refreshContext(ctx)
+	})
+}
+
+// StatContext is like Stat, giving up once ctx is done while waiting for a busy session
+func (col *Collection) StatContext(ctx context.Context) (info map[string]interface{}, err error) {
+	err = runContext(ctx, func() (er error) {
+		info, er = col.statContext(ctx)
+		return
+	})
+	return
+}
+
+// MetaContext is like Meta, giving up once ctx is done while waiting for a busy session
+func (col *Collection) MetaContext(ctx context.Context) (mc *MetaCollection, err error) {
+	err = runContext(ctx, func() (er error) {
+		mc, er = col.metaContext(ctx)
+		return
+	})
+	return
+}
+
+// PutContext is like Put, giving up once ctx is done while waiting for a busy session. Use PutParallel with
+// TransferOptions.Context to stop between buffers of a large upload.
+func (col *Collection) PutContext(ctx context.Context, localPath string, opts DataObjOptions) (obj *DataObj, err error) {
+	err = runContext(ctx, func() (er error) {
+		obj, er = col.putContext(ctx, localPath, opts)
+		return
+	})
+	return
+}
+
+// PutParallelContext is like PutParallel, stopping between buffers once ctx is cancelled
+func (col *Collection) PutParallelContext(ctx context.Context, localPath string, opts DataObjOptions, topts TransferOptions) (*DataObj, error) {
+	topts.Context = ctx
+	return col.PutParallel(localPath, opts, topts)
+}
+
+// PutDirContext is like PutDir. Once ctx is cancelled, files which haven't started uploading fail with ctx.Err().
+func (col *Collection) PutDirContext(ctx context.Context, localDir string, opts PutDirOptions) (*PutDirReport, error) {
+	opts.Context = ctx
+	return col.PutDir(localDir, opts)
+}
+
+// SyncContext is like Sync. Once ctx is cancelled, remaining actions fail with ctx.Err().
+func (col *Collection) SyncContext(ctx context.Context, localDir string, opts SyncOptions) (*SyncReport, error) {
+	opts.Transfer.Context = ctx
+	return col.Sync(localDir, opts)
+}
+
+// DownloadToContext is like DownloadTo, giving up once ctx is done while waiting for a busy session
+func (col *Collection) DownloadToContext(ctx context.Context, localPath string) error {
+	return runContext(ctx, func() error {
+		return col.downloadToContext(ctx, localPath)
+	})
+}
+
+// CreateSubCollectionContext is like CreateSubCollection, giving up once ctx is done while waiting for a busy session
+func (col *Collection) CreateSubCollectionContext(ctx context.Context, name string) (sub *Collection, err error) {
+	err = runContext(ctx, func() (er error) {
+		sub, er = createCollectionContext(ctx, name, col)
+		return
+	})
+	return
+}
+
+// DeleteContext is like Delete, giving up once ctx is done while waiting for a busy session
+func (col *Collection) DeleteContext(ctx context.Context, recursive bool) error {
+	return runContext(ctx, func() error {
+		return col.rmContext(ctx, recursive, true)
+	})
+}
+
+// CopyToContext is like CopyTo, giving up once ctx is done while waiting for a busy session
+func (col *Collection) CopyToContext(ctx context.Context, iRODSCollection interface{}) error {
+	return runContext(ctx, func() error {
+		return col.copyToContext(ctx, iRODSCollection)
+	})
+}
+
+// MoveToContext is like MoveTo, giving up once ctx is done while waiting for a busy session
+func (col *Collection) MoveToContext(ctx context.Context, iRODSCollection interface{}) error {
+	return runContext(ctx, func() error {
+		return col.moveToContext(ctx, iRODSCollection)
+	})
+}
+
+// RenameContext is like Rename, giving up once ctx is done while waiting for a busy session
+func (col *Collection) RenameContext(ctx context.Context, newFileName string) error {
+	return runContext(ctx, func() error {
+		return col.renameContext(ctx, newFileName)
+	})
+}
+
+// ReadContext is like Read, giving up once ctx is done while waiting for a busy session. Use ReadChunkContext to stop between chunks.
+func (obj *DataObj) ReadContext(ctx context.Context) (data []byte, err error) {
+	err = runContext(ctx, func() (er error) {
+		data, er = obj.readAllContext(ctx)
+		return
+	})
+	return
+}
+
+// ReadBytesContext is like ReadBytes, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) ReadBytesContext(ctx context.Context, pos int64, length int) (data []byte, err error) {
+	err = runContext(ctx, func() (er error) {
+		data, er = obj.readBytesContext(ctx, pos, length)
+		return
+	})
+	return
+}
+
+// WriteContext is like Write, giving up once ctx is done while waiting for a busy session. Use WriteBytesContext to stop between chunks.
+func (obj *DataObj) WriteContext(ctx context.Context, data []byte) error {
+	return runContext(ctx, func() error {
+		return obj.writeAllContext(ctx, data)
+	})
+}
+
+// DownloadToContext is like DownloadTo, stopping between chunks once ctx is cancelled
+func (obj *DataObj) DownloadToContext(ctx context.Context, localPath string) error {
+	return obj.DownloadToParallelContext(ctx, localPath, TransferOptions{Concurrency: 1})
+}
+
+// DownloadToParallelContext is like DownloadToParallel, stopping between buffers once ctx is cancelled
+func (obj *DataObj) DownloadToParallelContext(ctx context.Context, localPath string, topts TransferOptions) error {
+	topts.Context = ctx
+	return obj.DownloadToParallel(localPath, topts)
+}
+
+// StatContext is like Stat, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) StatContext(ctx context.Context) (info map[string]interface{}, err error) {
+	err = runContext(ctx, func() (er error) {
+		info, er = obj.statContext(ctx)
+		return
+	})
+	return
+}
+
+// ChksumContext is like Chksum, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) ChksumContext(ctx context.Context) (sum string, err error) {
+	err = runContext(ctx, func() (er error) {
+		sum, er = obj.chksumContext(ctx)
+		return
+	})
+	return
+}
+
+// MetaContext is like Meta, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) MetaContext(ctx context.Context) (mc *MetaCollection, err error) {
+	err = runContext(ctx, func() (er error) {
+		mc, er = obj.metaContext(ctx)
+		return
+	})
+	return
+}
+
+// DeleteContext is like Delete, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) DeleteContext(ctx context.Context, recursive bool) error {
+	return runContext(ctx, func() error {
+		return obj.rmContext(ctx, recursive, true)
+	})
+}
+
+// CopyToContext is like CopyTo, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) CopyToContext(ctx context.Context, iRODSCollection interface{}) error {
+	return runContext(ctx, func() error {
+		return obj.copyToContext(ctx, iRODSCollection)
+	})
+}
+
+// MoveToContext is like MoveTo, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) MoveToContext(ctx context.Context, iRODSCollection interface{}) error {
+	return runContext(ctx, func() error {
+		return obj.moveToContext(ctx, iRODSCollection)
+	})
+}
+
+// RenameContext is like Rename, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) RenameContext(ctx context.Context, newFileName string) error {
+	return runContext(ctx, func() error {
+		return obj.renameContext(ctx, newFileName)
+	})
+}
+
+// ReplicateContext is like Replicate, giving up once ctx is done while waiting for a busy session
+func (obj *DataObj) ReplicateContext(ctx context.Context, targetResource interface{}, opts DataObjOptions) error {
+	return runContext(ctx, func() error {
+		return obj.replicateContext(ctx, targetResource, opts)
+	})
+}
+
+// AddContext is like Add, giving up once ctx is done while waiting for a busy session
+func (mc *MetaCollection) AddContext(ctx context.Context, m Meta) (nm *Meta, err error) {
+	err = runContext(ctx, func() (er error) {
+		nm, er = mc.addContext(ctx, m)
+		return
+	})
+	return
+}
+
+// DeleteContext is like Delete, giving up once ctx is done while waiting for a busy session
+func (mc *MetaCollection) DeleteContext(ctx context.Context, attr string) error {
+	return runContext(ctx, func() error {
+		return mc.deleteContext(ctx, attr)
+	})
+}
+
+// RefreshContext is like Refresh, giving up once ctx is done while waiting for a busy session
+func (mc *MetaCollection) RefreshContext(ctx context.Context) error {
+	return runContext(ctx, func() error {
+		return mc.refreshContext(ctx)
+	})
+}
+
+// FetchInfoContext is like FetchInfo, giving up once ctx is done while waiting for a busy session
+func (usr *User) FetchInfoContext(ctx context.Context) (info map[string]string, err error) {
+	err = runContext(ctx, func() (er error) {
+		info, er = usr.fetchInfoContext(ctx)
+		return
+	})
+	return
+}
+
+// DeleteContext is like Delete, giving up once ctx is done while waiting for a busy session
+func (usr *User) DeleteContext(ctx context.Context) error {
+	return runContext(ctx, func() error {
+		return usr.deleteContext(ctx)
+	})
+}
+
+// FetchUsersContext is like FetchUsers, giving up once ctx is done while waiting for a busy session
+func (grp *Group) FetchUsersContext(ctx context.Context) (usrs Users, err error) {
+	err = runContext(ctx, func() (er error) {
+		usrs, er = grp.fetchUsersContext(ctx)
+		return
+	})
+	return
+}
+
+// DeleteContext is like Delete, giving up once ctx is done while waiting for a busy session
+func (grp *Group) DeleteContext(ctx context.Context) error {
+	return runContext(ctx, func() error {
+		return grp.deleteContext(ctx)
+	})
+}
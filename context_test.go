@@ -0,0 +1,294 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// busyTransport waits for busy before every read and write, like the iRODS C API does for a session used by
+// another goroutine, unless ctx is done already. onWrite is called after each write.
+type busyTransport struct {
+	Transport
+	busy    chan struct{}
+	onWrite func(data []byte)
+}
+
+func (t *busyTransport) ReadContext(ctx context.Context, handle int, length int64) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-t.busy:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return t.Transport.Read(handle, length)
+}
+
+func (t *busyTransport) WriteContext(ctx context.Context, handle int, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case <-t.busy:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := t.Transport.Write(handle, data); err != nil {
+		return err
+	}
+
+	if t.onWrite != nil {
+		t.onWrite(data)
+	}
+
+	return nil
+}
+
+// free returns a channel letting every read and write through
+func free() chan struct{} {
+	busy := make(chan struct{})
+	close(busy)
+
+	return busy
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	if err := runContext(ctx, func() error { called = true; return nil }); err != context.Canceled || called {
+		t.Errorf("Expected a cancelled context to stop the call before it starts, got %v (called: %v)", err, called)
+	}
+
+	// Once started, the result of the call is returned even if ctx is cancelled meanwhile
+	ctx, cancel = context.WithCancel(context.Background())
+	if err := runContext(ctx, func() error { cancel(); return nil }); err != nil {
+		t.Errorf("Expected the result of the call, got %v", err)
+	}
+}
+
+func TestGetCconContextWaiting(t *testing.T) {
+	ccon := new(rcComm)
+	con := &Connection{cconBuffer: make(chan *rcComm, 1), sessions: []*session{newSession(ccon)}}
+	con.cconBuffer <- ccon
+
+	// Every handle is checked out
+	held, err := con.getCcon()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := con.getCconContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected to give up waiting for the handle, got %v", err)
+	}
+
+	con.returnCcon(held)
+
+	// The handle is free, but its session is busy with a data object handle
+	if _, _, err := con.handleCcon(0); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := con.getCconContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected to give up waiting for the session, got %v", err)
+	}
+	if len(con.cconBuffer) != 1 {
+		t.Error("Expected the handle to be returned after giving up on its session")
+	}
+
+	con.unlockSession(ccon)
+
+	if got, err := con.getCconContext(context.Background()); err != nil || got != ccon {
+		t.Errorf("Expected to get the handle, got %v", err)
+	}
+}
+
+// sessionTransport checks out a session of con for each Ping and Stat, like the iRODS C API's transport does
+type sessionTransport struct {
+	Transport
+	con *Connection
+	ctx context.Context
+}
+
+func (t *sessionTransport) withContext(ctx context.Context) Transport {
+	return &sessionTransport{Transport: t.Transport, con: t.con, ctx: ctx}
+}
+
+func (t *sessionTransport) session() error {
+	ctx := t.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ccon, err := t.con.getCconContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.con.returnCcon(ccon)
+
+	return nil
+}
+
+func (t *sessionTransport) Ping() error {
+	if err := t.session(); err != nil {
+		return err
+	}
+	return t.Transport.Ping()
+}
+
+func (t *sessionTransport) Stat(path string) (*TransportEntry, error) {
+	if err := t.session(); err != nil {
+		return nil, err
+	}
+	return t.Transport.Stat(path)
+}
+
+func TestContextSessionBusy(t *testing.T) {
+	con, _ := memDataObj(t, "context.txt", []byte("hello world\n"))
+
+	ccon := new(rcComm)
+	con.cconBuffer = make(chan *rcComm, 1)
+	con.sessions = []*session{newSession(ccon)}
+	con.cconBuffer <- ccon
+	con.transport = &sessionTransport{Transport: con.transport, con: con}
+
+	// Another goroutine holds the only session until release is closed
+	held, release, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ccon, err := con.getCcon()
+		if err != nil {
+			t.Error(err)
+			close(held)
+			return
+		}
+		close(held)
+
+		<-release
+		con.returnCcon(ccon)
+	}()
+	<-held
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := con.PingContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected PingContext to give up waiting for the session, got %v", err)
+	}
+	if _, err := con.PathTypeContext(ctx, "/tempZone/home/rods/context.txt"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected PathTypeContext to give up waiting for the session, got %v", err)
+	}
+	if _, err := con.CollectionContext(ctx, CollectionOptions{Path: "/tempZone/home/rods"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected CollectionContext to give up waiting for the session, got %v", err)
+	}
+
+	close(release)
+	<-done
+
+	if typ, err := con.PathTypeContext(context.Background(), "/tempZone/home/rods/context.txt"); err != nil || typ != DataObjType {
+		t.Errorf("Expected the data object once the session is free, got %v (%v)", typ, err)
+	}
+}
+
+func TestClientContextPoolExhausted(t *testing.T) {
+	client := memClient(t)
+	client.Pool = memPool(PoolOptions{MaxIdle: 1, MaxOpen: 1})
+	defer client.Pool.Close()
+
+	con, err := client.Pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Pool.Put(con)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	called := false
+	if err := client.OpenConnectionContext(ctx, func(*Connection) { called = true }); !errors.Is(err, context.DeadlineExceeded) || called {
+		t.Errorf("Expected to give up waiting for a connection from the pool, got %v (called: %v)", err, called)
+	}
+}
+
+func TestReadChunkContextWaiting(t *testing.T) {
+	con, obj := memDataObj(t, "context.txt", []byte("hello world\n"))
+	con.transport = &busyTransport{Transport: con.transport, busy: make(chan struct{})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	chunks := 0
+	if err := obj.ReadChunkContext(ctx, 4, func([]byte) { chunks++ }); err != context.DeadlineExceeded || chunks != 0 {
+		t.Errorf("Expected to give up waiting before the first chunk, got %v after %v chunks", err, chunks)
+	}
+}
+
+func TestReadChunkContextBetweenChunks(t *testing.T) {
+	con, obj := memDataObj(t, "context.txt", []byte("hello world\n"))
+	con.transport = &busyTransport{Transport: con.transport, busy: free()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var read []byte
+	err := obj.ReadChunkContext(ctx, 4, func(chunk []byte) {
+		read = append(read, chunk...)
+		cancel()
+	})
+
+	if err != context.Canceled || string(read) != "hell" {
+		t.Errorf("Expected to stop after the first chunk, got %q (%v)", read, err)
+	}
+}
+
+func TestWriteBytesContext(t *testing.T) {
+	con, obj := memDataObj(t, "context.txt", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	writes := 0
+	con.transport = &busyTransport{Transport: con.transport, busy: free(), onWrite: func([]byte) {
+		writes++
+		cancel()
+	}}
+
+	data := bytes.Repeat([]byte{1}, DefaultTransferBufferSize+10)
+
+	if err := obj.WriteBytesContext(ctx, data); err != context.Canceled || writes != 1 {
+		t.Errorf("Expected to stop after the first chunk, got %v after %v writes", err, writes)
+	}
+	if obj.offset != DefaultTransferBufferSize {
+		t.Errorf("Expected the first chunk to be written, offset is %v", obj.offset)
+	}
+
+	// Waiting for a busy session
+	con.transport.(*busyTransport).busy = make(chan struct{})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := obj.WriteBytesContext(ctx, data); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected to give up waiting for the session, got %v", err)
+	}
+}
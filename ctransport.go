@@ -0,0 +1,1289 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// cTransport is the default Transport, it uses the iRODS C API over the connection's rcComm_t handle
+type cTransport struct {
+	con *Connection
+
+	// ctx bounds the wait for a session, see withContext
+	ctx context.Context
+}
+
+// withContext returns a cTransport of the same connection, whose calls give up waiting for a session once ctx is done
+func (t *cTransport) withContext(ctx context.Context) Transport {
+	return &cTransport{con: t.con, ctx: ctx}
+}
+
+// context returns the context the transport is bound to
+func (t *cTransport) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+
+	return t.ctx
+}
+
+// getCcon checks out a session of the connection, waiting for it as long as the bound context allows
+func (t *cTransport) getCcon() (*rcComm, error) {
+	return t.con.getCconContext(t.context())
+}
+
+func cBool(b bool) C.int {
+	if b {
+		return C.int(1)
+	}
+	return C.int(0)
+}
+
+// stringResult converts a goRodsStringResult_t to a string slice, and frees it
+func stringResult(result *C.goRodsStringResult_t) []string {
+	defer C.gorods_free_string_result(result)
+
+	arrLen := int(result.size)
+
+	// Convert C array to slice, backed by arr *C.char
+	slice := (*[1 << 30]*C.char)(unsafe.Pointer(result.strArr))[:arrLen:arrLen]
+
+	response := make([]string, 0, arrLen)
+
+	for _, str := range slice {
+		response = append(response, C.GoString(str))
+	}
+
+	return response
+}
+
+func cTimeToTime(cTime *C.char) time.Time {
+	unixStamp, _ := strconv.ParseInt(C.GoString(cTime), 10, 64)
+	return time.Unix(unixStamp, 0)
+}
+
+// splitLines splits the newline separated values returned by some of the wrapper functions
+func splitLines(strs []string) []string {
+	response := make([]string, 0, len(strs))
+
+	for _, str := range strs {
+		for _, line := range strings.Split(strings.Trim(str, " \n"), "\n") {
+			if line != "" {
+				response = append(response, line)
+			}
+		}
+	}
+
+	return response
+}
+
+// infoResult converts "key: value" lines returned by the wrapper functions into a map, and frees result
+func infoResult(result *C.goRodsStringResult_t) map[string]string {
+	response := make(map[string]string)
+
+	for _, attr := range splitLines(stringResult(result)) {
+		split := strings.SplitN(attr, ": ", 2)
+
+		if len(split) == 2 {
+			response[split[0]] = split[1]
+		} else {
+			response[split[0]] = ""
+		}
+	}
+
+	return response
+}
+
+func collEntToEntry(data *C.collEnt_t) *TransportEntry {
+	entry := new(TransportEntry)
+
+	entry.OwnerName = C.GoString(data.ownerName)
+	entry.CreateTime = cTimeToTime(data.createTime)
+	entry.ModifyTime = cTimeToTime(data.modifyTime)
+
+	if data.objType == C.DATA_OBJ_T {
+		entry.Type = DataObjType
+		entry.Path = C.GoString(data.collName) + "/" + C.GoString(data.dataName)
+		entry.Size = int64(data.dataSize)
+		entry.Checksum = C.GoString(data.chksum)
+		entry.DataId = C.GoString(data.dataId)
+		entry.PhyPath = C.GoString(data.phyPath)
+		entry.Resource = C.GoString(data.resource)
+		entry.RescHier = C.GoString(data.resc_hier)
+		entry.ReplNum = int(data.replNum)
+		entry.ReplStatus = int(data.replStatus)
+	} else {
+		entry.Type = CollectionType
+		entry.Path = C.GoString(data.collName)
+	}
+
+	return entry
+}
+
+func (t *cTransport) Disconnect() error {
+	return t.con.eachSession(cDisconnect)
+}
+
+func (t *cTransport) Ping() error {
+	var errMsg *C.char
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_ping(ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) LocalZone() (string, error) {
+	var (
+		cZoneName *C.char
+		err       *C.char
+	)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return "", er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_local_zone(ccon, &cZoneName, &err); status != 0 {
+		return "", NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.free(unsafe.Pointer(cZoneName))
+
+	return strings.Trim(C.GoString(cZoneName), " \n"), nil
+}
+
+func (t *cTransport) Stat(path string) (*TransportEntry, error) {
+	var (
+		err        *C.char
+		statResult *C.rodsObjStat_t
+	)
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_stat_dataobject(cPath, &statResult, ccon, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.freeRodsObjStat(statResult)
+
+	entry := new(TransportEntry)
+
+	switch statResult.objType {
+	case C.DATA_OBJ_T:
+		entry.Type = DataObjType
+	case C.COLL_OBJ_T:
+		entry.Type = CollectionType
+	default:
+		entry.Type = UnknownType
+	}
+
+	entry.Path = path
+	entry.Size = int64(statResult.objSize)
+	entry.Mode = int(statResult.dataMode)
+	entry.DataId = C.GoString(&statResult.dataId[0])
+	entry.Checksum = C.GoString(&statResult.chksum[0])
+	entry.OwnerName = C.GoString(&statResult.ownerName[0])
+	entry.OwnerZone = C.GoString(&statResult.ownerZone[0])
+	entry.CreateTime = cTimeToTime(&statResult.createTime[0])
+	entry.ModifyTime = cTimeToTime(&statResult.modifyTime[0])
+
+	return entry, nil
+}
+
+func (t *cTransport) DataObj(path string) (*TransportEntry, error) {
+	var cObjData C.collEnt_t
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_dataobject(ccon, cPath, &cObjData); status < 0 {
+		return nil, NewTransportError(int(status), path)
+	}
+
+	return collEntToEntry(&cObjData), nil
+}
+
+// openCollection opens a collection handle, the caller must hold ccon and close the handle
+func openCollection(path string, trimRepls bool, handle *C.collHandle_t, ccon *C.rcComm_t) error {
+	var errMsg *C.char
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	if status := C.gorods_open_collection(cPath, cBool(trimRepls), handle, ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) List(path string, trimRepls bool) ([]*TransportEntry, error) {
+	var (
+		handle C.collHandle_t
+		colEnt C.collEnt_t
+		errMsg *C.char
+	)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if er := openCollection(path, trimRepls, &handle, ccon); er != nil {
+		return nil, er
+	}
+
+	handle.genQueryInp.options = C.RETURN_TOTAL_ROW_COUNT
+
+	entries := make([]*TransportEntry, 0)
+
+	for int(C.rclReadCollection(ccon, &handle, &colEnt)) >= 0 {
+		entries = append(entries, collEntToEntry(&colEnt))
+	}
+
+	if status := C.gorods_close_collection(&handle, &errMsg); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return entries, nil
+}
+
+func (t *cTransport) ListPage(path string, trimRepls bool, offset int, limit int) (*TransportPage, error) {
+	var (
+		handle C.collHandle_t
+		colEnt C.collEnt_t
+		cOpts  C.goRodsQueryOpts_t
+		errMsg *C.char
+	)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if er := openCollection(path, trimRepls, &handle, ccon); er != nil {
+		return nil, er
+	}
+
+	page := new(TransportPage)
+
+	cOpts.limit = C.int(limit)
+	cOpts.offset = C.int(offset)
+
+	for int(C.gorods_rclReadCollectionCols(ccon, &handle, &colEnt, cOpts)) >= 0 {
+		page.Collections = append(page.Collections, collEntToEntry(&colEnt))
+	}
+
+	page.ColTotal = int(handle.collSqlResult.totalRowCount)
+	colCnt := int(handle.collSqlResult.rowCnt)
+
+	C.clearCollSqlResult(&handle.collSqlResult)
+
+	newLimit := limit - colCnt
+	newOffset := offset
+
+	if colCnt == 0 && page.ColTotal > 0 {
+		newOffset = offset - page.ColTotal
+	}
+
+	// Don't grab any objects once the limit is reached
+	if newLimit != 0 {
+		cOpts.limit = C.int(newLimit)
+		cOpts.offset = C.int(newOffset)
+
+		for int(C.gorods_rclReadCollectionObjs(ccon, &handle, &colEnt, cOpts)) >= 0 {
+			page.DataObjs = append(page.DataObjs, collEntToEntry(&colEnt))
+		}
+
+		page.ObjTotal = int(handle.dataObjSqlResult.totalRowCount)
+
+		C.clearDataObjSqlResult(&handle.dataObjSqlResult)
+	}
+
+	if status := C.gorods_close_collection(&handle, &errMsg); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return page, nil
+}
+
+func (t *cTransport) CreateCollection(path string) error {
+	var errMsg *C.char
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_create_collection(cPath, ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) CreateDataObj(path string, size int64, mode int, force bool, resource string) error {
+	var (
+		errMsg *C.char
+		handle C.int
+	)
+
+	cPath := C.CString(path)
+	cResource := C.CString(resource)
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cResource))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_create_dataobject(cPath, C.rodsLong_t(size), C.int(mode), cBool(force), cResource, &handle, ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	// The data object is opened by its own handle when needed, release the one used for creation
+	if status := C.gorods_close_dataobject(handle, ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Put(localPath string, path string, size int64, mode int, force bool, resource string) error {
+	var errMsg *C.char
+
+	cPath := C.CString(path)
+	cLocalPath := C.CString(localPath)
+	cResource := C.CString(resource)
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cLocalPath))
+	defer C.free(unsafe.Pointer(cResource))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_put_dataobject(cLocalPath, cPath, C.rodsLong_t(size), C.int(mode), cBool(force), cResource, ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Remove(path string, isCollection bool, recursive bool, force bool, rmTrash bool) error {
+	var errMsg *C.char
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_rm(cPath, cBool(isCollection), cBool(recursive), cBool(force), cBool(rmTrash), ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Move(src string, dst string, isCollection bool) error {
+	var (
+		err     *C.char
+		oprType C.int = C.RENAME_DATA_OBJ
+	)
+
+	if isCollection {
+		oprType = C.RENAME_COLL
+	}
+
+	s := C.CString(src)
+	d := C.CString(dst)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(d))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_move_dataobject(s, d, oprType, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Copy(src string, dst string, force bool, resource string) error {
+	var err *C.char
+
+	s := C.CString(src)
+	d := C.CString(dst)
+	cResource := C.CString(resource)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(d))
+	defer C.free(unsafe.Pointer(cResource))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_copy_dataobject(s, d, cBool(force), cResource, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Checksum(path string) (string, error) {
+	var (
+		err       *C.char
+		chksumOut *C.char
+	)
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return "", er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_checksum_dataobject(cPath, &chksumOut, ccon, &err); status != 0 {
+		return "", NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.free(unsafe.Pointer(chksumOut))
+
+	return C.GoString(chksumOut), nil
+}
+
+func (t *cTransport) Open(path string, resource string, replNum int, flags int) (int, error) {
+	var (
+		errMsg *C.char
+		handle C.int
+		cFlags C.int = C.O_RDONLY
+	)
+
+	if flags == os.O_RDWR {
+		cFlags = C.O_RDWR
+	}
+
+	cPath := C.CString(path)
+	cResource := C.CString(resource)
+	cReplNum := C.CString(strconv.Itoa(replNum))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cResource))
+	defer C.free(unsafe.Pointer(cReplNum))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return -1, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_open_dataobject(cPath, cResource, cReplNum, cFlags, &handle, ccon, &errMsg); status != 0 {
+		return -1, NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	// Later calls on the handle need to go to the same session
+	return t.con.sessionHandle(ccon, int(handle)), nil
+}
+
+func (t *cTransport) Read(handle int, length int64) ([]byte, error) {
+	return t.ReadContext(t.context(), handle, length)
+}
+
+// ReadContext is like Read, but gives up waiting for the session of handle once ctx is done
+func (t *cTransport) ReadContext(ctx context.Context, handle int, length int64) ([]byte, error) {
+	var (
+		buffer    C.bytesBuf_t
+		err       *C.char
+		bytesRead C.int
+	)
+
+	ccon, cHandle, er := t.con.handleCconContext(ctx, handle)
+	if er != nil {
+		return nil, er
+	}
+	defer t.con.unlockSession(ccon)
+
+	if status := C.gorods_read_dataobject(C.int(cHandle), C.rodsLong_t(length), &buffer, &bytesRead, ccon, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	buf := unsafe.Pointer(buffer.buf)
+	defer C.free(buf)
+
+	return C.GoBytes(buf, bytesRead), nil
+}
+
+func (t *cTransport) Write(handle int, data []byte) error {
+	return t.WriteContext(t.context(), handle, data)
+}
+
+// WriteContext is like Write, but gives up waiting for the session of handle once ctx is done
+func (t *cTransport) WriteContext(ctx context.Context, handle int, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var err *C.char
+
+	ccon, cHandle, er := t.con.handleCconContext(ctx, handle)
+	if er != nil {
+		return er
+	}
+	defer t.con.unlockSession(ccon)
+
+	if status := C.gorods_write_dataobject(C.int(cHandle), unsafe.Pointer(&data[0]), C.int(len(data)), ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Seek(handle int, offset int64) error {
+	var err *C.char
+
+	ccon, cHandle, er := t.con.handleCconContext(t.context(), handle)
+	if er != nil {
+		return er
+	}
+	defer t.con.unlockSession(ccon)
+
+	if status := C.gorods_lseek_dataobject(C.int(cHandle), C.rodsLong_t(offset), ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Close(handle int) error {
+	var errMsg *C.char
+
+	// Closing waits for the session whatever the context, so the handle isn't leaked
+	ccon, cHandle, er := t.con.handleCcon(handle)
+	if er != nil {
+		return er
+	}
+	defer t.con.unlockSession(ccon)
+
+	if status := C.gorods_close_dataobject(C.int(cHandle), ccon, &errMsg); status != 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Meta(typ int, path string, zone string) (Metas, error) {
+	var (
+		err        *C.char
+		metaResult C.goRodsMetaResult_t
+		status     C.int
+	)
+
+	name := C.CString(filepath.Base(path))
+	cwd := C.CString(filepath.Dir(path))
+	cZone := C.CString(zone)
+	defer C.free(unsafe.Pointer(name))
+	defer C.free(unsafe.Pointer(cwd))
+	defer C.free(unsafe.Pointer(cZone))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	switch typ {
+	case DataObjType:
+		status = C.gorods_meta_dataobj(name, cwd, cZone, &metaResult, ccon, &err)
+	case CollectionType:
+		status = C.gorods_meta_collection(name, cwd, cZone, &metaResult, ccon, &err)
+	case UserType, GroupType, AdminType, GroupAdminType:
+		status = C.gorods_meta_user(name, cZone, &metaResult, ccon, &err)
+	default:
+		return nil, NewTransportError(-1, "unrecognized meta type constant")
+	}
+
+	if status == C.CAT_NO_ROWS_FOUND {
+		return Metas{}, nil
+	} else if status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	defer C.freeGoRodsMetaResult(&metaResult)
+
+	size := int(metaResult.size)
+
+	slice := (*[1 << 30]C.goRodsMeta_t)(unsafe.Pointer(metaResult.metaArr))[:size:size]
+
+	response := make(Metas, 0, size)
+
+	for _, meta := range slice {
+		response = append(response, &Meta{
+			Attribute: C.GoString(meta.name),
+			Value:     C.GoString(meta.value),
+			Units:     C.GoString(meta.units),
+		})
+	}
+
+	return response, nil
+}
+
+func (t *cTransport) AddMeta(typ int, path string, m Meta) error {
+	var err *C.char
+
+	mT := C.CString(GetShortTypeString(typ))
+	cPath := C.CString(path)
+	na := C.CString(m.Attribute)
+	nv := C.CString(m.Value)
+	nu := C.CString(m.Units)
+	defer C.free(unsafe.Pointer(mT))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(na))
+	defer C.free(unsafe.Pointer(nv))
+	defer C.free(unsafe.Pointer(nu))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_add_meta(mT, cPath, na, nv, nu, ccon, &err); status < 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) RemoveMeta(typ int, path string, m Meta) error {
+	var err *C.char
+
+	mT := C.CString(GetShortTypeString(typ))
+	cPath := C.CString(path)
+	oa := C.CString(m.Attribute)
+	ov := C.CString(m.Value)
+	ou := C.CString(m.Units)
+	defer C.free(unsafe.Pointer(mT))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(oa))
+	defer C.free(unsafe.Pointer(ov))
+	defer C.free(unsafe.Pointer(ou))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_rm_meta(mT, cPath, oa, ov, ou, ccon, &err); status < 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) ModMeta(typ int, path string, old Meta, updated Meta) error {
+	var err *C.char
+
+	mT := C.CString(GetShortTypeString(typ))
+	cPath := C.CString(path)
+	oa := C.CString(old.Attribute)
+	ov := C.CString(old.Value)
+	ou := C.CString(old.Units)
+	na := C.CString(updated.Attribute)
+	nv := C.CString(updated.Value)
+	nu := C.CString(updated.Units)
+	defer C.free(unsafe.Pointer(mT))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(oa))
+	defer C.free(unsafe.Pointer(ov))
+	defer C.free(unsafe.Pointer(ou))
+	defer C.free(unsafe.Pointer(na))
+	defer C.free(unsafe.Pointer(nv))
+	defer C.free(unsafe.Pointer(nu))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_mod_meta(mT, cPath, oa, ov, ou, na, nv, nu, ccon, &err); status < 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+// aclResult converts a goRodsACLResult_t to a slice of *TransportACL, and frees it
+func aclResult(result *C.goRodsACLResult_t) []*TransportACL {
+	defer C.gorods_free_acl_result(result)
+
+	arrLen := int(result.size)
+
+	// Convert C array to slice, backed by arr *C.goRodsACL_t
+	slice := (*[1 << 30]C.goRodsACL_t)(unsafe.Pointer(result.aclArr))[:arrLen:arrLen]
+
+	response := make([]*TransportACL, 0, arrLen)
+
+	for _, acl := range slice {
+		response = append(response, &TransportACL{
+			Name:   C.GoString(acl.name),
+			Type:   C.GoString(acl.acltype),
+			Access: C.GoString(acl.dataAccess),
+		})
+	}
+
+	return response
+}
+
+func (t *cTransport) DataObjACL(dataId string, zone string) ([]*TransportACL, error) {
+	var (
+		result C.goRodsACLResult_t
+		err    *C.char
+	)
+
+	cDataId := C.CString(dataId)
+	zoneHint := C.CString(zone)
+	defer C.free(unsafe.Pointer(cDataId))
+	defer C.free(unsafe.Pointer(zoneHint))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_dataobject_acl(ccon, cDataId, &result, zoneHint, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return aclResult(&result), nil
+}
+
+func (t *cTransport) CollectionACL(path string, zone string) ([]*TransportACL, error) {
+	var (
+		result C.goRodsACLResult_t
+		err    *C.char
+	)
+
+	collName := C.CString(path)
+	zoneHint := C.CString(zone)
+	defer C.free(unsafe.Pointer(collName))
+	defer C.free(unsafe.Pointer(zoneHint))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_collection_acl(ccon, collName, &result, zoneHint, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return aclResult(&result), nil
+}
+
+func (t *cTransport) Chmod(path string, zone string, user string, access string, recursive bool) error {
+	var err *C.char
+
+	cUser := C.CString(user)
+	cPath := C.CString(path)
+	cZone := C.CString(zone)
+	cAccessLevel := C.CString(access)
+	defer C.free(unsafe.Pointer(cUser))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cZone))
+	defer C.free(unsafe.Pointer(cAccessLevel))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_chmod(ccon, cPath, cZone, cUser, cAccessLevel, cBool(recursive), &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) Inheritance(path string) (bool, error) {
+	var (
+		enabled C.int
+		err     *C.char
+	)
+
+	collName := C.CString(path)
+	defer C.free(unsafe.Pointer(collName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return false, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_collection_inheritance(ccon, collName, &enabled, &err); status != 0 {
+		return false, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return int(enabled) > 0, nil
+}
+
+func (t *cTransport) Users() ([]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_users(ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return splitLines(stringResult(&result)), nil
+}
+
+func (t *cTransport) Groups() ([]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_groups(ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return stringResult(&result), nil
+}
+
+func (t *cTransport) Zones() ([]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_zones(ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return splitLines(stringResult(&result)), nil
+}
+
+func (t *cTransport) Resources() ([]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_resources_new(ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return stringResult(&result), nil
+}
+
+func (t *cTransport) UserInfo(name string) (map[string]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_user(cName, ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return infoResult(&result), nil
+}
+
+func (t *cTransport) UserGroups(user string) ([]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	cName := C.CString(user)
+	defer C.free(unsafe.Pointer(cName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_user_groups(ccon, cName, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return stringResult(&result), nil
+}
+
+func (t *cTransport) GroupMembers(group string) ([]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	cGroupName := C.CString(group)
+	defer C.free(unsafe.Pointer(cGroupName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_group(ccon, &result, cGroupName, &err); status != 0 {
+		if status == C.CAT_NO_ROWS_FOUND {
+			return []string{}, nil
+		}
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return splitLines(stringResult(&result)), nil
+}
+
+func (t *cTransport) CreateUser(name string, zone string, typ string) error {
+	var err *C.char
+
+	cUserName := C.CString(name)
+	cZoneName := C.CString(zone)
+	cType := C.CString(typ)
+	defer C.free(unsafe.Pointer(cUserName))
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cType))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_create_user(cUserName, cZoneName, cType, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) DeleteUser(name string, zone string) error {
+	var err *C.char
+
+	cUserName := C.CString(name)
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cUserName))
+	defer C.free(unsafe.Pointer(cZoneName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_delete_user(cUserName, cZoneName, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) ChangePassword(user string, newPass string, myPass string) error {
+	var err *C.char
+
+	cUserName := C.CString(user)
+	cNewPass := C.CString(newPass)
+	cMyPass := C.CString(myPass)
+	defer C.free(unsafe.Pointer(cUserName))
+	defer C.free(unsafe.Pointer(cNewPass))
+	defer C.free(unsafe.Pointer(cMyPass))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_change_user_password(cUserName, cNewPass, cMyPass, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) CreateGroup(name string, zone string) error {
+	var err *C.char
+
+	cGroupName := C.CString(name)
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cGroupName))
+	defer C.free(unsafe.Pointer(cZoneName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_create_group(cGroupName, cZoneName, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) DeleteGroup(name string, zone string) error {
+	var err *C.char
+
+	cGroupName := C.CString(name)
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cGroupName))
+	defer C.free(unsafe.Pointer(cZoneName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_delete_group(cGroupName, cZoneName, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) AddToGroup(user string, zone string, group string) error {
+	var err *C.char
+
+	cUserName := C.CString(user)
+	cZoneName := C.CString(zone)
+	cGroupName := C.CString(group)
+	defer C.free(unsafe.Pointer(cUserName))
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cGroupName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_add_user_to_group(cUserName, cZoneName, cGroupName, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) RemoveFromGroup(user string, zone string, group string) error {
+	var err *C.char
+
+	cUserName := C.CString(user)
+	cZoneName := C.CString(zone)
+	cGroupName := C.CString(group)
+	defer C.free(unsafe.Pointer(cUserName))
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cGroupName))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_remove_user_from_group(cUserName, cZoneName, cGroupName, ccon, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+func (t *cTransport) ZoneInfo(name string) (map[string]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	cZone := C.CString(name)
+	defer C.free(unsafe.Pointer(cZone))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_zone(cZone, ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return infoResult(&result), nil
+}
+
+func (t *cTransport) ResourceInfo(name string) (map[string]string, error) {
+	var (
+		result C.goRodsStringResult_t
+		err    *C.char
+	)
+
+	result.size = C.int(0)
+
+	cResource := C.CString(name)
+	defer C.free(unsafe.Pointer(cResource))
+
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	if status := C.gorods_get_resource(cResource, ccon, &result, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return infoResult(&result), nil
+}
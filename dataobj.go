@@ -3,16 +3,13 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 	"unsafe"
@@ -35,7 +32,7 @@ type DataObj struct {
 	resource *Resource
 	phyPath  string
 
-	openedAs C.int
+	openedAs int
 
 	ownerName string
 	owner     *User
@@ -51,7 +48,7 @@ type DataObj struct {
 	// Col field is a pointer to the Collection containing the data object
 	col *Collection
 
-	chandle C.int
+	handle int
 }
 
 // Reader provides an io.Reader interface for *gorods.DataObj
@@ -95,13 +92,16 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 	return
 }
 
-// DataObjOptions is used for passing options to the CreateDataObj and DataObj.Copy function
+// DataObjOptions is used for passing options to the CreateDataObj and DataObj.Copy function.
+// When Verify is set, Collection.Put and PutParallel compute the checksum of the local file while it's uploaded,
+// then have the server checksum the new data object and fail with an error matching ErrChecksumMismatch if they differ.
 type DataObjOptions struct {
 	Name     string
 	Size     int64
 	Mode     int
 	Force    bool
 	Resource interface{}
+	Verify   bool
 }
 
 // String returns path of data object
@@ -111,7 +111,7 @@ func (obj *DataObj) String() string {
 
 // init function called from Collection.ReadCollection()
 // We don't init() here or return errors here because it takes forever. Lazy loading is better in this case.
-func initDataObj(data *C.collEnt_t, col *Collection, con *Connection) *DataObj {
+func initDataObj(data *TransportEntry, col *Collection, con *Connection) *DataObj {
 
 	dataObj := new(DataObj)
 
@@ -119,27 +119,27 @@ func initDataObj(data *C.collEnt_t, col *Collection, con *Connection) *DataObj {
 	dataObj.col = col
 	dataObj.con = con
 	dataObj.offset = 0
-	dataObj.name = C.GoString(data.dataName)
-	dataObj.path = C.GoString(data.collName) + "/" + dataObj.name
-	dataObj.size = int64(data.dataSize)
-	dataObj.chandle = C.int(-1)
-	dataObj.checksum = C.GoString(data.chksum)
-	dataObj.dataId = C.GoString(data.dataId)
-	dataObj.phyPath = C.GoString(data.phyPath)
-	dataObj.openedAs = C.int(-1)
-
-	dataObj.replNum = int(data.replNum)
-	dataObj.rescHier = C.GoString(data.resc_hier)
-	dataObj.replStatus = int(data.replStatus)
-
-	dataObj.ownerName = C.GoString(data.ownerName)
-	dataObj.createTime = cTimeToTime(data.createTime)
-	dataObj.modifyTime = cTimeToTime(data.modifyTime)
+	dataObj.name = filepath.Base(data.Path)
+	dataObj.path = data.Path
+	dataObj.size = data.Size
+	dataObj.handle = -1
+	dataObj.checksum = data.Checksum
+	dataObj.dataId = data.DataId
+	dataObj.phyPath = data.PhyPath
+	dataObj.openedAs = -1
+
+	dataObj.replNum = data.ReplNum
+	dataObj.rescHier = data.RescHier
+	dataObj.replStatus = data.ReplStatus
+
+	dataObj.ownerName = data.OwnerName
+	dataObj.createTime = data.CreateTime
+	dataObj.modifyTime = data.ModifyTime
 
 	if rsrcs, err := dataObj.con.Resources(); err != nil {
 		return nil
 	} else {
-		if r := rsrcs.FindByName(C.GoString(data.resource)); r != nil {
+		if r := rsrcs.FindByName(data.Resource); r != nil {
 			dataObj.resource = r
 		}
 	}
@@ -175,7 +175,7 @@ func getDataObjByParentCollection(startPath string, con *Connection) (*DataObj,
 
 			return obj, nil
 		} else {
-			return nil, newError(Fatal, -1, fmt.Sprintf("Can't find DataObj within collection %v", collectionDir))
+			return nil, newError(Fatal, -1, fmt.Sprintf("Can't find DataObj within collection %v", collectionDir)).withPath(startPath).wrap(ErrNotFound)
 		}
 	} else {
 		return nil, err
@@ -185,22 +185,13 @@ func getDataObjByParentCollection(startPath string, con *Connection) (*DataObj,
 
 // getDataObj initializes specified data object located at startPath using gorods.connection.
 // Called by Connection.DataObject()
-func getDataObj(startPath string, con *Connection) (*DataObj, error) {
-
-	var cObjData C.collEnt_t
-
-	ccon := con.GetCcon()
+func getDataObj(ctx context.Context, startPath string, con *Connection) (*DataObj, error) {
 
-	cPath := C.CString(startPath)
-	defer C.free(unsafe.Pointer(cPath))
-
-	if status := C.gorods_get_dataobject(ccon, cPath, &cObjData); status < 0 {
-		con.ReturnCcon(ccon)
-		return nil, newError(Fatal, -1, fmt.Sprintf("Error getting data object at %v", startPath))
+	entry, er := con.transportContext(ctx).DataObj(startPath)
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("Error getting data object at %v", startPath)).withPath(startPath)
 	}
 
-	con.ReturnCcon(ccon)
-
 	collectionDir := filepath.Dir(startPath)
 
 	opts := CollectionOptions{
@@ -208,11 +199,11 @@ func getDataObj(startPath string, con *Connection) (*DataObj, error) {
 		Recursive: false,
 	}
 
-	if col, err := con.Collection(opts); err == nil {
-		return initDataObj(&cObjData, col, con), nil
+	if col, err := con.collectionContext(ctx, opts); err == nil {
+		return initDataObj(entry, col, con), nil
 	} else {
 		// Couldn't open the parent collection...
-		return initDataObj(&cObjData, nil, con), nil
+		return initDataObj(entry, nil, con), nil
 	}
 
 }
@@ -220,51 +211,22 @@ func getDataObj(startPath string, con *Connection) (*DataObj, error) {
 // CreateDataObj creates and adds a data object to the specified collection using provided options. Returns the newly created data object.
 func CreateDataObj(opts DataObjOptions, coll *Collection) (*DataObj, error) {
 
-	var (
-		errMsg   *C.char
-		handle   C.int
-		force    int
-		resource *C.char
-	)
-
-	if opts.Force {
-		force = 1
-	} else {
-		force = 0
-	}
-
-	if opts.Resource != nil {
-		switch opts.Resource.(type) {
-		case string:
-			resource = C.CString(opts.Resource.(string))
-		case *Resource:
-			r := opts.Resource.(*Resource)
-			resource = C.CString(r.Name())
-		default:
-			return nil, newError(Fatal, -1, fmt.Sprintf("Wrong variable type passed in Resource field"))
-		}
-	} else {
-		resource = C.CString("")
+	resource, er := resourceName(opts.Resource)
+	if er != nil {
+		return nil, er
 	}
 
-	path := C.CString(coll.path + "/" + opts.Name)
-
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(resource))
-
-	ccon := coll.con.GetCcon()
+	path := coll.path + "/" + opts.Name
 
-	if status := C.gorods_create_dataobject(path, C.rodsLong_t(opts.Size), C.int(opts.Mode), C.int(force), resource, &handle, ccon, &errMsg); status != 0 {
-		coll.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Create DataObject Failed: %v, Does the file already exist?", C.GoString(errMsg)))
+	if er := coll.con.transport.CreateDataObj(path, opts.Size, opts.Mode, opts.Force, resource); er != nil {
+		return nil, transportError(er, "iRODS Create DataObject Failed").withPath(path)
 	}
-	coll.con.ReturnCcon(ccon)
 
 	// if err := coll.Refresh(); err != nil {
 	// 	return nil, err
 	// }
 
-	if do, err := getDataObj(C.GoString(path), coll.con); err != nil {
+	if do, err := getDataObj(context.Background(), path, coll.con); err != nil {
 		return nil, err
 	} else {
 		return do, nil
@@ -273,16 +235,24 @@ func CreateDataObj(opts DataObjOptions, coll *Collection) (*DataObj, error) {
 }
 
 func (obj *DataObj) init() error {
-	if int(obj.chandle) < 0 {
-		return obj.Open()
+	return obj.initContext(context.Background())
+}
+
+func (obj *DataObj) initContext(ctx context.Context) error {
+	if obj.handle < 0 {
+		return obj.openContext(ctx, os.O_RDONLY)
 	}
 
 	return nil
 }
 
 func (obj *DataObj) initRW() error {
-	if int(obj.chandle) < 0 {
-		return obj.OpenRW()
+	return obj.initRWContext(context.Background())
+}
+
+func (obj *DataObj) initRWContext(ctx context.Context) error {
+	if obj.handle < 0 {
+		return obj.openContext(ctx, os.O_RDWR)
 	}
 
 	return nil
@@ -304,33 +274,17 @@ func (obj *DataObj) Writer() *Writer {
 // designers#tempZone:read object]
 func (obj *DataObj) ACL() (ACLs, error) {
 
-	var (
-		result   C.goRodsACLResult_t
-		err      *C.char
-		zoneHint *C.char
-	)
-
-	zone, zErr := obj.con.LocalZone()
+	zone, zErr := obj.con.pathZone(obj.path)
 	if zErr != nil {
 		return nil, zErr
-	} else {
-		zoneHint = C.CString(zone.Name())
 	}
 
-	cDataId := C.CString(obj.dataId)
-	defer C.free(unsafe.Pointer(cDataId))
-	defer C.free(unsafe.Pointer(zoneHint))
-
-	ccon := obj.con.GetCcon()
-
-	if status := C.gorods_get_dataobject_acl(ccon, cDataId, &result, zoneHint, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Data Object ACL Failed: %v", C.GoString(err)))
+	acls, er := obj.con.transport.DataObjACL(obj.dataId, zone)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Data Object ACL Failed").withPath(obj.path)
 	}
 
-	obj.con.ReturnCcon(ccon)
-
-	return aclSliceToResponse(&result, obj.con)
+	return aclsToResponse(acls, obj.con)
 
 }
 
@@ -347,7 +301,7 @@ func (obj *DataObj) GrantAccess(userOrGroup AccessObject, accessLevel int, recur
 
 // Handle returns the internal handle index
 func (obj *DataObj) Handle() int {
-	return int(obj.chandle)
+	return obj.handle
 }
 
 // Type gets the type (DataObjType), used in interfaces
@@ -399,15 +353,17 @@ func (obj *DataObj) PhyPath() string {
 	return obj.phyPath
 }
 
-// ReplNum returns the replica index of the data object
+// ReplNum returns the replica index of the data object. Replicas returns every replica.
 func (obj *DataObj) ReplNum() int {
 	return obj.replNum
 }
 
+// RescHier returns the resource hierarchy of the data object's replica
 func (obj *DataObj) RescHier() string {
 	return obj.rescHier
 }
 
+// ReplStatus returns the status of the data object's replica, ReplicaGood or ReplicaStale
 func (obj *DataObj) ReplStatus() int {
 	return obj.replStatus
 }
@@ -470,30 +426,13 @@ func (obj *DataObj) Trash(recursive bool) error {
 
 // Rm is equivalent to irm {-r} {-f}
 func (obj *DataObj) Rm(recursive bool, force bool) error {
-	var errMsg *C.char
-
-	path := C.CString(obj.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	var (
-		cForce     C.int
-		cRecursive C.int
-	)
-
-	if force {
-		cForce = C.int(1)
-	}
-
-	if recursive {
-		cRecursive = C.int(1)
-	}
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+	return obj.rmContext(context.Background(), recursive, force)
+}
 
-	if status := C.gorods_rm(path, 0, cRecursive, cForce, C.int(0), ccon, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Rm DataObject Failed: %v", C.GoString(errMsg)))
+// rmContext is like Rm, but gives up waiting for a session once ctx is done
+func (obj *DataObj) rmContext(ctx context.Context, recursive bool, force bool) error {
+	if er := obj.con.transportContext(ctx).Remove(obj.path, false, recursive, force, false); er != nil {
+		return transportError(er, "iRODS Rm DataObject Failed").withPath(obj.path)
 	}
 
 	return nil
@@ -501,82 +440,68 @@ func (obj *DataObj) Rm(recursive bool, force bool) error {
 
 // RmTrash is used (sometimes internally) by GoRODS to delete items in the trash permanently. The data object's path should be in the trash collection.
 func (obj *DataObj) RmTrash() error {
-	var errMsg *C.char
-
-	path := C.CString(obj.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+	return obj.rmTrashContext(context.Background())
+}
 
-	if status := C.gorods_rm(path, C.int(0), C.int(1), C.int(1), C.int(1), ccon, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS RmTrash DataObject Failed: %v", C.GoString(errMsg)))
+// rmTrashContext is like RmTrash, but gives up waiting for a session once ctx is done
+func (obj *DataObj) rmTrashContext(ctx context.Context) error {
+	if er := obj.con.transportContext(ctx).Remove(obj.path, false, true, true, true); er != nil {
+		return transportError(er, "iRODS RmTrash DataObject Failed").withPath(obj.path)
 	}
 
 	return nil
 }
 
-// Open opens a connection to iRODS and sets the data object handle
-func (obj *DataObj) Open() error {
-	var errMsg *C.char
-
-	path := C.CString(obj.path)
-	resourceName := C.CString(obj.resource.Name())
-	replNum := C.CString(strconv.Itoa(obj.replNum))
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(resourceName))
-	defer C.free(unsafe.Pointer(replNum))
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
-
-	if status := C.gorods_open_dataobject(path, resourceName, replNum, C.O_RDONLY, &obj.chandle, ccon, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Open DataObject Failed: %v, %v", obj.path, C.GoString(errMsg)))
+// rescName returns the name of the resource the data object is opened on, or an empty string to let the server pick
+func (obj *DataObj) rescName() string {
+	if obj.resource == nil {
+		return ""
 	}
 
-	obj.openedAs = C.O_RDONLY
+	return obj.resource.Name()
+}
 
-	return nil
+// Open opens a connection to iRODS and sets the data object handle
+func (obj *DataObj) Open() error {
+	return obj.openContext(context.Background(), os.O_RDONLY)
 }
 
 // OpenRW opens a connection to iRODS and sets the data object handle for read/write access
 func (obj *DataObj) OpenRW() error {
-	var errMsg *C.char
-
-	path := C.CString(obj.path)
-	resourceName := C.CString(obj.resource.Name())
-	replNum := C.CString(strconv.Itoa(obj.replNum))
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(resourceName))
-	defer C.free(unsafe.Pointer(replNum))
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+	return obj.openContext(context.Background(), os.O_RDWR)
+}
 
-	if status := C.gorods_open_dataobject(path, resourceName, replNum, C.O_RDWR, &obj.chandle, ccon, &errMsg); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS OpenRW DataObject Failed: %v, %v", obj.path, C.GoString(errMsg)))
+// openContext opens the data object with flags (os.O_RDONLY or os.O_RDWR), giving up waiting for a session once ctx is done
+func (obj *DataObj) openContext(ctx context.Context, flags int) error {
+	handle, er := obj.con.transportContext(ctx).Open(obj.path, obj.rescName(), obj.replNum, flags)
+	if er != nil {
+		op := "Open"
+		if flags == os.O_RDWR {
+			op = "OpenRW"
+		}
+		return transportError(er, fmt.Sprintf("iRODS %v DataObject Failed: %v", op, obj.path)).withPath(obj.path)
 	}
 
-	obj.openedAs = C.O_RDWR
+	obj.handle = handle
+	obj.openedAs = flags
 
 	return nil
 }
 
 // Close closes the data object, resets handler
 func (obj *DataObj) Close() error {
-	var errMsg *C.char
+	if obj.handle > -1 {
 
-	if int(obj.chandle) > -1 {
+		if er := obj.con.transport.Close(obj.handle); er != nil {
+			// The handle is gone either way
+			if errors.Is(er, ErrHandleLost) {
+				obj.handle = -1
+			}
 
-		ccon := obj.con.GetCcon()
-		defer obj.con.ReturnCcon(ccon)
-
-		if status := C.gorods_close_dataobject(obj.chandle, ccon, &errMsg); status != 0 {
-			return newError(Fatal, status, fmt.Sprintf("iRODS Close DataObject Failed: %v, %v", obj.path, C.GoString(errMsg)))
+			return transportError(er, fmt.Sprintf("iRODS Close DataObject Failed: %v", obj.path)).withPath(obj.path)
 		}
 
-		obj.chandle = C.int(-1)
+		obj.handle = -1
 	}
 
 	return nil
@@ -584,209 +509,165 @@ func (obj *DataObj) Close() error {
 
 // Read reads the entire data object into memory and returns a []byte slice. Don't use this for large files.
 func (obj *DataObj) Read() ([]byte, error) {
-	if er := obj.init(); er != nil {
+	return obj.readAllContext(context.Background())
+}
+
+// readAllContext is like Read, but gives up waiting for a session once ctx is done
+func (obj *DataObj) readAllContext(ctx context.Context) ([]byte, error) {
+	if er := obj.initContext(ctx); er != nil {
 		return nil, er
 	}
 
-	var (
-		buffer    C.bytesBuf_t
-		err       *C.char
-		bytesRead C.int
-	)
-
-	if er := obj.LSeek(0); er != nil {
+	if er := obj.lSeekContext(ctx, 0); er != nil {
 		return nil, er
 	}
 
-	ccon := obj.con.GetCcon()
-
-	if status := C.gorods_read_dataobject(obj.chandle, C.rodsLong_t(obj.size), &buffer, &bytesRead, ccon, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Read DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+	data, er := obj.con.transportContext(ctx).Read(obj.handle, obj.size)
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("iRODS Read DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
-	obj.con.ReturnCcon(ccon)
-
-	buf := unsafe.Pointer(buffer.buf)
-	defer C.free(buf)
-
-	data := C.GoBytes(buf, bytesRead)
-
 	return data, obj.Close()
 }
 
+// ByteArr holds bytes returned by ReadChunkFree and FastReadFree. Ptr is only set when Contents is backed by
+// C memory, which must be released with Free.
 type ByteArr struct {
 	Contents []byte
 	Ptr      unsafe.Pointer
 }
 
 func (br ByteArr) Free() {
-	C.free(br.Ptr)
+	cFree(br.Ptr)
 }
 
-// ReadChunkFree is similar to ReadChunk, except it doesn't copy bytes into a new byte slice, making the process more efficient. It uses the existing C byte array and casts it as a go []byte. You must explicitally call ByteArr.Free on the returned struct or there will be a memory leak.
+// ReadChunkFree is like ReadChunk, passing each chunk as a *ByteArr. It's kept for compatibility: chunks are now
+// read into Go memory by the Transport, but ByteArr.Free should still be called on them.
 func (obj *DataObj) ReadChunkFree(size int64, callback func(*ByteArr)) error {
-	if er := obj.init(); er != nil {
-		return er
-	}
-
-	var (
-		buffer    C.bytesBuf_t
-		err       *C.char
-		bytesRead C.int
-	)
-
-	if er := obj.LSeek(0); er != nil {
-		return er
-	}
-
-	for obj.offset < obj.size {
-
-		ccon := obj.con.GetCcon()
-
-		if status := C.gorods_read_dataobject(obj.chandle, C.rodsLong_t(size), &buffer, &bytesRead, ccon, &err); status != 0 {
-			obj.con.ReturnCcon(ccon)
-			return newError(Fatal, status, fmt.Sprintf("iRODS Read DataObject Failed: %v, %v", obj.path, C.GoString(err)))
-		}
-
-		obj.con.ReturnCcon(ccon)
-
-		buf := unsafe.Pointer(buffer.buf)
-
-		bufLen := int(buffer.len)
-		data := (*[1 << 30]byte)(unsafe.Pointer(buf))[:bufLen:bufLen]
-
+	return obj.ReadChunk(size, func(chunk []byte) {
 		callback(&ByteArr{
-			Contents: data,
-			Ptr:      buf,
+			Contents: chunk,
 		})
-
-		if er := obj.LSeek(obj.offset + size); er != nil {
-			return er
-		}
-	}
-
-	if er := obj.LSeek(0); er != nil {
-		return er
-	}
-
-	return obj.Close()
+	})
 }
 
-// FastReadFree is similar to ReadBytes, except it doesn't copy bytes into a new byte slice, making the process more efficient. It uses the existing C byte array and casts it as a go []byte. You must explicitally call ByteArr.Free on the returned struct or there will be a memory leak.
+// FastReadFree is like ReadBytes, returning the bytes as a *ByteArr. It's kept for compatibility: bytes are now
+// read into Go memory by the Transport, but ByteArr.Free should still be called on the result.
 func (obj *DataObj) FastReadFree(pos int64, length int) (*ByteArr, error) {
-	if er := obj.init(); er != nil {
+	data, er := obj.ReadBytes(pos, length)
+	if er != nil {
 		return nil, er
 	}
 
-	var (
-		buffer    C.bytesBuf_t
-		err       *C.char
-		bytesRead C.int
-	)
-
-	if er := obj.LSeek(pos); er != nil {
-		return nil, er
-	}
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
-
-	if status := C.gorods_read_dataobject(obj.chandle, C.rodsLong_t(length), &buffer, &bytesRead, ccon, &err); status != 0 {
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS ReadBytes DataObject Failed: %v, %v", obj.path, C.GoString(err)))
-	}
-
-	buf := unsafe.Pointer(buffer.buf)
-
-	bufLen := int(buffer.len)
-	data := (*[1 << 30]byte)(unsafe.Pointer(buf))[:bufLen:bufLen]
-
 	return &ByteArr{
 		Contents: data,
-		Ptr:      buf,
 	}, nil
 }
 
-// FastRead is similar to ReadBytes, except it doesn't copy bytes into a new byte slice, making the process more efficient. It uses the existing C byte array and casts it as a go []byte. Once your call back is run, the allocated memory is freed automatically.
+// FastRead is like ReadBytes, passing the bytes to callback instead of returning them.
 // This function will block until bytes are received and your callback has been run.
 func (obj *DataObj) FastRead(pos int64, length int, callback func([]byte) error) error {
-	if er := obj.init(); er != nil {
+	data, er := obj.ReadBytes(pos, length)
+	if er != nil {
 		return er
 	}
 
-	var (
-		buffer    C.bytesBuf_t
-		err       *C.char
-		bytesRead C.int
-	)
-
-	if er := obj.LSeek(pos); er != nil {
-		return er
-	}
+	return callback(data)
+}
 
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+// ReadBytes reads bytes from a data object at the specified position and length, returns []byte slice and error.
+func (obj *DataObj) ReadBytes(pos int64, length int) ([]byte, error) {
+	return obj.readBytesContext(context.Background(), pos, length)
+}
 
-	if status := C.gorods_read_dataobject(obj.chandle, C.rodsLong_t(length), &buffer, &bytesRead, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS ReadBytes DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+// readBytesContext is like ReadBytes, but gives up waiting for a session once ctx is done
+func (obj *DataObj) readBytesContext(ctx context.Context, pos int64, length int) ([]byte, error) {
+	if er := obj.initContext(ctx); er != nil {
+		return nil, er
 	}
 
-	buf := unsafe.Pointer(buffer.buf)
-	defer C.free(buf)
+	if er := obj.lSeekContext(ctx, pos); er != nil {
+		return nil, er
+	}
 
-	bufLen := int(buffer.len)
+	data, er := obj.con.transportContext(ctx).Read(obj.handle, int64(length))
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("iRODS ReadBytes DataObject Failed: %v", obj.path)).withPath(obj.path)
+	}
 
-	data := (*[1 << 30]byte)(unsafe.Pointer(buf))[:bufLen:bufLen]
+	return data, nil
+}
 
-	return callback(data)
+// readNext reads up to length bytes from wherever the object's offset pointer is currently set to, and advances the pointer.
+// Unlike ReadBytes it doesn't seek before reading, saving a round trip for sequential reads.
+func (obj *DataObj) readNext(length int) ([]byte, error) {
+	return obj.readNextContext(context.Background(), length)
 }
 
-// ReadBytes reads bytes from a data object at the specified position and length, returns []byte slice and error.
-func (obj *DataObj) ReadBytes(pos int64, length int) ([]byte, error) {
-	if er := obj.init(); er != nil {
+// readNextContext is like readNext, but gives up waiting for the data object's session once ctx is done
+func (obj *DataObj) readNextContext(ctx context.Context, length int) ([]byte, error) {
+	if er := obj.initContext(ctx); er != nil {
 		return nil, er
 	}
 
-	var (
-		buffer    C.bytesBuf_t
-		err       *C.char
-		bytesRead C.int
-	)
+	data, er := readContext(ctx, obj.con.transport, obj.handle, int64(length))
+	if er != nil {
+		if cErr := ctx.Err(); cErr != nil {
+			return nil, cErr
+		}
+		return nil, transportError(er, fmt.Sprintf("iRODS Read DataObject Failed: %v", obj.path)).withPath(obj.path)
+	}
 
-	if er := obj.LSeek(pos); er != nil {
-		return nil, er
+	obj.offset += int64(len(data))
+
+	return data, nil
+}
+
+// writeNext writes data wherever the object's offset pointer is currently set to, and advances the pointer.
+// Unlike WriteBytes it doesn't seek after writing, saving a round trip for sequential writes.
+func (obj *DataObj) writeNext(data []byte) error {
+	return obj.writeNextContext(context.Background(), data)
+}
+
+// writeNextContext is like writeNext, but gives up waiting for the data object's session once ctx is done
+func (obj *DataObj) writeNextContext(ctx context.Context, data []byte) error {
+	if er := obj.initRWContext(ctx); er != nil {
+		return er
 	}
 
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+	if len(data) == 0 {
+		return nil
+	}
 
-	if status := C.gorods_read_dataobject(obj.chandle, C.rodsLong_t(length), &buffer, &bytesRead, ccon, &err); status != 0 {
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS ReadBytes DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+	if er := writeContext(ctx, obj.con.transport, obj.handle, data); er != nil {
+		if cErr := ctx.Err(); cErr != nil {
+			return cErr
+		}
+		return transportError(er, fmt.Sprintf("iRODS Write DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
-	buf := unsafe.Pointer(buffer.buf)
-	defer C.free(buf)
+	obj.offset += int64(len(data))
 
-	data := C.GoBytes(buf, bytesRead)
+	if obj.offset > obj.size {
+		obj.size = obj.offset
+	}
 
-	return data, nil
+	return nil
 }
 
 // LSeek sets the read/write offset pointer of a data object, returns error
 func (obj *DataObj) LSeek(offset int64) error {
-	if er := obj.init(); er != nil {
+	return obj.lSeekContext(context.Background(), offset)
+}
+
+// lSeekContext is like LSeek, but gives up waiting for a session once ctx is done
+func (obj *DataObj) lSeekContext(ctx context.Context, offset int64) error {
+	if er := obj.initContext(ctx); er != nil {
 		return er
 	}
 
-	var (
-		err *C.char
-	)
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
-
-	if status := C.gorods_lseek_dataobject(obj.chandle, C.rodsLong_t(offset), ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS LSeek DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+	if er := obj.con.transportContext(ctx).Seek(obj.handle, offset); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS LSeek DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
 	obj.offset = offset
@@ -796,99 +677,80 @@ func (obj *DataObj) LSeek(offset int64) error {
 
 // ReadChunk reads the entire data object in chunks (size of chunk specified by size parameter), passing the data into a callback function for each chunk. Use this to read/write large files.
 func (obj *DataObj) ReadChunk(size int64, callback func([]byte)) error {
-	if er := obj.init(); er != nil {
+	return obj.ReadChunkContext(context.Background(), size, callback)
+}
+
+// ReadChunkContext is like ReadChunk, but stops between chunks once ctx is cancelled or its deadline passes, returning ctx.Err().
+func (obj *DataObj) ReadChunkContext(ctx context.Context, size int64, callback func([]byte)) error {
+	if er := obj.initContext(ctx); er != nil {
 		return er
 	}
 
-	var (
-		buffer    C.bytesBuf_t
-		err       *C.char
-		bytesRead C.int
-	)
-
-	if er := obj.LSeek(0); er != nil {
+	if er := obj.lSeekContext(ctx, 0); er != nil {
 		return er
 	}
 
 	for obj.offset < obj.size {
 
-		ccon := obj.con.GetCcon()
-
-		if status := C.gorods_read_dataobject(obj.chandle, C.rodsLong_t(size), &buffer, &bytesRead, ccon, &err); status != 0 {
-			obj.con.ReturnCcon(ccon)
-			return newError(Fatal, status, fmt.Sprintf("iRODS Read DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+		// Stops between chunks, and while waiting for a session that's busy with another goroutine
+		chunk, er := readContext(ctx, obj.con.transport, obj.handle, size)
+		if er != nil {
+			if cErr := ctx.Err(); cErr != nil {
+				obj.Close()
+				return cErr
+			}
+			return transportError(er, fmt.Sprintf("iRODS Read DataObject Failed: %v", obj.path)).withPath(obj.path)
 		}
 
-		obj.con.ReturnCcon(ccon)
-
-		buf := unsafe.Pointer(buffer.buf)
-
-		chunk := C.GoBytes(buf, bytesRead)
-
-		C.free(buf)
-
 		callback(chunk)
 
-		if er := obj.LSeek(obj.offset + size); er != nil {
+		if er := obj.lSeekContext(ctx, obj.offset+size); er != nil {
 			return er
 		}
 	}
 
-	if er := obj.LSeek(0); er != nil {
+	if er := obj.lSeekContext(ctx, 0); er != nil {
 		return er
 	}
 
 	return obj.Close()
 }
 
-// DownloadTo downloads and writes the entire data object to the provided path. Don't use this with large files unless you have RAM to spare, use ReadChunk() instead. Returns error.
+// DownloadTo downloads and writes the entire data object to the provided path. The data is streamed to disk over a single connection,
+// use DownloadToParallel for large files. Returns error.
 func (obj *DataObj) DownloadTo(localPath string) error {
-	if er := obj.init(); er != nil {
-		return er
-	}
-
-	if objContents, err := obj.Read(); err != nil {
-		return err
-	} else {
-		if er := ioutil.WriteFile(localPath, objContents, 0644); er != nil {
-			return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, er))
-		}
-	}
-
-	return nil
+	return obj.DownloadToParallel(localPath, TransferOptions{
+		Concurrency: 1,
+	})
 }
 
 // Write writes the data to the data object, starting from the beginning. Returns error.
 func (obj *DataObj) Write(data []byte) error {
-	if er := obj.initRW(); er != nil {
+	return obj.writeAllContext(context.Background(), data)
+}
+
+// writeAllContext is like Write, but gives up waiting for a session once ctx is done
+func (obj *DataObj) writeAllContext(ctx context.Context, data []byte) error {
+	if er := obj.initRWContext(ctx); er != nil {
 		return er
 	}
 
-	if !(obj.openedAs == C.O_RDWR || obj.openedAs == C.O_WRONLY) {
+	if !(obj.openedAs == os.O_RDWR || obj.openedAs == os.O_WRONLY) {
 		obj.Close()
-		obj.OpenRW()
+		if er := obj.openContext(ctx, os.O_RDWR); er != nil {
+			return er
+		}
 	}
 
-	if er := obj.LSeek(0); er != nil {
+	if er := obj.lSeekContext(ctx, 0); er != nil {
 		return er
 	}
 
-	size := int64(len(data))
-
-	dataPointer := unsafe.Pointer(&data[0]) // Do I need to free this? It might be done by go
-
-	var err *C.char
-
-	ccon := obj.con.GetCcon()
-
-	if status := C.gorods_write_dataobject(obj.chandle, dataPointer, C.int(size), ccon, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return newError(Fatal, status, fmt.Sprintf("iRODS Write DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+	if er := obj.con.transportContext(ctx).Write(obj.handle, data); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Write DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
-	obj.con.ReturnCcon(ccon)
-
-	obj.size = size
+	obj.size = int64(len(data))
 
 	return obj.Close()
 }
@@ -899,29 +761,60 @@ func (obj *DataObj) WriteBytes(data []byte) error {
 		return er
 	}
 
-	if !(obj.openedAs == C.O_RDWR || obj.openedAs == C.O_WRONLY) {
+	if !(obj.openedAs == os.O_RDWR || obj.openedAs == os.O_WRONLY) {
 		obj.Close()
-		obj.OpenRW()
+		if er := obj.OpenRW(); er != nil {
+			return er
+		}
+	}
+
+	if er := obj.con.transport.Write(obj.handle, data); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Write DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
-	size := int64(len(data))
+	obj.size = int64(len(data)) + obj.offset
 
-	dataPointer := unsafe.Pointer(&data[0]) // Do I need to free this? It might be done by go
+	return obj.LSeek(obj.size)
+}
 
-	var err *C.char
+// WriteBytesContext is like WriteBytes, but sends data in chunks of DefaultTransferBufferSize bytes, stopping between chunks
+// once ctx is cancelled or its deadline passes. In that case ctx.Err() is returned, and only part of data has been written.
+func (obj *DataObj) WriteBytesContext(ctx context.Context, data []byte) error {
+	if er := ctx.Err(); er != nil {
+		return er
+	}
 
-	ccon := obj.con.GetCcon()
+	if er := obj.initRWContext(ctx); er != nil {
+		return er
+	}
 
-	if status := C.gorods_write_dataobject(obj.chandle, dataPointer, C.int(size), ccon, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return newError(Fatal, status, fmt.Sprintf("iRODS Write DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+	if !(obj.openedAs == os.O_RDWR || obj.openedAs == os.O_WRONLY) {
+		obj.Close()
+		if er := obj.openContext(ctx, os.O_RDWR); er != nil {
+			return er
+		}
 	}
 
-	obj.con.ReturnCcon(ccon)
+	for len(data) > 0 {
+		if er := ctx.Err(); er != nil {
+			return er
+		}
 
-	obj.size = size + obj.offset
+		n := len(data)
+		if n > DefaultTransferBufferSize {
+			n = DefaultTransferBufferSize
+		}
 
-	return obj.LSeek(obj.size)
+		if er := obj.writeNextContext(ctx, data[:n]); er != nil {
+			return er
+		}
+
+		data = data[n:]
+	}
+
+	obj.size = obj.offset
+
+	return obj.lSeekContext(ctx, obj.size)
 }
 
 // Stat returns a map (key/value pairs) of the system meta information. The following keys can be used with the map:
@@ -942,38 +835,17 @@ func (obj *DataObj) WriteBytes(data []byte) error {
 //
 // "modifyTime"
 func (obj *DataObj) Stat() (map[string]interface{}, error) {
+	return obj.statContext(context.Background())
+}
 
-	var (
-		err        *C.char
-		statResult *C.rodsObjStat_t
-	)
-
-	path := C.CString(obj.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
-
-	if status := C.gorods_stat_dataobject(path, &statResult, ccon, &err); status != 0 {
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Close Stat Failed: %v, %v", obj.path, C.GoString(err)))
+// statContext is like Stat, but gives up waiting for a session once ctx is done
+func (obj *DataObj) statContext(ctx context.Context) (map[string]interface{}, error) {
+	entry, er := obj.con.transportContext(ctx).Stat(obj.path)
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("iRODS Close Stat Failed: %v", obj.path)).withPath(obj.path)
 	}
 
-	result := make(map[string]interface{})
-
-	result["objSize"] = int(statResult.objSize)
-	result["dataMode"] = int(statResult.dataMode)
-
-	result["dataId"] = C.GoString(&statResult.dataId[0])
-	result["chksum"] = C.GoString(&statResult.chksum[0])
-	result["ownerName"] = C.GoString(&statResult.ownerName[0])
-	result["ownerZone"] = C.GoString(&statResult.ownerZone[0])
-	result["createTime"] = C.GoString(&statResult.createTime[0])
-	result["modifyTime"] = C.GoString(&statResult.modifyTime[0])
-
-	C.freeRodsObjStat(statResult)
-
-	return result, nil
+	return statToMap(entry), nil
 }
 
 // Attribute gets slice of Meta AVU triples, matching by Attribute name for DataObj
@@ -1009,9 +881,14 @@ func (obj *DataObj) DeleteMeta(attr string) (*MetaCollection, error) {
 
 // Meta returns collection of Meta AVU triple structs of the data object
 func (obj *DataObj) Meta() (*MetaCollection, error) {
+	return obj.metaContext(context.Background())
+}
+
+// metaContext is like Meta, but gives up waiting for a session once ctx is done
+func (obj *DataObj) metaContext(ctx context.Context) (*MetaCollection, error) {
 
 	if obj.metaCol == nil {
-		if mc, err := newMetaCollection(obj); err == nil {
+		if mc, err := newMetaCollection(ctx, obj); err == nil {
 			obj.metaCol = mc
 		} else {
 			return nil, err
@@ -1023,9 +900,13 @@ func (obj *DataObj) Meta() (*MetaCollection, error) {
 
 // CopyTo copies the data object to the specified collection. Supports Collection struct or string as input. Also refreshes the destination collection automatically to maintain correct state. Returns error.
 func (obj *DataObj) CopyTo(iRODSCollection interface{}) error {
+	return obj.copyToContext(context.Background(), iRODSCollection)
+}
+
+// copyToContext is like CopyTo, but gives up waiting for a session once ctx is done
+func (obj *DataObj) copyToContext(ctx context.Context, iRODSCollection interface{}) error {
 
 	var (
-		err                         *C.char
 		destination                 string
 		destinationCollectionString string
 		destinationCollection       *Collection
@@ -1052,30 +933,17 @@ func (obj *DataObj) CopyTo(iRODSCollection interface{}) error {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Copy DataObject Failed, unknown variable type passed as collection"))
 	}
 
-	path := C.CString(obj.path)
-	dest := C.CString(destination)
-	resource := C.CString("")
-
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(dest))
-	defer C.free(unsafe.Pointer(resource))
-
-	ccon := obj.con.GetCcon()
-
-	if status := C.gorods_copy_dataobject(path, dest, C.int(0), resource, ccon, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return newError(Fatal, status, fmt.Sprintf("iRODS Copy DataObject Failed: %v, %v", destination, C.GoString(err)))
+	if er := obj.con.transportContext(ctx).Copy(obj.path, destination, false, ""); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Copy DataObject Failed: %v", destination)).withPath(obj.path)
 	}
 
-	obj.con.ReturnCcon(ccon)
-
 	// Find & reload destination collection
 	switch iRODSCollection.(type) {
 	case string:
 		var colEr error
 
 		// Can't find, load collection into memory
-		destinationCollection, colEr = obj.con.Collection(CollectionOptions{
+		destinationCollection, colEr = obj.con.collectionContext(ctx, CollectionOptions{
 			Path:      destinationCollectionString,
 			Recursive: false,
 		})
@@ -1090,7 +958,7 @@ func (obj *DataObj) CopyTo(iRODSCollection interface{}) error {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Copy DataObject Failed, unknown variable type passed as collection"))
 	}
 
-	destinationCollection.Refresh()
+	destinationCollection.refreshContext(ctx)
 
 	return nil
 }
@@ -1099,9 +967,6 @@ func (obj *DataObj) CopyTo(iRODSCollection interface{}) error {
 func (obj *DataObj) CopyToOpts(iRODSCollection interface{}, opts DataObjOptions) error {
 
 	var (
-		err                         *C.char
-		resource                    *C.char
-		force                       int
 		destination                 string
 		destinationCollectionString string
 		destinationCollection       *Collection
@@ -1128,38 +993,15 @@ func (obj *DataObj) CopyToOpts(iRODSCollection interface{}, opts DataObjOptions)
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Copy DataObject Failed, unknown variable type passed as collection"))
 	}
 
-	if opts.Force {
-		force = 1
-	} else {
-		force = 0
-	}
-
-	switch opts.Resource.(type) {
-	case string:
-		resource = C.CString(opts.Resource.(string))
-	case *Resource:
-		r := opts.Resource.(*Resource)
-		resource = C.CString(r.Name())
-	default:
-		newError(Fatal, -1, fmt.Sprintf("Wrong variable type passed in Resource field"))
+	resource, er := resourceName(opts.Resource)
+	if er != nil {
+		return er
 	}
 
-	path := C.CString(obj.path)
-	dest := C.CString(destination)
-
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(dest))
-	defer C.free(unsafe.Pointer(resource))
-
-	ccon := obj.con.GetCcon()
-
-	if status := C.gorods_copy_dataobject(path, dest, C.int(force), resource, ccon, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return newError(Fatal, status, fmt.Sprintf("iRODS Copy DataObject Failed: %v, %v", destination, C.GoString(err)))
+	if er := obj.con.transport.Copy(obj.path, destination, opts.Force, resource); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Copy DataObject Failed: %v", destination)).withPath(obj.path)
 	}
 
-	obj.con.ReturnCcon(ccon)
-
 	// Find & reload destination collection
 	switch iRODSCollection.(type) {
 	case string:
@@ -1188,9 +1030,13 @@ func (obj *DataObj) CopyToOpts(iRODSCollection interface{}, opts DataObjOptions)
 
 // MoveTo moves the data object to the specified collection. Supports Collection struct or string as input. Also refreshes the source and destination collections automatically to maintain correct state. Returns error.
 func (obj *DataObj) MoveTo(iRODSCollection interface{}) error {
+	return obj.moveToContext(context.Background(), iRODSCollection)
+}
+
+// moveToContext is like MoveTo, but gives up waiting for a session once ctx is done
+func (obj *DataObj) moveToContext(ctx context.Context, iRODSCollection interface{}) error {
 
 	var (
-		err                         *C.char
 		destination                 string
 		destinationCollectionString string
 		destinationCollection       *Collection
@@ -1217,23 +1063,12 @@ func (obj *DataObj) MoveTo(iRODSCollection interface{}) error {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Move DataObject Failed, unknown variable type passed as collection"))
 	}
 
-	path := C.CString(obj.path)
-	dest := C.CString(destination)
-
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(dest))
-
-	ccon := obj.con.GetCcon()
-
-	if status := C.gorods_move_dataobject(path, dest, C.RENAME_DATA_OBJ, ccon, &err); status != 0 {
-		obj.con.ReturnCcon(ccon)
-		return newError(Fatal, status, fmt.Sprintf("iRODS Move DataObject Failed S:%v, D:%v, %v", obj.path, destination, C.GoString(err)))
+	if er := obj.con.transportContext(ctx).Move(obj.path, destination, false); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Move DataObject Failed S:%v, D:%v", obj.path, destination)).withPath(obj.path)
 	}
 
-	obj.con.ReturnCcon(ccon)
-
 	// Reload source collection, we are now detached
-	obj.col.Refresh()
+	obj.col.refreshContext(ctx)
 
 	// Find & reload destination collection
 	switch iRODSCollection.(type) {
@@ -1241,7 +1076,7 @@ func (obj *DataObj) MoveTo(iRODSCollection interface{}) error {
 		var colEr error
 
 		// Can't find, load collection into memory
-		destinationCollection, colEr = obj.con.Collection(CollectionOptions{
+		destinationCollection, colEr = obj.con.collectionContext(ctx, CollectionOptions{
 			Path:      destinationCollectionString,
 			Recursive: false,
 		})
@@ -1256,46 +1091,39 @@ func (obj *DataObj) MoveTo(iRODSCollection interface{}) error {
 		return newError(Fatal, -1, fmt.Sprintf("iRODS Move DataObject Failed, unknown variable type passed as collection"))
 	}
 
-	destinationCollection.Refresh()
+	destinationCollection.refreshContext(ctx)
 
 	// Reassign obj.col to destination collection
 	obj.col = destinationCollection
 	obj.path = destinationCollection.path + "/" + obj.name
 
-	obj.chandle = C.int(-1)
+	obj.handle = -1
 
 	return nil
 }
 
 // Rename is equivalent to the Linux mv command except that the data object must stay within the current collection (directory), returns error.
 func (obj *DataObj) Rename(newFileName string) error {
+	return obj.renameContext(context.Background(), newFileName)
+}
+
+// renameContext is like Rename, but gives up waiting for a session once ctx is done
+func (obj *DataObj) renameContext(ctx context.Context, newFileName string) error {
 
 	if strings.Contains(newFileName, "/") {
 		return newError(Fatal, -1, fmt.Sprintf("Can't Rename DataObject, path detected in: %v", newFileName))
 	}
 
-	var err *C.char
-
-	source := obj.path
 	destination := obj.col.path + "/" + newFileName
 
-	s := C.CString(source)
-	d := C.CString(destination)
-
-	defer C.free(unsafe.Pointer(s))
-	defer C.free(unsafe.Pointer(d))
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
-
-	if status := C.gorods_move_dataobject(s, d, C.RENAME_DATA_OBJ, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Rename DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+	if er := obj.con.transportContext(ctx).Move(obj.path, destination, false); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Rename DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
 	obj.name = newFileName
 	obj.path = destination
 
-	obj.chandle = C.int(-1)
+	obj.handle = -1
 
 	return nil
 }
@@ -1307,35 +1135,26 @@ func (obj *DataObj) Unlink() error {
 
 // Chksum returns md5 hash string of data object
 func (obj *DataObj) Chksum() (string, error) {
+	return obj.chksumContext(context.Background())
+}
 
-	var (
-		err       *C.char
-		chksumOut *C.char
-	)
-
-	path := C.CString(obj.path)
-
-	defer C.free(unsafe.Pointer(path))
-
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
-
-	if status := C.gorods_checksum_dataobject(path, &chksumOut, ccon, &err); status != 0 {
-		return "", newError(Fatal, status, fmt.Sprintf("iRODS Chksum DataObject Failed: %v, %v", obj.path, C.GoString(err)))
+// chksumContext is like Chksum, but gives up waiting for a session once ctx is done
+func (obj *DataObj) chksumContext(ctx context.Context) (string, error) {
+	chksum, er := obj.con.transportContext(ctx).Checksum(obj.path)
+	if er != nil {
+		return "", transportError(er, fmt.Sprintf("iRODS Chksum DataObject Failed: %v", obj.path)).withPath(obj.path)
 	}
 
-	obj.checksum = C.GoString(chksumOut)
-
-	C.free(unsafe.Pointer(chksumOut))
+	obj.checksum = chksum
 
 	return obj.checksum, nil
 }
 
-// Verify returns true or false depending on whether the checksum md5 string matches
-func (obj *DataObj) Verify(md5Checksum string) bool {
+// Verify returns true or false depending on whether the checksum matches the one computed by the server.
+// checksum can be a hex md5 digest (with or without the "md5:" prefix) or an iRODS "sha2:" checksum, see ParseChecksum.
+func (obj *DataObj) Verify(checksum string) bool {
 	if chksum, err := obj.Chksum(); err == nil {
-		chksumSplit := strings.Split(chksum, ":")
-		return (md5Checksum == chksumSplit[1])
+		return ChecksumsEqual(chksum, checksum)
 	}
 
 	return false
@@ -1353,10 +1172,11 @@ type TrimOptions struct {
 
 // TrimRepls trims data object replicas (removes from resource servers), using the rules defined in opts.
 func (obj *DataObj) TrimRepls(opts TrimOptions) error {
-	var (
-		err         *C.char
-		resourceStr string
-	)
+	var resourceStr string
+
+	if er := obj.con.requireCcon("TrimRepls"); er != nil {
+		return er
+	}
 
 	switch opts.TargetResource.(type) {
 	case string:
@@ -1368,33 +1188,31 @@ func (obj *DataObj) TrimRepls(opts TrimOptions) error {
 
 	}
 
-	cNumCopies := C.CString(strconv.Itoa(opts.NumCopiesKeep))
-	cAgeStr := C.CString(strconv.Itoa(opts.MinAgeMins))
-	cPath := C.CString(obj.Path())
-	cResource := C.CString(resourceStr)
-	defer C.free(unsafe.Pointer(cNumCopies))
-	defer C.free(unsafe.Pointer(cAgeStr))
-	defer C.free(unsafe.Pointer(cPath))
-	defer C.free(unsafe.Pointer(cResource))
+	return obj.con.retry(true, func() error {
+		ccon, er := obj.con.getCcon()
+		if er != nil {
+			return er
+		}
 
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+		defer obj.con.returnCcon(ccon)
 
-	if status := C.gorods_trimrepls_dataobject(ccon, cPath, cAgeStr, cResource, cNumCopies, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS TrimRepls Failed: %v, %v", obj.path, C.GoString(err)))
-	}
+		if er := cTrimRepls(ccon, obj.Path(), resourceStr, opts); er != nil {
+			return transportError(er, fmt.Sprintf("iRODS TrimRepls Failed: %v", obj.path)).withPath(obj.path)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // MoveToResource moves data object to the specified resource.
 // Accepts string or *Resource type.
 func (obj *DataObj) MoveToResource(targetResource interface{}) error {
 
-	var (
-		err         *C.char
-		resourceStr string
-	)
+	var resourceStr string
+
+	if er := obj.con.requireCcon("MoveToResource"); er != nil {
+		return er
+	}
 
 	switch targetResource.(type) {
 	case string:
@@ -1406,31 +1224,36 @@ func (obj *DataObj) MoveToResource(targetResource interface{}) error {
 
 	}
 
-	cSourceResource := C.CString(obj.resource.name)
-	cPath := C.CString(obj.Path())
-	cResource := C.CString(resourceStr)
-	defer C.free(unsafe.Pointer(cSourceResource))
-	defer C.free(unsafe.Pointer(cPath))
-	defer C.free(unsafe.Pointer(cResource))
+	return obj.con.retry(true, func() error {
+		ccon, er := obj.con.getCcon()
+		if er != nil {
+			return er
+		}
 
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+		defer obj.con.returnCcon(ccon)
 
-	if status := C.gorods_phymv_dataobject(ccon, cPath, cSourceResource, cResource, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS MoveToResource Failed: %v, %v", obj.path, C.GoString(err)))
-	}
+		if er := cPhyMove(ccon, obj.Path(), obj.resource.name, resourceStr); er != nil {
+			return transportError(er, fmt.Sprintf("iRODS MoveToResource Failed: %v", obj.path)).withPath(obj.path)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // Replicate copies the data object to the specified resource.
 // Accepts string or *Resource type for targetResource parameter.
 func (obj *DataObj) Replicate(targetResource interface{}, opts DataObjOptions) error {
+	return obj.replicateContext(context.Background(), targetResource, opts)
+}
 
-	var (
-		err         *C.char
-		resourceStr string
-	)
+// replicateContext is like Replicate, but gives up waiting for a session once ctx is done
+func (obj *DataObj) replicateContext(ctx context.Context, targetResource interface{}, opts DataObjOptions) error {
+
+	var resourceStr string
+
+	if er := obj.con.requireCcon("ReplicateOpts"); er != nil {
+		return er
+	}
 
 	switch targetResource.(type) {
 	case string:
@@ -1442,28 +1265,30 @@ func (obj *DataObj) Replicate(targetResource interface{}, opts DataObjOptions) e
 
 	}
 
-	cPath := C.CString(obj.Path())
-	cResource := C.CString(resourceStr)
-	defer C.free(unsafe.Pointer(cPath))
-	defer C.free(unsafe.Pointer(cResource))
+	return obj.con.retryContext(ctx, true, func() error {
+		ccon, er := obj.con.getCconContext(ctx)
+		if er != nil {
+			return er
+		}
 
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+		defer obj.con.returnCcon(ccon)
 
-	if status := C.gorods_repl_dataobject(ccon, cPath, cResource, C.int(0), C.int(opts.Mode), C.rodsLong_t(opts.Size), &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS ReplicateOpts Failed: %v, %v", obj.path, C.GoString(err)))
-	}
+		if er := cReplicate(ccon, obj.Path(), resourceStr, false, opts); er != nil {
+			return transportError(er, fmt.Sprintf("iRODS ReplicateOpts Failed: %v", obj.path)).withPath(obj.path)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // Backup is similar to Replicate. In backup mode, if a good copy already exists in this resource group or resource, don't make another one.
 func (obj *DataObj) Backup(targetResource interface{}, opts DataObjOptions) error {
 
-	var (
-		err         *C.char
-		resourceStr string
-	)
+	var resourceStr string
+
+	if er := obj.con.requireCcon("Backup"); er != nil {
+		return er
+	}
 
 	switch targetResource.(type) {
 	case string:
@@ -1475,17 +1300,18 @@ func (obj *DataObj) Backup(targetResource interface{}, opts DataObjOptions) erro
 
 	}
 
-	cPath := C.CString(obj.Path())
-	cResource := C.CString(resourceStr)
-	defer C.free(unsafe.Pointer(cPath))
-	defer C.free(unsafe.Pointer(cResource))
+	return obj.con.retry(true, func() error {
+		ccon, er := obj.con.getCcon()
+		if er != nil {
+			return er
+		}
 
-	ccon := obj.con.GetCcon()
-	defer obj.con.ReturnCcon(ccon)
+		defer obj.con.returnCcon(ccon)
 
-	if status := C.gorods_repl_dataobject(ccon, cPath, cResource, C.int(1), C.int(opts.Mode), C.rodsLong_t(opts.Size), &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS Backup Failed: %v, %v", obj.path, C.GoString(err)))
-	}
+		if er := cReplicate(ccon, obj.Path(), resourceStr, true, opts); er != nil {
+			return transportError(er, fmt.Sprintf("iRODS Backup Failed: %v", obj.path)).withPath(obj.path)
+		}
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,75 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, University of Florida Research Foundation, Inc. and The BioTeam, Inc.  ***
+ *** For more information please refer to the LICENSE.md file                                   ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"strconv"
+	"unsafe"
+)
+
+// cFree releases memory allocated by the iRODS C API
+func cFree(ptr unsafe.Pointer) {
+	C.free(ptr)
+}
+
+// cTrimRepls trims the replicas of the data object at path, like itrim
+func cTrimRepls(ccon *rcComm, path string, resource string, opts TrimOptions) error {
+	var err *C.char
+
+	cNumCopies := C.CString(strconv.Itoa(opts.NumCopiesKeep))
+	cAgeStr := C.CString(strconv.Itoa(opts.MinAgeMins))
+	cPath := C.CString(path)
+	cResource := C.CString(resource)
+	defer C.free(unsafe.Pointer(cNumCopies))
+	defer C.free(unsafe.Pointer(cAgeStr))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cResource))
+
+	if status := C.gorods_trimrepls_dataobject(ccon, cPath, cAgeStr, cResource, cNumCopies, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+// cPhyMove moves the replica of the data object at path from the source resource to the destination, like iphymv
+func cPhyMove(ccon *rcComm, path string, source string, destination string) error {
+	var err *C.char
+
+	cSourceResource := C.CString(source)
+	cPath := C.CString(path)
+	cResource := C.CString(destination)
+	defer C.free(unsafe.Pointer(cSourceResource))
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cResource))
+
+	if status := C.gorods_phymv_dataobject(ccon, cPath, cSourceResource, cResource, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+// cReplicate replicates the data object at path to resource, like irepl. backup skips resources that already have
+// a good replica (irepl -B).
+func cReplicate(ccon *rcComm, path string, resource string, backup bool, opts DataObjOptions) error {
+	var err *C.char
+
+	cPath := C.CString(path)
+	cResource := C.CString(resource)
+	defer C.free(unsafe.Pointer(cPath))
+	defer C.free(unsafe.Pointer(cResource))
+
+	if status := C.gorods_repl_dataobject(ccon, cPath, cResource, cBool(backup), C.int(opts.Mode), C.rodsLong_t(opts.Size), &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
@@ -3,133 +3,137 @@
 
 package gorods
 
-//import "fmt"
+import (
+	"strings"
+	"testing"
+)
 
-// func TestDataObjCreateDeleteWrite(t *testing.T) {
+// memClient returns a *Client over a MemTransport holding /tempZone/home/rods/hello.txt
+func memClient(t *testing.T) *Client {
+	client, conErr := New(*memOptions(NewMemTransport("tempZone", "rods")))
 
-// 	client, conErr := New(testCreds)
+	// Ensure the client initialized successfully
+	if conErr != nil {
+		t.Fatal(conErr)
+	}
 
-// 	// Ensure the client initialized successfully and connected to the iCAT server
-// 	if conErr != nil {
-// 		t.Fatal(conErr)
-// 	}
+	if openErr := client.OpenCollection(CollectionOptions{
+		Path: "/tempZone/home/rods",
+	}, func(col *Collection, con *Connection) {
 
-// 	// Open a data object reference for /tempZone/home/rods/hello.txt
-// 	if openErr := client.OpenCollection(CollectionOptions{
-// 		Path: fmt.Sprintf("/%v/home/%v", testCreds.Zone, testCreds.Username),
-// 	}, func(col *Collection, con *Connection) {
+		do, createErr := col.CreateDataObj(DataObjOptions{
+			Name: "hello.txt",
+		})
 
-// 		do, createErr := col.CreateDataObj(DataObjOptions{
-// 			Name: "test123.txt",
-// 		})
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
 
-// 		if createErr != nil {
-// 			t.Fatal(createErr)
-// 		}
+		if wrErr := do.Write([]byte("Hello, World!\n")); wrErr != nil {
+			t.Fatal(wrErr)
+		}
 
-// 		wrErr := do.Write([]byte("test123content"))
-// 		if wrErr != nil {
-// 			t.Fatal(wrErr)
-// 		}
+	}); openErr != nil {
+		t.Fatal(openErr)
+	}
 
-// 		_, statErr := do.Stat()
-// 		if statErr != nil {
-// 			t.Fatal(statErr)
-// 		}
+	return client
+}
 
-// 		// if chErr := do.Chmod("developers", Write, false); chErr != nil {
-// 		// 	t.Fatal(chErr)
-// 		// }
+func TestDataObjCreateDeleteWrite(t *testing.T) {
+	client := memClient(t)
 
-// 		// acl, aclErr := do.ACL()
-// 		// if aclErr != nil {
-// 		// 	t.Fatal(aclErr)
-// 		// }
+	// Open a collection reference for /tempZone/home/rods
+	if openErr := client.OpenCollection(CollectionOptions{
+		Path: "/tempZone/home/rods",
+	}, func(col *Collection, con *Connection) {
 
-// 		// acl[0].String()
+		do, createErr := col.CreateDataObj(DataObjOptions{
+			Name: "test123.txt",
+		})
 
-// 		// if acl[1].User().Name() != "rods" {
-// 		// 	t.Errorf("Expected string 'rods', got '%s'", acl[1].User().Name())
-// 		// }
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
 
-// 		// if acl[0].Group().Name() != "developers" {
-// 		// 	t.Errorf("Expected string 'developers', got '%s'", acl[0].Group().Name())
-// 		// }
+		wrErr := do.Write([]byte("test123content"))
+		if wrErr != nil {
+			t.Fatal(wrErr)
+		}
 
-// 		cont, readErr := do.Read()
-// 		if readErr != nil {
-// 			t.Fatal(readErr)
-// 		}
+		_, statErr := do.Stat()
+		if statErr != nil {
+			t.Fatal(statErr)
+		}
 
-// 		if string(cont) != "test123content" {
-// 			t.Errorf("Expected string 'test123content', got '%s'", string(cont))
-// 		}
+		cont, readErr := do.Read()
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
 
-// 		delErr := do.Delete(false)
-// 		if delErr != nil {
-// 			t.Fatal(delErr)
-// 		}
+		if string(cont) != "test123content" {
+			t.Errorf("Expected string 'test123content', got '%s'", string(cont))
+		}
 
-// 	}); openErr != nil {
-// 		t.Fatal(openErr)
-// 	}
+		delErr := do.Delete(false)
+		if delErr != nil {
+			t.Fatal(delErr)
+		}
 
-// }
+		if typ, _ := con.PathType("/tempZone/home/rods/test123.txt"); typ == DataObjType {
+			t.Error("Expected test123.txt to be deleted")
+		}
 
-// func TestDataObjRead(t *testing.T) {
-// 	client, conErr := New(testCreds)
+	}); openErr != nil {
+		t.Fatal(openErr)
+	}
 
-// 	// Ensure the client initialized successfully and connected to the iCAT server
-// 	if conErr != nil {
-// 		t.Fatal(conErr)
-// 	}
+}
 
-// 	// Open a data object reference for /tempZone/home/rods/hello.txt
-// 	if openErr := client.OpenDataObject(fmt.Sprintf("/%v/home/%v/hello.txt", testCreds.Zone, testCreds.Username), func(myFile *DataObj, con *Connection) {
+func TestDataObjRead(t *testing.T) {
+	client := memClient(t)
 
-// 		// read the contents
-// 		if contents, readErr := myFile.Read(); readErr == nil {
+	// Open a data object reference for /tempZone/home/rods/hello.txt
+	if openErr := client.OpenDataObject("/tempZone/home/rods/hello.txt", func(myFile *DataObj, con *Connection) {
 
-// 			c := string(contents)
+		// read the contents
+		if contents, readErr := myFile.Read(); readErr == nil {
 
-// 			if strings.Trim(c, "\n") != "Hello, World!" {
-// 				t.Errorf("Expected string 'Hello, World!', got '%s'", c)
-// 			}
-// 		} else {
-// 			t.Fatal(readErr)
-// 		}
+			c := string(contents)
 
-// 	}); openErr != nil {
-// 		t.Fatal(openErr)
-// 	}
+			if strings.Trim(c, "\n") != "Hello, World!" {
+				t.Errorf("Expected string 'Hello, World!', got '%s'", c)
+			}
+		} else {
+			t.Fatal(readErr)
+		}
 
-// }
+	}); openErr != nil {
+		t.Fatal(openErr)
+	}
 
-// func TestDataObjReadBytes(t *testing.T) {
-// 	client, conErr := New(testCreds)
+}
 
-// 	// Ensure the client initialized successfully and connected to the iCAT server
-// 	if conErr != nil {
-// 		t.Fatal(conErr)
-// 	}
+func TestDataObjReadBytes(t *testing.T) {
+	client := memClient(t)
 
-// 	// Open a data object reference for /tempZone/home/rods/hello.txt
-// 	if openErr := client.OpenDataObject(fmt.Sprintf("/%v/home/%v/hello.txt", testCreds.Zone, testCreds.Username), func(myFile *DataObj, con *Connection) {
+	// Open a data object reference for /tempZone/home/rods/hello.txt
+	if openErr := client.OpenDataObject("/tempZone/home/rods/hello.txt", func(myFile *DataObj, con *Connection) {
 
-// 		// read the contents
-// 		if contents, readErr := myFile.ReadBytes(7, 6); readErr == nil {
+		// read the contents
+		if contents, readErr := myFile.ReadBytes(7, 6); readErr == nil {
 
-// 			c := string(contents)
+			c := string(contents)
 
-// 			if c != "World!" {
-// 				t.Errorf("Expected string 'World!', got '%s'", c)
-// 			}
-// 		} else {
-// 			t.Fatal(readErr)
-// 		}
+			if c != "World!" {
+				t.Errorf("Expected string 'World!', got '%s'", c)
+			}
+		} else {
+			t.Fatal(readErr)
+		}
 
-// 	}); openErr != nil {
-// 		t.Fatal(openErr)
-// 	}
+	}); openErr != nil {
+		t.Fatal(openErr)
+	}
 
-// }
+}
@@ -0,0 +1,352 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Environment holds the settings of an irods_environment.json file. Load one with LoadEnvironment or
+// LoadEnvironmentFile, or create one for a new user with NewEnvironment and Write it out.
+type Environment struct {
+	Host            string `json:"irods_host,omitempty"`
+	Port            int    `json:"irods_port,omitempty"`
+	Username        string `json:"irods_user_name,omitempty"`
+	Zone            string `json:"irods_zone_name,omitempty"`
+	DefaultResource string `json:"irods_default_resource,omitempty"`
+	Home            string `json:"irods_home,omitempty"`
+	Cwd             string `json:"irods_cwd,omitempty"`
+
+	AuthenticationScheme string `json:"irods_authentication_scheme,omitempty"`
+	AuthenticationFile   string `json:"irods_authentication_file,omitempty"`
+
+	ClientServerNegotiation string `json:"irods_client_server_negotiation,omitempty"`
+	ClientServerPolicy      string `json:"irods_client_server_policy,omitempty"`
+	SSLCACertificateFile    string `json:"irods_ssl_ca_certificate_file,omitempty"`
+	SSLCACertificatePath    string `json:"irods_ssl_ca_certificate_path,omitempty"`
+	SSLVerifyServer         string `json:"irods_ssl_verify_server,omitempty"`
+	SSLCertificateChainFile string `json:"irods_ssl_certificate_chain_file,omitempty"`
+	SSLCertificateKeyFile   string `json:"irods_ssl_certificate_key_file,omitempty"`
+	SSLDHParamsFile         string `json:"irods_ssl_dh_params_file,omitempty"`
+
+	EncryptionAlgorithm     string `json:"irods_encryption_algorithm,omitempty"`
+	EncryptionKeySize       int    `json:"irods_encryption_key_size,omitempty"`
+	EncryptionSaltSize      int    `json:"irods_encryption_salt_size,omitempty"`
+	EncryptionNumHashRounds int    `json:"irods_encryption_num_hash_rounds,omitempty"`
+
+	DefaultHashScheme string `json:"irods_default_hash_scheme,omitempty"`
+	MatchHashPolicy   string `json:"irods_match_hash_policy,omitempty"`
+
+	DefaultNumberOfTransferThreads int `json:"irods_default_number_of_transfer_threads,omitempty"`
+	MaxSizeForSingleBufferMB       int `json:"irods_maximum_size_for_single_buffer_in_megabytes,omitempty"`
+	TransferBufferSizeMB           int `json:"irods_transfer_buffer_size_for_parallel_transfer_in_megabytes,omitempty"`
+	ConnectionPoolRefreshTime      int `json:"irods_connection_pool_refresh_time_in_seconds,omitempty"`
+
+	LogLevel    int    `json:"irods_log_level,omitempty"`
+	PluginsHome string `json:"irods_plugins_home,omitempty"`
+
+	// Path is the file the environment was loaded from, or last written to
+	Path string `json:"-"`
+
+	// UnknownKeys lists the keys of the file that GoRODS doesn't know about. They're kept out of the struct,
+	// and aren't written back by Write.
+	UnknownKeys []string `json:"-"`
+}
+
+// EnvironmentProblem is an invalid key of an irods_environment.json file
+type EnvironmentProblem struct {
+	Key     string
+	Message string
+}
+
+func (p EnvironmentProblem) String() string {
+	return fmt.Sprintf("%v: %v", p.Key, p.Message)
+}
+
+// environmentFields maps the JSON keys of Environment to their field index
+var environmentFields = func() map[string]int {
+	fields := make(map[string]int)
+
+	typ := reflect.TypeOf(Environment{})
+	for i := 0; i < typ.NumField(); i++ {
+		key := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if key != "" && key != "-" {
+			fields[key] = i
+		}
+	}
+
+	return fields
+}()
+
+// EnvironmentFile returns the path of the environment file the iRODS C API reads: $IRODS_ENVIRONMENT_FILE when
+// it's set, ~/.irods/irods_environment.json otherwise.
+func EnvironmentFile() string {
+	if path := os.Getenv("IRODS_ENVIRONMENT_FILE"); path != "" {
+		return path
+	}
+
+	return filepath.Join(homeDir(), ".irods", "irods_environment.json")
+}
+
+func homeDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+
+	if usr, err := user.Current(); err == nil {
+		return usr.HomeDir
+	}
+
+	return ""
+}
+
+// LoadEnvironment loads the environment file returned by EnvironmentFile
+func LoadEnvironment() (*Environment, error) {
+	return LoadEnvironmentFile(EnvironmentFile())
+}
+
+// LoadEnvironmentFile parses the environment file at path. Keys that GoRODS doesn't know are listed in
+// UnknownKeys. When a value has the wrong type, or fails Validate, the environment is returned along with an error
+// listing every problem.
+func LoadEnvironmentFile(path string) (*Environment, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Load Environment Failed: %v", err))
+	}
+
+	env, problems, err := parseEnvironment(data)
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Load Environment Failed: %v is not valid JSON: %v", path, err))
+	}
+
+	env.Path = path
+
+	problems = append(problems, env.Problems()...)
+
+	if len(problems) > 0 {
+		return env, environmentError(fmt.Sprintf("iRODS Load Environment Failed: %v", path), problems)
+	}
+
+	return env, nil
+}
+
+// parseEnvironment decodes data key by key, so every value with the wrong type is reported
+func parseEnvironment(data []byte) (*Environment, []EnvironmentProblem, error) {
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	env := new(Environment)
+	problems := make([]EnvironmentProblem, 0)
+	value := reflect.ValueOf(env).Elem()
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		i, ok := environmentFields[key]
+		if !ok {
+			env.UnknownKeys = append(env.UnknownKeys, key)
+			continue
+		}
+
+		field := value.Field(i)
+		if err := json.Unmarshal(raw[key], field.Addr().Interface()); err != nil {
+			problems = append(problems, EnvironmentProblem{Key: key, Message: fmt.Sprintf("expected a %v", field.Kind())})
+		}
+	}
+
+	return env, problems, nil
+}
+
+func environmentError(message string, problems []EnvironmentProblem) error {
+	strs := make([]string, len(problems))
+	for i, p := range problems {
+		strs[i] = p.String()
+	}
+
+	return newError(Fatal, -1, fmt.Sprintf("%v: %v", message, strings.Join(strs, ", ")))
+}
+
+// Problems returns the settings with invalid values
+func (env *Environment) Problems() []EnvironmentProblem {
+	problems := make([]EnvironmentProblem, 0)
+
+	add := func(key string, format string, args ...interface{}) {
+		problems = append(problems, EnvironmentProblem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	oneOf := func(key string, value string, allowed ...string) {
+		if value == "" {
+			return
+		}
+		for _, a := range allowed {
+			if strings.EqualFold(value, a) {
+				return
+			}
+		}
+		add(key, "%q isn't one of %v", value, strings.Join(allowed, ", "))
+	}
+
+	if env.Port < 0 || env.Port > 65535 {
+		add("irods_port", "%v is out of range", env.Port)
+	}
+
+	if env.Home != "" && !strings.HasPrefix(env.Home, "/") {
+		add("irods_home", "%q isn't an absolute path", env.Home)
+	}
+	if env.Cwd != "" && !strings.HasPrefix(env.Cwd, "/") {
+		add("irods_cwd", "%q isn't an absolute path", env.Cwd)
+	}
+
+	oneOf("irods_authentication_scheme", env.AuthenticationScheme, "native", "pam", "krb", "gsi", "pam_password")
+	oneOf("irods_client_server_negotiation", env.ClientServerNegotiation, "request_server_negotiation", "none", "off")
+	oneOf("irods_client_server_policy", env.ClientServerPolicy, CSNegRequire, CSNegRefuse, CSNegDontCare)
+	oneOf("irods_ssl_verify_server", env.SSLVerifyServer, SSLVerifyHostname, SSLVerifyCert, SSLVerifyNone)
+	oneOf("irods_default_hash_scheme", env.DefaultHashScheme, "SHA256", "MD5")
+	oneOf("irods_match_hash_policy", env.MatchHashPolicy, "compatible", "strict")
+
+	nonNegative := map[string]int{
+		"irods_encryption_key_size":                                     env.EncryptionKeySize,
+		"irods_encryption_salt_size":                                    env.EncryptionSaltSize,
+		"irods_encryption_num_hash_rounds":                              env.EncryptionNumHashRounds,
+		"irods_default_number_of_transfer_threads":                      env.DefaultNumberOfTransferThreads,
+		"irods_maximum_size_for_single_buffer_in_megabytes":             env.MaxSizeForSingleBufferMB,
+		"irods_transfer_buffer_size_for_parallel_transfer_in_megabytes": env.TransferBufferSizeMB,
+		"irods_connection_pool_refresh_time_in_seconds":                 env.ConnectionPoolRefreshTime,
+	}
+
+	for key, v := range nonNegative {
+		if v < 0 {
+			add(key, "%v can't be negative", v)
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+
+	return problems
+}
+
+// Validate returns an error listing the settings with invalid values, or nil
+func (env *Environment) Validate() error {
+	if problems := env.Problems(); len(problems) > 0 {
+		return environmentError("iRODS Validate Environment Failed", problems)
+	}
+
+	return nil
+}
+
+// NewEnvironment returns the environment iinit would write for user of zone, on the server at host:port
+func NewEnvironment(host string, port int, zone string, username string) *Environment {
+	home := fmt.Sprintf("/%v/home/%v", zone, username)
+
+	return &Environment{
+		Host:                    host,
+		Port:                    port,
+		Username:                username,
+		Zone:                    zone,
+		Home:                    home,
+		Cwd:                     home,
+		AuthenticationScheme:    "native",
+		ClientServerNegotiation: "request_server_negotiation",
+		ClientServerPolicy:      CSNegRefuse,
+		EncryptionAlgorithm:     "AES-256-CBC",
+		EncryptionKeySize:       32,
+		EncryptionSaltSize:      8,
+		EncryptionNumHashRounds: 16,
+		DefaultHashScheme:       "SHA256",
+		MatchHashPolicy:         "compatible",
+	}
+}
+
+// Write validates the environment, and writes it to path (creating its directory) readable only by its owner.
+// When path is empty, the environment is written to env.Path, or EnvironmentFile() if that's empty too.
+func (env *Environment) Write(path string) error {
+	if path == "" {
+		path = env.Path
+	}
+	if path == "" {
+		path = EnvironmentFile()
+	}
+
+	if err := env.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(env, "", "    ")
+	if err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Write Environment Failed: %v", err))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Write Environment Failed: %v", err))
+	}
+
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Write Environment Failed: %v", err))
+	}
+
+	env.Path = path
+
+	return nil
+}
+
+// Merge returns UserDefined options built from the environment, where the fields already set in opts take
+// precedence. The password isn't part of the environment, it's taken from opts or read from AuthFile.
+func (env *Environment) Merge(opts ConnectionOptions) ConnectionOptions {
+	opts.Type = UserDefined
+
+	setString := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	setInt := func(dst *int, src int) {
+		if *dst == 0 {
+			*dst = src
+		}
+	}
+
+	setString(&opts.Host, env.Host)
+	setInt(&opts.Port, env.Port)
+	setString(&opts.Zone, env.Zone)
+	setString(&opts.Username, env.Username)
+	setString(&opts.AuthFile, env.AuthenticationFile)
+
+	if opts.AuthType == 0 {
+		switch strings.ToLower(env.AuthenticationScheme) {
+		case "pam", "pam_password":
+			opts.AuthType = PAMAuth
+		case "native":
+			opts.AuthType = PasswordAuth
+		}
+	}
+
+	// The policy only applies when the environment asks for negotiation
+	if strings.EqualFold(env.ClientServerNegotiation, "request_server_negotiation") {
+		setString(&opts.ClientServerPolicy, env.ClientServerPolicy)
+	}
+
+	setString(&opts.SSLCACertificateFile, env.SSLCACertificateFile)
+	setString(&opts.SSLVerifyServer, env.SSLVerifyServer)
+	setString(&opts.EncryptionAlgorithm, env.EncryptionAlgorithm)
+	setInt(&opts.EncryptionKeySize, env.EncryptionKeySize)
+	setInt(&opts.EncryptionSaltSize, env.EncryptionSaltSize)
+	setInt(&opts.EncryptionNumHashRounds, env.EncryptionNumHashRounds)
+
+	return opts
+}
@@ -0,0 +1,165 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempEnvironment(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "gorods-env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "irods_environment.json")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadEnvironmentFile(t *testing.T) {
+	path := writeTempEnvironment(t, `{
+		"irods_host": "irods.example.org",
+		"irods_port": 1247,
+		"irods_user_name": "alice",
+		"irods_zone_name": "tempZone",
+		"irods_client_server_negotiation": "request_server_negotiation",
+		"irods_client_server_policy": "CS_NEG_REQUIRE",
+		"irods_something_new": true
+	}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	env, err := LoadEnvironmentFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Host != "irods.example.org" || env.Port != 1247 || env.Username != "alice" || env.Zone != "tempZone" {
+		t.Errorf("Unexpected environment %+v", env)
+	}
+
+	if len(env.UnknownKeys) != 1 || env.UnknownKeys[0] != "irods_something_new" {
+		t.Errorf("Expected irods_something_new to be reported as unknown, got %v", env.UnknownKeys)
+	}
+
+	if env.Path != path {
+		t.Errorf("Expected Path %v, got %v", path, env.Path)
+	}
+}
+
+func TestLoadEnvironmentFileProblems(t *testing.T) {
+	path := writeTempEnvironment(t, `{
+		"irods_host": "irods.example.org",
+		"irods_port": "1247",
+		"irods_client_server_policy": "CS_NEG_MAYBE",
+		"irods_encryption_key_size": -1
+	}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	env, err := LoadEnvironmentFile(path)
+	if err == nil {
+		t.Fatal("Expected invalid keys to be reported")
+	}
+
+	if env == nil || env.Host != "irods.example.org" {
+		t.Errorf("Expected the valid settings to be returned along with the error, got %+v", env)
+	}
+
+	for _, key := range []string{"irods_port", "irods_client_server_policy", "irods_encryption_key_size"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected %v to be reported, got %v", key, err)
+		}
+	}
+
+	if _, err := LoadEnvironmentFile(writeTempEnvironment(t, "{not json")); err == nil {
+		t.Error("Expected malformed JSON to fail")
+	}
+}
+
+func TestEnvironmentFileOverride(t *testing.T) {
+	old, set := os.LookupEnv("IRODS_ENVIRONMENT_FILE")
+	defer func() {
+		if set {
+			os.Setenv("IRODS_ENVIRONMENT_FILE", old)
+		} else {
+			os.Unsetenv("IRODS_ENVIRONMENT_FILE")
+		}
+	}()
+
+	os.Setenv("IRODS_ENVIRONMENT_FILE", "/tmp/custom_env.json")
+	if EnvironmentFile() != "/tmp/custom_env.json" {
+		t.Errorf("Expected IRODS_ENVIRONMENT_FILE to be used, got %v", EnvironmentFile())
+	}
+
+	os.Unsetenv("IRODS_ENVIRONMENT_FILE")
+	if !strings.HasSuffix(EnvironmentFile(), filepath.Join(".irods", "irods_environment.json")) {
+		t.Errorf("Expected the default environment file, got %v", EnvironmentFile())
+	}
+}
+
+func TestEnvironmentWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gorods-env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".irods", "irods_environment.json")
+
+	env := NewEnvironment("irods.example.org", 1247, "tempZone", "alice")
+	if err := env.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file to be private, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := LoadEnvironmentFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Home != "/tempZone/home/alice" || loaded.ClientServerPolicy != CSNegRefuse || len(loaded.UnknownKeys) != 0 {
+		t.Errorf("Unexpected environment after round trip %+v", loaded)
+	}
+
+	env.Port = 70000
+	if err := env.Write(path); err == nil {
+		t.Error("Expected an invalid environment not to be written")
+	}
+}
+
+func TestEnvironmentMerge(t *testing.T) {
+	env := NewEnvironment("irods.example.org", 1247, "tempZone", "alice")
+	env.ClientServerPolicy = CSNegRequire
+
+	opts := env.Merge(ConnectionOptions{Type: EnvironmentDefined, Username: "bob", Password: "secret"})
+
+	if opts.Type != UserDefined || opts.Host != "irods.example.org" || opts.Port != 1247 || opts.Zone != "tempZone" {
+		t.Errorf("Expected the environment to fill in the options, got %+v", opts)
+	}
+
+	if opts.Username != "bob" || opts.Password != "secret" {
+		t.Errorf("Expected explicit options to take precedence, got %+v", opts)
+	}
+
+	if opts.AuthType != PasswordAuth || opts.ClientServerPolicy != CSNegRequire || opts.EncryptionKeySize != 32 {
+		t.Errorf("Expected authentication and SSL settings to be merged, got %+v", opts)
+	}
+
+	env.ClientServerNegotiation = "none"
+	if opts := env.Merge(ConnectionOptions{}); opts.ClientServerPolicy != "" {
+		t.Errorf("Expected the policy to be ignored without negotiation, got %v", opts.ClientServerPolicy)
+	}
+}
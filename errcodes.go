@@ -0,0 +1,45 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// iRODS status codes GoRODS checks for, from rodsErrorTable.h. They're defined here rather than taken from the
+// C headers, so the Transports that don't use the iRODS C API build without cgo.
+const (
+	sysSockOpenErr                  = -1000
+	sysHeaderReadLenErr             = -4000
+	sysHeaderWriteLenErr            = -5000
+	sysExceedConnectCnt             = -9000
+	sysUnmatchedAPINum              = -12000
+	sysNoAPIPriv                    = -13000
+	sysFileDescOutOfRange           = -19000
+	sysNotSupported                 = -66000
+	sysInvalidInputParam            = -130000
+	sysSockReadTimedout             = -115000
+	sysSockReadErr                  = -116000
+	sysSockConnectErr               = -162000
+	userSockOpenErr                 = -304000
+	userSockConnectErr              = -305000
+	userFileDoesNotExist            = -310000
+	overwriteWithoutForceFlag       = -312000
+	userChksumMismatch              = -314000
+	userSockConnectTimedout         = -347000
+	objPathDoesNotExist             = -358000
+	catNoRowsFound                  = -808000
+	catalogAlreadyHasItemByThatName = -809000
+	catUnknownCollection            = -814000
+	catUnknownFile                  = -817000
+	catNoAccessPermission           = -818000
+	catSuccessButWithNoInfo         = -819000
+	catCollectionNotEmpty           = -821000
+	catInvalidAuthentication        = -826000
+	catInvalidUser                  = -827000
+	catInvalidZone                  = -828000
+	catInvalidGroup                 = -829000
+	catInsufficientPrivilegeLevel   = -830000
+	catInvalidResource              = -831000
+	catNameExistsAsCollection       = -833000
+	catNameExistsAsDataObj          = -834000
+	catPasswordExpired              = -840000
+	pamAuthPasswordFailed           = -993000
+)
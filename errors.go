@@ -3,13 +3,11 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
-	"unsafe"
 )
 
 // Log level constants
@@ -19,11 +17,37 @@ const (
 	Fatal
 )
 
-// GoRodsError stores information about errors
+// Sentinel errors, matched by *GoRodsError using errors.Is. For example:
+//
+// 	if _, err := con.DataObject("/tempZone/home/rods/missing.txt"); errors.Is(err, gorods.ErrNotFound) {
+// 		// ...
+// 	}
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrAccessDenied  = errors.New("access denied")
+	ErrNoRowsFound   = errors.New("no rows found")
+	ErrAuthFailed    = errors.New("authentication failed")
+
+	// ErrNotSupported is wrapped by errors of operations that need the iRODS C API, on connections using another Transport,
+	// and of operations the server doesn't support
+	ErrNotSupported = errors.New("not supported by transport")
+
+	// ErrChecksumMismatch is matched by errors reporting that data doesn't match its checksum, see DataObj.VerifyFile
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// GoRodsError stores information about errors. Code is the numeric iRODS status code (0 if the error didn't come from iRODS),
+// and IRODSCode its symbolic name. Op is the operation that failed (for example "Put DataObject"), and Path the iRODS path
+// it failed on, if any. Err holds the underlying cause, if any, and is returned by Unwrap.
 type GoRodsError struct {
 	LogLevel  int
 	Message   string
 	IRODSCode string
+	Code      int
+	Op        string
+	Path      string
+	Err       error
 	Time      time.Time
 }
 
@@ -41,6 +65,76 @@ func (err *GoRodsError) String() string {
 	return fmt.Sprintf("%v: %v - %v%v", err.Time, err.lookupError(err.LogLevel), err.Message, err.IRODSCode)
 }
 
+// Unwrap returns the underlying cause of the error, if any
+func (err *GoRodsError) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether the iRODS status code matches one of the sentinel errors (ErrNotFound, ErrAlreadyExists,
+// ErrAccessDenied, ErrNoRowsFound, ErrAuthFailed or ErrChecksumMismatch). It's used by errors.Is. Note that
+// CAT_NO_ROWS_FOUND matches both ErrNoRowsFound and ErrNotFound.
+func (err *GoRodsError) Is(target error) bool {
+	if err.Code == 0 || target == nil {
+		return false
+	}
+
+	for _, kind := range errorKinds(err.Code) {
+		if kind == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// errorKinds maps an iRODS status code to the sentinel errors it matches. Codes can carry an errno in their last
+// three digits (e.g. -310002 is USER_FILE_DOES_NOT_EXIST with ENOENT), which is stripped first.
+func errorKinds(code int) []error {
+	switch code / 1000 * 1000 {
+	case catNoRowsFound:
+		return []error{ErrNoRowsFound, ErrNotFound}
+	case userFileDoesNotExist, objPathDoesNotExist, catUnknownFile, catUnknownCollection, catInvalidUser, catInvalidResource, catInvalidZone, catInvalidGroup:
+		return []error{ErrNotFound}
+	case catNameExistsAsCollection, catNameExistsAsDataObj, overwriteWithoutForceFlag, catalogAlreadyHasItemByThatName:
+		return []error{ErrAlreadyExists}
+	case catNoAccessPermission, sysNoAPIPriv, catInsufficientPrivilegeLevel:
+		return []error{ErrAccessDenied}
+	case catInvalidAuthentication, catPasswordExpired, pamAuthPasswordFailed:
+		return []error{ErrAuthFailed}
+	case userChksumMismatch:
+		return []error{ErrChecksumMismatch}
+	case sysNotSupported, sysUnmatchedAPINum:
+		return []error{ErrNotSupported}
+	}
+
+	return nil
+}
+
+// errorOp returns the operation named by messages in the form "iRODS <Op> Failed..."
+func errorOp(message string) string {
+	if !strings.HasPrefix(message, "iRODS ") {
+		return ""
+	}
+
+	if n := strings.Index(message, " Failed"); n > len("iRODS ") {
+		return message[len("iRODS "):n]
+	}
+
+	return ""
+}
+
+// withPath records the iRODS path the operation failed on
+func (err *GoRodsError) withPath(path string) *GoRodsError {
+	err.Path = path
+	return err
+}
+
+// wrap records the underlying cause of the error, so it can be matched with errors.Is and errors.As
+func (err *GoRodsError) wrap(cause error) *GoRodsError {
+	err.Err = cause
+	return err
+}
+
 func (err *GoRodsError) lookupError(code int) string {
 	var constLookup = map[int]string{
 		Info:  "Info",
@@ -51,25 +145,17 @@ func (err *GoRodsError) lookupError(code int) string {
 	return constLookup[code]
 }
 
-func newError(logLevel int, status C.int, message string) *GoRodsError {
-	var (
-		errStr    *C.char
-		subErrStr *C.char
-	)
-
+func newError(logLevel int, status int, message string) *GoRodsError {
 	err := new(GoRodsError)
 
 	err.LogLevel = logLevel
 	err.Message = message
+	err.Op = errorOp(message)
 	err.Time = time.Now()
 
 	if status != -1 {
-		defer C.free(unsafe.Pointer(errStr))
-		defer C.free(unsafe.Pointer(subErrStr))
-
-		errStr = C.rodsErrorName(status, &subErrStr)
-
-		err.IRODSCode = " " + C.GoString(errStr) + " " + C.GoString(subErrStr)
+		err.Code = status
+		err.IRODSCode = " " + errorName(status)
 	}
 
 	return err
@@ -0,0 +1,24 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, University of Florida Research Foundation, Inc. and The BioTeam, Inc.  ***
+ *** For more information please refer to the LICENSE.md file                                   ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+// errorName returns the symbolic name of an iRODS status code, followed by the name of the errno it carries
+func errorName(status int) string {
+	var subErrStr *C.char
+
+	errStr := C.rodsErrorName(C.int(status), &subErrStr)
+	defer C.free(unsafe.Pointer(subErrStr))
+
+	return C.GoString(errStr) + " " + C.GoString(subErrStr)
+}
@@ -0,0 +1,111 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestErrorOp(t *testing.T) {
+
+	tests := map[string]string{
+		"iRODS Put DataObject Failed: /tempZone/home/rods/a.txt":   "Put DataObject",
+		"iRODS Move DataObject Failed S:/a, D:/b, rcDataObjRename": "Move DataObject",
+		"iRODS Get Meta Failed, no match":                          "Get Meta",
+		"Unable to save checkpoint state.json":                     "",
+		"iRODS rcDisconnect":                                       "",
+	}
+
+	for message, expected := range tests {
+		if op := errorOp(message); op != expected {
+			t.Errorf("errorOp(%q) = %q, expected %q", message, op, expected)
+		}
+	}
+}
+
+func TestErrorWrap(t *testing.T) {
+
+	cause := &os.PathError{Op: "open", Path: "/tmp/missing", Err: os.ErrNotExist}
+
+	err := error((&GoRodsError{Message: "iRODS Put DataObject Failed"}).withPath("/tempZone/home/rods/missing").wrap(cause))
+
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("errors.Is didn't match the wrapped cause")
+	}
+
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "/tmp/missing" {
+		t.Error("errors.As didn't find the wrapped *os.PathError")
+	}
+
+	var rodsErr *GoRodsError
+	if !errors.As(err, &rodsErr) || rodsErr.Path != "/tempZone/home/rods/missing" {
+		t.Error("errors.As didn't find the *GoRodsError")
+	}
+
+	// Errors without an iRODS code only match sentinels they wrap
+	if errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is matched ErrNotFound without an iRODS code")
+	}
+
+	if notFound := (&GoRodsError{}).wrap(ErrNotFound); !errors.Is(notFound, ErrNotFound) {
+		t.Error("errors.Is didn't match the wrapped ErrNotFound")
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+
+	sentinels := []error{ErrNotFound, ErrAlreadyExists, ErrAccessDenied, ErrNoRowsFound, ErrAuthFailed, ErrChecksumMismatch, ErrNotSupported}
+
+	tests := []struct {
+		code     int
+		expected []error
+	}{
+		{catNoRowsFound, []error{ErrNoRowsFound, ErrNotFound}},
+		{userFileDoesNotExist, []error{ErrNotFound}},
+		{objPathDoesNotExist, []error{ErrNotFound}},
+		{catUnknownFile, []error{ErrNotFound}},
+		{catUnknownCollection, []error{ErrNotFound}},
+		{catInvalidUser, []error{ErrNotFound}},
+		{catInvalidResource, []error{ErrNotFound}},
+		{catInvalidZone, []error{ErrNotFound}},
+		{catInvalidGroup, []error{ErrNotFound}},
+		{catNameExistsAsCollection, []error{ErrAlreadyExists}},
+		{catNameExistsAsDataObj, []error{ErrAlreadyExists}},
+		{overwriteWithoutForceFlag, []error{ErrAlreadyExists}},
+		{catalogAlreadyHasItemByThatName, []error{ErrAlreadyExists}},
+		{catNoAccessPermission, []error{ErrAccessDenied}},
+		{sysNoAPIPriv, []error{ErrAccessDenied}},
+		{catInsufficientPrivilegeLevel, []error{ErrAccessDenied}},
+		{catInvalidAuthentication, []error{ErrAuthFailed}},
+		{catPasswordExpired, []error{ErrAuthFailed}},
+		{pamAuthPasswordFailed, []error{ErrAuthFailed}},
+		{userChksumMismatch, []error{ErrChecksumMismatch}},
+		{sysNotSupported, []error{ErrNotSupported}},
+		{sysUnmatchedAPINum, []error{ErrNotSupported}},
+		{catCollectionNotEmpty, nil},
+		{sysSockReadErr, nil},
+	}
+
+	for _, test := range tests {
+		// The errno in the last three digits doesn't change the kind of error
+		for _, code := range []int{test.code, test.code - 2} {
+			err := fmt.Errorf("wrapped: %w", newError(Fatal, code, "iRODS Test Failed"))
+
+			for _, sentinel := range sentinels {
+				expected := false
+				for _, e := range test.expected {
+					expected = expected || e == sentinel
+				}
+
+				if errors.Is(err, sentinel) != expected {
+					t.Errorf("Expected errors.Is(%v, %q) to be %v", code, sentinel, expected)
+				}
+			}
+		}
+	}
+}
@@ -3,15 +3,12 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
-	"unsafe"
 )
 
 // Group holds info about iRODS groups
@@ -131,11 +128,16 @@ func (grp *Group) Users() (Users, error) {
 
 // Delete deletes the group from iCAT server. Refreshes Connection group cache.
 func (grp *Group) Delete() error {
-	if err := deleteGroup(grp.Name(), grp.Zone(), grp.con); err != nil {
+	return grp.deleteContext(context.Background())
+}
+
+// deleteContext is like Delete, but gives up waiting for a session once ctx is done
+func (grp *Group) deleteContext(ctx context.Context) error {
+	if err := deleteGroup(ctx, grp.Name(), grp.Zone(), grp.con); err != nil {
 		return err
 	}
 
-	if err := grp.con.RefreshGroups(); err != nil {
+	if err := grp.con.refreshGroupsContext(ctx); err != nil {
 		return err
 	}
 
@@ -240,49 +242,9 @@ func (grp *Group) RefreshUsers() error {
 
 // FetchInfo returns a map of fresh group info from the iCAT server
 func (grp *Group) FetchInfo() (map[string]string, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
-
-	cGroup := C.CString(grp.name)
-	defer C.free(unsafe.Pointer(cGroup))
-
-	ccon := grp.con.GetCcon()
-
-	if status := C.gorods_get_user(cGroup, ccon, &result, &err); status != 0 {
-		grp.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Group Info Failed: %v", C.GoString(err)))
-	}
-
-	grp.con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
-	response := make(map[string]string)
-
-	for _, groupInfo := range slice {
-
-		groupAttributes := strings.Split(strings.Trim(C.GoString(groupInfo), " \n"), "\n")
-
-		for _, attr := range groupAttributes {
-
-			split := strings.Split(attr, ": ")
-
-			attrName := split[0]
-			attrVal := split[1]
-
-			response[attrName] = attrVal
-
-		}
+	response, er := grp.con.transport.UserInfo(grp.name)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Group Info Failed")
 	}
 
 	return response, nil
@@ -290,45 +252,23 @@ func (grp *Group) FetchInfo() (map[string]string, error) {
 
 // FetchUsers returns a slice of fresh *User from the iCAT server
 func (grp *Group) FetchUsers() (Users, error) {
+	return grp.fetchUsersContext(context.Background())
+}
 
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
-
-	cGroupName := C.CString(grp.name)
-	defer C.free(unsafe.Pointer(cGroupName))
-
-	ccon := grp.con.GetCcon()
-
-	if status := C.gorods_get_group(ccon, &result, cGroupName, &err); status != 0 {
-		grp.con.ReturnCcon(ccon)
-		if status == C.CAT_NO_ROWS_FOUND {
-			return make(Users, 0), nil
-		} else {
-
-			return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Group %v Failed: %v", grp.name, C.GoString(err)))
-		}
+// fetchUsersContext is like FetchUsers, but gives up waiting for a session once ctx is done
+func (grp *Group) fetchUsersContext(ctx context.Context) (Users, error) {
 
+	members, er := grp.con.transportContext(ctx).GroupMembers(grp.name)
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("iRODS Get Group %v Failed", grp.name))
 	}
 
-	grp.con.ReturnCcon(ccon)
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
-	if usrs, err := grp.con.Users(); err == nil {
+	if usrs, err := grp.con.usersContext(ctx); err == nil {
 		response := make(Users, 0)
 
-		for _, userNames := range slice {
+		for _, userName := range members {
 
-			usrFrags := strings.Split(C.GoString(userNames), "#")
+			usrFrags := strings.Split(userName, "#")
 
 			if usr := usrs.FindByName(usrFrags[0], grp.con); usr != nil {
 				response = append(response, usr)
@@ -437,7 +377,7 @@ func (grp *Group) DeleteMeta(attr string) (*MetaCollection, error) {
 func (grp *Group) Meta() (*MetaCollection, error) {
 
 	if grp.metaCol == nil {
-		if mc, err := newMetaCollection(grp); err == nil {
+		if mc, err := newMetaCollection(context.Background(), grp); err == nil {
 			grp.metaCol = mc
 		} else {
 			return nil, err
@@ -448,85 +388,32 @@ func (grp *Group) Meta() (*MetaCollection, error) {
 }
 
 func addToGroup(userName string, zone *Zone, groupName string, con *Connection) error {
-
-	var (
-		err *C.char
-	)
-
-	cUserName := C.CString(userName)
-	cZoneName := C.CString(zone.Name())
-	cGroupName := C.CString(groupName)
-	defer C.free(unsafe.Pointer(cUserName))
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cGroupName))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status := C.gorods_add_user_to_group(cUserName, cZoneName, cGroupName, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS AddToGroup %v Failed: %v", groupName, C.GoString(err)))
+	if er := con.transport.AddToGroup(userName, zone.Name(), groupName); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS AddToGroup %v Failed", groupName))
 	}
 
 	return nil
 }
 
 func removeFromGroup(userName string, zone *Zone, groupName string, con *Connection) error {
-	var (
-		err *C.char
-	)
-
-	cUserName := C.CString(userName)
-	cZoneName := C.CString(zone.Name())
-	cGroupName := C.CString(groupName)
-	defer C.free(unsafe.Pointer(cUserName))
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cGroupName))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status := C.gorods_remove_user_from_group(cUserName, cZoneName, cGroupName, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS AddToGroup %v Failed: %v", groupName, C.GoString(err)))
+	if er := con.transport.RemoveFromGroup(userName, zone.Name(), groupName); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS AddToGroup %v Failed", groupName))
 	}
 
 	return nil
 }
 
-func deleteGroup(groupName string, zone *Zone, con *Connection) error {
-	var (
-		err *C.char
-	)
-
-	cZoneName := C.CString(zone.Name())
-	cGroupName := C.CString(groupName)
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cGroupName))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status := C.gorods_delete_group(cGroupName, cZoneName, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS DeleteGroup %v Failed: %v", groupName, C.GoString(err)))
+func deleteGroup(ctx context.Context, groupName string, zone *Zone, con *Connection) error {
+	if er := con.transportContext(ctx).DeleteGroup(groupName, zone.Name()); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS DeleteGroup %v Failed", groupName))
 	}
 
 	return nil
 }
 
-func createGroup(groupName string, zone *Zone, con *Connection) error {
-	var (
-		err *C.char
-	)
-
-	cZoneName := C.CString(zone.Name())
-	cGroupName := C.CString(groupName)
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cGroupName))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status := C.gorods_create_group(cGroupName, cZoneName, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS CreateGroup %v Failed: %v", groupName, C.GoString(err)))
+func createGroup(ctx context.Context, groupName string, zone *Zone, con *Connection) error {
+	if er := con.transportContext(ctx).CreateGroup(groupName, zone.Name()); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS CreateGroup %v Failed", groupName))
 	}
 
 	return nil
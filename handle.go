@@ -0,0 +1,289 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Handle is an open data object descriptor, with its own position, independent of the *DataObj it was opened from.
+// It implements io.ReadSeeker, io.ReaderAt, io.WriterAt, io.Writer, io.WriterTo and io.Closer, so it can be passed
+// to http.ServeContent, archive/zip, io.Copy and anything else expecting a file. Handles are safe for concurrent use,
+// but calls are serialized over the data object's connection.
+type Handle struct {
+	parent *DataObj
+	obj    *DataObj
+	pos    int64
+	rw     bool
+	closed bool
+	mu     sync.Mutex
+}
+
+// OpenHandle opens a new read only *Handle for the data object. The handle must be closed when you're done with it.
+func (obj *DataObj) OpenHandle() (*Handle, error) {
+	h := &Handle{parent: obj, obj: obj.withConnection(obj.con)}
+
+	if er := h.obj.Open(); er != nil {
+		return nil, er
+	}
+
+	return h, nil
+}
+
+// OpenHandleRW opens a new read/write *Handle for the data object. The handle must be closed when you're done with it.
+func (obj *DataObj) OpenHandleRW() (*Handle, error) {
+	h := &Handle{parent: obj, obj: obj.withConnection(obj.con), rw: true}
+
+	if er := h.obj.OpenRW(); er != nil {
+		return nil, er
+	}
+
+	return h, nil
+}
+
+// Size returns the size of the data object, including data written through the handle
+func (h *Handle) Size() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.obj.size
+}
+
+// seekOffset computes the absolute offset for Seek
+func seekOffset(cur int64, size int64, offset int64, whence int) (int64, error) {
+	var abs int64
+
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = cur + offset
+	case io.SeekEnd:
+		abs = size + offset
+	default:
+		return cur, newError(Fatal, -1, fmt.Sprintf("iRODS Seek DataObject Failed: invalid whence %v", whence))
+	}
+
+	if abs < 0 {
+		return cur, newError(Fatal, -1, fmt.Sprintf("iRODS Seek DataObject Failed: negative position %v", abs))
+	}
+
+	return abs, nil
+}
+
+// check returns an error if the handle was closed
+func (h *Handle) check() error {
+	if h.closed {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS DataObject Handle Failed: %v, handle is closed", h.obj.path))
+	}
+
+	return nil
+}
+
+// readAt reads into p starting at off, until p is full or the end of the data object is reached
+func (h *Handle) readAt(p []byte, off int64) (int, error) {
+	if h.obj.offset != off {
+		if er := h.obj.LSeek(off); er != nil {
+			return 0, er
+		}
+	}
+
+	n := 0
+
+	for n < len(p) {
+		data, err := h.obj.readNext(len(p) - n)
+		if err != nil {
+			return n, err
+		}
+
+		if len(data) == 0 {
+			return n, io.EOF
+		}
+
+		n += copy(p[n:], data)
+	}
+
+	return n, nil
+}
+
+// writeAt writes p starting at off
+func (h *Handle) writeAt(p []byte, off int64) (int, error) {
+	if !h.rw {
+		return 0, newError(Fatal, -1, fmt.Sprintf("iRODS Write DataObject Failed: %v, handle is read only", h.obj.path))
+	}
+
+	if h.obj.offset != off {
+		if er := h.obj.LSeek(off); er != nil {
+			return 0, er
+		}
+	}
+
+	if er := h.obj.writeNext(p); er != nil {
+		return 0, er
+	}
+
+	return len(p), nil
+}
+
+// Read implements io.Reader, reading from the handle's current position
+func (h *Handle) Read(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if er := h.check(); er != nil {
+		return 0, er
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if h.obj.offset != h.pos {
+		if er := h.obj.LSeek(h.pos); er != nil {
+			return 0, er
+		}
+	}
+
+	data, err := h.obj.readNext(len(p))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, data)
+	h.pos += int64(n)
+
+	return n, nil
+}
+
+// Seek implements io.Seeker. The position is only sent to iRODS on the next read or write.
+func (h *Handle) Seek(offset int64, whence int) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if er := h.check(); er != nil {
+		return h.pos, er
+	}
+
+	abs, err := seekOffset(h.pos, h.obj.size, offset, whence)
+	if err != nil {
+		return h.pos, err
+	}
+
+	h.pos = abs
+
+	return abs, nil
+}
+
+// ReadAt implements io.ReaderAt. It doesn't change the handle's position.
+func (h *Handle) ReadAt(p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if er := h.check(); er != nil {
+		return 0, er
+	}
+
+	if off < 0 {
+		return 0, newError(Fatal, -1, fmt.Sprintf("iRODS ReadAt DataObject Failed: %v, negative offset %v", h.obj.path, off))
+	}
+
+	return h.readAt(p, off)
+}
+
+// Write implements io.Writer, writing at the handle's current position. The handle must be opened with OpenHandleRW.
+func (h *Handle) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if er := h.check(); er != nil {
+		return 0, er
+	}
+
+	n, err := h.writeAt(p, h.pos)
+	h.pos += int64(n)
+
+	return n, err
+}
+
+// WriteAt implements io.WriterAt. It doesn't change the handle's position. The handle must be opened with OpenHandleRW.
+func (h *Handle) WriteAt(p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if er := h.check(); er != nil {
+		return 0, er
+	}
+
+	if off < 0 {
+		return 0, newError(Fatal, -1, fmt.Sprintf("iRODS WriteAt DataObject Failed: %v, negative offset %v", h.obj.path, off))
+	}
+
+	return h.writeAt(p, off)
+}
+
+// WriteTo implements io.WriterTo, copying everything from the handle's current position to the end of the data object into w.
+// Data is read in chunks of DefaultTransferBufferSize bytes.
+func (h *Handle) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if er := h.check(); er != nil {
+		return 0, er
+	}
+
+	if h.obj.offset != h.pos {
+		if er := h.obj.LSeek(h.pos); er != nil {
+			return 0, er
+		}
+	}
+
+	var total int64
+
+	for {
+		data, err := h.obj.readNext(DefaultTransferBufferSize)
+		if err != nil {
+			return total, err
+		}
+
+		if len(data) == 0 {
+			return total, nil
+		}
+
+		n, wErr := w.Write(data)
+		total += int64(n)
+		h.pos += int64(n)
+
+		if wErr != nil {
+			return total, wErr
+		}
+
+		if n != len(data) {
+			return total, io.ErrShortWrite
+		}
+	}
+}
+
+// Close implements io.Closer, closing the data object descriptor in iRODS
+func (h *Handle) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+
+	h.closed = true
+
+	if h.rw && h.obj.size > h.parent.size {
+		h.parent.size = h.obj.size
+	}
+
+	return h.obj.Close()
+}
@@ -0,0 +1,149 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestSeekOffset(t *testing.T) {
+
+	tests := []struct {
+		offset   int64
+		whence   int
+		expected int64
+	}{
+		{5, io.SeekStart, 5},
+		{3, io.SeekCurrent, 7},
+		{-2, io.SeekCurrent, 2},
+		{0, io.SeekEnd, 10},
+		{-4, io.SeekEnd, 6},
+		{5, io.SeekEnd, 15},
+	}
+
+	for _, test := range tests {
+		if abs, err := seekOffset(4, 10, test.offset, test.whence); err != nil {
+			t.Error(err)
+		} else if abs != test.expected {
+			t.Errorf("Expected seek(%v, %v) to land on %v, got %v", test.offset, test.whence, test.expected, abs)
+		}
+	}
+
+	if _, err := seekOffset(4, 10, -5, io.SeekCurrent); err == nil {
+		t.Error("Expected error seeking to a negative position")
+	}
+
+	if _, err := seekOffset(4, 10, 0, 42); err == nil {
+		t.Error("Expected error for invalid whence")
+	}
+}
+
+func TestHandleSeekRead(t *testing.T) {
+	_, obj := memDataObj(t, "hello.txt", []byte("Hello, World!\n"))
+
+	h, err := obj.OpenHandle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	tests := []struct {
+		offset   int64
+		whence   int
+		length   int
+		pos      int64
+		expected string
+	}{
+		{7, io.SeekStart, 5, 7, "World"},
+		{-5, io.SeekCurrent, 3, 7, "Wor"},
+		{2, io.SeekCurrent, 2, 12, "!\n"},
+		{-6, io.SeekEnd, 4, 8, "orld"},
+		{0, io.SeekStart, 5, 0, "Hello"},
+	}
+
+	for _, test := range tests {
+		pos, err := h.Seek(test.offset, test.whence)
+		if err != nil || pos != test.pos {
+			t.Errorf("Expected seek(%v, %v) to land on %v, got %v (%v)", test.offset, test.whence, test.pos, pos, err)
+			continue
+		}
+
+		buf := make([]byte, test.length)
+		if n, err := io.ReadFull(h, buf); err != nil || string(buf[:n]) != test.expected {
+			t.Errorf("Expected %q at %v, got %q (%v)", test.expected, test.pos, buf[:n], err)
+		}
+	}
+
+	// ReadAt doesn't move the handle, which is after "Hello"
+	buf := make([]byte, 6)
+	if n, err := h.ReadAt(buf, 11); err != io.EOF || string(buf[:n]) != "d!\n" {
+		t.Errorf("Expected a short read of \"d!\\n\" with io.EOF, got %q (%v)", buf[:n], err)
+	}
+
+	if rest, err := ioutil.ReadAll(h); err != nil || string(rest) != ", World!\n" {
+		t.Errorf("Expected the rest of the data object, got %q (%v)", rest, err)
+	}
+
+	if pos, err := h.Seek(0, io.SeekCurrent); err != nil || pos != 14 {
+		t.Errorf("Expected to be at the end of the data object, got %v (%v)", pos, err)
+	}
+}
+
+func TestHandleSeekPastEOF(t *testing.T) {
+	_, obj := memDataObj(t, "hello.txt", []byte("Hello, World!\n"))
+
+	h, err := obj.OpenHandle()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pos, err := h.Seek(5, io.SeekEnd); err != nil || pos != 19 {
+		t.Fatalf("Expected seeking past the end to succeed, got %v (%v)", pos, err)
+	}
+
+	if n, err := h.Read(make([]byte, 4)); n != 0 || err != io.EOF {
+		t.Errorf("Expected io.EOF reading past the end, got %v bytes (%v)", n, err)
+	}
+
+	if n, err := h.ReadAt(make([]byte, 4), 100); n != 0 || err != io.EOF {
+		t.Errorf("Expected io.EOF reading at 100, got %v bytes (%v)", n, err)
+	}
+
+	if _, err := h.Seek(-20, io.SeekEnd); err == nil {
+		t.Error("Expected error seeking before the start")
+	}
+
+	h.Close()
+
+	if _, err := h.Seek(0, io.SeekStart); err == nil {
+		t.Error("Expected error seeking a closed handle")
+	}
+
+	// Writing past the end leaves a hole of zeros
+	rw, err := obj.OpenHandleRW()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rw.Seek(2, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rw.Write([]byte("!")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rw.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	if content, err := ioutil.ReadAll(rw); err != nil || !bytes.Equal(content, []byte("Hello, World!\n\x00\x00!")) {
+		t.Errorf("Expected the write past the end to extend the data object, got %q (%v)", content, err)
+	}
+
+	rw.Close()
+}
@@ -3,21 +3,12 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
 	"fmt"
 	"strconv"
 	"time"
-	"unsafe"
 )
 
-func cTimeToTime(cTime *C.char) time.Time {
-	unixStamp, _ := strconv.ParseInt(C.GoString(cTime), 10, 64)
-	return time.Unix(unixStamp, 0)
-}
-
 func timeStringToTime(ts string) time.Time {
 	unixStamp, _ := strconv.ParseInt(ts, 10, 64)
 	return time.Unix(unixStamp, 0)
@@ -117,20 +108,12 @@ func getTypeString(typ int) string {
 	}
 }
 
-func aclSliceToResponse(result *C.goRodsACLResult_t, con *Connection) (ACLs, error) {
-	defer C.gorods_free_acl_result(result)
-
-	unsafeArr := unsafe.Pointer(result.aclArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.goRodsACL_t
-	slice := (*[1 << 30]C.goRodsACL_t)(unsafeArr)[:arrLen:arrLen]
-
+func aclsToResponse(acls []*TransportACL, con *Connection) (ACLs, error) {
 	response := make(ACLs, 0)
 
-	for _, acl := range slice {
+	for _, acl := range acls {
 
-		typeString := C.GoString(acl.acltype)
+		typeString := acl.Type
 
 		typeMap := map[string]int{
 			"rodsgroup":  GroupType,
@@ -143,7 +126,7 @@ func aclSliceToResponse(result *C.goRodsACLResult_t, con *Connection) (ACLs, err
 			aclType = UnknownType
 		}
 
-		accessString := C.GoString(acl.dataAccess)
+		accessString := acl.Access
 		var accessLevel int
 		switch accessString {
 		case "own":
@@ -159,7 +142,7 @@ func aclSliceToResponse(result *C.goRodsACLResult_t, con *Connection) (ACLs, err
 		var accessObject AccessObject
 		if aclType == UserType || aclType == AdminType || aclType == GroupAdminType {
 			if usrs, err := con.Users(); err == nil {
-				if existingUsr := usrs.FindByName(C.GoString(acl.name), con); existingUsr != nil {
+				if existingUsr := usrs.FindByName(acl.Name, con); existingUsr != nil {
 					accessObject = existingUsr
 				} else {
 					return nil, newError(Fatal, -1, fmt.Sprintf("iRODS GetACL Failed: can't find iRODS user by string"))
@@ -169,7 +152,7 @@ func aclSliceToResponse(result *C.goRodsACLResult_t, con *Connection) (ACLs, err
 			}
 		} else if aclType == GroupType {
 			if grps, err := con.Groups(); err == nil {
-				if existingGrp := grps.FindByName(C.GoString(acl.name), con); existingGrp != nil {
+				if existingGrp := grps.FindByName(acl.Name, con); existingGrp != nil {
 					accessObject = existingGrp
 				} else {
 					return nil, newError(Fatal, -1, fmt.Sprintf("iRODS GetACL Failed: can't find iRODS group by string"))
@@ -192,6 +175,37 @@ func aclSliceToResponse(result *C.goRodsACLResult_t, con *Connection) (ACLs, err
 	return response, nil
 }
 
+// statToMap converts entry to the map returned by DataObj.Stat and Collection.Stat
+func statToMap(entry *TransportEntry) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	result["objSize"] = int(entry.Size)
+	result["dataMode"] = entry.Mode
+
+	result["dataId"] = entry.DataId
+	result["chksum"] = entry.Checksum
+	result["ownerName"] = entry.OwnerName
+	result["ownerZone"] = entry.OwnerZone
+	result["createTime"] = timeToString(entry.CreateTime)
+	result["modifyTime"] = timeToString(entry.ModifyTime)
+
+	return result
+}
+
+// resourceName returns the name of resource, which is a string, *Resource or nil (an empty name)
+func resourceName(resource interface{}) (string, error) {
+	switch r := resource.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return r, nil
+	case *Resource:
+		return r.Name(), nil
+	default:
+		return "", newError(Fatal, -1, fmt.Sprintf("Wrong variable type passed in Resource field"))
+	}
+}
+
 func isString(obj interface{}) bool {
 	switch obj.(type) {
 	case string:
@@ -3,9 +3,6 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
 	"encoding/json"
 	"fmt"
@@ -0,0 +1,383 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// ServerInfo returns information about the server, including its zone. It's also a cheap way to check that the
+// connection still works.
+func (c *Conn) ServerInfo() (*MiscSvrInfo, error) {
+	info := new(MiscSvrInfo)
+
+	if _, _, err := c.Request(GetMiscSvrInfoAN, nil, nil, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// Stat returns the system metadata of the data object or collection at path
+func (c *Conn) Stat(path string) (*ObjStat, error) {
+	stat := new(ObjStat)
+
+	if _, _, err := c.Request(ObjStatAN, DataObjInp{ObjPath: path}, nil, stat); err != nil {
+		return nil, err
+	}
+
+	return stat, nil
+}
+
+// CreateCollection creates the collection at path, and its missing parents when recursive is set
+func (c *Conn) CreateCollection(path string, recursive bool) error {
+	inp := CollInp{CollName: path}
+
+	if recursive {
+		inp.KeyVals.Add(RecursiveOprKW, "")
+	}
+
+	_, _, err := c.Request(CollCreateAN, inp, nil, nil)
+
+	return err
+}
+
+// RemoveCollection removes the collection at path. Unless force is set, it's moved to the trash.
+// rmTrash must be set to remove a collection that's in the trash.
+func (c *Conn) RemoveCollection(path string, recursive bool, force bool, rmTrash bool) error {
+	inp := CollInp{CollName: path}
+
+	if recursive {
+		inp.KeyVals.Add(RecursiveOprKW, "")
+	}
+	if force {
+		inp.KeyVals.Add(ForceFlagKW, "")
+	}
+	if rmTrash {
+		inp.KeyVals.Add(RmTrashKW, "")
+	}
+
+	_, _, err := c.Request(RmCollAN, inp, nil, nil)
+
+	return err
+}
+
+// CreateDataObj creates the data object at path, on resource if it's not empty, and returns a descriptor opened for writing
+func (c *Conn) CreateDataObj(path string, resource string, mode int, force bool) (int, error) {
+	inp := DataObjInp{
+		ObjPath:    path,
+		CreateMode: mode,
+		OpenFlags:  OCreat | OWronly | OTrunc,
+	}
+
+	inp.KeyVals.Add(DataTypeKW, "generic")
+
+	if resource != "" {
+		inp.KeyVals.Add(DestRescNameKW, resource)
+	}
+	if force {
+		inp.KeyVals.Add(ForceFlagKW, "")
+	}
+
+	fd, _, err := c.Request(DataObjCreateAN, inp, nil, nil)
+
+	return fd, err
+}
+
+// OpenDataObj opens the data object at path with flags (ORdonly, OWronly or ORdwr) and returns its descriptor.
+// When resource isn't empty, the replica on that resource is opened, otherwise replica replNum is when it's positive.
+func (c *Conn) OpenDataObj(path string, resource string, replNum int, flags int) (int, error) {
+	inp := DataObjInp{
+		ObjPath:   path,
+		OpenFlags: flags,
+	}
+
+	if resource != "" {
+		inp.KeyVals.Add(RescNameKW, resource)
+	} else if replNum > 0 {
+		inp.KeyVals.Add(ReplNumKW, strconv.Itoa(replNum))
+	}
+
+	fd, _, err := c.Request(DataObjOpenAN, inp, nil, nil)
+
+	return fd, err
+}
+
+// Read reads up to length bytes from the open data object fd
+func (c *Conn) Read(fd int, length int) ([]byte, error) {
+	n, bs, err := c.Request(DataObjReadAN, OpenedDataObjInp{L1descInx: fd, Len: length}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < len(bs) {
+		bs = bs[:n]
+	}
+	if bs == nil {
+		bs = []byte{}
+	}
+
+	return bs, nil
+}
+
+// Write writes data to the open data object fd
+func (c *Conn) Write(fd int, data []byte) error {
+	_, _, err := c.Request(DataObjWriteAN, OpenedDataObjInp{L1descInx: fd, Len: len(data)}, data, nil)
+
+	return err
+}
+
+// Seek sets the offset of the open data object fd, whence is 0, 1 or 2 like io.Seeker. It returns the new offset.
+func (c *Conn) Seek(fd int, offset int64, whence int) (int64, error) {
+	out := new(FileLseekOut)
+
+	if _, _, err := c.Request(DataObjLseekAN, OpenedDataObjInp{L1descInx: fd, Offset: offset, Whence: whence}, nil, out); err != nil {
+		return 0, err
+	}
+
+	return out.Offset, nil
+}
+
+// CloseDataObj closes the open data object fd
+func (c *Conn) CloseDataObj(fd int) error {
+	_, _, err := c.Request(DataObjCloseAN, OpenedDataObjInp{L1descInx: fd}, nil, nil)
+
+	return err
+}
+
+// RemoveDataObj removes the data object at path. Unless force is set, it's moved to the trash.
+// rmTrash must be set to remove a data object that's in the trash.
+func (c *Conn) RemoveDataObj(path string, force bool, rmTrash bool) error {
+	inp := DataObjInp{ObjPath: path}
+
+	if force {
+		inp.KeyVals.Add(ForceFlagKW, "")
+	}
+	if rmTrash {
+		inp.KeyVals.Add(RmTrashKW, "")
+	}
+
+	_, _, err := c.Request(DataObjUnlinkAN, inp, nil, nil)
+
+	return err
+}
+
+// Rename moves the data object or collection at src to dst
+func (c *Conn) Rename(src string, dst string, isCollection bool) error {
+	opr := RenameDataObj
+	if isCollection {
+		opr = RenameColl
+	}
+
+	inp := DataObjCopyInp{
+		Inputs: []DataObjInp{
+			{ObjPath: src, OprType: opr},
+			{ObjPath: dst, OprType: opr},
+		},
+	}
+
+	_, _, err := c.Request(DataObjRenameAN, inp, nil, nil)
+
+	return err
+}
+
+// Copy copies the data object at src to dst, on resource if it's not empty
+func (c *Conn) Copy(src string, dst string, resource string, force bool) error {
+	dest := DataObjInp{ObjPath: dst, OprType: CopyDest}
+
+	if resource != "" {
+		dest.KeyVals.Add(DestRescNameKW, resource)
+	}
+	if force {
+		dest.KeyVals.Add(ForceFlagKW, "")
+	}
+
+	inp := DataObjCopyInp{
+		Inputs: []DataObjInp{
+			{ObjPath: src, OprType: CopySrc},
+			dest,
+		},
+	}
+
+	_, _, err := c.Request(DataObjCopyAN, inp, nil, nil)
+
+	return err
+}
+
+// Checksum returns the checksum of the data object at path, computing it if needed or if force is set
+func (c *Conn) Checksum(path string, force bool) (string, error) {
+	inp := DataObjInp{ObjPath: path}
+
+	if force {
+		inp.KeyVals.Add(ForceChksumKW, "")
+	}
+
+	out := new(Str)
+
+	if _, _, err := c.Request(DataObjChksumAN, inp, nil, out); err != nil {
+		return "", err
+	}
+
+	return out.MyStr, nil
+}
+
+// VerifyChecksum has the server recompute the checksum of replica replNum of the data object at path and compare
+// it with the one in the catalog, returning it. A mismatch is reported as a USER_CHKSUM_MISMATCH error.
+func (c *Conn) VerifyChecksum(path string, replNum int) (string, error) {
+	inp := DataObjInp{ObjPath: path}
+
+	inp.KeyVals.Add(VerifyChksumKW, "")
+	inp.KeyVals.Add(ReplNumKW, strconv.Itoa(replNum))
+
+	out := new(Str)
+
+	if _, _, err := c.Request(DataObjChksumAN, inp, nil, out); err != nil {
+		return "", err
+	}
+
+	return out.MyStr, nil
+}
+
+// AVU is an attribute, value, units triple
+type AVU struct {
+	Attribute string
+	Value     string
+	Units     string
+}
+
+// AddAVU adds avu to the object named name. typ is "-d" for data objects, "-C" for collections, "-u" for users
+// and groups, or "-R" for resources.
+func (c *Conn) AddAVU(typ string, name string, avu AVU) error {
+	_, _, err := c.Request(ModAVUMetadataAN, ModAVUMetadataInp{
+		Arg0: "add",
+		Arg1: typ,
+		Arg2: name,
+		Arg3: avu.Attribute,
+		Arg4: avu.Value,
+		Arg5: avu.Units,
+	}, nil, nil)
+
+	return err
+}
+
+// RemoveAVU removes avu from the object named name, typ is the same as for AddAVU
+func (c *Conn) RemoveAVU(typ string, name string, avu AVU) error {
+	_, _, err := c.Request(ModAVUMetadataAN, ModAVUMetadataInp{
+		Arg0: "rm",
+		Arg1: typ,
+		Arg2: name,
+		Arg3: avu.Attribute,
+		Arg4: avu.Value,
+		Arg5: avu.Units,
+	}, nil, nil)
+
+	return err
+}
+
+// ModAVU replaces old with updated on the object named name, typ is the same as for AddAVU
+func (c *Conn) ModAVU(typ string, name string, old AVU, updated AVU) error {
+	inp := ModAVUMetadataInp{
+		Arg0: "mod",
+		Arg1: typ,
+		Arg2: name,
+		Arg3: old.Attribute,
+		Arg4: old.Value,
+		Arg5: old.Units,
+		Arg6: "n:" + updated.Attribute,
+		Arg7: "v:" + updated.Value,
+	}
+
+	// An empty "u:" would be rejected, the units argument is left out instead
+	if updated.Units != "" {
+		inp.Arg8 = "u:" + updated.Units
+	}
+
+	_, _, err := c.Request(ModAVUMetadataAN, inp, nil, nil)
+
+	return err
+}
+
+// ModAccess sets the access level of user (of zone, which may be empty) on path. access is "null", "read",
+// "write" or "own", or "inherit" and "noinherit" for collections.
+func (c *Conn) ModAccess(path string, zone string, user string, access string, recursive bool) error {
+	inp := ModAccessControlInp{
+		AccessLevel: access,
+		UserName:    user,
+		Zone:        zone,
+		Path:        path,
+	}
+
+	if recursive {
+		inp.RecursiveFlag = 1
+	}
+
+	_, _, err := c.Request(ModAccessControlAN, inp, nil, nil)
+
+	return err
+}
+
+// GeneralAdmin runs an iadmin like operation, e.g. GeneralAdmin("add", "user", "alice", "rodsuser", "tempZone").
+// At most 10 arguments are used.
+func (c *Conn) GeneralAdmin(args ...string) error {
+	padded := make([]string, 10)
+	copy(padded, args)
+
+	_, _, err := c.Request(GeneralAdminAN, GeneralAdminInp{
+		Arg0: padded[0],
+		Arg1: padded[1],
+		Arg2: padded[2],
+		Arg3: padded[3],
+		Arg4: padded[4],
+		Arg5: padded[5],
+		Arg6: padded[6],
+		Arg7: padded[7],
+		Arg8: padded[8],
+		Arg9: padded[9],
+	}, nil, nil)
+
+	return err
+}
+
+// TicketAdmin runs an iticket like operation, e.g. TicketAdmin("create", "myticket", "read", "/tempZone/home/rods/file").
+// At most 6 arguments are used.
+func (c *Conn) TicketAdmin(args ...string) error {
+	padded := make([]string, 6)
+	copy(padded, args)
+
+	_, _, err := c.Request(TicketAdminAN, TicketAdminInp{
+		Arg1: padded[0],
+		Arg2: padded[1],
+		Arg3: padded[2],
+		Arg4: padded[3],
+		Arg5: padded[4],
+		Arg6: padded[5],
+	}, nil, nil)
+
+	return err
+}
+
+// AtomicApplyMetadata applies the metadata operations described by the JSON document input to a single object in
+// one transaction, which needs iRODS 4.2.8 or later. When an operation fails none of them are applied, and the JSON
+// description of the failure sent by the server is returned along with the error.
+func (c *Conn) AtomicApplyMetadata(input []byte) ([]byte, error) {
+	// The server expects a NUL terminated string
+	buf := append(append([]byte{}, input...), 0)
+
+	var out BinBytesBuf
+
+	_, _, err := c.Request(AtomicApplyMetadataOperationsAN, BinBytesBuf{
+		BufLen: len(buf),
+		Buf:    base64.StdEncoding.EncodeToString(buf),
+	}, nil, &out)
+
+	output, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(out.Buf))
+	if err == nil && decodeErr != nil {
+		err = decodeErr
+	}
+
+	return bytes.TrimRight(output, "\x00"), err
+}
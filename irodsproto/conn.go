@@ -0,0 +1,575 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+// Package irodsproto is a pure-Go client for the iRODS XML protocol. It doesn't use cgo or the iRODS client
+// libraries, so programs using it can be cross-compiled into static binaries.
+//
+// It covers connecting (with optional SSL negotiation), native (password) authentication, data object
+// open/read/write/seek/close, collection operations, AVU metadata, access control, general admin and GenQuery.
+// GoRODS uses it when ConnectionOptions.PureGo is set, but it can also be used on its own:
+//
+//	conn, err := irodsproto.Dial(irodsproto.Config{
+//		Host:     "localhost",
+//		Port:     1247,
+//		User:     "rods",
+//		Zone:     "tempZone",
+//		Password: "password",
+//	})
+package irodsproto
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types of the iRODS protocol
+const (
+	msgConnect    = "RODS_CONNECT"
+	msgVersion    = "RODS_VERSION"
+	msgAPIRequest = "RODS_API_REQ"
+	msgAPIReply   = "RODS_API_REPLY"
+	msgDisconnect = "RODS_DISCONNECT"
+)
+
+const (
+	// xmlProt selects the XML protocol in the startup pack
+	xmlProt = 1
+
+	challengeLen   = 64
+	maxPasswordLen = 50
+
+	// maxHeaderLen guards against reading garbage as a header length
+	maxHeaderLen = 1024 * 1024
+
+	// maxMessageLen caps each part of a message, so a corrupt length can't make us allocate gigabytes. Servers
+	// send at most 32MB of binary data in one message.
+	maxMessageLen = 64 * 1024 * 1024
+)
+
+// ClientRelVersion and ClientAPIVersion are the iRODS release and API version sent to the server when connecting
+var (
+	ClientRelVersion = "rods4.2.0"
+	ClientAPIVersion = "d"
+)
+
+// Config holds the account and server to connect to. ProxyUser and ProxyZone default to User and Zone.
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Zone     string
+	Password string
+
+	ProxyUser string
+	ProxyZone string
+
+	// Option is sent to the server in the startup pack, it shows up as the client's name in ips
+	Option string
+
+	// Timeout applies to establishing the TCP connection, and is the deadline for each exchange with the server
+	// after that: a request fails when the server doesn't reply within it. Progress messages sent during long
+	// collection operations restart the deadline. Zero means no timeout.
+	Timeout time.Duration
+
+	// SSL holds the client-server negotiation settings, the connection uses plain TCP when it's the zero value
+	SSL SSLConfig
+}
+
+// Error is returned when the server replies with a negative status
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("iRODS error %v", e.Code)
+	}
+	return fmt.Sprintf("iRODS error %v: %v", e.Code, e.Message)
+}
+
+// Conn is a connection to an iRODS server. Requests are serialized, so a Conn can be shared by goroutines,
+// but only one request is in flight at a time.
+type Conn struct {
+	mu sync.Mutex
+
+	conn    net.Conn
+	config  Config
+	version *Version
+	closed  bool
+}
+
+// Dial connects to the server described by config, and authenticates with its password
+func Dial(config Config) (*Conn, error) {
+	if config.ProxyUser == "" {
+		config.ProxyUser = config.User
+	}
+	if config.ProxyZone == "" {
+		config.ProxyZone = config.Zone
+	}
+	if config.Port == 0 {
+		config.Port = 1247
+	}
+	if config.Option == "" {
+		config.Option = "gorods"
+	}
+
+	if err := config.SSL.validate(); err != nil {
+		return nil, err
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+
+	netConn, err := net.DialTimeout("tcp", addr, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newConn(netConn, config)
+
+	if err := conn.startup(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	if err := conn.authenticate(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// newConn wraps an established network connection, which still has to be started up and authenticated
+func newConn(netConn net.Conn, config Config) *Conn {
+	return &Conn{
+		conn:   netConn,
+		config: config,
+	}
+}
+
+// Version returns the server version received when connecting
+func (c *Conn) Version() *Version {
+	return c.version
+}
+
+// Config returns the configuration the connection was made with
+func (c *Conn) Config() Config {
+	return c.config
+}
+
+// Close sends a disconnect message and closes the network connection
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+
+	// The server doesn't reply to disconnects, and we're closing anyway
+	c.writeMessage(msgDisconnect, nil, nil, nil, 0)
+
+	return c.conn.Close()
+}
+
+// startup sends the startup pack, negotiates SSL when it's configured, and reads the server version
+func (c *Conn) startup() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setDeadline()
+	defer c.clearDeadline()
+
+	pack := StartupPack{
+		IrodsProt:      xmlProt,
+		ProxyUser:      c.config.ProxyUser,
+		ProxyRcatZone:  c.config.ProxyZone,
+		ClientUser:     c.config.User,
+		ClientRcatZone: c.config.Zone,
+		RelVersion:     ClientRelVersion,
+		APIVersion:     ClientAPIVersion,
+		Option:         c.config.Option,
+	}
+
+	policy := c.config.SSL.ClientServerPolicy
+	if policy != "" {
+		pack.Option += reqSvrNeg
+	}
+
+	body, err := marshal(pack)
+	if err != nil {
+		return err
+	}
+
+	if err := c.writeMessage(msgConnect, body, nil, nil, 0); err != nil {
+		return err
+	}
+
+	msg, err := c.readMessage()
+	if err != nil {
+		return err
+	}
+
+	result := CSNegUseTCP
+
+	if msg.header.Type == msgCSNeg {
+		if result, err = c.negotiate(msg); err != nil {
+			return err
+		}
+
+		if msg, err = c.readMessage(); err != nil {
+			return err
+		}
+	} else if policy == CSNegRequire {
+		return &Error{Code: ServerNegotiationError, Message: "SSL is required, but the server didn't negotiate"}
+	}
+
+	if msg.header.Type != msgVersion {
+		return fmt.Errorf("irodsproto: expected %v, got %v", msgVersion, msg.header.Type)
+	}
+
+	if err := msg.err(); err != nil {
+		return err
+	}
+
+	version := new(Version)
+	if err := unmarshal(msg.body, version); err != nil {
+		return err
+	}
+
+	if version.Status < 0 {
+		return &Error{Code: version.Status, Message: "connection refused"}
+	}
+
+	c.version = version
+
+	if result == CSNegUseSSL {
+		return c.startSSL()
+	}
+
+	return nil
+}
+
+// authenticate runs the native authentication challenge/response exchange
+func (c *Conn) authenticate() error {
+	challenge := new(AuthRequestOut)
+	if _, _, err := c.Request(AuthRequestAN, nil, nil, challenge); err != nil {
+		return err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(challenge.Challenge)
+	if err != nil {
+		return fmt.Errorf("irodsproto: bad auth challenge: %v", err)
+	}
+
+	response := AuthResponseInp{
+		Response: AuthResponse(raw, c.config.Password),
+		Username: c.config.ProxyUser,
+	}
+
+	_, _, err = c.Request(AuthResponseAN, response, nil, nil)
+
+	return err
+}
+
+// AuthResponse computes the answer to a native authentication challenge: the md5 digest of the challenge
+// followed by the zero padded password, with zero bytes replaced by ones, base64 encoded.
+func AuthResponse(challenge []byte, password string) string {
+	if len(challenge) > challengeLen {
+		challenge = challenge[:challengeLen]
+	}
+
+	padded := make([]byte, maxPasswordLen)
+	copy(padded, password)
+
+	h := md5.New()
+	h.Write(challenge)
+	h.Write(padded)
+	digest := h.Sum(nil)
+
+	for i := range digest {
+		if digest[i] == 0 {
+			digest[i] = 1
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(digest)
+}
+
+// message is a message read from the server
+type message struct {
+	header MsgHeader
+	body   []byte
+	error  []byte
+	bs     []byte
+}
+
+// err returns the error carried by a reply with a negative intInfo
+func (m *message) err() error {
+	if m.header.IntInfo >= 0 {
+		return nil
+	}
+
+	rErr := &Error{Code: m.header.IntInfo}
+
+	if len(m.error) > 0 {
+		var pi RError
+		if unmarshal(m.error, &pi) == nil {
+			msgs := make([]string, 0, len(pi.Messages))
+			for _, msg := range pi.Messages {
+				msgs = append(msgs, strings.TrimSpace(msg.Msg))
+			}
+			rErr.Message = strings.Join(msgs, "; ")
+		}
+	}
+
+	return rErr
+}
+
+// Request calls the API number api with the packing instruction struct in (nil for none) and the binary input bs.
+// The reply body is unmarshalled into out, unless it's nil. It's also unmarshalled when the server returns an
+// error, as some APIs describe the failure there. It returns the reply's intInfo and binary output.
+func (c *Conn) Request(api int, in interface{}, bs []byte, out interface{}) (int, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return 0, nil, fmt.Errorf("irodsproto: connection closed")
+	}
+
+	var body []byte
+
+	if in != nil {
+		var err error
+		if body, err = marshal(in); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	c.setDeadline()
+	defer c.clearDeadline()
+
+	if err := c.writeMessage(msgAPIRequest, body, nil, bs, api); err != nil {
+		return 0, nil, c.broken(err)
+	}
+
+	msg, err := c.readMessage()
+	if err != nil {
+		return 0, nil, c.broken(err)
+	}
+
+	// Long running collection operations send their progress, which must be acknowledged
+	for msg.header.IntInfo == SysSvrToCliCollStat {
+		c.setDeadline()
+
+		if err := c.writeInt(SysCliToSvrCollStatReply); err != nil {
+			return 0, nil, c.broken(err)
+		}
+		if msg, err = c.readMessage(); err != nil {
+			return 0, nil, c.broken(err)
+		}
+	}
+
+	if msg.header.Type != msgAPIReply {
+		return 0, nil, fmt.Errorf("irodsproto: expected %v, got %v", msgAPIReply, msg.header.Type)
+	}
+
+	if err := msg.err(); err != nil {
+		if out != nil && len(msg.body) > 0 {
+			unmarshal(msg.body, out)
+		}
+		return msg.header.IntInfo, nil, err
+	}
+
+	if out != nil && len(msg.body) > 0 {
+		if err := unmarshal(msg.body, out); err != nil {
+			return msg.header.IntInfo, nil, err
+		}
+	}
+
+	return msg.header.IntInfo, msg.bs, nil
+}
+
+// setDeadline gives the server Config.Timeout from now to take and answer what we send
+func (c *Conn) setDeadline() {
+	if c.config.Timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.config.Timeout))
+	}
+}
+
+func (c *Conn) clearDeadline() {
+	if c.config.Timeout > 0 {
+		c.conn.SetDeadline(time.Time{})
+	}
+}
+
+// broken closes the connection after a failed read or write, which leaves the message stream in an unknown state.
+// Later requests fail straight away, rather than reading what's left of an earlier reply.
+func (c *Conn) broken(err error) error {
+	c.closed = true
+	c.conn.Close()
+
+	return err
+}
+
+func (c *Conn) writeInt(i int) error {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, uint32(i))
+
+	_, err := c.conn.Write(buf)
+
+	return err
+}
+
+// writeHeader sends a header on its own, without the parts it announces
+func (c *Conn) writeHeader(header MsgHeader) error {
+	buf, err := headerBytes(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.conn.Write(buf)
+
+	return err
+}
+
+// headerBytes returns the length prefixed header
+func headerBytes(header MsgHeader) ([]byte, error) {
+	data, err := marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+
+	return append(buf, data...), nil
+}
+
+// writeMessage sends a header, followed by its body, error and binary parts
+func (c *Conn) writeMessage(typ string, body []byte, errBody []byte, bs []byte, intInfo int) error {
+	header, err := headerBytes(MsgHeader{
+		Type:     typ,
+		MsgLen:   len(body),
+		ErrorLen: len(errBody),
+		BsLen:    len(bs),
+		IntInfo:  intInfo,
+	})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(header)
+	buf.Write(body)
+	buf.Write(errBody)
+	buf.Write(bs)
+
+	_, err = c.conn.Write(buf.Bytes())
+
+	return err
+}
+
+// readMessage reads a header and the parts it announces
+func (c *Conn) readMessage() (*message, error) {
+	header, err := c.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &message{header: *header}
+
+	if msg.body, err = c.readN(msg.header.MsgLen); err != nil {
+		return nil, err
+	}
+	if msg.error, err = c.readN(msg.header.ErrorLen); err != nil {
+		return nil, err
+	}
+	if msg.bs, err = c.readN(msg.header.BsLen); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// readHeader reads a length prefixed header
+func (c *Conn) readHeader() (*MsgHeader, error) {
+	lenBuf := make([]byte, 4)
+	if _, err := io.ReadFull(c.conn, lenBuf); err != nil {
+		return nil, err
+	}
+
+	headerLen := binary.BigEndian.Uint32(lenBuf)
+	if headerLen > maxHeaderLen {
+		return nil, fmt.Errorf("irodsproto: header length %v is too large", headerLen)
+	}
+
+	headerBuf := make([]byte, headerLen)
+	if _, err := io.ReadFull(c.conn, headerBuf); err != nil {
+		return nil, err
+	}
+
+	header := new(MsgHeader)
+	if err := unmarshal(headerBuf, header); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// readN reads the n bytes of a message part
+func (c *Conn) readN(n int) ([]byte, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	if n > maxMessageLen {
+		return nil, fmt.Errorf("irodsproto: message length %v is too large", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.conn, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// The iRODS XML parser only understands the named entities, and expects control characters as is
+var requestReplacer = strings.NewReplacer(
+	"&#34;", "&quot;",
+	"&#39;", "&apos;",
+	"&#x9;", "\t",
+	"&#xA;", "\n",
+	"&#xD;", "\r",
+)
+
+func marshal(v interface{}) ([]byte, error) {
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(requestReplacer.Replace(string(buf))), nil
+}
+
+func unmarshal(data []byte, v interface{}) error {
+	// The server NUL terminates some messages
+	data = bytes.TrimRight(data, "\x00")
+
+	return xml.Unmarshal(data, v)
+}
@@ -0,0 +1,359 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer answers requests on the server end of a pipe, using handler to build each reply
+type fakeServer struct {
+	conn *Conn
+	t    *testing.T
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *Conn) {
+	client, server := net.Pipe()
+
+	return &fakeServer{conn: newConn(server, Config{}), t: t}, newConn(client, Config{User: "rods", Zone: "tempZone", ProxyUser: "rods", ProxyZone: "tempZone", Password: "secret", Option: "test"})
+}
+
+func (s *fakeServer) expect(typ string, intInfo int) *message {
+	msg, err := s.conn.readMessage()
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	if msg.header.Type != typ || msg.header.IntInfo != intInfo {
+		s.t.Fatalf("Expected %v %v, got %v %v", typ, intInfo, msg.header.Type, msg.header.IntInfo)
+	}
+
+	return msg
+}
+
+func (s *fakeServer) reply(typ string, body interface{}, bs []byte, intInfo int) {
+	var data []byte
+
+	if body != nil {
+		var err error
+		if data, err = marshal(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+
+	if err := s.conn.writeMessage(typ, data, nil, bs, intInfo); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func TestConnectAndAuthenticate(t *testing.T) {
+	server, client := newFakeServer(t)
+	challenge := bytes.Repeat([]byte{7}, challengeLen)
+
+	go func() {
+		msg := server.expect(msgConnect, 0)
+
+		var pack StartupPack
+		if err := unmarshal(msg.body, &pack); err != nil {
+			t.Error(err)
+		}
+		if pack.ProxyUser != "rods" || pack.ClientRcatZone != "tempZone" || pack.IrodsProt != xmlProt {
+			t.Errorf("Unexpected startup pack %+v", pack)
+		}
+
+		server.reply(msgVersion, Version{RelVersion: "rods4.2.8", APIVersion: "d"}, nil, 0)
+
+		server.expect(msgAPIRequest, AuthRequestAN)
+		server.reply(msgAPIReply, AuthRequestOut{Challenge: base64.StdEncoding.EncodeToString(challenge)}, nil, 0)
+
+		msg = server.expect(msgAPIRequest, AuthResponseAN)
+
+		var response AuthResponseInp
+		if err := unmarshal(msg.body, &response); err != nil {
+			t.Error(err)
+		}
+		if response.Response != AuthResponse(challenge, "secret") || response.Username != "rods" {
+			t.Errorf("Unexpected auth response %+v", response)
+		}
+
+		server.reply(msgAPIReply, nil, nil, 0)
+	}()
+
+	if err := client.startup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Version().RelVersion != "rods4.2.8" {
+		t.Errorf("Expected server version rods4.2.8, got %v", client.Version().RelVersion)
+	}
+
+	if err := client.authenticate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthResponse(t *testing.T) {
+	challenge := bytes.Repeat([]byte{1}, challengeLen)
+
+	// Extra challenge bytes are ignored
+	if AuthResponse(challenge, "secret") != AuthResponse(append(challenge, 2, 3), "secret") {
+		t.Error("Expected only the first 64 bytes of the challenge to be used")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(AuthResponse(challenge, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(raw) != 16 || bytes.IndexByte(raw, 0) != -1 {
+		t.Errorf("Expected 16 bytes without zeros, got %v", raw)
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	go func() {
+		server.expect(msgAPIRequest, ObjStatAN)
+
+		errBody, _ := marshal(RError{Count: 1, Messages: []RErrMsg{{Status: -310000, Msg: "no such object"}}})
+		server.conn.writeMessage(msgAPIReply, nil, errBody, nil, -310000)
+	}()
+
+	_, err := client.Stat("/tempZone/home/rods/missing")
+
+	rErr, ok := err.(*Error)
+	if !ok || rErr.Code != -310000 || rErr.Message != "no such object" {
+		t.Errorf("Expected error -310000 with its message, got %v", err)
+	}
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	go func() {
+		server.expect(msgAPIRequest, ObjStatAN)
+		server.conn.writeHeader(MsgHeader{Type: msgAPIReply, MsgLen: maxMessageLen + 1})
+	}()
+
+	if _, err := client.Stat("/tempZone/home/rods/a.txt"); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Expected the message length to be rejected, got %v", err)
+	}
+
+	if _, err := client.Stat("/tempZone/home/rods/a.txt"); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("Expected the connection to be closed after a bad reply, got %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	server := &fakeServer{conn: newConn(serverConn, Config{}), t: t}
+	client := newConn(clientConn, Config{Timeout: 50 * time.Millisecond})
+
+	go func() {
+		// Reply to the first request only after the deadline passed
+		server.expect(msgAPIRequest, ObjStatAN)
+		time.Sleep(100 * time.Millisecond)
+	}()
+
+	_, err := client.Stat("/tempZone/home/rods/a.txt")
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Errorf("Expected the request to time out, got %v", err)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	go func() {
+		msg := server.expect(msgAPIRequest, DataObjWriteAN)
+		if string(msg.bs) != "hello" {
+			t.Errorf("Expected to receive %q, got %q", "hello", msg.bs)
+		}
+		server.reply(msgAPIReply, nil, nil, len(msg.bs))
+
+		server.expect(msgAPIRequest, DataObjReadAN)
+		server.reply(msgAPIReply, nil, []byte("hello"), 5)
+	}()
+
+	if err := client.Write(3, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := client.Read(3, 1024); err != nil {
+		t.Fatal(err)
+	} else if string(data) != "hello" {
+		t.Errorf("Expected to read %q, got %q", "hello", data)
+	}
+}
+
+func TestQueryPages(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	go func() {
+		msg := server.expect(msgAPIRequest, GenQueryAN)
+
+		var inp GenQueryInp
+		if err := unmarshal(msg.body, &inp); err != nil {
+			t.Error(err)
+		}
+		if inp.Conditions.Len != 1 || inp.Conditions.Values[0] != "= '/tempZone/home'" || inp.Selects.Inx[1] != ColCollName {
+			t.Errorf("Unexpected query %+v", inp)
+		}
+
+		server.reply(msgAPIReply, GenQueryOut{RowCnt: 2, AttriCnt: 2, ContinueInx: 1, Results: []SqlResult{
+			{AttriInx: ColCollName, Values: []string{"/tempZone/home/a", "/tempZone/home/b"}},
+			{AttriInx: ColCollId, Values: []string{"1", "2"}},
+		}}, nil, 0)
+
+		msg = server.expect(msgAPIRequest, GenQueryAN)
+		if err := unmarshal(msg.body, &inp); err != nil {
+			t.Error(err)
+		}
+		if inp.ContinueInx != 1 {
+			t.Errorf("Expected second page to continue the query, got %+v", inp)
+		}
+
+		server.reply(msgAPIReply, GenQueryOut{RowCnt: 1, AttriCnt: 2, Results: []SqlResult{
+			{AttriInx: ColCollName, Values: []string{"/tempZone/home/c"}},
+			{AttriInx: ColCollId, Values: []string{"3"}},
+		}}, nil, 0)
+	}()
+
+	rows, err := client.Query(&GenQuery{
+		Select:   Columns(ColCollId, ColCollName),
+		Where:    []Condition{Equal(ColCollParentName, "/tempZone/home")},
+		PageSize: 2,
+		Offset:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || rows[0][0] != "2" || rows[1][1] != "/tempZone/home/c" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+}
+
+func TestTicketAdmin(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	go func() {
+		msg := server.expect(msgAPIRequest, TicketAdminAN)
+
+		var inp TicketAdminInp
+		if err := unmarshal(msg.body, &inp); err != nil {
+			t.Error(err)
+		}
+		if inp.Arg1 != "mod" || inp.Arg2 != "abc" || inp.Arg3 != "uses" || inp.Arg4 != "10" || inp.Arg5 != "" {
+			t.Errorf("Unexpected ticket arguments %+v", inp)
+		}
+
+		server.reply(msgAPIReply, nil, nil, 0)
+	}()
+
+	if err := client.TicketAdmin("mod", "abc", "uses", "10"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	go func() {
+		msg := server.expect(msgAPIRequest, DataObjChksumAN)
+
+		var inp DataObjInp
+		if err := unmarshal(msg.body, &inp); err != nil {
+			t.Error(err)
+		}
+		if inp.KeyVals.Len != 2 || inp.KeyVals.Keys[0] != VerifyChksumKW || inp.KeyVals.Keys[1] != ReplNumKW || inp.KeyVals.Values[1] != "2" {
+			t.Errorf("Unexpected keywords %+v", inp.KeyVals)
+		}
+
+		server.reply(msgAPIReply, Str{MyStr: "sha2:abc="}, nil, 0)
+		server.expect(msgAPIRequest, DataObjChksumAN)
+		server.reply(msgAPIReply, nil, nil, -314000)
+	}()
+
+	if sum, err := client.VerifyChecksum("/tempZone/home/rods/a.txt", 2); err != nil || sum != "sha2:abc=" {
+		t.Fatalf("Expected sha2:abc=, got %q (%v)", sum, err)
+	}
+
+	if _, err := client.VerifyChecksum("/tempZone/home/rods/a.txt", 0); err == nil || err.(*Error).Code != -314000 {
+		t.Errorf("Expected the mismatch to be reported, got %v", err)
+	}
+}
+
+func TestAtomicApplyMetadata(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	failure := `{"operation_index":1,"error_message":"duplicate"}`
+
+	go func() {
+		msg := server.expect(msgAPIRequest, AtomicApplyMetadataOperationsAN)
+
+		var inp BinBytesBuf
+		if err := unmarshal(msg.body, &inp); err != nil {
+			t.Error(err)
+		}
+		if data, err := base64.StdEncoding.DecodeString(inp.Buf); err != nil || string(data) != "{\"operations\":[]}\x00" || inp.BufLen != len(data) {
+			t.Errorf("Unexpected input %+v", inp)
+		}
+
+		server.reply(msgAPIReply, BinBytesBuf{BufLen: 3, Buf: base64.StdEncoding.EncodeToString([]byte("{}\x00"))}, nil, 0)
+
+		server.expect(msgAPIRequest, AtomicApplyMetadataOperationsAN)
+		server.reply(msgAPIReply, BinBytesBuf{BufLen: len(failure), Buf: base64.StdEncoding.EncodeToString([]byte(failure))}, nil, -809000)
+	}()
+
+	if out, err := client.AtomicApplyMetadata([]byte(`{"operations":[]}`)); err != nil || string(out) != "{}" {
+		t.Fatalf("Expected an empty JSON object, got %q (%v)", out, err)
+	}
+
+	if out, err := client.AtomicApplyMetadata([]byte(`{"operations":[]}`)); err == nil || err.(*Error).Code != -809000 || string(out) != failure {
+		t.Errorf("Expected the failure to be described, got %q (%v)", out, err)
+	}
+}
+
+func TestMarshalEscaping(t *testing.T) {
+	data, err := marshal(Str{MyStr: "it's \"quoted\" & <tagged>\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "<STR_PI><myStr>it&apos;s &quot;quoted&quot; &amp; &lt;tagged&gt;\n</myStr></STR_PI>"
+	if string(data) != expected {
+		t.Errorf("Expected %v, got %v", expected, string(data))
+	}
+
+	var str Str
+	if err := unmarshal(append(data, 0), &str); err != nil || !strings.HasPrefix(str.MyStr, "it's") {
+		t.Errorf("Expected to unmarshal the escaped string, got %q, %v", str.MyStr, err)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	server, client, err := Pipe(Config{User: "rods", Zone: "tempZone", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		var inp DataObjInp
+		if _, err := server.Expect(ObjStatAN, &inp); err != nil || inp.ObjPath != "/tempZone/home/rods" {
+			t.Errorf("Unexpected request %+v (%v)", inp, err)
+		}
+		server.Reply(ObjStat{ObjType: CollObjT}, nil, 0)
+	}()
+
+	if stat, err := client.Stat("/tempZone/home/rods"); err != nil || stat.ObjType != CollObjT {
+		t.Errorf("Expected a collection, got %+v (%v)", stat, err)
+	}
+}
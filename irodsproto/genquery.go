@@ -0,0 +1,324 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import "fmt"
+
+// GenQuery column numbers, from rodsGenQuery.h
+const (
+	ColZoneId         = 101
+	ColZoneName       = 102
+	ColZoneType       = 103
+	ColZoneConnection = 104
+	ColZoneComment    = 105
+	ColZoneCreateTime = 106
+	ColZoneModifyTime = 107
+
+	ColUserId         = 201
+	ColUserName       = 202
+	ColUserType       = 203
+	ColUserZone       = 204
+	ColUserInfo       = 206
+	ColUserComment    = 207
+	ColUserCreateTime = 208
+	ColUserModifyTime = 209
+
+	ColRescId            = 301
+	ColRescName          = 302
+	ColRescZoneName      = 303
+	ColRescTypeName      = 304
+	ColRescClassName     = 305
+	ColRescLoc           = 306
+	ColRescVaultPath     = 307
+	ColRescFreeSpace     = 308
+	ColRescInfo          = 309
+	ColRescComment       = 310
+	ColRescCreateTime    = 311
+	ColRescModifyTime    = 312
+	ColRescStatus        = 313
+	ColRescFreeSpaceTime = 314
+	ColRescChildren      = 315
+	ColRescContext       = 316
+	ColRescParent        = 317
+	ColRescParentContext = 318
+
+	ColDataId         = 401
+	ColDataCollId     = 402
+	ColDataName       = 403
+	ColDataReplNum    = 404
+	ColDataVersion    = 405
+	ColDataTypeName   = 406
+	ColDataSize       = 407
+	ColDataRescName   = 409
+	ColDataPath       = 410
+	ColDataOwnerName  = 411
+	ColDataOwnerZone  = 412
+	ColDataReplStatus = 413
+	ColDataStatus     = 414
+	ColDataChecksum   = 415
+	ColDataComments   = 418
+	ColDataCreateTime = 419
+	ColDataModifyTime = 420
+	ColDataMode       = 421
+	ColDataRescHier   = 422
+
+	ColCollId          = 500
+	ColCollName        = 501
+	ColCollParentName  = 502
+	ColCollOwnerName   = 503
+	ColCollOwnerZone   = 504
+	ColCollInheritance = 506
+	ColCollComments    = 507
+	ColCollCreateTime  = 508
+	ColCollModifyTime  = 509
+
+	ColMetaDataAttrName  = 600
+	ColMetaDataAttrValue = 601
+	ColMetaDataAttrUnits = 602
+	ColMetaCollAttrName  = 610
+	ColMetaCollAttrValue = 611
+	ColMetaCollAttrUnits = 612
+	ColMetaRescAttrName  = 630
+	ColMetaRescAttrValue = 631
+	ColMetaRescAttrUnits = 632
+	ColMetaUserAttrName  = 640
+	ColMetaUserAttrValue = 641
+	ColMetaUserAttrUnits = 642
+
+	ColDataAccessType     = 700
+	ColDataAccessName     = 701
+	ColDataTokenNamespace = 702
+	ColDataAccessUserId   = 703
+	ColDataAccessDataId   = 704
+	ColCollAccessType     = 710
+	ColCollAccessName     = 711
+	ColCollTokenNamespace = 712
+	ColCollAccessUserId   = 713
+	ColCollAccessCollId   = 714
+
+	ColUserGroupId   = 900
+	ColUserGroupName = 901
+
+	ColQuotaUserId          = 2000
+	ColQuotaRescId          = 2001
+	ColQuotaLimit           = 2002
+	ColQuotaOver            = 2003
+	ColQuotaModifyTime      = 2004
+	ColQuotaUsageUserId     = 2010
+	ColQuotaUsageRescId     = 2011
+	ColQuotaUsage           = 2012
+	ColQuotaUsageModifyTime = 2013
+	ColQuotaRescName        = 2020
+	ColQuotaUserName        = 2021
+	ColQuotaUserZone        = 2022
+	ColQuotaUserType        = 2023
+
+	ColCollUserName = 1300
+	ColCollUserZone = 1301
+
+	ColTicketId                   = 2200
+	ColTicketString               = 2201
+	ColTicketType                 = 2202
+	ColTicketUserId               = 2203
+	ColTicketObjectId             = 2204
+	ColTicketObjectType           = 2205
+	ColTicketUsesLimit            = 2206
+	ColTicketUsesCount            = 2207
+	ColTicketExpiry               = 2208
+	ColTicketCreateTime           = 2209
+	ColTicketModifyTime           = 2210
+	ColTicketWriteFileCount       = 2211
+	ColTicketWriteFileLimit       = 2212
+	ColTicketWriteByteCount       = 2213
+	ColTicketWriteByteLimit       = 2214
+	ColTicketAllowedHostTicketId  = 2220
+	ColTicketAllowedHost          = 2221
+	ColTicketAllowedUserTicketId  = 2222
+	ColTicketAllowedUserName      = 2223
+	ColTicketAllowedGroupTicketId = 2224
+	ColTicketAllowedGroupName     = 2225
+	ColTicketDataName             = 2226
+	ColTicketDataCollName         = 2227
+	ColTicketCollName             = 2228
+	ColTicketOwnerName            = 2229
+	ColTicketOwnerZone            = 2230
+)
+
+// Select flags, ORed into the value of a selected column
+const (
+	SelectNormal = 1
+	OrderBy      = 0x400
+	OrderByDesc  = 0x800
+)
+
+// Query options
+const (
+	ReturnTotalRowCount = 0x20
+	NoDistinct          = 0x40
+	AutoClose           = 0x100
+	UpperCaseWhere      = 0x200
+)
+
+// DefaultMaxRows is the page size used when GenQuery.PageSize isn't set
+const DefaultMaxRows = 500
+
+// Selection is a selected column, along with its select flags
+type Selection struct {
+	Column int
+	Flags  int
+}
+
+// Condition restricts a column, Expr is the SQL like condition without the column, e.g. "= 'foo'" or "like '%.txt'"
+type Condition struct {
+	Column int
+	Expr   string
+}
+
+// GenQuery is a general query. Rows are returned with their values in the order of Select.
+type GenQuery struct {
+	Select []Selection
+	Where  []Condition
+
+	// Options is a combination of NoDistinct, UpperCaseWhere...
+	Options int
+	// Zone sends the query to another zone when it's not empty
+	Zone string
+
+	// Offset skips that many rows, Limit stops after that many rows when it's positive
+	Offset int
+	Limit  int
+
+	// PageSize is the number of rows requested at a time, DefaultMaxRows when zero
+	PageSize int
+}
+
+// Columns returns selections of columns without flags
+func Columns(columns ...int) []Selection {
+	response := make([]Selection, len(columns))
+
+	for i, col := range columns {
+		response[i] = Selection{Column: col, Flags: SelectNormal}
+	}
+
+	return response
+}
+
+// Equal returns a condition matching column against value exactly
+func Equal(column int, value string) Condition {
+	return Condition{Column: column, Expr: fmt.Sprintf("= '%v'", value)}
+}
+
+func (q *GenQuery) input() GenQueryInp {
+	inp := GenQueryInp{
+		MaxRows: q.PageSize,
+		Options: q.Options,
+	}
+
+	if inp.MaxRows <= 0 {
+		inp.MaxRows = DefaultMaxRows
+	}
+
+	if q.Zone != "" {
+		inp.KeyVals.Add(ZoneKW, q.Zone)
+	}
+
+	for _, sel := range q.Select {
+		inp.Selects.Inx = append(inp.Selects.Inx, sel.Column)
+		inp.Selects.Values = append(inp.Selects.Values, sel.Flags)
+	}
+	inp.Selects.Len = len(q.Select)
+
+	for _, cond := range q.Where {
+		inp.Conditions.Inx = append(inp.Conditions.Inx, cond.Column)
+		inp.Conditions.Values = append(inp.Conditions.Values, cond.Expr)
+	}
+	inp.Conditions.Len = len(q.Where)
+
+	return inp
+}
+
+// rows converts a page of results, by column, into rows in the order of q.Select
+func (q *GenQuery) rows(out *GenQueryOut) ([][]string, error) {
+	byColumn := make(map[int][]string, len(out.Results))
+	for _, res := range out.Results {
+		byColumn[res.AttriInx] = res.Values
+	}
+
+	rows := make([][]string, out.RowCnt)
+
+	for r := range rows {
+		rows[r] = make([]string, len(q.Select))
+
+		for i, sel := range q.Select {
+			values, ok := byColumn[sel.Column]
+			if !ok || r >= len(values) {
+				return nil, fmt.Errorf("irodsproto: missing value for column %v in query results", sel.Column)
+			}
+
+			rows[r][i] = values[r]
+		}
+	}
+
+	return rows, nil
+}
+
+// Query runs q, fetching pages until every row (or Limit rows after Offset) was read. No rows isn't an error.
+func (c *Conn) Query(q *GenQuery) ([][]string, error) {
+	inp := q.input()
+	response := make([][]string, 0)
+	skip := q.Offset
+
+	for {
+		out := new(GenQueryOut)
+
+		if _, _, err := c.Request(GenQueryAN, inp, nil, out); err != nil {
+			if rErr, ok := err.(*Error); ok && rErr.Code == CatNoRowsFound {
+				return response, nil
+			}
+			return nil, err
+		}
+
+		rows, err := q.rows(out)
+		if err != nil {
+			return nil, err
+		}
+
+		if skip > 0 {
+			if skip >= len(rows) {
+				skip -= len(rows)
+				rows = nil
+			} else {
+				rows = rows[skip:]
+				skip = 0
+			}
+		}
+
+		response = append(response, rows...)
+
+		done := out.ContinueInx == 0
+		if q.Limit > 0 && len(response) >= q.Limit {
+			response = response[:q.Limit]
+			done = true
+		}
+
+		if done {
+			if out.ContinueInx != 0 {
+				c.closeQuery(inp, out.ContinueInx)
+			}
+			return response, nil
+		}
+
+		inp.ContinueInx = out.ContinueInx
+	}
+}
+
+// closeQuery releases the server side statement of an unfinished query
+func (c *Conn) closeQuery(inp GenQueryInp, continueInx int) error {
+	inp.MaxRows = 0
+	inp.ContinueInx = continueInx
+
+	_, _, err := c.Request(GenQueryAN, inp, nil, nil)
+
+	return err
+}
@@ -0,0 +1,263 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Client-server negotiation policies, as in irods_client_server_policy
+const (
+	CSNegRequire  = "CS_NEG_REQUIRE"
+	CSNegRefuse   = "CS_NEG_REFUSE"
+	CSNegDontCare = "CS_NEG_DONT_CARE"
+)
+
+// Negotiation results
+const (
+	CSNegUseSSL  = "CS_NEG_USE_SSL"
+	CSNegUseTCP  = "CS_NEG_USE_TCP"
+	CSNegFailure = "CS_NEG_FAILURE"
+)
+
+// Server verification modes, as in irods_ssl_verify_server
+const (
+	VerifyHostname = "hostname"
+	VerifyCert     = "cert"
+	VerifyNone     = "none"
+)
+
+// Encryption defaults, used for the parameters left at zero
+const (
+	DefaultEncryptionAlgorithm     = "AES-256-CBC"
+	DefaultEncryptionKeySize       = 32
+	DefaultEncryptionSaltSize      = 8
+	DefaultEncryptionNumHashRounds = 16
+)
+
+// ServerNegotiationError is the status of errors caused by incompatible negotiation policies
+const ServerNegotiationError = -192000
+
+const (
+	msgCSNeg        = "RODS_CS_NEG_T"
+	msgSharedSecret = "SHARED_SECRET"
+
+	// reqSvrNeg is appended to the startup pack option to ask for negotiation
+	reqSvrNeg = "request_server_negotiation"
+
+	csNegResultKW = "cs_neg_result_kw"
+
+	csNegStatusFailure = 0
+	csNegStatusSuccess = 1
+)
+
+// CSNeg_PI is exchanged during client-server negotiation
+type CSNeg struct {
+	XMLName xml.Name `xml:"CS_NEG_PI"`
+	Status  int      `xml:"status"`
+	Result  string   `xml:"result"`
+}
+
+// SSLConfig holds the client-server negotiation and SSL settings. The zero value doesn't negotiate, and the
+// connection uses plain TCP.
+type SSLConfig struct {
+	// ClientServerPolicy is CSNegRequire, CSNegRefuse or CSNegDontCare. Negotiation is only requested when it's set.
+	ClientServerPolicy string
+
+	// CACertificateFile is a PEM bundle used to verify the server, the system roots are used when it's empty
+	CACertificateFile string
+	// VerifyServer is VerifyHostname (the default), VerifyCert or VerifyNone
+	VerifyServer string
+
+	// Encryption parameters sent to the server once SSL is established, defaults are used for zero values
+	EncryptionAlgorithm     string
+	EncryptionKeySize       int
+	EncryptionSaltSize      int
+	EncryptionNumHashRounds int
+}
+
+// Negotiate returns the outcome of the client policy against the server policy, like the iRODS negotiation table
+func Negotiate(client string, server string) string {
+	switch {
+	case client == CSNegRequire && server == CSNegRefuse, client == CSNegRefuse && server == CSNegRequire:
+		return CSNegFailure
+	case client == CSNegRefuse || server == CSNegRefuse:
+		return CSNegUseTCP
+	}
+
+	return CSNegUseSSL
+}
+
+// validate checks the policy and verification mode
+func (s *SSLConfig) validate() error {
+	switch s.ClientServerPolicy {
+	case "", CSNegRequire, CSNegRefuse, CSNegDontCare:
+	default:
+		return fmt.Errorf("irodsproto: unknown client server policy %q", s.ClientServerPolicy)
+	}
+
+	switch s.VerifyServer {
+	case "", VerifyHostname, VerifyCert, VerifyNone:
+	default:
+		return fmt.Errorf("irodsproto: unknown server verification mode %q", s.VerifyServer)
+	}
+
+	if s.EncryptionKeySize < 0 || s.EncryptionSaltSize < 0 || s.EncryptionNumHashRounds < 0 {
+		return fmt.Errorf("irodsproto: encryption parameters can't be negative")
+	}
+
+	return nil
+}
+
+// tlsConfig builds the TLS configuration used to connect to host
+func (s *SSLConfig) tlsConfig(host string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: host}
+
+	if s.CACertificateFile != "" {
+		pem, err := ioutil.ReadFile(s.CACertificateFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("irodsproto: no certificates found in %v", s.CACertificateFile)
+		}
+	}
+
+	switch s.VerifyServer {
+	case VerifyNone:
+		cfg.InsecureSkipVerify = true
+	case VerifyCert:
+		// The chain is verified, but not the host name
+		roots := cfg.RootCAs
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyChain(rawCerts, roots)
+		}
+	}
+
+	return cfg, nil
+}
+
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	certs := make([]*x509.Certificate, len(rawCerts))
+
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+
+	if len(certs) == 0 {
+		return fmt.Errorf("irodsproto: server sent no certificate")
+	}
+
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(opts)
+
+	return err
+}
+
+// negotiate answers the server's negotiation message, and returns the outcome
+func (c *Conn) negotiate(msg *message) (string, error) {
+	var serverNeg CSNeg
+	if err := unmarshal(msg.body, &serverNeg); err != nil {
+		return "", err
+	}
+
+	result := Negotiate(c.config.SSL.ClientServerPolicy, serverNeg.Result)
+
+	reply := CSNeg{
+		Status: csNegStatusSuccess,
+		Result: fmt.Sprintf("%v=%v;", csNegResultKW, result),
+	}
+	if result == CSNegFailure {
+		reply.Status = csNegStatusFailure
+	}
+
+	body, err := marshal(reply)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.writeMessage(msgCSNeg, body, nil, nil, 0); err != nil {
+		return "", err
+	}
+
+	if result == CSNegFailure {
+		return "", &Error{
+			Code:    ServerNegotiationError,
+			Message: fmt.Sprintf("client policy %v is incompatible with server policy %v", c.config.SSL.ClientServerPolicy, strings.TrimSpace(serverNeg.Result)),
+		}
+	}
+
+	return result, nil
+}
+
+// startSSL upgrades the connection to TLS, then sends the encryption parameters and a shared secret like the
+// iRODS SSL network plugin does
+func (c *Conn) startSSL() error {
+	cfg, err := c.config.SSL.tlsConfig(c.config.Host)
+	if err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(c.conn, cfg)
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+
+	c.conn = tlsConn
+
+	ssl := c.config.SSL
+
+	if ssl.EncryptionAlgorithm == "" {
+		ssl.EncryptionAlgorithm = DefaultEncryptionAlgorithm
+	}
+	if ssl.EncryptionKeySize == 0 {
+		ssl.EncryptionKeySize = DefaultEncryptionKeySize
+	}
+	if ssl.EncryptionSaltSize == 0 {
+		ssl.EncryptionSaltSize = DefaultEncryptionSaltSize
+	}
+	if ssl.EncryptionNumHashRounds == 0 {
+		ssl.EncryptionNumHashRounds = DefaultEncryptionNumHashRounds
+	}
+
+	// The parameters are carried by the header fields alone
+	if err := c.writeHeader(MsgHeader{
+		Type:     ssl.EncryptionAlgorithm,
+		MsgLen:   ssl.EncryptionKeySize,
+		ErrorLen: ssl.EncryptionSaltSize,
+		BsLen:    ssl.EncryptionNumHashRounds,
+	}); err != nil {
+		return err
+	}
+
+	secret := make([]byte, ssl.EncryptionKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	return c.writeMessage(msgSharedSecret, secret, nil, nil, 0)
+}
+
+// SSL reports whether the connection was upgraded to TLS
+func (c *Conn) SSL() bool {
+	_, ok := c.conn.(*tls.Conn)
+	return ok
+}
@@ -0,0 +1,167 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		client, server, expected string
+	}{
+		{CSNegRequire, CSNegRequire, CSNegUseSSL},
+		{CSNegRequire, CSNegDontCare, CSNegUseSSL},
+		{CSNegRequire, CSNegRefuse, CSNegFailure},
+		{CSNegDontCare, CSNegRequire, CSNegUseSSL},
+		{CSNegDontCare, CSNegDontCare, CSNegUseSSL},
+		{CSNegDontCare, CSNegRefuse, CSNegUseTCP},
+		{CSNegRefuse, CSNegRequire, CSNegFailure},
+		{CSNegRefuse, CSNegDontCare, CSNegUseTCP},
+		{CSNegRefuse, CSNegRefuse, CSNegUseTCP},
+	}
+
+	for _, c := range cases {
+		if result := Negotiate(c.client, c.server); result != c.expected {
+			t.Errorf("Expected %v for client %v and server %v, got %v", c.expected, c.client, c.server, result)
+		}
+	}
+}
+
+func TestSSLConfigValidate(t *testing.T) {
+	if err := (&SSLConfig{ClientServerPolicy: "CS_NEG_MAYBE"}).validate(); err == nil {
+		t.Error("Expected an unknown policy to be rejected")
+	}
+
+	if err := (&SSLConfig{VerifyServer: "sometimes"}).validate(); err == nil {
+		t.Error("Expected an unknown verification mode to be rejected")
+	}
+
+	if err := (&SSLConfig{ClientServerPolicy: CSNegRequire, VerifyServer: VerifyCert, EncryptionKeySize: 32}).validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+// expectNegotiation reads the startup pack and answers with the server policy, returning the client's choice
+func (s *fakeServer) expectNegotiation(policy string) string {
+	msg := s.expect(msgConnect, 0)
+
+	var pack StartupPack
+	if err := unmarshal(msg.body, &pack); err != nil {
+		s.t.Error(err)
+	}
+	if !strings.HasSuffix(pack.Option, reqSvrNeg) {
+		s.t.Errorf("Expected the option to request negotiation, got %q", pack.Option)
+	}
+
+	s.reply(msgCSNeg, CSNeg{Status: csNegStatusSuccess, Result: policy}, nil, 0)
+
+	msg = s.expect(msgCSNeg, 0)
+
+	var neg CSNeg
+	if err := unmarshal(msg.body, &neg); err != nil {
+		s.t.Error(err)
+	}
+
+	return neg.Result
+}
+
+func TestNegotiateTCP(t *testing.T) {
+	server, client := newFakeServer(t)
+	client.config.SSL.ClientServerPolicy = CSNegDontCare
+
+	go func() {
+		if result := server.expectNegotiation(CSNegRefuse); result != "cs_neg_result_kw=CS_NEG_USE_TCP;" {
+			t.Errorf("Unexpected negotiation result %q", result)
+		}
+
+		server.reply(msgVersion, Version{RelVersion: "rods4.2.8"}, nil, 0)
+	}()
+
+	if err := client.startup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.SSL() {
+		t.Error("Expected a plain TCP connection")
+	}
+}
+
+func TestNegotiateFailure(t *testing.T) {
+	server, client := newFakeServer(t)
+	client.config.SSL.ClientServerPolicy = CSNegRequire
+
+	go server.expectNegotiation(CSNegRefuse)
+
+	err := client.startup()
+	if rErr, ok := err.(*Error); !ok || rErr.Code != ServerNegotiationError {
+		t.Errorf("Expected a negotiation error, got %v", err)
+	}
+}
+
+func TestNegotiateSSL(t *testing.T) {
+	server, client := newFakeServer(t)
+	client.config.SSL = SSLConfig{ClientServerPolicy: CSNegRequire, VerifyServer: VerifyNone, EncryptionKeySize: 16}
+
+	cert := selfSignedCert(t)
+
+	go func() {
+		server.expectNegotiation(CSNegDontCare)
+		server.reply(msgVersion, Version{RelVersion: "rods4.2.8"}, nil, 0)
+
+		server.conn.conn = tls.Server(server.conn.conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+		params, err := server.conn.readHeader()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if params.Type != DefaultEncryptionAlgorithm || params.MsgLen != 16 || params.ErrorLen != DefaultEncryptionSaltSize || params.BsLen != DefaultEncryptionNumHashRounds {
+			t.Errorf("Unexpected encryption parameters %+v", params)
+		}
+
+		if msg := server.expect(msgSharedSecret, 0); len(msg.body) != 16 {
+			t.Errorf("Expected a 16 byte shared secret, got %v bytes", len(msg.body))
+		}
+	}()
+
+	if err := client.startup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !client.SSL() {
+		t.Error("Expected the connection to use SSL")
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "irods.example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"irods.example.org"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
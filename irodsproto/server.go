@@ -0,0 +1,150 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+)
+
+// Server is the server end of a connection made with Pipe, for testing clients of this package without an iRODS
+// server. Tests read each request with Expect, and answer it with Reply or ReplyRows.
+type Server struct {
+	conn *Conn
+}
+
+// Pipe connects to a new Server over an in-memory pipe. The connection is started up and authenticated as the
+// user of config before it's returned, the Server accepts any password.
+func Pipe(config Config) (*Server, *Conn, error) {
+	if config.ProxyUser == "" {
+		config.ProxyUser = config.User
+	}
+	if config.ProxyZone == "" {
+		config.ProxyZone = config.Zone
+	}
+
+	clientConn, serverConn := net.Pipe()
+
+	server := &Server{conn: newConn(serverConn, Config{})}
+	client := newConn(clientConn, config)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.accept()
+	}()
+
+	err := client.startup()
+	if err == nil {
+		err = client.authenticate()
+	}
+
+	if sErr := <-served; err == nil {
+		err = sErr
+	}
+
+	if err != nil {
+		clientConn.Close()
+		serverConn.Close()
+		return nil, nil, err
+	}
+
+	return server, client, nil
+}
+
+// accept answers the startup and native authentication of a client
+func (s *Server) accept() error {
+	msg, err := s.conn.readMessage()
+	if err != nil {
+		return err
+	}
+	if msg.header.Type != msgConnect {
+		return fmt.Errorf("irodsproto: expected %v, got %v", msgConnect, msg.header.Type)
+	}
+
+	if err := s.reply(msgVersion, Version{RelVersion: ClientRelVersion, APIVersion: ClientAPIVersion}, nil, 0); err != nil {
+		return err
+	}
+
+	if _, err := s.Expect(AuthRequestAN, nil); err != nil {
+		return err
+	}
+
+	challenge := bytes.Repeat([]byte{1}, challengeLen)
+	if err := s.Reply(AuthRequestOut{Challenge: base64.StdEncoding.EncodeToString(challenge)}, nil, 0); err != nil {
+		return err
+	}
+
+	if _, err := s.Expect(AuthResponseAN, nil); err != nil {
+		return err
+	}
+
+	return s.Reply(nil, nil, 0)
+}
+
+// Expect reads the next request, which has to call api. Its body is unmarshalled into in, unless it's nil, and its
+// binary input is returned.
+func (s *Server) Expect(api int, in interface{}) ([]byte, error) {
+	msg, err := s.conn.readMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.header.Type != msgAPIRequest || msg.header.IntInfo != api {
+		return nil, fmt.Errorf("irodsproto: expected %v %v, got %v %v", msgAPIRequest, api, msg.header.Type, msg.header.IntInfo)
+	}
+
+	if in != nil {
+		if err := unmarshal(msg.body, in); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg.bs, nil
+}
+
+// Reply answers a request with out (nil for no body) and the binary output bs. A negative intInfo is an error status.
+func (s *Server) Reply(out interface{}, bs []byte, intInfo int) error {
+	return s.reply(msgAPIReply, out, bs, intInfo)
+}
+
+// ReplyRows answers the GenQuery inp with rows, which hold the values of the selected columns in order. No rows are
+// reported with CatNoRowsFound, like the server does.
+func (s *Server) ReplyRows(inp *GenQueryInp, rows [][]string) error {
+	if len(rows) == 0 {
+		return s.Reply(nil, nil, CatNoRowsFound)
+	}
+
+	out := GenQueryOut{RowCnt: len(rows), AttriCnt: len(inp.Selects.Inx)}
+
+	for i, col := range inp.Selects.Inx {
+		result := SqlResult{AttriInx: col, Values: make([]string, len(rows))}
+		for r, row := range rows {
+			result.Values[r] = row[i]
+		}
+
+		out.Results = append(out.Results, result)
+	}
+
+	return s.Reply(out, nil, 0)
+}
+
+// Close closes the server end of the pipe
+func (s *Server) Close() error {
+	return s.conn.conn.Close()
+}
+
+func (s *Server) reply(typ string, out interface{}, bs []byte, intInfo int) error {
+	var body []byte
+
+	if out != nil {
+		var err error
+		if body, err = marshal(out); err != nil {
+			return err
+		}
+	}
+
+	return s.conn.writeMessage(typ, body, nil, bs, intInfo)
+}
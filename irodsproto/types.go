@@ -0,0 +1,335 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package irodsproto
+
+import "encoding/xml"
+
+// API numbers, from apiNumber.h
+const (
+	DataObjCreateAN    = 601
+	DataObjOpenAN      = 602
+	DataObjUnlinkAN    = 615
+	DataObjRenameAN    = 627
+	DataObjChksumAN    = 629
+	ObjStatAN          = 633
+	DataObjCloseAN     = 673
+	DataObjLseekAN     = 674
+	DataObjReadAN      = 675
+	DataObjWriteAN     = 676
+	RmCollAN           = 679
+	CollCreateAN       = 681
+	DataObjCopyAN      = 696
+	GetMiscSvrInfoAN   = 700
+	GeneralAdminAN     = 701
+	GenQueryAN         = 702
+	AuthRequestAN      = 703
+	AuthResponseAN     = 704
+	ModAVUMetadataAN   = 706
+	ModAccessControlAN = 707
+	TicketAdminAN      = 723
+
+	AtomicApplyMetadataOperationsAN = 20002
+)
+
+// Status codes the client needs to know about, from rodsErrorTable.h
+const (
+	CatNoRowsFound           = -808000
+	SysSvrToCliCollStat      = 99999996
+	SysCliToSvrCollStatReply = 99999997
+)
+
+// Object types of ObjStat.ObjType
+const (
+	UnknownObjT = 0
+	DataObjT    = 1
+	CollObjT    = 2
+)
+
+// Open flags, as the server expects them regardless of the client's OS
+const (
+	ORdonly = 0
+	OWronly = 1
+	ORdwr   = 2
+	OCreat  = 0x40
+	OTrunc  = 0x200
+)
+
+// Operation types used by copies and renames
+const (
+	CopyDest      = 9
+	CopySrc       = 10
+	RenameDataObj = 11
+	RenameColl    = 12
+)
+
+// Keywords of KeyValPair
+const (
+	ForceFlagKW    = "forceFlag"
+	DestRescNameKW = "destRescName"
+	RescNameKW     = "rescName"
+	ReplNumKW      = "replNum"
+	DataTypeKW     = "dataType"
+	RecursiveOprKW = "recursiveOpr"
+	RmTrashKW      = "irodsRmTrash"
+	ZoneKW         = "zone"
+	ForceChksumKW  = "forceChksum"
+	VerifyChksumKW = "verifyChksum"
+)
+
+// MsgHeader_PI precedes every message
+type MsgHeader struct {
+	XMLName  xml.Name `xml:"MsgHeader_PI"`
+	Type     string   `xml:"type"`
+	MsgLen   int      `xml:"msgLen"`
+	ErrorLen int      `xml:"errorLen"`
+	BsLen    int      `xml:"bsLen"`
+	IntInfo  int      `xml:"intInfo"`
+}
+
+// StartupPack_PI is sent when connecting
+type StartupPack struct {
+	XMLName        xml.Name `xml:"StartupPack_PI"`
+	IrodsProt      int      `xml:"irodsProt"`
+	ReconnFlag     int      `xml:"reconnFlag"`
+	ConnectCnt     int      `xml:"connectCnt"`
+	ProxyUser      string   `xml:"proxyUser"`
+	ProxyRcatZone  string   `xml:"proxyRcatZone"`
+	ClientUser     string   `xml:"clientUser"`
+	ClientRcatZone string   `xml:"clientRcatZone"`
+	RelVersion     string   `xml:"relVersion"`
+	APIVersion     string   `xml:"apiVersion"`
+	Option         string   `xml:"option"`
+}
+
+// Version_PI is the server's reply to a startup pack
+type Version struct {
+	XMLName    xml.Name `xml:"Version_PI"`
+	Status     int      `xml:"status"`
+	RelVersion string   `xml:"relVersion"`
+	APIVersion string   `xml:"apiVersion"`
+	ReconnPort int      `xml:"reconnPort"`
+	ReconnAddr string   `xml:"reconnAddr"`
+	Cookie     int      `xml:"cookie"`
+}
+
+// RError_PI carries the error messages of a failed request
+type RError struct {
+	XMLName  xml.Name  `xml:"RError_PI"`
+	Count    int       `xml:"count"`
+	Messages []RErrMsg `xml:"RErrMsg_PI"`
+}
+
+// RErrMsg_PI is a single error message
+type RErrMsg struct {
+	Status int    `xml:"status"`
+	Msg    string `xml:"msg"`
+}
+
+// authRequestOut_PI holds the base64 encoded authentication challenge
+type AuthRequestOut struct {
+	XMLName   xml.Name `xml:"authRequestOut_PI"`
+	Challenge string   `xml:"challenge"`
+}
+
+// authResponseInp_PI answers the authentication challenge
+type AuthResponseInp struct {
+	XMLName  xml.Name `xml:"authResponseInp_PI"`
+	Response string   `xml:"response"`
+	Username string   `xml:"username"`
+}
+
+// MiscSvrInfo_PI describes the server
+type MiscSvrInfo struct {
+	XMLName        xml.Name `xml:"MiscSvrInfo_PI"`
+	ServerType     int      `xml:"serverType"`
+	ServerBootTime int      `xml:"serverBootTime"`
+	RelVersion     string   `xml:"relVersion"`
+	APIVersion     string   `xml:"apiVersion"`
+	RodsZone       string   `xml:"rodsZone"`
+}
+
+// KeyValPair_PI is a list of keyword/value options
+type KeyValPair struct {
+	XMLName xml.Name `xml:"KeyValPair_PI"`
+	Len     int      `xml:"ssLen"`
+	Keys    []string `xml:"keyWord"`
+	Values  []string `xml:"svalue"`
+}
+
+// Add appends the keyword key with value
+func (kv *KeyValPair) Add(key string, value string) {
+	kv.Keys = append(kv.Keys, key)
+	kv.Values = append(kv.Values, value)
+	kv.Len = len(kv.Keys)
+}
+
+// DataObjInp_PI describes a data object to operate on
+type DataObjInp struct {
+	XMLName    xml.Name   `xml:"DataObjInp_PI"`
+	ObjPath    string     `xml:"objPath"`
+	CreateMode int        `xml:"createMode"`
+	OpenFlags  int        `xml:"openFlags"`
+	Offset     int64      `xml:"offset"`
+	DataSize   int64      `xml:"dataSize"`
+	NumThreads int        `xml:"numThreads"`
+	OprType    int        `xml:"oprType"`
+	KeyVals    KeyValPair `xml:"KeyValPair_PI"`
+}
+
+// DataObjCopyInp_PI holds the source and destination of a copy or rename
+type DataObjCopyInp struct {
+	XMLName xml.Name     `xml:"DataObjCopyInp_PI"`
+	Inputs  []DataObjInp `xml:"DataObjInp_PI"`
+}
+
+// OpenedDataObjInp_PI describes an operation on an open data object
+type OpenedDataObjInp struct {
+	XMLName      xml.Name   `xml:"OpenedDataObjInp_PI"`
+	L1descInx    int        `xml:"l1descInx"`
+	Len          int        `xml:"len"`
+	Whence       int        `xml:"whence"`
+	OprType      int        `xml:"oprType"`
+	Offset       int64      `xml:"offset"`
+	BytesWritten int64      `xml:"bytesWritten"`
+	KeyVals      KeyValPair `xml:"KeyValPair_PI"`
+}
+
+// fileLseekOut_PI holds the offset after a seek
+type FileLseekOut struct {
+	XMLName xml.Name `xml:"fileLseekOut_PI"`
+	Offset  int64    `xml:"offset"`
+}
+
+// CollInpNew_PI describes a collection to operate on
+type CollInp struct {
+	XMLName  xml.Name   `xml:"CollInpNew_PI"`
+	CollName string     `xml:"collName"`
+	Flags    int        `xml:"flags"`
+	OprType  int        `xml:"oprType"`
+	KeyVals  KeyValPair `xml:"KeyValPair_PI"`
+}
+
+// RodsObjStat_PI is the system metadata of a data object or collection
+type ObjStat struct {
+	XMLName    xml.Name `xml:"RodsObjStat_PI"`
+	ObjSize    int64    `xml:"objSize"`
+	ObjType    int      `xml:"objType"`
+	DataMode   int      `xml:"dataMode"`
+	DataId     string   `xml:"dataId"`
+	Chksum     string   `xml:"chksum"`
+	OwnerName  string   `xml:"ownerName"`
+	OwnerZone  string   `xml:"ownerZone"`
+	CreateTime string   `xml:"createTime"`
+	ModifyTime string   `xml:"modifyTime"`
+}
+
+// STR_PI is a single string
+type Str struct {
+	XMLName xml.Name `xml:"STR_PI"`
+	MyStr   string   `xml:"myStr"`
+}
+
+// BinBytesBuf is a binary buffer, the XML protocol carries it base64 encoded
+type BinBytesBuf struct {
+	XMLName xml.Name `xml:"BinBytesBuf_PI"`
+	BufLen  int      `xml:"buflen"`
+	Buf     string   `xml:"buf"`
+}
+
+// ModAVUMetadataInp_PI holds the arguments of an imeta like operation
+type ModAVUMetadataInp struct {
+	XMLName xml.Name `xml:"ModAVUMetadataInp_PI"`
+	Arg0    string   `xml:"arg0"`
+	Arg1    string   `xml:"arg1"`
+	Arg2    string   `xml:"arg2"`
+	Arg3    string   `xml:"arg3"`
+	Arg4    string   `xml:"arg4"`
+	Arg5    string   `xml:"arg5"`
+	Arg6    string   `xml:"arg6"`
+	Arg7    string   `xml:"arg7"`
+	Arg8    string   `xml:"arg8"`
+	Arg9    string   `xml:"arg9"`
+}
+
+// modAccessControlInp_PI holds the arguments of an ichmod like operation
+type ModAccessControlInp struct {
+	XMLName       xml.Name `xml:"modAccessControlInp_PI"`
+	RecursiveFlag int      `xml:"recursiveFlag"`
+	AccessLevel   string   `xml:"accessLevel"`
+	UserName      string   `xml:"userName"`
+	Zone          string   `xml:"zone"`
+	Path          string   `xml:"path"`
+}
+
+// generalAdminInp_PI holds the arguments of an iadmin like operation
+type GeneralAdminInp struct {
+	XMLName xml.Name `xml:"generalAdminInp_PI"`
+	Arg0    string   `xml:"arg0"`
+	Arg1    string   `xml:"arg1"`
+	Arg2    string   `xml:"arg2"`
+	Arg3    string   `xml:"arg3"`
+	Arg4    string   `xml:"arg4"`
+	Arg5    string   `xml:"arg5"`
+	Arg6    string   `xml:"arg6"`
+	Arg7    string   `xml:"arg7"`
+	Arg8    string   `xml:"arg8"`
+	Arg9    string   `xml:"arg9"`
+}
+
+// ticketAdminInp_PI holds the arguments of an iticket like operation
+type TicketAdminInp struct {
+	XMLName xml.Name   `xml:"ticketAdminInp_PI"`
+	Arg1    string     `xml:"arg1"`
+	Arg2    string     `xml:"arg2"`
+	Arg3    string     `xml:"arg3"`
+	Arg4    string     `xml:"arg4"`
+	Arg5    string     `xml:"arg5"`
+	Arg6    string     `xml:"arg6"`
+	KeyVals KeyValPair `xml:"KeyValPair_PI"`
+}
+
+// InxIvalPair_PI lists the selected columns and their flags
+type InxIvalPair struct {
+	XMLName xml.Name `xml:"InxIvalPair_PI"`
+	Len     int      `xml:"iiLen"`
+	Inx     []int    `xml:"inx"`
+	Values  []int    `xml:"ivalue"`
+}
+
+// InxValPair_PI lists the conditions on columns
+type InxValPair struct {
+	XMLName xml.Name `xml:"InxValPair_PI"`
+	Len     int      `xml:"isLen"`
+	Inx     []int    `xml:"inx"`
+	Values  []string `xml:"svalue"`
+}
+
+// GenQueryInp_PI is a general query
+type GenQueryInp struct {
+	XMLName           xml.Name    `xml:"GenQueryInp_PI"`
+	MaxRows           int         `xml:"maxRows"`
+	ContinueInx       int         `xml:"continueInx"`
+	PartialStartIndex int         `xml:"partialStartIndex"`
+	Options           int         `xml:"options"`
+	KeyVals           KeyValPair  `xml:"KeyValPair_PI"`
+	Selects           InxIvalPair `xml:"InxIvalPair_PI"`
+	Conditions        InxValPair  `xml:"InxValPair_PI"`
+}
+
+// GenQueryOut_PI holds a page of general query results, by column
+type GenQueryOut struct {
+	XMLName       xml.Name    `xml:"GenQueryOut_PI"`
+	RowCnt        int         `xml:"rowCnt"`
+	AttriCnt      int         `xml:"attriCnt"`
+	ContinueInx   int         `xml:"continueInx"`
+	TotalRowCount int         `xml:"totalRowCount"`
+	Results       []SqlResult `xml:"SqlResult_PI"`
+}
+
+// SqlResult_PI holds the values of one column
+type SqlResult struct {
+	AttriInx int      `xml:"attriInx"`
+	ResLen   int      `xml:"reslen"`
+	Values   []string `xml:"value"`
+}
@@ -0,0 +1,1399 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemTransport is a Transport that keeps collections, data objects, metadata, ACLs, users, groups, zones and
+// resources in memory. It's meant for testing code that uses GoRODS without a live iCAT server:
+//
+//	con, err := gorods.NewConnection(&gorods.ConnectionOptions{
+//		Type:      gorods.UserDefined,
+//		Zone:      "tempZone",
+//		Username:  "rods",
+//		Transport: gorods.NewMemTransport("tempZone", "rods"),
+//	})
+//
+// Every operation is carried out as the connected user, without any permission checks. Connections sharing a
+// MemTransport see the same catalog, and it's safe for concurrent use.
+type MemTransport struct {
+	mu sync.Mutex
+
+	zone string
+	user string
+
+	nextId     int
+	nextHandle int
+
+	colls     map[string]*memColl
+	objs      map[string]*memObj
+	handles   map[int]*memHandle
+	users     map[string]*memUser
+	zones     map[string]map[string]string
+	resources map[string]map[string]string
+	meta      map[string]Metas
+}
+
+type memColl struct {
+	id         string
+	owner      string
+	inherit    bool
+	acl        map[string]string
+	createTime time.Time
+	modifyTime time.Time
+}
+
+type memObj struct {
+	id         string
+	data       []byte
+	mode       int
+	owner      string
+	resource   string
+	checksum   string
+	acl        map[string]string
+	createTime time.Time
+	modifyTime time.Time
+}
+
+type memHandle struct {
+	path   string
+	offset int64
+	write  bool
+}
+
+// memUser holds both users and groups, as the iCAT does. members is only used by groups.
+type memUser struct {
+	id         string
+	name       string
+	zone       string
+	typ        string
+	password   string
+	members    map[string]bool
+	createTime time.Time
+	modifyTime time.Time
+}
+
+// NewMemTransport returns an empty MemTransport for zone. It contains the rodsadmin user, its home and trash
+// collections, the "public" group and the "demoResc" resource.
+func NewMemTransport(zone string, user string) *MemTransport {
+	t := &MemTransport{
+		zone:      zone,
+		user:      user,
+		nextId:    10000,
+		colls:     make(map[string]*memColl),
+		objs:      make(map[string]*memObj),
+		handles:   make(map[int]*memHandle),
+		users:     make(map[string]*memUser),
+		zones:     make(map[string]map[string]string),
+		resources: make(map[string]map[string]string),
+		meta:      make(map[string]Metas),
+	}
+
+	t.addZone(zone, "local", "")
+	t.addResource("demoResc", "unixfilesystem")
+
+	t.users["public"] = t.newUser("public", zone, "rodsgroup")
+	t.users[user] = t.newUser(user, zone, "rodsadmin")
+	t.users["public"].members[user] = true
+
+	for _, p := range []string{"/" + zone, "/" + zone + "/home", "/" + zone + "/trash", "/" + zone + "/trash/home"} {
+		t.colls[p] = t.newColl(user)
+	}
+
+	t.colls["/"+zone+"/home/"+user] = t.newColl(user)
+	t.colls["/"+zone+"/trash/home/"+user] = t.newColl(user)
+
+	return t
+}
+
+// AddZone registers a remote zone, so it's returned by Zones and ZoneInfo.
+func (t *MemTransport) AddZone(name string, conString string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.addZone(name, "remote", conString)
+}
+
+// AddResource registers a resource of type typ (such as "unixfilesystem"), so data objects can be stored on it.
+func (t *MemTransport) AddResource(name string, typ string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.addResource(name, typ)
+}
+
+// AddChildResource makes the resource child a child of the resource parent, as iRODS 4.2 records it: the parent's
+// id is stored in the child's resc_parent attribute.
+func (t *MemTransport) AddChildResource(parent string, child string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.resources[child]["resc_parent"] = t.resources[parent]["resc_id"]
+}
+
+func (t *MemTransport) id() string {
+	t.nextId++
+	return strconv.Itoa(t.nextId)
+}
+
+func memNow() time.Time {
+	return time.Unix(time.Now().Unix(), 0)
+}
+
+func (t *MemTransport) addZone(name string, typ string, conString string) {
+	now := timeToString(memNow())
+
+	t.zones[name] = map[string]string{
+		"zone_id":          t.id(),
+		"zone_name":        name,
+		"zone_type_name":   typ,
+		"zone_conn_string": conString,
+		"r_comment":        "",
+		"create_ts":        now,
+		"modify_ts":        now,
+	}
+}
+
+func (t *MemTransport) addResource(name string, typ string) {
+	now := timeToString(memNow())
+
+	t.resources[name] = map[string]string{
+		"resc_id":         t.id(),
+		"resc_name":       name,
+		"zone_name":       t.zone,
+		"resc_type_name":  typ,
+		"resc_class_name": "cache",
+		"resc_net":        "localhost",
+		"resc_def_path":   "/var/lib/irods/Vault",
+		"resc_context":    "",
+		"resc_children":   "",
+		"resc_parent":     "",
+		"resc_info":       "",
+		"resc_status":     "",
+		"resc_objcount":   "0",
+		"free_space":      "",
+		"free_space_ts":   "",
+		"r_comment":       "",
+		"create_ts":       now,
+		"modify_ts":       now,
+	}
+}
+
+func (t *MemTransport) newUser(name string, zone string, typ string) *memUser {
+	now := memNow()
+
+	return &memUser{
+		id:         t.id(),
+		name:       name,
+		zone:       zone,
+		typ:        typ,
+		members:    make(map[string]bool),
+		createTime: now,
+		modifyTime: now,
+	}
+}
+
+func (t *MemTransport) newColl(owner string) *memColl {
+	now := memNow()
+
+	return &memColl{
+		id:         t.id(),
+		owner:      owner,
+		acl:        map[string]string{owner: "own"},
+		createTime: now,
+		modifyTime: now,
+	}
+}
+
+func memError(status int, format string, args ...interface{}) error {
+	return NewTransportError(status, fmt.Sprintf(format, args...))
+}
+
+func cleanPath(p string) string {
+	if p == "" {
+		return p
+	}
+	return filepath.Clean(p)
+}
+
+// children returns the paths of the collections and data objects directly inside the collection p, sorted
+func (t *MemTransport) children(p string) (colls []string, objs []string) {
+	for c := range t.colls {
+		if c != p && filepath.Dir(c) == p {
+			colls = append(colls, c)
+		}
+	}
+
+	for o := range t.objs {
+		if filepath.Dir(o) == p {
+			objs = append(objs, o)
+		}
+	}
+
+	sort.Strings(colls)
+	sort.Strings(objs)
+
+	return colls, objs
+}
+
+// exists returns an error if p is already used by a collection or data object
+func (t *MemTransport) exists(p string) error {
+	if _, ok := t.colls[p]; ok {
+		return memError(catNameExistsAsCollection, "%v already exists as a collection", p)
+	}
+	if _, ok := t.objs[p]; ok {
+		return memError(catNameExistsAsDataObj, "%v already exists as a data object", p)
+	}
+	return nil
+}
+
+func (t *MemTransport) parent(p string) (*memColl, error) {
+	if col, ok := t.colls[filepath.Dir(p)]; ok {
+		return col, nil
+	}
+	return nil, memError(catUnknownCollection, "parent collection of %v does not exist", p)
+}
+
+// inheritedACL returns the ACL of a new item in parent, which is parent's ACL when inheritance is enabled
+func (t *MemTransport) inheritedACL(parent *memColl) map[string]string {
+	acl := map[string]string{t.user: "own"}
+
+	if parent.inherit {
+		for name, access := range parent.acl {
+			acl[name] = access
+		}
+	}
+
+	return acl
+}
+
+func (t *MemTransport) collEntry(p string, col *memColl) *TransportEntry {
+	return &TransportEntry{
+		Type:       CollectionType,
+		Path:       p,
+		DataId:     col.id,
+		OwnerName:  col.owner,
+		OwnerZone:  t.zone,
+		CreateTime: col.createTime,
+		ModifyTime: col.modifyTime,
+	}
+}
+
+func (t *MemTransport) objEntry(p string, obj *memObj) *TransportEntry {
+	return &TransportEntry{
+		Type:       DataObjType,
+		Path:       p,
+		Size:       int64(len(obj.data)),
+		Mode:       obj.mode,
+		DataId:     obj.id,
+		Checksum:   obj.checksum,
+		OwnerName:  obj.owner,
+		OwnerZone:  t.zone,
+		Resource:   obj.resource,
+		RescHier:   obj.resource,
+		PhyPath:    t.resources[obj.resource]["resc_def_path"] + strings.TrimPrefix(p, "/"+t.zone),
+		ReplNum:    0,
+		ReplStatus: 1,
+		CreateTime: obj.createTime,
+		ModifyTime: obj.modifyTime,
+	}
+}
+
+// Disconnect does nothing, so connections can share a MemTransport.
+func (t *MemTransport) Disconnect() error {
+	return nil
+}
+
+func (t *MemTransport) Ping() error {
+	return nil
+}
+
+func (t *MemTransport) LocalZone() (string, error) {
+	return t.zone, nil
+}
+
+func (t *MemTransport) Stat(path string) (*TransportEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if obj, ok := t.objs[path]; ok {
+		return t.objEntry(path, obj), nil
+	}
+	if col, ok := t.colls[path]; ok {
+		return t.collEntry(path, col), nil
+	}
+
+	return nil, memError(userFileDoesNotExist, "%v does not exist", path)
+}
+
+func (t *MemTransport) DataObj(path string) (*TransportEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if obj, ok := t.objs[path]; ok {
+		return t.objEntry(path, obj), nil
+	}
+
+	return nil, memError(catUnknownFile, "%v", path)
+}
+
+func (t *MemTransport) list(path string) ([]*TransportEntry, []*TransportEntry, error) {
+	path = cleanPath(path)
+
+	if _, ok := t.colls[path]; !ok {
+		return nil, nil, memError(catUnknownCollection, "collection %v does not exist", path)
+	}
+
+	colls, objs := t.children(path)
+
+	colEntries := make([]*TransportEntry, 0, len(colls))
+	for _, c := range colls {
+		colEntries = append(colEntries, t.collEntry(c, t.colls[c]))
+	}
+
+	objEntries := make([]*TransportEntry, 0, len(objs))
+	for _, o := range objs {
+		objEntries = append(objEntries, t.objEntry(o, t.objs[o]))
+	}
+
+	return colEntries, objEntries, nil
+}
+
+// List ignores trimRepls, data objects only have a single replica.
+func (t *MemTransport) List(path string, trimRepls bool) ([]*TransportEntry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	colls, objs, er := t.list(path)
+	if er != nil {
+		return nil, er
+	}
+
+	return append(colls, objs...), nil
+}
+
+// ListPage returns every remaining entry when limit isn't positive.
+func (t *MemTransport) ListPage(path string, trimRepls bool, offset int, limit int) (*TransportPage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	colls, objs, er := t.list(path)
+	if er != nil {
+		return nil, er
+	}
+
+	page := &TransportPage{
+		ColTotal: len(colls),
+		ObjTotal: len(objs),
+	}
+
+	all := append(colls, objs...)
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(all) {
+		offset = len(all)
+	}
+
+	end := len(all)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	for _, entry := range all[offset:end] {
+		if entry.Type == CollectionType {
+			page.Collections = append(page.Collections, entry)
+		} else {
+			page.DataObjs = append(page.DataObjs, entry)
+		}
+	}
+
+	return page, nil
+}
+
+func (t *MemTransport) CreateCollection(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if er := t.exists(path); er != nil {
+		return er
+	}
+
+	parent, er := t.parent(path)
+	if er != nil {
+		return er
+	}
+
+	col := t.newColl(t.user)
+	col.acl = t.inheritedACL(parent)
+	col.inherit = parent.inherit
+
+	t.colls[path] = col
+
+	return nil
+}
+
+// createObj stores data at path, replacing the existing data object if force is set
+func (t *MemTransport) createObj(path string, data []byte, mode int, force bool, resource string) error {
+	path = cleanPath(path)
+
+	if resource == "" {
+		resource = "demoResc"
+	}
+	if _, ok := t.resources[resource]; !ok {
+		return memError(catInvalidResource, "resource %v does not exist", resource)
+	}
+
+	parent, er := t.parent(path)
+	if er != nil {
+		return er
+	}
+
+	if _, ok := t.colls[path]; ok {
+		return memError(catNameExistsAsCollection, "%v already exists as a collection", path)
+	}
+	if _, ok := t.objs[path]; ok && !force {
+		return memError(overwriteWithoutForceFlag, "%v already exists", path)
+	}
+
+	now := memNow()
+
+	t.objs[path] = &memObj{
+		id:         t.id(),
+		data:       data,
+		mode:       mode,
+		owner:      t.user,
+		resource:   resource,
+		acl:        t.inheritedACL(parent),
+		createTime: now,
+		modifyTime: now,
+	}
+
+	return nil
+}
+
+// CreateDataObj ignores size, the data object is empty until written to.
+func (t *MemTransport) CreateDataObj(path string, size int64, mode int, force bool, resource string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.createObj(path, []byte{}, mode, force, resource)
+}
+
+func (t *MemTransport) Put(localPath string, path string, size int64, mode int, force bool, resource string) error {
+	data, er := ioutil.ReadFile(localPath)
+	if er != nil {
+		return memError(-1, "%v", er)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.createObj(path, data, mode, force, resource)
+}
+
+// trashPath returns where path goes when it's removed without force
+func (t *MemTransport) trashPath(path string) string {
+	return "/" + t.zone + "/trash" + strings.TrimPrefix(path, "/"+t.zone)
+}
+
+// mkdirAll creates the collection p and its missing parents
+func (t *MemTransport) mkdirAll(p string) {
+	if _, ok := t.colls[p]; ok || p == "/" {
+		return
+	}
+
+	t.mkdirAll(filepath.Dir(p))
+	t.colls[p] = t.newColl(t.user)
+}
+
+// rename moves everything at and under src to dst, along with its metadata
+func (t *MemTransport) rename(src string, dst string) {
+	move := func(p string) string {
+		if p == src {
+			return dst
+		}
+		if strings.HasPrefix(p, src+"/") {
+			return dst + strings.TrimPrefix(p, src)
+		}
+		return p
+	}
+
+	for p, col := range t.colls {
+		if n := move(p); n != p {
+			delete(t.colls, p)
+			t.colls[n] = col
+			t.moveMeta(CollectionType, p, n)
+		}
+	}
+
+	for p, obj := range t.objs {
+		if n := move(p); n != p {
+			delete(t.objs, p)
+			t.objs[n] = obj
+			t.moveMeta(DataObjType, p, n)
+		}
+	}
+
+	for _, h := range t.handles {
+		h.path = move(h.path)
+	}
+}
+
+// remove deletes everything at and under p, along with its metadata
+func (t *MemTransport) remove(p string) {
+	for c := range t.colls {
+		if c == p || strings.HasPrefix(c, p+"/") {
+			delete(t.colls, c)
+			delete(t.meta, metaKey(CollectionType, c))
+		}
+	}
+
+	for o := range t.objs {
+		if o == p || strings.HasPrefix(o, p+"/") {
+			delete(t.objs, o)
+			delete(t.meta, metaKey(DataObjType, o))
+		}
+	}
+}
+
+func (t *MemTransport) Remove(path string, isCollection bool, recursive bool, force bool, rmTrash bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if isCollection {
+		if _, ok := t.colls[path]; !ok {
+			return memError(catUnknownCollection, "collection %v does not exist", path)
+		}
+
+		if colls, objs := t.children(path); !recursive && len(colls)+len(objs) > 0 {
+			return memError(catCollectionNotEmpty, "collection %v is not empty", path)
+		}
+	} else if _, ok := t.objs[path]; !ok {
+		return memError(catUnknownFile, "data object %v does not exist", path)
+	}
+
+	if force || rmTrash {
+		t.remove(path)
+		return nil
+	}
+
+	trash := t.trashPath(path)
+
+	t.remove(trash)
+	t.mkdirAll(filepath.Dir(trash))
+	t.rename(path, trash)
+
+	return nil
+}
+
+func (t *MemTransport) Move(src string, dst string, isCollection bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	src = cleanPath(src)
+	dst = cleanPath(dst)
+
+	if isCollection {
+		if _, ok := t.colls[src]; !ok {
+			return memError(catUnknownCollection, "collection %v does not exist", src)
+		}
+		if strings.HasPrefix(dst, src+"/") {
+			return memError(-1, "can't move %v into itself", src)
+		}
+	} else if _, ok := t.objs[src]; !ok {
+		return memError(catUnknownFile, "data object %v does not exist", src)
+	}
+
+	if er := t.exists(dst); er != nil {
+		return er
+	}
+	if _, er := t.parent(dst); er != nil {
+		return er
+	}
+
+	t.rename(src, dst)
+
+	return nil
+}
+
+func (t *MemTransport) Copy(src string, dst string, force bool, resource string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	src = cleanPath(src)
+
+	obj, ok := t.objs[src]
+	if !ok {
+		return memError(catUnknownFile, "data object %v does not exist", src)
+	}
+
+	if resource == "" {
+		resource = obj.resource
+	}
+
+	data := make([]byte, len(obj.data))
+	copy(data, obj.data)
+
+	return t.createObj(dst, data, obj.mode, force, resource)
+}
+
+// Checksum returns a sha2 checksum, formatted like the iRODS ones.
+func (t *MemTransport) Checksum(path string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	obj, ok := t.objs[cleanPath(path)]
+	if !ok {
+		return "", memError(catUnknownFile, "data object %v does not exist", path)
+	}
+
+	if obj.checksum == "" {
+		sum := sha256.Sum256(obj.data)
+		obj.checksum = "sha2:" + base64.StdEncoding.EncodeToString(sum[:])
+	}
+
+	return obj.checksum, nil
+}
+
+// Open ignores resource and replNum, data objects only have a single replica.
+func (t *MemTransport) Open(path string, resource string, replNum int, flags int) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if _, ok := t.objs[path]; !ok {
+		return -1, memError(catUnknownFile, "data object %v does not exist", path)
+	}
+
+	t.nextHandle++
+	t.handles[t.nextHandle] = &memHandle{
+		path:  path,
+		write: flags != 0,
+	}
+
+	return t.nextHandle, nil
+}
+
+// handle returns the open handle h along with its data object
+func (t *MemTransport) handle(h int) (*memHandle, *memObj, error) {
+	handle, ok := t.handles[h]
+	if !ok {
+		return nil, nil, memError(sysFileDescOutOfRange, "handle %v is not open", h)
+	}
+
+	obj, ok := t.objs[handle.path]
+	if !ok {
+		return nil, nil, memError(catUnknownFile, "data object %v does not exist", handle.path)
+	}
+
+	return handle, obj, nil
+}
+
+func (t *MemTransport) Read(handle int, length int64) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, obj, er := t.handle(handle)
+	if er != nil {
+		return nil, er
+	}
+
+	size := int64(len(obj.data))
+
+	if h.offset >= size || length <= 0 {
+		return []byte{}, nil
+	}
+
+	end := h.offset + length
+	if end > size {
+		end = size
+	}
+
+	data := make([]byte, end-h.offset)
+	copy(data, obj.data[h.offset:end])
+
+	h.offset = end
+
+	return data, nil
+}
+
+func (t *MemTransport) Write(handle int, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, obj, er := t.handle(handle)
+	if er != nil {
+		return er
+	}
+
+	if !h.write {
+		return memError(-1, "data object %v was opened read only", h.path)
+	}
+
+	end := h.offset + int64(len(data))
+
+	if end > int64(len(obj.data)) {
+		grown := make([]byte, end)
+		copy(grown, obj.data)
+		obj.data = grown
+	}
+
+	copy(obj.data[h.offset:end], data)
+
+	h.offset = end
+	obj.checksum = ""
+	obj.modifyTime = memNow()
+
+	return nil
+}
+
+func (t *MemTransport) Seek(handle int, offset int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, _, er := t.handle(handle)
+	if er != nil {
+		return er
+	}
+
+	if offset < 0 {
+		return memError(-1, "negative offset %v", offset)
+	}
+
+	h.offset = offset
+
+	return nil
+}
+
+func (t *MemTransport) Close(handle int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.handles[handle]; !ok {
+		return memError(sysFileDescOutOfRange, "handle %v is not open", handle)
+	}
+
+	delete(t.handles, handle)
+
+	return nil
+}
+
+// metaKey returns the key of the AVUs of the object of type typ named path. Users and groups share a namespace.
+func metaKey(typ int, path string) string {
+	switch typ {
+	case DataObjType:
+		return "d:" + path
+	case CollectionType:
+		return "C:" + path
+	case ResourceType:
+		return "R:" + path
+	default:
+		return "u:" + path
+	}
+}
+
+func (t *MemTransport) moveMeta(typ int, src string, dst string) {
+	if metas, ok := t.meta[metaKey(typ, src)]; ok {
+		delete(t.meta, metaKey(typ, src))
+		t.meta[metaKey(typ, dst)] = metas
+	}
+}
+
+// metaTarget returns an error if the object of type typ named path doesn't exist
+func (t *MemTransport) metaTarget(typ int, path string) error {
+	switch typ {
+	case DataObjType:
+		if _, ok := t.objs[path]; !ok {
+			return memError(catUnknownFile, "data object %v does not exist", path)
+		}
+	case CollectionType:
+		if _, ok := t.colls[path]; !ok {
+			return memError(catUnknownCollection, "collection %v does not exist", path)
+		}
+	case ResourceType:
+		if _, ok := t.resources[path]; !ok {
+			return memError(catInvalidResource, "resource %v does not exist", path)
+		}
+	default:
+		if _, ok := t.users[path]; !ok {
+			return memError(catInvalidUser, "user %v does not exist", path)
+		}
+	}
+
+	return nil
+}
+
+func (t *MemTransport) Meta(typ int, path string, zone string) (Metas, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	response := make(Metas, 0)
+
+	for _, m := range t.meta[metaKey(typ, cleanPath(path))] {
+		response = append(response, &Meta{Attribute: m.Attribute, Value: m.Value, Units: m.Units})
+	}
+
+	return response, nil
+}
+
+func (t *MemTransport) AddMeta(typ int, path string, m Meta) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if er := t.metaTarget(typ, path); er != nil {
+		return er
+	}
+
+	key := metaKey(typ, path)
+
+	if t.meta[key].MatchOne(&m) != nil {
+		return memError(catalogAlreadyHasItemByThatName, "%v already has AVU %v %v %v", path, m.Attribute, m.Value, m.Units)
+	}
+
+	t.meta[key] = append(t.meta[key], &Meta{Attribute: m.Attribute, Value: m.Value, Units: m.Units})
+
+	return nil
+}
+
+func (t *MemTransport) RemoveMeta(typ int, path string, m Meta) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := metaKey(typ, cleanPath(path))
+
+	for n, am := range t.meta[key] {
+		if am.Attribute == m.Attribute && am.Value == m.Value && am.Units == m.Units {
+			t.meta[key] = append(t.meta[key][:n], t.meta[key][n+1:]...)
+			return nil
+		}
+	}
+
+	return memError(catNoRowsFound, "%v has no AVU %v %v %v", path, m.Attribute, m.Value, m.Units)
+}
+
+func (t *MemTransport) ModMeta(typ int, path string, old Meta, updated Meta) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := metaKey(typ, cleanPath(path))
+
+	am := t.meta[key].MatchOne(&old)
+	if am == nil {
+		return memError(catNoRowsFound, "%v has no AVU %v %v %v", path, old.Attribute, old.Value, old.Units)
+	}
+
+	am.Attribute = updated.Attribute
+	am.Value = updated.Value
+	am.Units = updated.Units
+
+	return nil
+}
+
+// AtomicApplyMetadata applies the operations of the JSON document input to one object all at once, see
+// metaBatcher. When one of them fails none is applied, and the returned JSON document reports its index.
+func (t *MemTransport) AtomicApplyMetadata(input []byte) ([]byte, error) {
+	var inp atomicMetaInput
+	if err := json.Unmarshal(input, &inp); err != nil {
+		return nil, memError(sysInvalidInputParam, "invalid atomic metadata input: %v", err)
+	}
+
+	var typ int
+
+	switch inp.EntityType {
+	case "data_object":
+		typ = DataObjType
+	case "collection":
+		typ = CollectionType
+	case "resource":
+		typ = ResourceType
+	case "user":
+		typ = UserType
+	default:
+		return nil, memError(sysInvalidInputParam, "invalid entity type %v", inp.EntityType)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path := cleanPath(inp.EntityName)
+
+	if er := t.metaTarget(typ, path); er != nil {
+		return nil, er
+	}
+
+	key := metaKey(typ, path)
+	metas := append(Metas{}, t.meta[key]...)
+
+	for i, op := range inp.Operations {
+		m := Meta{Attribute: op.Attribute, Value: op.Value, Units: op.Units}
+
+		status := 0
+		message := ""
+
+		switch op.Operation {
+		case "add":
+			if metas.MatchOne(&m) != nil {
+				status = catalogAlreadyHasItemByThatName
+				message = fmt.Sprintf("%v already has AVU %v %v %v", path, m.Attribute, m.Value, m.Units)
+			} else {
+				metas = append(metas, &m)
+			}
+		case "remove":
+			status = catNoRowsFound
+			message = fmt.Sprintf("%v has no AVU %v %v %v", path, m.Attribute, m.Value, m.Units)
+
+			for n, am := range metas {
+				if am.Attribute == m.Attribute && am.Value == m.Value && am.Units == m.Units {
+					metas = append(metas[:n:n], metas[n+1:]...)
+					status = 0
+					break
+				}
+			}
+		default:
+			status = sysInvalidInputParam
+			message = fmt.Sprintf("invalid operation %v", op.Operation)
+		}
+
+		if status != 0 {
+			index := i
+			output, _ := json.Marshal(atomicMetaOutput{OperationIndex: &index, ErrorMessage: message})
+			return output, memError(status, "operation %v failed", i)
+		}
+	}
+
+	t.meta[key] = metas
+
+	return []byte("{}"), nil
+}
+
+func (t *MemTransport) acl(acl map[string]string) []*TransportACL {
+	names := make([]string, 0, len(acl))
+	for name := range acl {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response := make([]*TransportACL, 0, len(names))
+
+	for _, name := range names {
+		typ := "rodsuser"
+		if usr, ok := t.users[name]; ok {
+			typ = usr.typ
+		}
+
+		response = append(response, &TransportACL{Name: name, Type: typ, Access: acl[name]})
+	}
+
+	return response
+}
+
+func (t *MemTransport) DataObjACL(dataId string, zone string) ([]*TransportACL, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, obj := range t.objs {
+		if obj.id == dataId {
+			return t.acl(obj.acl), nil
+		}
+	}
+
+	return nil, memError(catUnknownFile, "data object with id %v does not exist", dataId)
+}
+
+func (t *MemTransport) CollectionACL(path string, zone string) ([]*TransportACL, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	col, ok := t.colls[cleanPath(path)]
+	if !ok {
+		return nil, memError(catUnknownCollection, "collection %v does not exist", path)
+	}
+
+	return t.acl(col.acl), nil
+}
+
+func setAccess(acl map[string]string, user string, access string) {
+	switch access {
+	case "null":
+		delete(acl, user)
+	case "read":
+		acl[user] = "read object"
+	case "write":
+		acl[user] = "modify object"
+	case "own":
+		acl[user] = "own"
+	}
+}
+
+func (t *MemTransport) Chmod(path string, zone string, user string, access string, recursive bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = cleanPath(path)
+
+	if access != "inherit" && access != "noinherit" {
+		if _, ok := t.users[user]; !ok {
+			return memError(catInvalidUser, "user %v does not exist", user)
+		}
+	}
+
+	if obj, ok := t.objs[path]; ok {
+		setAccess(obj.acl, user, access)
+		return nil
+	}
+
+	if _, ok := t.colls[path]; !ok {
+		return memError(catUnknownFile, "%v does not exist", path)
+	}
+
+	for p, col := range t.colls {
+		if p == path || (recursive && strings.HasPrefix(p, path+"/")) {
+			switch access {
+			case "inherit":
+				col.inherit = true
+			case "noinherit":
+				col.inherit = false
+			default:
+				setAccess(col.acl, user, access)
+			}
+		}
+	}
+
+	if recursive && access != "inherit" && access != "noinherit" {
+		for p, obj := range t.objs {
+			if strings.HasPrefix(p, path+"/") {
+				setAccess(obj.acl, user, access)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *MemTransport) Inheritance(path string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	col, ok := t.colls[cleanPath(path)]
+	if !ok {
+		return false, memError(catUnknownCollection, "collection %v does not exist", path)
+	}
+
+	return col.inherit, nil
+}
+
+func (t *MemTransport) Users() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	response := make([]string, 0)
+	for _, usr := range t.users {
+		if usr.typ != "rodsgroup" {
+			response = append(response, usr.name+"#"+usr.zone)
+		}
+	}
+	sort.Strings(response)
+
+	return response, nil
+}
+
+func (t *MemTransport) Groups() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	response := make([]string, 0)
+	for _, usr := range t.users {
+		if usr.typ == "rodsgroup" {
+			response = append(response, usr.name)
+		}
+	}
+	sort.Strings(response)
+
+	return response, nil
+}
+
+func sortedKeys(m map[string]map[string]string) []string {
+	response := make([]string, 0, len(m))
+	for k := range m {
+		response = append(response, k)
+	}
+	sort.Strings(response)
+
+	return response
+}
+
+func (t *MemTransport) Zones() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return sortedKeys(t.zones), nil
+}
+
+func (t *MemTransport) Resources() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return sortedKeys(t.resources), nil
+}
+
+func (t *MemTransport) UserInfo(name string) (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usr, ok := t.users[name]
+	if !ok {
+		return nil, memError(catInvalidUser, "user %v does not exist", name)
+	}
+
+	return map[string]string{
+		"user_id":        usr.id,
+		"user_name":      usr.name,
+		"user_type_name": usr.typ,
+		"zone_name":      usr.zone,
+		"user_info":      "",
+		"r_comment":      "",
+		"create_ts":      timeToString(usr.createTime),
+		"modify_ts":      timeToString(usr.modifyTime),
+	}, nil
+}
+
+func (t *MemTransport) UserGroups(user string) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.users[user]; !ok {
+		return nil, memError(catInvalidUser, "user %v does not exist", user)
+	}
+
+	response := make([]string, 0)
+	for _, grp := range t.users {
+		if grp.members[user] {
+			response = append(response, grp.name)
+		}
+	}
+	sort.Strings(response)
+
+	return response, nil
+}
+
+// group returns the group name, or an error if it's not a group
+func (t *MemTransport) group(name string) (*memUser, error) {
+	if grp, ok := t.users[name]; ok && grp.typ == "rodsgroup" {
+		return grp, nil
+	}
+	return nil, memError(catInvalidGroup, "group %v does not exist", name)
+}
+
+func (t *MemTransport) GroupMembers(group string) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	grp, er := t.group(group)
+	if er != nil {
+		return nil, er
+	}
+
+	response := make([]string, 0, len(grp.members))
+	for name := range grp.members {
+		response = append(response, name+"#"+t.users[name].zone)
+	}
+	sort.Strings(response)
+
+	return response, nil
+}
+
+// CreateUser also creates the home collection of the user, and adds it to the "public" group.
+func (t *MemTransport) CreateUser(name string, zone string, typ string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.zones[zone]; !ok {
+		return memError(catInvalidZone, "zone %v does not exist", zone)
+	}
+	if _, ok := t.users[name]; ok {
+		return memError(catalogAlreadyHasItemByThatName, "user %v already exists", name)
+	}
+
+	t.users[name] = t.newUser(name, zone, typ)
+
+	if public, ok := t.users["public"]; ok {
+		public.members[name] = true
+	}
+
+	if zone == t.zone {
+		home := "/" + t.zone + "/home/" + name
+		if _, ok := t.colls[home]; !ok {
+			t.colls[home] = t.newColl(name)
+		}
+	}
+
+	return nil
+}
+
+func (t *MemTransport) DeleteUser(name string, zone string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if usr, ok := t.users[name]; !ok || usr.typ == "rodsgroup" {
+		return memError(catInvalidUser, "user %v does not exist", name)
+	}
+
+	delete(t.users, name)
+	delete(t.meta, metaKey(UserType, name))
+
+	for _, grp := range t.users {
+		delete(grp.members, name)
+	}
+
+	return nil
+}
+
+func (t *MemTransport) ChangePassword(user string, newPass string, myPass string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usr, ok := t.users[user]
+	if !ok {
+		return memError(catInvalidUser, "user %v does not exist", user)
+	}
+
+	usr.password = newPass
+	usr.modifyTime = memNow()
+
+	return nil
+}
+
+func (t *MemTransport) CreateGroup(name string, zone string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.users[name]; ok {
+		return memError(catalogAlreadyHasItemByThatName, "group %v already exists", name)
+	}
+
+	t.users[name] = t.newUser(name, t.zone, "rodsgroup")
+
+	return nil
+}
+
+func (t *MemTransport) DeleteGroup(name string, zone string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, er := t.group(name); er != nil {
+		return er
+	}
+
+	delete(t.users, name)
+	delete(t.meta, metaKey(GroupType, name))
+
+	return nil
+}
+
+func (t *MemTransport) AddToGroup(user string, zone string, group string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	grp, er := t.group(group)
+	if er != nil {
+		return er
+	}
+
+	if _, ok := t.users[user]; !ok {
+		return memError(catInvalidUser, "user %v does not exist", user)
+	}
+	if grp.members[user] {
+		return memError(catalogAlreadyHasItemByThatName, "user %v is already a member of %v", user, group)
+	}
+
+	grp.members[user] = true
+
+	return nil
+}
+
+func (t *MemTransport) RemoveFromGroup(user string, zone string, group string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	grp, er := t.group(group)
+	if er != nil {
+		return er
+	}
+
+	if !grp.members[user] {
+		return memError(catInvalidUser, "user %v is not a member of %v", user, group)
+	}
+
+	delete(grp.members, user)
+
+	return nil
+}
+
+func copyInfo(info map[string]string) map[string]string {
+	response := make(map[string]string, len(info))
+	for k, v := range info {
+		response[k] = v
+	}
+
+	return response
+}
+
+func (t *MemTransport) ZoneInfo(name string) (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, ok := t.zones[name]
+	if !ok {
+		return nil, memError(catInvalidZone, "zone %v does not exist", name)
+	}
+
+	return copyInfo(info), nil
+}
+
+func (t *MemTransport) ResourceInfo(name string) (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, ok := t.resources[name]
+	if !ok {
+		return nil, memError(catInvalidResource, "resource %v does not exist", name)
+	}
+
+	response := copyInfo(info)
+
+	count := 0
+	for _, obj := range t.objs {
+		if obj.resource == name {
+			count++
+		}
+	}
+	response["resc_objcount"] = strconv.Itoa(count)
+
+	return response, nil
+}
@@ -0,0 +1,212 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"testing"
+)
+
+// memOptions returns the options of a connection to tempZone as rods, over transport
+func memOptions(transport Transport) *ConnectionOptions {
+	return &ConnectionOptions{
+		Type:      UserDefined,
+		Zone:      "tempZone",
+		Username:  "rods",
+		Transport: transport,
+	}
+}
+
+func memConnection(t *testing.T) *Connection {
+	con, err := NewConnection(memOptions(NewMemTransport("tempZone", "rods")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return con
+}
+
+// memDataObj returns a connection over a new MemTransport, and the data object name it holds in /tempZone/home/rods
+func memDataObj(t *testing.T, name string, data []byte) (*Connection, *DataObj) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.CreateDataObj(DataObjOptions{Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := obj.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	return con, obj
+}
+
+func TestMemTransportCollection(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := home.CreateSubCollection("sub"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := home.CreateSubCollection("sub"); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists creating an existing collection, got %v", err)
+	}
+
+	if _, err := home.CreateDataObj(DataObjOptions{Name: "hello.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := home.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := home.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 2 || all[0].Name() != "sub" || all[1].Name() != "hello.txt" {
+		t.Errorf("Expected collection to contain sub and hello.txt, got %v", all)
+	}
+
+	if _, err := con.Collection(CollectionOptions{Path: "/tempZone/home/nobody"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound opening a missing collection, got %v", err)
+	}
+}
+
+func TestMemTransportDataObj(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.CreateDataObj(DataObjOptions{Name: "data.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := obj.Write([]byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := obj.Read(); err != nil {
+		t.Fatal(err)
+	} else if string(data) != "hello world" {
+		t.Errorf("Expected to read back %q, got %q", "hello world", data)
+	}
+
+	if data, err := obj.ReadBytes(6, 5); err != nil {
+		t.Fatal(err)
+	} else if string(data) != "world" {
+		t.Errorf("Expected ReadBytes to return %q, got %q", "world", data)
+	}
+	obj.Close()
+
+	if chksum, err := obj.Chksum(); err != nil {
+		t.Fatal(err)
+	} else if chksum != "sha2:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=" {
+		t.Errorf("Unexpected checksum %v", chksum)
+	}
+
+	if err := obj.Rename("renamed.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := con.DataObject("/tempZone/home/rods/renamed.txt"); err != nil {
+		t.Error(err)
+	}
+
+	if err := obj.Rm(false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := con.transport.Stat("/tempZone/trash/home/rods/renamed.txt"); err != nil {
+		t.Errorf("Expected removed data object in the trash, got %v", err)
+	}
+}
+
+func TestMemTransportMeta(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.CreateDataObj(DataObjOptions{Name: "meta.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := obj.AddMeta(Meta{Attribute: "color", Value: "blue", Units: "rgb"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := obj.AddMeta(Meta{Attribute: "color", Value: "blue", Units: "rgb"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Expected ErrAlreadyExists adding a duplicate AVU, got %v", err)
+	}
+
+	if metas, err := obj.Attribute("color"); err != nil {
+		t.Fatal(err)
+	} else if len(metas) != 1 || metas[0].Value != "blue" || metas[0].Units != "rgb" {
+		t.Errorf("Expected a single color AVU, got %v", metas)
+	}
+}
+
+func TestMemTransportUsers(t *testing.T) {
+	con := memConnection(t)
+
+	usr, err := con.CreateUser("alice", UserType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grp, err := con.CreateGroup("lab")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := grp.AddUser(usr); err != nil {
+		t.Fatal(err)
+	}
+
+	if usrs, err := grp.Users(); err != nil {
+		t.Fatal(err)
+	} else if len(usrs) != 1 || usrs[0].Name() != "alice" {
+		t.Errorf("Expected lab to contain alice, got %v", usrs)
+	}
+
+	if _, err := con.Collection(CollectionOptions{Path: "/tempZone/home/alice"}); err != nil {
+		t.Errorf("Expected home collection for alice, got %v", err)
+	}
+}
+
+func TestMemTransportNotSupported(t *testing.T) {
+	con := memConnection(t)
+
+	if _, err := con.IQuestSQL("select count(*) from r_data_main"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported running SQL, got %v", err)
+	}
+}
+
+func TestGetCconNotSupported(t *testing.T) {
+	con := memConnection(t)
+
+	if ccon, err := con.getCcon(); ccon != nil || !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported checking out the C API handle, got %v", err)
+	}
+}
@@ -3,14 +3,10 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
-	"unsafe"
 )
 
 // Meta structs contain information about a single iRODS metadata attribute-value-units (AVU) triple
@@ -60,23 +56,19 @@ type MetaCollection struct {
 	Con   *Connection
 }
 
-func newMetaCollection(obj MetaObj) (*MetaCollection, error) {
+func newMetaCollection(ctx context.Context, obj MetaObj) (*MetaCollection, error) {
 
 	result := new(MetaCollection)
 	result.Obj = obj
 	result.Con = obj.Con()
 
-	if err := result.init(); err != nil {
+	if err := result.initContext(ctx); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
-func (m *Meta) getTypeRodsString() string {
-	return GetShortTypeString(m.Parent.Obj.Type())
-}
-
 // SetValue will modify metadata AVU value only
 func (m *Meta) SetValue(value string) (*Meta, error) {
 	return m.Set(value, m.Units)
@@ -99,32 +91,17 @@ func (m *Meta) Rename(attributeName string) (*Meta, error) {
 
 // Delete deletes the current Meta struct from iRODS object
 func (m *Meta) Delete() (*MetaCollection, error) {
+	return m.deleteContext(context.Background())
+}
 
-	mT := C.CString(m.getTypeRodsString())
-	path := C.CString(m.Parent.Obj.Path())
-	oa := C.CString(m.Attribute)
-	ov := C.CString(m.Value)
-	ou := C.CString(m.Units)
-
-	defer C.free(unsafe.Pointer(mT))
-	defer C.free(unsafe.Pointer(path))
-	defer C.free(unsafe.Pointer(oa))
-	defer C.free(unsafe.Pointer(ov))
-	defer C.free(unsafe.Pointer(ou))
-
-	var err *C.char
-
-	ccon := m.Parent.Con.GetCcon()
-
-	if status := C.gorods_rm_meta(mT, path, oa, ov, ou, ccon, &err); status < 0 {
-		m.Parent.Con.ReturnCcon(ccon)
+// deleteContext is like Delete, but gives up waiting for a session once ctx is done
+func (m *Meta) deleteContext(ctx context.Context) (*MetaCollection, error) {
 
-		return m.Parent, newError(Fatal, status, fmt.Sprintf("iRODS rm Meta Failed: %v, %v", m.Parent.Obj.Path(), C.GoString(err)))
+	if er := m.Parent.Con.transportContext(ctx).RemoveMeta(m.Parent.Obj.Type(), m.Parent.Obj.Path(), *m); er != nil {
+		return m.Parent, transportError(er, fmt.Sprintf("iRODS rm Meta Failed: %v", m.Parent.Obj.Path())).withPath(m.Parent.Obj.Path())
 	}
 
-	m.Parent.Con.ReturnCcon(ccon)
-
-	m.Parent.Refresh()
+	m.Parent.refreshContext(ctx)
 
 	return m.Parent, nil
 }
@@ -133,35 +110,16 @@ func (m *Meta) Delete() (*MetaCollection, error) {
 func (m *Meta) SetAll(attributeName string, value string, units string) (newMeta *Meta, e error) {
 
 	if attributeName != m.Attribute || value != m.Value || units != m.Units {
-		mT := C.CString(m.getTypeRodsString())
-		path := C.CString(m.Parent.Obj.Path())
-		oa := C.CString(m.Attribute)
-		ov := C.CString(m.Value)
-		ou := C.CString(m.Units)
-		na := C.CString(attributeName)
-		nv := C.CString(value)
-		nu := C.CString(units)
-
-		defer C.free(unsafe.Pointer(mT))
-		defer C.free(unsafe.Pointer(path))
-		defer C.free(unsafe.Pointer(oa))
-		defer C.free(unsafe.Pointer(ov))
-		defer C.free(unsafe.Pointer(ou))
-		defer C.free(unsafe.Pointer(na))
-		defer C.free(unsafe.Pointer(nv))
-		defer C.free(unsafe.Pointer(nu))
-
-		var err *C.char
-
-		ccon := m.Parent.Con.GetCcon()
-
-		if status := C.gorods_mod_meta(mT, path, oa, ov, ou, na, nv, nu, ccon, &err); status < 0 {
-			m.Parent.Con.ReturnCcon(ccon)
-			e = newError(Fatal, status, fmt.Sprintf("iRODS Set Meta Failed: %v, %v", m.Parent.Obj.Path(), C.GoString(err)))
-			return
+		updated := Meta{
+			Attribute: attributeName,
+			Value:     value,
+			Units:     units,
 		}
 
-		m.Parent.Con.ReturnCcon(ccon)
+		if er := m.Parent.Con.transport.ModMeta(m.Parent.Obj.Type(), m.Parent.Obj.Path(), *m, updated); er != nil {
+			e = transportError(er, fmt.Sprintf("iRODS Set Meta Failed: %v", m.Parent.Obj.Path())).withPath(m.Parent.Obj.Path())
+			return
+		}
 
 		m.Attribute = attributeName
 		m.Value = value
@@ -175,9 +133,13 @@ func (m *Meta) SetAll(attributeName string, value string, units string) (newMeta
 }
 
 func (mc *MetaCollection) init() error {
+	return mc.initContext(context.Background())
+}
+
+func (mc *MetaCollection) initContext(ctx context.Context) error {
 	// If MetaCollection hasn't been opened, do it!
 	if len(mc.Metas) < 1 {
-		if err := mc.ReadMeta(); err != nil {
+		if err := mc.readMetaContext(ctx); err != nil {
 			return err
 		}
 	}
@@ -188,106 +150,56 @@ func (mc *MetaCollection) init() error {
 // Refresh clears existing metadata triples and grabs updated copy from iCAT server.
 // It's an alias of ReadMeta()
 func (mc *MetaCollection) Refresh() error {
-	return mc.ReadMeta()
+	return mc.refreshContext(context.Background())
+}
+
+// refreshContext is like Refresh, but gives up waiting for a session once ctx is done
+func (mc *MetaCollection) refreshContext(ctx context.Context) error {
+	return mc.readMetaContext(ctx)
 }
 
 // ReadMeta clears existing metadata triples and grabs updated copy from iCAT server.
 func (mc *MetaCollection) ReadMeta() error {
-	var (
-		err        *C.char
-		metaResult C.goRodsMetaResult_t
-	)
-
-	mc.Metas = make(Metas, 0)
+	return mc.readMetaContext(context.Background())
+}
 
-	name := C.CString(mc.Obj.Name())
+// readMetaContext is like ReadMeta, but gives up waiting for a session once ctx is done
+func (mc *MetaCollection) readMetaContext(ctx context.Context) error {
+	var zone string
 
-	defer C.free(unsafe.Pointer(name))
+	mc.Metas = make(Metas, 0)
 
 	switch mc.Obj.Type() {
-	case DataObjType:
-
-		ccon := mc.Con.GetCcon()
-		defer mc.Con.ReturnCcon(ccon)
-
-		cwdGo := (mc.Obj.(*DataObj)).Col().Path()
-		cwd := C.CString(cwdGo)
-
-		defer C.free(unsafe.Pointer(cwd))
-
-		if status := C.gorods_meta_dataobj(name, cwd, &metaResult, ccon, &err); status != 0 {
-			if status == C.CAT_NO_ROWS_FOUND {
-				return nil
-			} else {
-				return newError(Fatal, status, fmt.Sprintf("iRODS Get Meta Failed: %v, %v, %v", cwdGo, C.GoString(err), status))
-			}
-		}
-	case CollectionType:
-
-		ccon := mc.Con.GetCcon()
-		defer mc.Con.ReturnCcon(ccon)
-
-		cwdGo := filepath.Dir(mc.Obj.Path())
-		cwd := C.CString(cwdGo)
-
-		defer C.free(unsafe.Pointer(cwd))
-
-		if status := C.gorods_meta_collection(name, cwd, &metaResult, ccon, &err); status != 0 {
-			if status == C.CAT_NO_ROWS_FOUND {
-				return nil
-			} else {
-				return newError(Fatal, status, fmt.Sprintf("iRODS Get Meta Failed: %v, %v, %v", cwdGo, C.GoString(err), status))
-			}
-		}
-	case ResourceType:
+	case DataObjType, CollectionType:
+		zone = PathZone(mc.Obj.Path())
 
-	case ResourceGroupType:
+	case ResourceType, ResourceGroupType:
+		return nil
 
 	case UserType, GroupType, AdminType, GroupAdminType:
 
-		gZone, zErr := mc.Con.LocalZone()
+		gZone, zErr := mc.Con.localZoneContext(ctx)
 		if zErr != nil {
 			return zErr
 		}
 
-		ccon := mc.Con.GetCcon()
-		defer mc.Con.ReturnCcon(ccon)
-
-		zone := C.CString(gZone.Name())
-		name := C.CString(mc.Obj.Name())
-		defer C.free(unsafe.Pointer(name))
-		defer C.free(unsafe.Pointer(zone))
-
-		if status := C.gorods_meta_user(name, zone, &metaResult, ccon, &err); status != 0 {
-			if status == C.CAT_NO_ROWS_FOUND {
-				return nil
-			} else {
-				return newError(Fatal, status, fmt.Sprintf("iRODS Get Meta Failed: %v, %v, %v", C.GoString(name), C.GoString(err), status))
-			}
-		}
+		zone = gZone.Name()
 
 	default:
 		return newError(Fatal, -1, "unrecognized meta type constant")
 	}
 
-	size := int(metaResult.size)
-
-	slice := (*[1 << 30]C.goRodsMeta_t)(unsafe.Pointer(metaResult.metaArr))[:size:size]
-
-	for _, meta := range slice {
-
-		m := new(Meta)
+	metas, er := mc.Con.transportContext(ctx).Meta(mc.Obj.Type(), mc.Obj.Path(), zone)
+	if er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Get Meta Failed: %v", mc.Obj.Path())).withPath(mc.Obj.Path())
+	}
 
-		m.Attribute = C.GoString(meta.name)
-		m.Value = C.GoString(meta.value)
-		m.Units = C.GoString(meta.units)
+	for _, m := range metas {
 		m.Parent = mc
 
 		mc.Metas = append(mc.Metas, m)
 	}
 
-	C.freeGoRodsMetaResult(&metaResult)
-
 	return nil
 }
 
@@ -331,7 +243,7 @@ func (mc *MetaCollection) First(attr string) (*Meta, error) {
 		}
 	}
 
-	return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Get Meta Failed, no match"))
+	return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Get Meta Failed, no match")).wrap(ErrNotFound)
 }
 
 // Get returns a Meta struct slice (since attributes can share the same name in iRODS), matching by their Attribute field. Similar to Attribute() function of other types
@@ -349,7 +261,7 @@ func (mc *MetaCollection) Get(attr string) (Metas, error) {
 	}
 
 	if len(result) == 0 {
-		return result, newError(Fatal, -1, fmt.Sprintf("iRODS Get Meta Failed, no match"))
+		return result, newError(Fatal, -1, fmt.Sprintf("iRODS Get Meta Failed, no match")).wrap(ErrNotFound)
 	}
 
 	return result, nil
@@ -379,7 +291,12 @@ func (mc *MetaCollection) Each(iterator func(*Meta)) error {
 
 // Delete deletes the meta AVU triple from the data object, identified by it's Attribute field
 func (mc *MetaCollection) Delete(attr string) (err error) {
-	if err = mc.init(); err != nil {
+	return mc.deleteContext(context.Background(), attr)
+}
+
+// deleteContext is like Delete, but gives up waiting for a session once ctx is done
+func (mc *MetaCollection) deleteContext(ctx context.Context, attr string) (err error) {
+	if err = mc.initContext(ctx); err != nil {
 		return
 	}
 
@@ -390,7 +307,7 @@ func (mc *MetaCollection) Delete(attr string) (err error) {
 	}
 
 	for _, m := range meta {
-		if _, err = m.Delete(); err != nil {
+		if _, err = m.deleteContext(ctx); err != nil {
 			return
 		}
 	}
@@ -400,7 +317,12 @@ func (mc *MetaCollection) Delete(attr string) (err error) {
 
 // Add creates a new meta AVU triple, returns pointer to the created Meta struct
 func (mc *MetaCollection) Add(m Meta) (*Meta, error) {
-	if er := mc.init(); er != nil {
+	return mc.addContext(context.Background(), m)
+}
+
+// addContext is like Add, but gives up waiting for a session once ctx is done
+func (mc *MetaCollection) addContext(ctx context.Context, m Meta) (*Meta, error) {
+	if er := mc.initContext(ctx); er != nil {
 		return nil, er
 	}
 
@@ -408,7 +330,7 @@ func (mc *MetaCollection) Add(m Meta) (*Meta, error) {
 		if len(existingMeta) > 0 {
 			for _, am := range existingMeta {
 				if m.Value == am.Value {
-					return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Add Meta Failed: Attribute + Value already exists"))
+					return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Add Meta Failed: Attribute + Value already exists")).wrap(ErrAlreadyExists)
 				}
 			}
 		}
@@ -417,30 +339,11 @@ func (mc *MetaCollection) Add(m Meta) (*Meta, error) {
 	if m.Attribute != "" && m.Value != "" {
 		m.Parent = mc
 
-		mT := C.CString(m.getTypeRodsString())
-		path := C.CString(m.Parent.Obj.Path())
-		na := C.CString(m.Attribute)
-		nv := C.CString(m.Value)
-		nu := C.CString(m.Units)
-
-		defer C.free(unsafe.Pointer(mT))
-		defer C.free(unsafe.Pointer(path))
-		defer C.free(unsafe.Pointer(na))
-		defer C.free(unsafe.Pointer(nv))
-		defer C.free(unsafe.Pointer(nu))
-
-		var err *C.char
-
-		ccon := m.Parent.Con.GetCcon()
-
-		if status := C.gorods_add_meta(mT, path, na, nv, nu, ccon, &err); status < 0 {
-			m.Parent.Con.ReturnCcon(ccon)
-			return nil, newError(Fatal, status, fmt.Sprintf("iRODS Add Meta Failed: %v, %v", m.Parent.Obj.Path(), C.GoString(err)))
+		if er := m.Parent.Con.transportContext(ctx).AddMeta(m.Parent.Obj.Type(), m.Parent.Obj.Path(), m); er != nil {
+			return nil, transportError(er, fmt.Sprintf("iRODS Add Meta Failed: %v", m.Parent.Obj.Path())).withPath(m.Parent.Obj.Path())
 		}
 
-		m.Parent.Con.ReturnCcon(ccon)
-
-		m.Parent.Refresh()
+		m.Parent.refreshContext(ctx)
 
 	} else {
 		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Add Meta Failed: Please specify Attribute and Value fields"))
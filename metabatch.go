@@ -0,0 +1,543 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// MetaOpType is the kind of change made by a MetaOp
+type MetaOpType string
+
+// Metadata changes of a MetaBatch
+const (
+	// MetaAdd adds the AVU, like imeta add
+	MetaAdd MetaOpType = "add"
+	// MetaSet replaces the AVUs with the same attribute by the AVU, like imeta set
+	MetaSet MetaOpType = "set"
+	// MetaRemove removes the AVU, or every AVU with the attribute when Value is empty, like imeta rm
+	MetaRemove MetaOpType = "remove"
+)
+
+// MetaOp is a single metadata change of a MetaBatch. The object is identified by Type (DataObjType, CollectionType,
+// ResourceType, or one of the user and group types) and Path, which is the name of users, groups and resources.
+// Obj is optional, the metadata it caches is cleared once the change is applied.
+type MetaOp struct {
+	Op   MetaOpType
+	Type int
+	Path string
+	Meta Meta
+	Obj  MetaObj
+}
+
+// validate checks that the operation has the fields it needs
+func (op MetaOp) validate() error {
+	if op.Path == "" {
+		return newError(Fatal, -1, "iRODS Apply Meta Failed: Path is required")
+	}
+
+	if metaEntityType(op.Type) == "" {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v: Unsupported object type %v", op.Path, op.Type))
+	}
+
+	switch op.Op {
+	case MetaAdd, MetaSet:
+		if op.Meta.Attribute == "" || op.Meta.Value == "" {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v: Please specify Attribute and Value fields", op.Path))
+		}
+	case MetaRemove:
+		if op.Meta.Attribute == "" {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v: Please specify the Attribute field", op.Path))
+		}
+	default:
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v: Unknown operation %q", op.Path, op.Op))
+	}
+
+	return nil
+}
+
+// MetaBatch is a list of metadata changes, possibly on many objects, applied with Connection.ApplyMeta
+type MetaBatch struct {
+	Ops []MetaOp
+}
+
+// NewMetaBatch returns an empty *MetaBatch
+func NewMetaBatch() *MetaBatch {
+	return new(MetaBatch)
+}
+
+// Add appends an operation adding m to obj
+func (batch *MetaBatch) Add(obj MetaObj, m Meta) *MetaBatch {
+	return batch.append(MetaAdd, obj, m)
+}
+
+// Set appends an operation replacing the AVUs of obj with the attribute of m by m
+func (batch *MetaBatch) Set(obj MetaObj, m Meta) *MetaBatch {
+	return batch.append(MetaSet, obj, m)
+}
+
+// Remove appends an operation removing m from obj, or every AVU with its attribute when m.Value is empty
+func (batch *MetaBatch) Remove(obj MetaObj, m Meta) *MetaBatch {
+	return batch.append(MetaRemove, obj, m)
+}
+
+func (batch *MetaBatch) append(op MetaOpType, obj MetaObj, m Meta) *MetaBatch {
+	batch.Ops = append(batch.Ops, MetaOp{Op: op, Type: obj.Type(), Path: obj.Path(), Meta: m, Obj: obj})
+	return batch
+}
+
+// MetaBatchOptions control how Connection.ApplyMeta applies a MetaBatch.
+// When Atomic is set, the changes to each object are applied in a single transaction using the atomic metadata
+// API of iRODS 4.2.8 and later: if one fails, none of the changes to that object are. Otherwise each change is a
+// separate request, and a failure doesn't stop the following changes. Atomicity is per object, changes to other
+// objects are applied independently either way. The API only adds and removes AVUs, so MetaSet and MetaRemove
+// without a Value are turned into removals of the AVUs the object has when the batch reaches it: that read isn't
+// part of the transaction, and AVUs another client adds in between are left in place.
+// AdminMode lets a rodsadmin change the metadata of objects they have no permission on, it's only used when Atomic
+// is set.
+// Concurrency is the number of objects updated at once, it defaults to the number of sessions of the connection
+// (see ConnectionOptions.Sessions). The changes to a single object are always applied in order.
+// If Context is set, objects which haven't been started when it's cancelled are skipped, with ctx.Err() reported.
+type MetaBatchOptions struct {
+	Atomic      bool
+	AdminMode   bool
+	Concurrency int
+	Context     context.Context
+}
+
+// MetaOpResult is the outcome of a single operation of a MetaBatch
+type MetaOpResult struct {
+	Op      MetaOp
+	Applied bool
+	Err     error
+}
+
+// MetaBatchReport holds the result of every operation of a MetaBatch, in the order of MetaBatch.Ops
+type MetaBatchReport struct {
+	Results []*MetaOpResult
+}
+
+// Failed returns the results of the operations which weren't applied
+func (rep *MetaBatchReport) Failed() []*MetaOpResult {
+	failed := make([]*MetaOpResult, 0)
+
+	for _, res := range rep.Results {
+		if !res.Applied {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+// metaEntity is an object whose metadata is changed by a MetaBatch, with the results of its operations in order
+type metaEntity struct {
+	typ     int
+	path    string
+	results []*MetaOpResult
+}
+
+// rawMetaOp is an AVU added or removed, which a MetaOp is made of
+type rawMetaOp struct {
+	add  bool
+	meta Meta
+}
+
+// ApplyMeta applies the metadata changes of batch, see MetaBatchOptions. The metadata of each object is read once
+// beforehand if set or attribute removal operations need it, and isn't read again afterwards. Failures are
+// recorded in the returned report instead of aborting the batch. The returned error is non-nil if anything failed.
+func (con *Connection) ApplyMeta(batch *MetaBatch, opts MetaBatchOptions) (*MetaBatchReport, error) {
+	report := &MetaBatchReport{Results: make([]*MetaOpResult, len(batch.Ops))}
+
+	entities := make([]*metaEntity, 0)
+	byKey := make(map[string]*metaEntity)
+
+	for i, op := range batch.Ops {
+		res := &MetaOpResult{Op: op}
+		report.Results[i] = res
+
+		if res.Err = op.validate(); res.Err != nil && !opts.Atomic {
+			continue
+		}
+
+		key := metaEntityType(op.Type) + ":" + op.Path
+
+		ent, ok := byKey[key]
+		if !ok {
+			ent = &metaEntity{typ: op.Type, path: op.Path}
+			byKey[key] = ent
+			entities = append(entities, ent)
+		}
+
+		ent.results = append(ent.results, res)
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	apply := func(ent *metaEntity) {
+		if er := ctx.Err(); er != nil {
+			for _, res := range ent.results {
+				res.Err = er
+			}
+			return
+		}
+
+		if opts.Atomic {
+			con.applyMetaAtomic(ent, opts.AdminMode)
+		} else {
+			con.applyMetaPipelined(ent)
+		}
+
+		for _, res := range ent.results {
+			if res.Applied && res.Op.Obj != nil {
+				resetMeta(res.Op.Obj)
+			}
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = con.Sessions()
+	}
+	if concurrency > len(entities) {
+		concurrency = len(entities)
+	}
+
+	if concurrency <= 1 {
+		for _, ent := range entities {
+			apply(ent)
+		}
+	} else {
+		var wg sync.WaitGroup
+
+		work := make(chan *metaEntity)
+
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for ent := range work {
+					apply(ent)
+				}
+			}()
+		}
+
+		for _, ent := range entities {
+			work <- ent
+		}
+
+		close(work)
+		wg.Wait()
+	}
+
+	if failed := report.Failed(); len(failed) > 0 {
+		return report, newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v of %v operations failed, first error: %v", len(failed), len(report.Results), failed[0].Err))
+	}
+
+	return report, nil
+}
+
+// applyMetaPipelined applies the operations of ent one after the other, a failure doesn't stop the next ones
+func (con *Connection) applyMetaPipelined(ent *metaEntity) {
+	var current Metas
+
+	for _, res := range ent.results {
+		if current == nil && needsCurrentMeta(res.Op) {
+			metas, err := con.currentMeta(ent)
+			if err != nil {
+				res.Err = err
+				continue
+			}
+			current = metas
+		}
+
+		raw, next := expandMetaOp(current, res.Op)
+
+		for _, r := range raw {
+			var er error
+			if r.add {
+				er = con.transport.AddMeta(ent.typ, ent.path, r.meta)
+			} else {
+				er = con.transport.RemoveMeta(ent.typ, ent.path, r.meta)
+			}
+
+			if er != nil {
+				res.Err = transportError(er, fmt.Sprintf("iRODS Apply Meta Failed: %v", ent.path)).withPath(ent.path)
+				break
+			}
+		}
+
+		if res.Err != nil {
+			// Part of the operation may have been applied, read the metadata again if it's needed
+			current = nil
+			continue
+		}
+
+		current = next
+		res.Applied = true
+	}
+}
+
+// applyMetaAtomic applies the operations of ent in a single transaction, as a rodsadmin when adminMode is set.
+// The metadata set and attribute removals are expanded from is read beforehand, outside of the transaction.
+func (con *Connection) applyMetaAtomic(ent *metaEntity, adminMode bool) {
+	fail := func(err error) {
+		for _, res := range ent.results {
+			if res.Err == nil {
+				res.Err = err
+			}
+		}
+	}
+
+	for i, res := range ent.results {
+		if res.Err != nil {
+			fail(newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v: Not applied, operation %v of the object is invalid", ent.path, i)).withPath(ent.path))
+			return
+		}
+	}
+
+	var current Metas
+
+	for _, res := range ent.results {
+		if needsCurrentMeta(res.Op) {
+			metas, err := con.currentMeta(ent)
+			if err != nil {
+				fail(err)
+				return
+			}
+			current = metas
+			break
+		}
+	}
+
+	input := atomicMetaInput{
+		AdminMode:  adminMode,
+		EntityName: ent.path,
+		EntityType: metaEntityType(ent.typ),
+		Operations: make([]atomicMetaOperation, 0),
+	}
+
+	// origin maps each raw operation to the result of the operation it's part of
+	origin := make([]*MetaOpResult, 0)
+
+	for _, res := range ent.results {
+		var raw []rawMetaOp
+		raw, current = expandMetaOp(current, res.Op)
+
+		for _, r := range raw {
+			operation := "remove"
+			if r.add {
+				operation = "add"
+			}
+
+			input.Operations = append(input.Operations, atomicMetaOperation{
+				Operation: operation,
+				Attribute: r.meta.Attribute,
+				Value:     r.meta.Value,
+				Units:     r.meta.Units,
+			})
+			origin = append(origin, res)
+		}
+	}
+
+	if len(input.Operations) > 0 {
+		index, err := con.atomicApplyMeta(input)
+		if err != nil {
+			if index >= 0 && index < len(origin) {
+				origin[index].Err = err
+				fail(newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v: Not applied, %v %v failed", ent.path, origin[index].Op.Op, origin[index].Op.Meta.Attribute)).withPath(ent.path))
+			} else {
+				fail(err)
+			}
+			return
+		}
+	}
+
+	for _, res := range ent.results {
+		res.Applied = true
+	}
+}
+
+// currentMeta reads the metadata of ent
+func (con *Connection) currentMeta(ent *metaEntity) (Metas, error) {
+	zone := ""
+
+	switch ent.typ {
+	case DataObjType, CollectionType:
+		zone = PathZone(ent.path)
+	case UserType, GroupType, AdminType, GroupAdminType:
+		z, err := con.LocalZone()
+		if err != nil {
+			return nil, err
+		}
+		zone = z.Name()
+	}
+
+	metas, er := con.transport.Meta(ent.typ, ent.path, zone)
+	if er != nil {
+		return nil, transportError(er, fmt.Sprintf("iRODS Get Meta Failed: %v", ent.path)).withPath(ent.path)
+	}
+
+	if metas == nil {
+		metas = Metas{}
+	}
+
+	return metas, nil
+}
+
+// needsCurrentMeta returns true if the AVUs op adds or removes depend on the current metadata
+func needsCurrentMeta(op MetaOp) bool {
+	return op.Op == MetaSet || (op.Op == MetaRemove && op.Meta.Value == "")
+}
+
+// expandMetaOp returns the AVUs added and removed by op, given the current metadata of the object, and the metadata
+// once they're applied. current is only needed by set and attribute removal operations, when it's nil (unknown) the
+// returned metadata is nil too.
+func expandMetaOp(current Metas, op MetaOp) ([]rawMetaOp, Metas) {
+	m := Meta{Attribute: op.Meta.Attribute, Value: op.Meta.Value, Units: op.Meta.Units}
+
+	raw := make([]rawMetaOp, 0)
+	next := make(Metas, 0, len(current)+1)
+
+	switch op.Op {
+	case MetaAdd:
+		raw = append(raw, rawMetaOp{add: true, meta: m})
+		next = append(append(next, current...), &m)
+
+	case MetaSet:
+		present := false
+
+		for _, cm := range current {
+			if cm.Attribute != m.Attribute {
+				next = append(next, cm)
+			} else if cm.Value == m.Value && cm.Units == m.Units && !present {
+				present = true
+				next = append(next, cm)
+			} else {
+				raw = append(raw, rawMetaOp{meta: Meta{Attribute: cm.Attribute, Value: cm.Value, Units: cm.Units}})
+			}
+		}
+
+		if !present {
+			raw = append(raw, rawMetaOp{add: true, meta: m})
+			next = append(next, &m)
+		}
+
+	case MetaRemove:
+		if m.Value != "" {
+			raw = append(raw, rawMetaOp{meta: m})
+		}
+
+		removed := false
+
+		for _, cm := range current {
+			if cm.Attribute == m.Attribute && m.Value == "" {
+				raw = append(raw, rawMetaOp{meta: Meta{Attribute: cm.Attribute, Value: cm.Value, Units: cm.Units}})
+			} else if cm.Attribute == m.Attribute && cm.Value == m.Value && cm.Units == m.Units && !removed {
+				removed = true
+			} else {
+				next = append(next, cm)
+			}
+		}
+	}
+
+	if current == nil {
+		return raw, nil
+	}
+
+	return raw, next
+}
+
+// metaEntityType returns the entity type of the atomic metadata API for the object type typ, or an empty string
+func metaEntityType(typ int) string {
+	switch typ {
+	case DataObjType:
+		return "data_object"
+	case CollectionType:
+		return "collection"
+	case ResourceType:
+		return "resource"
+	case UserType, GroupType, AdminType, GroupAdminType:
+		return "user"
+	}
+
+	return ""
+}
+
+// resetMeta clears the metadata cached by obj, it's read again the next time it's needed
+func resetMeta(obj MetaObj) {
+	switch o := obj.(type) {
+	case *DataObj:
+		o.metaCol = nil
+	case *Collection:
+		o.metaCol = nil
+	case *User:
+		o.metaCol = nil
+	case *Group:
+		o.metaCol = nil
+	}
+}
+
+// atomicMetaInput is the JSON document sent to the atomic metadata API
+type atomicMetaInput struct {
+	AdminMode  bool                  `json:"admin_mode"`
+	EntityName string                `json:"entity_name"`
+	EntityType string                `json:"entity_type"`
+	Operations []atomicMetaOperation `json:"operations"`
+}
+
+type atomicMetaOperation struct {
+	Operation string `json:"operation"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+	Units     string `json:"units,omitempty"`
+}
+
+// atomicMetaOutput describes the operation that made the atomic metadata API fail
+type atomicMetaOutput struct {
+	OperationIndex *int   `json:"operation_index"`
+	ErrorMessage   string `json:"error_message"`
+}
+
+// atomicApplyMeta sends input to the atomic metadata API. When it fails, the index of the operation that failed is
+// returned if the server reported it, or -1.
+func (con *Connection) atomicApplyMeta(input atomicMetaInput) (int, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return -1, newError(Fatal, -1, fmt.Sprintf("iRODS Apply Meta Failed: %v", err)).wrap(err)
+	}
+
+	mb, ok := con.innerTransport(context.Background()).(metaBatcher)
+	if !ok {
+		return -1, con.notSupported("Apply Meta")
+	}
+
+	output, cause := mb.AtomicApplyMetadata(data)
+	if cause == nil {
+		return -1, nil
+	}
+
+	index := -1
+	message := fmt.Sprintf("iRODS Apply Meta Failed: %v", input.EntityName)
+
+	var out atomicMetaOutput
+	if json.Unmarshal(output, &out) == nil {
+		if out.OperationIndex != nil {
+			index = *out.OperationIndex
+		}
+		if out.ErrorMessage != "" {
+			message += ": " + out.ErrorMessage
+		}
+	}
+
+	return index, transportError(cause, message).withPath(input.EntityName)
+}
@@ -0,0 +1,51 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+// AtomicApplyMetadata applies metadata operations to one object all at once, see metaBatcher
+func (t *cTransport) AtomicApplyMetadata(input []byte) ([]byte, error) {
+	ccon, er := t.getCcon()
+	if er != nil {
+		return nil, er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	return cAtomicApplyMetadata(ccon, input)
+}
+
+// cAtomicApplyMetadata sends the JSON input to the atomic metadata API, and returns the JSON output of the server
+func cAtomicApplyMetadata(ccon *rcComm, input []byte) ([]byte, error) {
+	var (
+		errMsg  *C.char
+		cOutput *C.char
+		output  []byte
+	)
+
+	cInput := C.CString(string(input))
+	defer C.free(unsafe.Pointer(cInput))
+
+	status := C.gorods_atomic_apply_metadata_operations(cInput, &cOutput, ccon, &errMsg)
+
+	if cOutput != nil {
+		output = []byte(C.GoString(cOutput))
+		C.free(unsafe.Pointer(cOutput))
+	}
+
+	if status < 0 {
+		return output, NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return output, nil
+}
@@ -0,0 +1,263 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestExpandMetaOp(t *testing.T) {
+	current := Metas{
+		{Attribute: "color", Value: "red"},
+		{Attribute: "color", Value: "blue"},
+		{Attribute: "size", Value: "10", Units: "GB"},
+	}
+
+	raw, next := expandMetaOp(current, MetaOp{Op: MetaSet, Meta: Meta{Attribute: "color", Value: "blue"}})
+	if len(raw) != 1 || raw[0].add || raw[0].meta.Value != "red" {
+		t.Errorf("Expected set to only remove color red, got %+v", raw)
+	}
+	if len(next) != 2 {
+		t.Errorf("Expected color blue and size to remain, got %v", next)
+	}
+
+	raw, next = expandMetaOp(next, MetaOp{Op: MetaRemove, Meta: Meta{Attribute: "size"}})
+	if len(raw) != 1 || raw[0].add || raw[0].meta.Units != "GB" || len(next) != 1 {
+		t.Errorf("Expected the size AVU to be removed, got %+v, %v", raw, next)
+	}
+
+	raw, next = expandMetaOp(next, MetaOp{Op: MetaSet, Meta: Meta{Attribute: "shape", Value: "round"}})
+	if len(raw) != 1 || !raw[0].add || len(next) != 2 {
+		t.Errorf("Expected shape to be added, got %+v, %v", raw, next)
+	}
+
+	if raw, next := expandMetaOp(nil, MetaOp{Op: MetaAdd, Meta: Meta{Attribute: "a", Value: "b"}}); len(raw) != 1 || next != nil {
+		t.Errorf("Expected an add without known metadata, got %+v, %v", raw, next)
+	}
+}
+
+func TestApplyMeta(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := home.CreateDataObj(DataObjOptions{Name: "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+
+	b, err := home.CreateDataObj(DataObjOptions{Name: "b.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+
+	if _, err := a.AddMeta(Meta{Attribute: "status", Value: "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := NewMetaBatch().
+		Set(a, Meta{Attribute: "status", Value: "done"}).
+		Add(a, Meta{Attribute: "sample", Value: "s1"}).
+		Add(b, Meta{Attribute: "sample", Value: "s2"}).
+		Add(b, Meta{Attribute: "sample", Value: "s2"}).
+		Remove(home, Meta{Attribute: "missing", Value: "x"}).
+		Add(home, Meta{Attribute: "project"})
+
+	report, err := con.ApplyMeta(batch, MetaBatchOptions{Concurrency: 2})
+	if err == nil {
+		t.Error("Expected an error for the failed operations")
+	}
+
+	for i, expected := range []bool{true, true, true, false, false, false} {
+		if res := report.Results[i]; res.Applied != expected || (res.Err == nil) != expected {
+			t.Errorf("Expected operation %v to be applied: %v, got %v (%v)", i, expected, res.Applied, res.Err)
+		}
+	}
+
+	if !errors.Is(report.Results[3].Err, ErrAlreadyExists) {
+		t.Errorf("Expected the duplicate AVU to be reported, got %v", report.Results[3].Err)
+	}
+
+	if len(report.Failed()) != 3 {
+		t.Errorf("Expected 3 failed operations, got %v", report.Failed())
+	}
+
+	metas, err := a.Meta()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := metas.Get("status"); err != nil || len(status) != 1 || status[0].Value != "done" {
+		t.Errorf("Expected status to be set to done, got %v (%v)", status, err)
+	}
+
+	if sample, err := metas.First("sample"); err != nil || sample.Value != "s1" {
+		t.Errorf("Expected sample s1, got %v (%v)", sample, err)
+	}
+}
+
+func TestApplyMetaAtomic(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := con.ApplyMeta(NewMetaBatch().
+		Add(home, Meta{Attribute: "project", Value: "p1"}).
+		Add(home, Meta{Attribute: "owner"}), MetaBatchOptions{Atomic: true})
+	if err == nil {
+		t.Error("Expected an error for the invalid operation")
+	}
+
+	if res := report.Results[0]; res.Applied || res.Err == nil {
+		t.Errorf("Expected the valid operation not to be applied, got %v", res.Err)
+	}
+
+	if metas, err := con.transport.Meta(CollectionType, home.Path(), ""); err != nil || len(metas) != 0 {
+		t.Errorf("Expected no metadata to be added, got %v (%v)", metas, err)
+	}
+
+	if _, err := con.ApplyMeta(NewMetaBatch().
+		Add(home, Meta{Attribute: "project", Value: "p1"}).
+		Add(home, Meta{Attribute: "status", Value: "new"}), MetaBatchOptions{Atomic: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = con.ApplyMeta(NewMetaBatch().
+		Set(home, Meta{Attribute: "status", Value: "done"}).
+		Add(home, Meta{Attribute: "owner", Value: "alice"}).
+		Add(home, Meta{Attribute: "project", Value: "p1"}), MetaBatchOptions{Atomic: true})
+	if err == nil {
+		t.Error("Expected an error for the duplicate AVU")
+	}
+
+	for i, res := range report.Results {
+		if res.Applied || res.Err == nil {
+			t.Errorf("Expected operation %v not to be applied, got %v", i, res.Err)
+		}
+	}
+
+	if res := report.Results[2]; !errors.Is(res.Err, ErrAlreadyExists) || !strings.Contains(res.Err.Error(), "already has AVU project p1") {
+		t.Errorf("Expected the duplicate AVU to be reported, got %v", res.Err)
+	}
+
+	metas, err := con.transport.Meta(CollectionType, home.Path(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metas) != 2 || metas.MatchOne(&Meta{Attribute: "status", Value: "new"}) == nil {
+		t.Errorf("Expected the metadata to be left unchanged, got %v", metas)
+	}
+
+	if _, err := con.ApplyMeta(NewMetaBatch().
+		Set(home, Meta{Attribute: "status", Value: "done"}).
+		Remove(home, Meta{Attribute: "project"}), MetaBatchOptions{Atomic: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if metas, err := con.transport.Meta(CollectionType, home.Path(), ""); err != nil || len(metas) != 1 || metas[0].Value != "done" {
+		t.Errorf("Expected only status done to be left, got %v (%v)", metas, err)
+	}
+}
+
+func TestApplyMetaAtomicNotSupported(t *testing.T) {
+	con := memConnection(t)
+	con.transport = struct{ Transport }{con.transport}
+
+	home := &Collection{path: "/tempZone/home/rods", typ: CollectionType, con: con}
+
+	report, _ := con.ApplyMeta(NewMetaBatch().Add(home, Meta{Attribute: "project", Value: "p1"}), MetaBatchOptions{Atomic: true})
+	if res := report.Results[0]; res.Applied || !errors.Is(res.Err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", res.Err)
+	}
+}
+
+func TestApplyMetaAtomicPayload(t *testing.T) {
+	path := "/tempZone/home/rods"
+
+	var input atomicMetaInput
+
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		inp, err := expectQuery(s, map[int]string{irodsproto.ColCollName: "= '" + path + "'"})
+		if err != nil {
+			return err
+		}
+		if err := s.ReplyRows(inp, [][]string{{"status", "new", ""}, {"tmp", "a", ""}, {"other", "x", ""}}); err != nil {
+			return err
+		}
+
+		var buf irodsproto.BinBytesBuf
+		if _, err := s.Expect(irodsproto.AtomicApplyMetadataOperationsAN, &buf); err != nil {
+			return err
+		}
+
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(buf.Buf))
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(string(data), "\x00") {
+			return fmt.Errorf("Expected a NUL terminated document, got %q", data)
+		}
+		if err := json.Unmarshal([]byte(strings.TrimRight(string(data), "\x00")), &input); err != nil {
+			return err
+		}
+
+		out := base64.StdEncoding.EncodeToString([]byte("{}\x00"))
+		return s.Reply(irodsproto.BinBytesBuf{BufLen: 3, Buf: out}, nil, 0)
+	})
+
+	con := protoConnection(transport)
+	home := &Collection{path: path, typ: CollectionType, con: con}
+
+	report, err := con.ApplyMeta(NewMetaBatch().
+		Set(home, Meta{Attribute: "status", Value: "done"}).
+		Add(home, Meta{Attribute: "weight", Value: "10", Units: "kg"}).
+		Remove(home, Meta{Attribute: "tmp"}), MetaBatchOptions{Atomic: true, AdminMode: true})
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, res := range report.Results {
+		if !res.Applied {
+			t.Errorf("Expected %v %v to be applied, got %v", res.Op.Op, res.Op.Meta.Attribute, res.Err)
+		}
+	}
+
+	expected := atomicMetaInput{
+		AdminMode:  true,
+		EntityName: path,
+		EntityType: "collection",
+		Operations: []atomicMetaOperation{
+			{Operation: "remove", Attribute: "status", Value: "new"},
+			{Operation: "add", Attribute: "status", Value: "done"},
+			{Operation: "add", Attribute: "weight", Value: "10", Units: "kg"},
+			{Operation: "remove", Attribute: "tmp", Value: "a"},
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Expected the payload %+v, got %+v", expected, input)
+	}
+}
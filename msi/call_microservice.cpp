@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 #include "rsModAVUMetadata.hpp"
 #include "irods_re_structs.hpp"
 #include "irods_ms_plugin.hpp"
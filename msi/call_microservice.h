@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 #include "msParam.h"
 
 typedef struct msiCallInfo_t {
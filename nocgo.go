@@ -0,0 +1,156 @@
+//go:build !cgo
+// +build !cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Without cgo, GoRODS can't use the iRODS C API: connections need ConnectionOptions.PureGo or a custom Transport.
+// The functions below stand in for the ones calling the C API. Connections never have C sessions in these builds
+// (see Connection.requireCcon), so apart from connectC they aren't reached.
+
+// rcComm isn't zero-sized, so that separate handles compare unequal like the C pointers do
+type (
+	rcComm      struct{ _ byte }
+	genQueryInp struct{}
+)
+
+// errNoCgo returns the error of the operations that need the iRODS C API
+func errNoCgo() error {
+	return NewTransportError(-1, "GoRODS was built without cgo").wrap(ErrNotSupported)
+}
+
+func (con *Connection) connectC() error {
+	return newError(Fatal, -1, "iRODS Connect Failed: GoRODS was built without cgo, set ConnectionOptions.PureGo or Transport").wrap(ErrNotSupported)
+}
+
+func cDisconnect(ccon *rcComm) error {
+	return errNoCgo()
+}
+
+func cSetTicket(ccon *rcComm, t string) error {
+	return errNoCgo()
+}
+
+func cRegPhysObj(ccon *rcComm, opts RegOptions, collection bool, resourceName string) error {
+	return errNoCgo()
+}
+
+func cIRODSEnv() (string, string, int, string, error) {
+	return "", "", 0, "", errNoCgo()
+}
+
+func cSetThreads(ccon *rcComm, num int) {
+}
+
+func cThreads(ccon *rcComm) int {
+	return 0
+}
+
+func cSpecificQuery(ccon *rcComm, specificQuery string, queryArgs []string, zone string) ([][]string, error) {
+	return nil, errNoCgo()
+}
+
+func cIQuest(ccon *rcComm, query string, upperCase bool, zone string) ([]map[string]string, error) {
+	return nil, errNoCgo()
+}
+
+func cQueryMetaCollections(ccon *rcComm, qString string) ([]string, error) {
+	return nil, errNoCgo()
+}
+
+func cQueryMetaDataObjs(ccon *rcComm, qString string) ([]string, error) {
+	return nil, errNoCgo()
+}
+
+func cGenQueryOpen(ccon *rcComm, q *Query, zone string, pageSize int) (*genQueryInp, error) {
+	return nil, errNoCgo()
+}
+
+func cGenQueryNext(ccon *rcComm, cInp *genQueryInp) ([][]string, bool, error) {
+	return nil, false, errNoCgo()
+}
+
+func cGenQueryClose(ccon *rcComm, cInp *genQueryInp) error {
+	return errNoCgo()
+}
+
+func cGenQueryFree(cInp *genQueryInp) {}
+
+func cTrimRepls(ccon *rcComm, path string, resource string, opts TrimOptions) error {
+	return errNoCgo()
+}
+
+func cPhyMove(ccon *rcComm, path string, source string, destination string) error {
+	return errNoCgo()
+}
+
+func cReplicate(ccon *rcComm, path string, resource string, backup bool, opts DataObjOptions) error {
+	return errNoCgo()
+}
+
+// cFree does nothing, ByteArr.Ptr is never set without cgo
+func cFree(ptr unsafe.Pointer) {
+}
+
+func cDisplayMemInfo() {
+}
+
+// errorName returns the symbolic name of an iRODS status code, followed by the description of the errno it carries
+func errorName(status int) string {
+	name, ok := errorNames[status/1000*1000]
+	if !ok {
+		name = "UNKNOWN_ERROR"
+	}
+
+	if sub := -(status % 1000); sub > 0 {
+		return name + " " + syscall.Errno(sub).Error()
+	}
+
+	return name + " "
+}
+
+// errorNames are the symbolic names of the status codes in errcodes.go, the iRODS C API knows about all of them
+var errorNames = map[int]string{
+	sysSockOpenErr:                  "SYS_SOCK_OPEN_ERR",
+	sysHeaderReadLenErr:             "SYS_HEADER_READ_LEN_ERR",
+	sysHeaderWriteLenErr:            "SYS_HEADER_WRITE_LEN_ERR",
+	sysExceedConnectCnt:             "SYS_EXCEED_CONNECT_CNT",
+	sysUnmatchedAPINum:              "SYS_UNMATCHED_API_NUM",
+	sysNoAPIPriv:                    "SYS_NO_API_PRIV",
+	sysFileDescOutOfRange:           "SYS_FILE_DESC_OUT_OF_RANGE",
+	sysNotSupported:                 "SYS_NOT_SUPPORTED",
+	sysSockReadTimedout:             "SYS_SOCK_READ_TIMEDOUT",
+	sysSockReadErr:                  "SYS_SOCK_READ_ERR",
+	sysSockConnectErr:               "SYS_SOCK_CONNECT_ERR",
+	userSockOpenErr:                 "USER_SOCK_OPEN_ERR",
+	userSockConnectErr:              "USER_SOCK_CONNECT_ERR",
+	userFileDoesNotExist:            "USER_FILE_DOES_NOT_EXIST",
+	overwriteWithoutForceFlag:       "OVERWRITE_WITHOUT_FORCE_FLAG",
+	userChksumMismatch:              "USER_CHKSUM_MISMATCH",
+	userSockConnectTimedout:         "USER_SOCK_CONNECT_TIMEDOUT",
+	objPathDoesNotExist:             "OBJ_PATH_DOES_NOT_EXIST",
+	catNoRowsFound:                  "CAT_NO_ROWS_FOUND",
+	catalogAlreadyHasItemByThatName: "CATALOG_ALREADY_HAS_ITEM_BY_THAT_NAME",
+	catUnknownCollection:            "CAT_UNKNOWN_COLLECTION",
+	catUnknownFile:                  "CAT_UNKNOWN_FILE",
+	catNoAccessPermission:           "CAT_NO_ACCESS_PERMISSION",
+	catSuccessButWithNoInfo:         "CAT_SUCCESS_BUT_WITH_NO_INFO",
+	catCollectionNotEmpty:           "CAT_COLLECTION_NOT_EMPTY",
+	catInvalidAuthentication:        "CAT_INVALID_AUTHENTICATION",
+	catInvalidUser:                  "CAT_INVALID_USER",
+	catInvalidZone:                  "CAT_INVALID_ZONE",
+	catInvalidGroup:                 "CAT_INVALID_GROUP",
+	catInsufficientPrivilegeLevel:   "CAT_INSUFFICIENT_PRIVILEGE_LEVEL",
+	catInvalidResource:              "CAT_INVALID_RESOURCE",
+	catNameExistsAsCollection:       "CAT_NAME_EXISTS_AS_COLLECTION",
+	catNameExistsAsDataObj:          "CAT_NAME_EXISTS_AS_DATAOBJ",
+	catPasswordExpired:              "CAT_PASSWORD_EXPIRED",
+	pamAuthPasswordFailed:           "PAM_AUTH_PASSWORD_FAILED",
+}
@@ -0,0 +1,277 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolOptions control the size and lifetime of the authenticated connections kept by a *ConnectionPool.
+// MaxIdle is the number of idle connections kept around for reuse, MaxOpen is the maximum number
+// of connections checked out and idle at any one time (0 means unlimited). Idle connections older than
+// IdleTimeout are disconnected instead of being reused, and a background goroutine disconnects them as they
+// expire until the pool is closed. When SkipHealthCheck is false, every idle connection is pinged on checkout,
+// and transparently replaced with a new one if the iRODS agent went away.
+type PoolOptions struct {
+	MaxIdle         int
+	MaxOpen         int
+	IdleTimeout     time.Duration
+	SkipHealthCheck bool
+}
+
+// DefaultPoolOptions are used by gorods.New()
+var DefaultPoolOptions = PoolOptions{
+	MaxIdle:     2,
+	MaxOpen:     0,
+	IdleTimeout: 5 * time.Minute,
+}
+
+// PoolStats describes the current state of a *ConnectionPool
+type PoolStats struct {
+	Open int
+	Idle int
+}
+
+type idleConnection struct {
+	con      *Connection
+	returned time.Time
+}
+
+// ConnectionPool holds a bounded set of authenticated *Connection structs, so that
+// callers don't pay for a full iRODS connect and login on every operation.
+type ConnectionPool struct {
+	Options     *ConnectionOptions
+	PoolOptions PoolOptions
+
+	mu     sync.Mutex
+	idle   []*idleConnection
+	open   int
+	closed bool
+	slots  chan struct{}
+	done   chan struct{}
+}
+
+// NewConnectionPool creates an empty *ConnectionPool. Connections are opened lazily by Get(). When
+// PoolOptions.IdleTimeout is set, call Close to stop the goroutine disconnecting expired idle connections.
+func NewConnectionPool(opts *ConnectionOptions, poolOpts PoolOptions) *ConnectionPool {
+	pool := new(ConnectionPool)
+
+	pool.Options = opts
+	pool.PoolOptions = poolOpts
+	pool.done = make(chan struct{})
+
+	if poolOpts.MaxOpen > 0 {
+		pool.slots = make(chan struct{}, poolOpts.MaxOpen)
+	}
+
+	if poolOpts.IdleTimeout > 0 {
+		go pool.reap(poolOpts.IdleTimeout)
+	}
+
+	return pool
+}
+
+// Get checks out a connection from the pool. An idle connection is reused when one is available,
+// otherwise a new one is created. If PoolOptions.MaxOpen connections are already checked out,
+// Get blocks until one is returned with Put.
+func (pool *ConnectionPool) Get() (*Connection, error) {
+	return pool.GetContext(context.Background())
+}
+
+// GetContext is like Get, but gives up waiting for a free slot, or for the health check of an idle connection,
+// when ctx is cancelled or its deadline passes.
+func (pool *ConnectionPool) GetContext(ctx context.Context) (*Connection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if pool.slots != nil {
+		select {
+		case pool.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for {
+		pool.mu.Lock()
+
+		if pool.closed {
+			pool.mu.Unlock()
+			pool.releaseSlot()
+			return nil, newError(Fatal, -1, "iRODS Connection Pool Failed: pool is closed")
+		}
+
+		n := len(pool.idle)
+		if n == 0 {
+			pool.open++
+			pool.mu.Unlock()
+			break
+		}
+
+		ic := pool.idle[n-1]
+		pool.idle = pool.idle[:n-1]
+		pool.mu.Unlock()
+
+		if pool.PoolOptions.IdleTimeout > 0 && time.Since(ic.returned) > pool.PoolOptions.IdleTimeout {
+			pool.discard(ic.con)
+			continue
+		}
+
+		if !pool.PoolOptions.SkipHealthCheck {
+			if err := ic.con.pingContext(ctx); err != nil {
+				if cErr := ctx.Err(); cErr != nil {
+					// The connection wasn't checked, keep it for the next Get
+					pool.Put(ic.con)
+					return nil, cErr
+				}
+
+				// The agent went away, drop the connection and try the next idle one, or open a new one
+				pool.discard(ic.con)
+				continue
+			}
+		}
+
+		return ic.con, nil
+	}
+
+	con, err := NewConnection(pool.Options)
+	if err != nil {
+		pool.mu.Lock()
+		pool.open--
+		pool.mu.Unlock()
+		pool.releaseSlot()
+
+		return nil, newError(Fatal, -1, fmt.Sprintf("Can't open new connection: %v", err)).wrap(err)
+	}
+
+	return con, nil
+}
+
+// Put returns a connection obtained from Get back to the pool. Data objects and collections
+// opened on the connection are closed first. If the pool already holds PoolOptions.MaxIdle idle
+// connections, the connection is disconnected instead.
+func (pool *ConnectionPool) Put(con *Connection) error {
+	defer pool.releaseSlot()
+
+	closeErr := con.closeOpenedObjs()
+
+	pool.mu.Lock()
+
+	if closeErr != nil || pool.closed || !con.Connected || len(pool.idle) >= pool.PoolOptions.MaxIdle {
+		pool.open--
+		pool.mu.Unlock()
+
+		if er := con.Disconnect(); er != nil {
+			return er
+		}
+
+		return closeErr
+	}
+
+	pool.idle = append(pool.idle, &idleConnection{con, time.Now()})
+	pool.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns the number of open (checked out and idle) and idle connections in the pool.
+func (pool *ConnectionPool) Stats() PoolStats {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return PoolStats{
+		Open: pool.open,
+		Idle: len(pool.idle),
+	}
+}
+
+// Close disconnects all idle connections. Connections still checked out are disconnected when they are returned with Put.
+func (pool *ConnectionPool) Close() error {
+	pool.mu.Lock()
+
+	if !pool.closed {
+		close(pool.done)
+	}
+
+	pool.closed = true
+	idle := pool.idle
+	pool.idle = nil
+	pool.open -= len(idle)
+
+	pool.mu.Unlock()
+
+	var err error
+
+	for _, ic := range idle {
+		if er := ic.con.Disconnect(); er != nil && err == nil {
+			err = er
+		}
+	}
+
+	return err
+}
+
+// reap disconnects the idle connections older than timeout as they expire, until the pool is closed
+func (pool *ConnectionPool) reap(timeout time.Duration) {
+	interval := timeout / 2
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pool.done:
+			return
+		case <-ticker.C:
+		}
+
+		pool.mu.Lock()
+
+		expired := make([]*idleConnection, 0)
+		kept := pool.idle[:0]
+
+		for _, ic := range pool.idle {
+			if time.Since(ic.returned) > timeout {
+				expired = append(expired, ic)
+			} else {
+				kept = append(kept, ic)
+			}
+		}
+
+		for i := len(kept); i < len(pool.idle); i++ {
+			pool.idle[i] = nil
+		}
+
+		pool.idle = kept
+		pool.open -= len(expired)
+
+		pool.mu.Unlock()
+
+		for _, ic := range expired {
+			ic.con.Disconnect()
+		}
+	}
+}
+
+// discard disconnects a connection that was taken from the idle list and will not be reused
+func (pool *ConnectionPool) discard(con *Connection) {
+	con.Disconnect()
+
+	pool.mu.Lock()
+	pool.open--
+	pool.mu.Unlock()
+}
+
+func (pool *ConnectionPool) releaseSlot() {
+	if pool.slots != nil {
+		<-pool.slots
+	}
+}
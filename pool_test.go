@@ -0,0 +1,262 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memPool returns a *ConnectionPool of connections sharing a MemTransport
+func memPool(poolOpts PoolOptions) *ConnectionPool {
+	return NewConnectionPool(memOptions(NewMemTransport("tempZone", "rods")), poolOpts)
+}
+
+func TestPoolGetPut(t *testing.T) {
+	pool := memPool(PoolOptions{MaxIdle: 1})
+	defer pool.Close()
+
+	con, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := pool.Stats(); stats.Open != 1 || stats.Idle != 0 {
+		t.Errorf("Expected 1 open connection and none idle, got %+v", stats)
+	}
+
+	if err := pool.Put(con); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := pool.Stats(); stats.Open != 1 || stats.Idle != 1 {
+		t.Errorf("Expected the connection to be idle, got %+v", stats)
+	}
+
+	again, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != con || !again.Connected {
+		t.Error("Expected the idle connection to be reused")
+	}
+
+	if err := pool.Put(again); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	pool := memPool(PoolOptions{MaxIdle: 1, IdleTimeout: time.Nanosecond})
+	defer pool.Close()
+
+	con, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Put(con)
+	time.Sleep(time.Millisecond)
+
+	fresh, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fresh == con || con.Connected {
+		t.Error("Expected the expired connection to be disconnected and replaced")
+	}
+
+	if stats := pool.Stats(); stats.Open != 1 || stats.Idle != 0 {
+		t.Errorf("Expected 1 open connection, got %+v", stats)
+	}
+
+	pool.Put(fresh)
+}
+
+func TestPoolIdleReaper(t *testing.T) {
+	pool := memPool(PoolOptions{MaxIdle: 2, IdleTimeout: 10 * time.Millisecond})
+	defer pool.Close()
+
+	con, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Put(con)
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Idle != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := pool.Stats(); stats.Open != 0 || stats.Idle != 0 || con.Connected {
+		t.Errorf("Expected the expired connection to be disconnected without a Get, got %+v", stats)
+	}
+}
+
+// pingTransport fails the next failPings pings
+type pingTransport struct {
+	Transport
+	failPings int
+}
+
+func (t *pingTransport) Ping() error {
+	if t.failPings > 0 {
+		t.failPings--
+		return NewTransportError(sysSockReadErr, "connection reset by peer")
+	}
+
+	return t.Transport.Ping()
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	transport := &pingTransport{Transport: NewMemTransport("tempZone", "rods")}
+
+	pool := NewConnectionPool(memOptions(transport), PoolOptions{MaxIdle: 1})
+	defer pool.Close()
+
+	con, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Put(con)
+	transport.failPings = 1
+
+	fresh, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fresh == con || con.Connected || !fresh.Connected {
+		t.Error("Expected the connection failing the health check to be disconnected and replaced")
+	}
+
+	if stats := pool.Stats(); stats.Open != 1 || stats.Idle != 0 {
+		t.Errorf("Expected 1 open connection, got %+v", stats)
+	}
+
+	pool.Put(fresh)
+}
+
+func TestPoolMaxIdle(t *testing.T) {
+	pool := memPool(PoolOptions{MaxIdle: 1})
+	defer pool.Close()
+
+	first, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatal("Expected two distinct connections")
+	}
+
+	pool.Put(first)
+	pool.Put(second)
+
+	if stats := pool.Stats(); stats.Open != 1 || stats.Idle != 1 {
+		t.Errorf("Expected 1 idle connection, got %+v", stats)
+	}
+
+	if !first.Connected || second.Connected {
+		t.Error("Expected the connection returned past MaxIdle to be disconnected")
+	}
+}
+
+func TestPoolMaxOpen(t *testing.T) {
+	pool := memPool(PoolOptions{MaxIdle: 1, MaxOpen: 1})
+	defer pool.Close()
+
+	con, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := pool.GetContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the exhausted pool to block until the deadline, got %v", err)
+	}
+
+	got := make(chan *Connection)
+
+	go func() {
+		waiter, err := pool.Get()
+		if err != nil {
+			t.Error(err)
+		}
+		got <- waiter
+	}()
+
+	select {
+	case <-got:
+		t.Fatal("Expected Get to block while the pool is exhausted")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	pool.Put(con)
+
+	select {
+	case waiter := <-got:
+		if waiter != con {
+			t.Error("Expected the returned connection to be handed to the waiting Get")
+		}
+		pool.Put(waiter)
+	case <-time.After(time.Second):
+		t.Fatal("Expected Get to return once a connection is put back")
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	pool := memPool(PoolOptions{MaxIdle: 2})
+
+	idle, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	busy, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Put(idle)
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if idle.Connected || !busy.Connected {
+		t.Error("Expected Close to only disconnect the idle connection")
+	}
+
+	if stats := pool.Stats(); stats.Open != 1 || stats.Idle != 0 {
+		t.Errorf("Expected the checked out connection to stay open, got %+v", stats)
+	}
+
+	if _, err := pool.Get(); err == nil {
+		t.Error("Expected Get to fail on a closed pool")
+	}
+
+	pool.Put(busy)
+
+	if busy.Connected {
+		t.Error("Expected the connection returned to a closed pool to be disconnected")
+	}
+
+	if stats := pool.Stats(); stats.Open != 0 || stats.Idle != 0 {
+		t.Errorf("Expected no open connection, got %+v", stats)
+	}
+}
@@ -0,0 +1,964 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+// protoChunkSize is the largest read or write sent in a single protocol request
+const protoChunkSize = 4 * 1024 * 1024
+
+// protoTransport is the Transport used when ConnectionOptions.PureGo is set. It speaks the iRODS protocol
+// through the irodsproto package instead of the iRODS C API, and builds listings, metadata and ACLs from GenQueries.
+type protoTransport struct {
+	conn *irodsproto.Conn
+	zone string
+}
+
+// newProtoTransport connects and authenticates to the server described by opts. With EnvironmentDefined options,
+// the settings of irods_environment.json are loaded into opts first.
+func newProtoTransport(opts *ConnectionOptions) (*protoTransport, error) {
+	if opts.Type == EnvironmentDefined {
+		env, err := LoadEnvironment()
+		if err != nil {
+			return nil, err
+		}
+
+		merged := env.Merge(*opts)
+		merged.Type = EnvironmentDefined
+		*opts = merged
+	}
+
+	if opts.AuthType == PAMAuth {
+		return nil, newError(Fatal, -1, "PureGo connections only support native (password) authentication").wrap(ErrNotSupported)
+	}
+
+	conn, err := irodsproto.Dial(irodsproto.Config{
+		Host:     opts.Host,
+		Port:     opts.Port,
+		User:     opts.Username,
+		Zone:     opts.Zone,
+		Password: opts.Password,
+		SSL:      opts.sslConfig(),
+		Timeout:  opts.Timeout,
+	})
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	return &protoTransport{conn: conn, zone: opts.Zone}, nil
+}
+
+// protoError converts errors returned by irodsproto, keeping the iRODS status code
+func protoError(err error) error {
+	if rErr, ok := err.(*irodsproto.Error); ok {
+		return NewTransportError(rErr.Code, rErr.Message)
+	}
+
+	return NewTransportError(-1, err.Error()).wrap(err)
+}
+
+// query runs a GenQuery built from cols and conds, returning no rows isn't an error
+func (t *protoTransport) query(cols []int, conds ...irodsproto.Condition) ([][]string, error) {
+	return t.pathQuery("", cols, conds...)
+}
+
+// pathQuery runs a query about path, in the zone path belongs to so paths of federated zones can be queried
+func (t *protoTransport) pathQuery(path string, cols []int, conds ...irodsproto.Condition) ([][]string, error) {
+	rows, err := t.conn.Query(&irodsproto.GenQuery{
+		Select: irodsproto.Columns(cols...),
+		Where:  conds,
+		Zone:   PathZone(path),
+	})
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	return rows, nil
+}
+
+// column returns the first value of every row
+func column(rows [][]string) []string {
+	response := make([]string, len(rows))
+
+	for i, row := range rows {
+		response[i] = row[0]
+	}
+
+	return response
+}
+
+func (t *protoTransport) Disconnect() error {
+	if err := t.conn.Close(); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Ping() error {
+	if _, err := t.conn.ServerInfo(); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) LocalZone() (string, error) {
+	info, err := t.conn.ServerInfo()
+	if err != nil {
+		return "", protoError(err)
+	}
+
+	if info.RodsZone == "" {
+		return t.zone, nil
+	}
+
+	return info.RodsZone, nil
+}
+
+func (t *protoTransport) Stat(path string) (*TransportEntry, error) {
+	stat, err := t.conn.Stat(path)
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	entry := &TransportEntry{
+		Type:       UnknownType,
+		Path:       path,
+		Size:       stat.ObjSize,
+		Mode:       stat.DataMode,
+		DataId:     stat.DataId,
+		Checksum:   stat.Chksum,
+		OwnerName:  stat.OwnerName,
+		OwnerZone:  stat.OwnerZone,
+		CreateTime: timeStringToTime(stat.CreateTime),
+		ModifyTime: timeStringToTime(stat.ModifyTime),
+	}
+
+	switch stat.ObjType {
+	case irodsproto.DataObjT:
+		entry.Type = DataObjType
+	case irodsproto.CollObjT:
+		entry.Type = CollectionType
+	}
+
+	return entry, nil
+}
+
+// dataObjColumns are selected by dataObjQuery, in the order dataObjEntry reads them
+var dataObjColumns = []int{
+	irodsproto.ColCollName, irodsproto.ColDataName, irodsproto.ColDataId, irodsproto.ColDataSize,
+	irodsproto.ColDataMode, irodsproto.ColDataChecksum, irodsproto.ColDataOwnerName, irodsproto.ColDataOwnerZone,
+	irodsproto.ColDataRescName, irodsproto.ColDataRescHier, irodsproto.ColDataPath, irodsproto.ColDataReplNum,
+	irodsproto.ColDataReplStatus, irodsproto.ColDataCreateTime, irodsproto.ColDataModifyTime,
+}
+
+func dataObjEntry(row []string) *TransportEntry {
+	size, _ := strconv.ParseInt(row[3], 10, 64)
+	mode, _ := strconv.Atoi(row[4])
+	replNum, _ := strconv.Atoi(row[11])
+	replStatus, _ := strconv.Atoi(row[12])
+
+	return &TransportEntry{
+		Type:       DataObjType,
+		Path:       strings.TrimRight(row[0], "/") + "/" + row[1],
+		DataId:     row[2],
+		Size:       size,
+		Mode:       mode,
+		Checksum:   row[5],
+		OwnerName:  row[6],
+		OwnerZone:  row[7],
+		Resource:   row[8],
+		RescHier:   row[9],
+		PhyPath:    row[10],
+		ReplNum:    replNum,
+		ReplStatus: replStatus,
+		CreateTime: timeStringToTime(row[13]),
+		ModifyTime: timeStringToTime(row[14]),
+	}
+}
+
+// dataObjQuery returns every replica matching conds in the zone of path, sorted by path then replica number.
+// When trimRepls is set only the first replica of each data object is kept.
+func (t *protoTransport) dataObjQuery(path string, trimRepls bool, conds ...irodsproto.Condition) ([]*TransportEntry, error) {
+	rows, err := t.pathQuery(path, dataObjColumns, conds...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*TransportEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = dataObjEntry(row)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].ReplNum < entries[j].ReplNum
+	})
+
+	if !trimRepls {
+		return entries, nil
+	}
+
+	response := make([]*TransportEntry, 0, len(entries))
+	for _, entry := range entries {
+		if len(response) == 0 || response[len(response)-1].Path != entry.Path {
+			response = append(response, entry)
+		}
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) DataObj(path string) (*TransportEntry, error) {
+	entries, err := t.dataObjQuery(path, true,
+		irodsproto.Equal(irodsproto.ColCollName, filepath.Dir(path)),
+		irodsproto.Equal(irodsproto.ColDataName, filepath.Base(path)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, NewTransportError(catUnknownFile, fmt.Sprintf("data object %v does not exist", path))
+	}
+
+	return entries[0], nil
+}
+
+func (t *protoTransport) collections(path string) ([]*TransportEntry, error) {
+	rows, err := t.pathQuery(path, []int{
+		irodsproto.ColCollName, irodsproto.ColCollOwnerName, irodsproto.ColCollOwnerZone,
+		irodsproto.ColCollCreateTime, irodsproto.ColCollModifyTime,
+	}, irodsproto.Equal(irodsproto.ColCollParentName, path))
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*TransportEntry, 0, len(rows))
+
+	for _, row := range rows {
+		// The root collection is its own parent
+		if row[0] == path {
+			continue
+		}
+
+		response = append(response, &TransportEntry{
+			Type:       CollectionType,
+			Path:       row[0],
+			OwnerName:  row[1],
+			OwnerZone:  row[2],
+			CreateTime: timeStringToTime(row[3]),
+			ModifyTime: timeStringToTime(row[4]),
+		})
+	}
+
+	sort.Slice(response, func(i, j int) bool { return response[i].Path < response[j].Path })
+
+	return response, nil
+}
+
+func (t *protoTransport) list(path string, trimRepls bool) ([]*TransportEntry, []*TransportEntry, error) {
+	if stat, err := t.Stat(path); err != nil {
+		return nil, nil, err
+	} else if stat.Type != CollectionType {
+		return nil, nil, NewTransportError(catUnknownCollection, fmt.Sprintf("%v is not a collection", path))
+	}
+
+	colls, err := t.collections(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objs, err := t.dataObjQuery(path, trimRepls, irodsproto.Equal(irodsproto.ColCollName, path))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return colls, objs, nil
+}
+
+func (t *protoTransport) List(path string, trimRepls bool) ([]*TransportEntry, error) {
+	colls, objs, err := t.list(path, trimRepls)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(colls, objs...), nil
+}
+
+func (t *protoTransport) ListPage(path string, trimRepls bool, offset int, limit int) (*TransportPage, error) {
+	colls, objs, err := t.list(path, trimRepls)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TransportPage{
+		ColTotal: len(colls),
+		ObjTotal: len(objs),
+	}
+
+	all := append(colls, objs...)
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(all) {
+		offset = len(all)
+	}
+
+	end := len(all)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	for _, entry := range all[offset:end] {
+		if entry.Type == CollectionType {
+			page.Collections = append(page.Collections, entry)
+		} else {
+			page.DataObjs = append(page.DataObjs, entry)
+		}
+	}
+
+	return page, nil
+}
+
+func (t *protoTransport) CreateCollection(path string) error {
+	if err := t.conn.CreateCollection(path, false); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) CreateDataObj(path string, size int64, mode int, force bool, resource string) error {
+	fd, err := t.conn.CreateDataObj(path, resource, mode, force)
+	if err != nil {
+		return protoError(err)
+	}
+
+	if err := t.conn.CloseDataObj(fd); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Put(localPath string, path string, size int64, mode int, force bool, resource string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return NewTransportError(-1, err.Error())
+	}
+	defer file.Close()
+
+	fd, err := t.conn.CreateDataObj(path, resource, mode, force)
+	if err != nil {
+		return protoError(err)
+	}
+
+	buf := make([]byte, protoChunkSize)
+
+	for {
+		n, readErr := file.Read(buf)
+
+		if n > 0 {
+			if err := t.conn.Write(fd, buf[:n]); err != nil {
+				t.conn.CloseDataObj(fd)
+				return protoError(err)
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			t.conn.CloseDataObj(fd)
+			return NewTransportError(-1, readErr.Error())
+		}
+	}
+
+	if err := t.conn.CloseDataObj(fd); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Remove(path string, isCollection bool, recursive bool, force bool, rmTrash bool) error {
+	var err error
+
+	if isCollection {
+		err = t.conn.RemoveCollection(path, recursive, force, rmTrash)
+	} else {
+		err = t.conn.RemoveDataObj(path, force, rmTrash)
+	}
+
+	if err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Move(src string, dst string, isCollection bool) error {
+	if err := t.conn.Rename(src, dst, isCollection); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Copy(src string, dst string, force bool, resource string) error {
+	if err := t.conn.Copy(src, dst, resource, force); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Checksum(path string) (string, error) {
+	sum, err := t.conn.Checksum(path, false)
+	if err != nil {
+		return "", protoError(err)
+	}
+
+	return sum, nil
+}
+
+func (t *protoTransport) Open(path string, resource string, replNum int, flags int) (int, error) {
+	protoFlags := irodsproto.ORdonly
+	if flags == os.O_RDWR {
+		protoFlags = irodsproto.ORdwr
+	}
+
+	fd, err := t.conn.OpenDataObj(path, resource, replNum, protoFlags)
+	if err != nil {
+		return -1, protoError(err)
+	}
+
+	return fd, nil
+}
+
+func (t *protoTransport) Read(handle int, length int64) ([]byte, error) {
+	response := make([]byte, 0)
+
+	for int64(len(response)) < length {
+		chunk := length - int64(len(response))
+		if chunk > protoChunkSize {
+			chunk = protoChunkSize
+		}
+
+		data, err := t.conn.Read(handle, int(chunk))
+		if err != nil {
+			return nil, protoError(err)
+		}
+
+		response = append(response, data...)
+
+		// A short read means we've reached the end of the data object
+		if int64(len(data)) < chunk {
+			break
+		}
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) Write(handle int, data []byte) error {
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > protoChunkSize {
+			chunk = chunk[:protoChunkSize]
+		}
+
+		if err := t.conn.Write(handle, chunk); err != nil {
+			return protoError(err)
+		}
+
+		data = data[len(chunk):]
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Seek(handle int, offset int64) error {
+	if _, err := t.conn.Seek(handle, offset, 0); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Close(handle int) error {
+	if err := t.conn.CloseDataObj(handle); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Meta(typ int, path string, zone string) (Metas, error) {
+	var (
+		cols  []int
+		conds []irodsproto.Condition
+	)
+
+	switch typ {
+	case DataObjType:
+		cols = []int{irodsproto.ColMetaDataAttrName, irodsproto.ColMetaDataAttrValue, irodsproto.ColMetaDataAttrUnits}
+		conds = []irodsproto.Condition{
+			irodsproto.Equal(irodsproto.ColCollName, filepath.Dir(path)),
+			irodsproto.Equal(irodsproto.ColDataName, filepath.Base(path)),
+		}
+	case CollectionType:
+		cols = []int{irodsproto.ColMetaCollAttrName, irodsproto.ColMetaCollAttrValue, irodsproto.ColMetaCollAttrUnits}
+		conds = []irodsproto.Condition{irodsproto.Equal(irodsproto.ColCollName, path)}
+	case ResourceType:
+		cols = []int{irodsproto.ColMetaRescAttrName, irodsproto.ColMetaRescAttrValue, irodsproto.ColMetaRescAttrUnits}
+		conds = []irodsproto.Condition{irodsproto.Equal(irodsproto.ColRescName, path)}
+	case UserType, GroupType, AdminType, GroupAdminType:
+		cols = []int{irodsproto.ColMetaUserAttrName, irodsproto.ColMetaUserAttrValue, irodsproto.ColMetaUserAttrUnits}
+		conds = []irodsproto.Condition{irodsproto.Equal(irodsproto.ColUserName, path)}
+		if zone != "" {
+			conds = append(conds, irodsproto.Equal(irodsproto.ColUserZone, zone))
+		}
+	default:
+		return nil, NewTransportError(-1, "unrecognized meta type constant")
+	}
+
+	// Only data object and collection paths name a zone, resources and users are looked up in the local zone
+	rows, err := t.pathQuery(path, cols, conds...)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(Metas, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, &Meta{Attribute: row[0], Value: row[1], Units: row[2]})
+	}
+
+	return response, nil
+}
+
+func protoAVU(m Meta) irodsproto.AVU {
+	return irodsproto.AVU{Attribute: m.Attribute, Value: m.Value, Units: m.Units}
+}
+
+func (t *protoTransport) AddMeta(typ int, path string, m Meta) error {
+	if err := t.conn.AddAVU("-"+GetShortTypeString(typ), path, protoAVU(m)); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) RemoveMeta(typ int, path string, m Meta) error {
+	if err := t.conn.RemoveAVU("-"+GetShortTypeString(typ), path, protoAVU(m)); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) ModMeta(typ int, path string, old Meta, updated Meta) error {
+	if err := t.conn.ModAVU("-"+GetShortTypeString(typ), path, protoAVU(old), protoAVU(updated)); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) DataObjACL(dataId string, zone string) ([]*TransportACL, error) {
+	rows, err := t.conn.Query(&irodsproto.GenQuery{
+		Select: irodsproto.Columns(irodsproto.ColUserName, irodsproto.ColDataAccessName, irodsproto.ColUserType),
+		Where: []irodsproto.Condition{
+			irodsproto.Equal(irodsproto.ColDataAccessDataId, dataId),
+			irodsproto.Equal(irodsproto.ColDataTokenNamespace, "access_type"),
+		},
+		Zone: zone,
+	})
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	response := make([]*TransportACL, len(rows))
+	for i, row := range rows {
+		response[i] = &TransportACL{Name: row[0], Access: row[1], Type: row[2]}
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) CollectionACL(path string, zone string) ([]*TransportACL, error) {
+	rows, err := t.conn.Query(&irodsproto.GenQuery{
+		Select: irodsproto.Columns(irodsproto.ColCollUserName, irodsproto.ColCollAccessName),
+		Where: []irodsproto.Condition{
+			irodsproto.Equal(irodsproto.ColCollName, path),
+			irodsproto.Equal(irodsproto.ColCollTokenNamespace, "access_type"),
+		},
+		Zone: zone,
+	})
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	if len(rows) == 0 {
+		return []*TransportACL{}, nil
+	}
+
+	// The collection access columns can't be joined with the user type, so it's looked up separately
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = "'" + row[0] + "'"
+	}
+
+	typeRows, err := t.conn.Query(&irodsproto.GenQuery{
+		Select: irodsproto.Columns(irodsproto.ColUserName, irodsproto.ColUserType),
+		Where:  []irodsproto.Condition{{Column: irodsproto.ColUserName, Expr: fmt.Sprintf("in (%v)", strings.Join(names, ","))}},
+		Zone:   zone,
+	})
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	types := make(map[string]string, len(typeRows))
+	for _, row := range typeRows {
+		types[row[0]] = row[1]
+	}
+
+	response := make([]*TransportACL, len(rows))
+	for i, row := range rows {
+		response[i] = &TransportACL{Name: row[0], Access: row[1], Type: types[row[0]]}
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) Chmod(path string, zone string, user string, access string, recursive bool) error {
+	if err := t.conn.ModAccess(path, zone, user, access, recursive); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) Inheritance(path string) (bool, error) {
+	rows, err := t.pathQuery(path, []int{irodsproto.ColCollInheritance}, irodsproto.Equal(irodsproto.ColCollName, path))
+	if err != nil {
+		return false, err
+	}
+
+	if len(rows) == 0 {
+		return false, NewTransportError(catUnknownCollection, fmt.Sprintf("collection %v does not exist", path))
+	}
+
+	return rows[0][0] == "1", nil
+}
+
+func (t *protoTransport) Users() ([]string, error) {
+	rows, err := t.query([]int{irodsproto.ColUserName, irodsproto.ColUserZone},
+		irodsproto.Condition{Column: irodsproto.ColUserType, Expr: "<> 'rodsgroup'"})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]string, len(rows))
+	for i, row := range rows {
+		response[i] = row[0] + "#" + row[1]
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) Groups() ([]string, error) {
+	rows, err := t.query([]int{irodsproto.ColUserName}, irodsproto.Equal(irodsproto.ColUserType, "rodsgroup"))
+	if err != nil {
+		return nil, err
+	}
+
+	return column(rows), nil
+}
+
+func (t *protoTransport) Zones() ([]string, error) {
+	rows, err := t.query([]int{irodsproto.ColZoneName})
+	if err != nil {
+		return nil, err
+	}
+
+	return column(rows), nil
+}
+
+func (t *protoTransport) Resources() ([]string, error) {
+	rows, err := t.query([]int{irodsproto.ColRescName})
+	if err != nil {
+		return nil, err
+	}
+
+	return column(rows), nil
+}
+
+// info runs a query expected to return a single row, and maps its values to keys. notFound is returned when there are no rows.
+func (t *protoTransport) info(cols []int, keys []string, notFound error, conds ...irodsproto.Condition) (map[string]string, error) {
+	rows, err := t.query(cols, conds...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, notFound
+	}
+
+	response := make(map[string]string, len(keys))
+	for i, key := range keys {
+		response[key] = rows[0][i]
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) UserInfo(name string) (map[string]string, error) {
+	return t.info([]int{
+		irodsproto.ColUserId, irodsproto.ColUserName, irodsproto.ColUserType, irodsproto.ColUserZone,
+		irodsproto.ColUserInfo, irodsproto.ColUserComment, irodsproto.ColUserCreateTime, irodsproto.ColUserModifyTime,
+	}, []string{
+		"user_id", "user_name", "user_type_name", "zone_name", "user_info", "r_comment", "create_ts", "modify_ts",
+	}, NewTransportError(catInvalidUser, fmt.Sprintf("user %v does not exist", name)),
+		irodsproto.Equal(irodsproto.ColUserName, name))
+}
+
+func (t *protoTransport) UserGroups(user string) ([]string, error) {
+	rows, err := t.query([]int{irodsproto.ColUserGroupName}, irodsproto.Equal(irodsproto.ColUserName, user))
+	if err != nil {
+		return nil, err
+	}
+
+	return column(rows), nil
+}
+
+func (t *protoTransport) GroupMembers(group string) ([]string, error) {
+	rows, err := t.query([]int{irodsproto.ColUserName, irodsproto.ColUserZone},
+		irodsproto.Equal(irodsproto.ColUserGroupName, group),
+		irodsproto.Condition{Column: irodsproto.ColUserType, Expr: "<> 'rodsgroup'"})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]string, len(rows))
+	for i, row := range rows {
+		response[i] = row[0] + "#" + row[1]
+	}
+
+	return response, nil
+}
+
+func (t *protoTransport) admin(args ...string) error {
+	if err := t.conn.GeneralAdmin(args...); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+func (t *protoTransport) CreateUser(name string, zone string, typ string) error {
+	return t.admin("add", "user", name, typ, zone)
+}
+
+func (t *protoTransport) DeleteUser(name string, zone string) error {
+	return t.admin("rm", "user", name, zone)
+}
+
+func (t *protoTransport) ChangePassword(user string, newPass string, myPass string) error {
+	return newError(Fatal, -1, "changing passwords isn't supported by PureGo connections").wrap(ErrNotSupported)
+}
+
+func (t *protoTransport) CreateGroup(name string, zone string) error {
+	return t.admin("add", "user", name, "rodsgroup", zone)
+}
+
+func (t *protoTransport) DeleteGroup(name string, zone string) error {
+	return t.admin("rm", "user", name, zone)
+}
+
+func (t *protoTransport) AddToGroup(user string, zone string, group string) error {
+	return t.admin("modify", "group", group, "add", user, zone)
+}
+
+func (t *protoTransport) RemoveFromGroup(user string, zone string, group string) error {
+	return t.admin("modify", "group", group, "remove", user, zone)
+}
+
+func (t *protoTransport) ZoneInfo(name string) (map[string]string, error) {
+	return t.info([]int{
+		irodsproto.ColZoneId, irodsproto.ColZoneName, irodsproto.ColZoneType, irodsproto.ColZoneConnection,
+		irodsproto.ColZoneComment, irodsproto.ColZoneCreateTime, irodsproto.ColZoneModifyTime,
+	}, []string{
+		"zone_id", "zone_name", "zone_type_name", "zone_conn_string", "r_comment", "create_ts", "modify_ts",
+	}, NewTransportError(catInvalidZone, fmt.Sprintf("zone %v does not exist", name)),
+		irodsproto.Equal(irodsproto.ColZoneName, name))
+}
+
+func (t *protoTransport) ResourceInfo(name string) (map[string]string, error) {
+	return t.info([]int{
+		irodsproto.ColRescId, irodsproto.ColRescName, irodsproto.ColRescZoneName, irodsproto.ColRescTypeName,
+		irodsproto.ColRescClassName, irodsproto.ColRescLoc, irodsproto.ColRescVaultPath, irodsproto.ColRescContext,
+		irodsproto.ColRescChildren, irodsproto.ColRescParent, irodsproto.ColRescInfo, irodsproto.ColRescStatus,
+		irodsproto.ColRescFreeSpace, irodsproto.ColRescFreeSpaceTime, irodsproto.ColRescComment,
+		irodsproto.ColRescCreateTime, irodsproto.ColRescModifyTime,
+	}, []string{
+		"resc_id", "resc_name", "zone_name", "resc_type_name", "resc_class_name", "resc_net", "resc_def_path", "resc_context",
+		"resc_children", "resc_parent", "resc_info", "resc_status", "free_space", "free_space_ts", "r_comment",
+		"create_ts", "modify_ts",
+	}, NewTransportError(catInvalidResource, fmt.Sprintf("resource %v does not exist", name)),
+		irodsproto.Equal(irodsproto.ColRescName, name))
+}
+
+// protoColumns maps the columns of *Query to their GenQuery column numbers
+var protoColumns = map[Column]int{
+	ColZoneId: irodsproto.ColZoneId, ColZoneName: irodsproto.ColZoneName, ColZoneType: irodsproto.ColZoneType,
+	ColZoneConnStr: irodsproto.ColZoneConnection, ColZoneComment: irodsproto.ColZoneComment,
+
+	ColUserId: irodsproto.ColUserId, ColUserName: irodsproto.ColUserName, ColUserType: irodsproto.ColUserType,
+	ColUserZone: irodsproto.ColUserZone, ColUserComment: irodsproto.ColUserComment,
+	ColUserGroupId: irodsproto.ColUserGroupId, ColUserGroup: irodsproto.ColUserGroupName,
+
+	ColRescId: irodsproto.ColRescId, ColRescName: irodsproto.ColRescName, ColRescZoneName: irodsproto.ColRescZoneName,
+	ColRescTypeName: irodsproto.ColRescTypeName, ColRescClassName: irodsproto.ColRescClassName, ColRescLoc: irodsproto.ColRescLoc,
+	ColRescVaultPath: irodsproto.ColRescVaultPath, ColRescFreeSpace: irodsproto.ColRescFreeSpace, ColRescComment: irodsproto.ColRescComment,
+	ColRescStatus: irodsproto.ColRescStatus, ColRescChildren: irodsproto.ColRescChildren, ColRescContext: irodsproto.ColRescContext,
+	ColRescParent: irodsproto.ColRescParent,
+
+	ColDataId: irodsproto.ColDataId, ColDataName: irodsproto.ColDataName, ColDataReplNum: irodsproto.ColDataReplNum,
+	ColDataVersion: irodsproto.ColDataVersion, ColDataTypeName: irodsproto.ColDataTypeName, ColDataSize: irodsproto.ColDataSize,
+	ColDataRescName: irodsproto.ColDataRescName, ColDataRescHier: irodsproto.ColDataRescHier, ColDataPath: irodsproto.ColDataPath,
+	ColDataOwnerName: irodsproto.ColDataOwnerName, ColDataOwnerZone: irodsproto.ColDataOwnerZone,
+	ColDataReplStatus: irodsproto.ColDataReplStatus, ColDataStatus: irodsproto.ColDataStatus, ColDataChecksum: irodsproto.ColDataChecksum,
+	ColDataComments: irodsproto.ColDataComments, ColDataCreateTime: irodsproto.ColDataCreateTime,
+	ColDataModifyTime: irodsproto.ColDataModifyTime, ColDataCollId: irodsproto.ColDataCollId,
+
+	ColCollId: irodsproto.ColCollId, ColCollName: irodsproto.ColCollName, ColCollParentName: irodsproto.ColCollParentName,
+	ColCollOwnerName: irodsproto.ColCollOwnerName, ColCollOwnerZone: irodsproto.ColCollOwnerZone,
+	ColCollInheritance: irodsproto.ColCollInheritance, ColCollComments: irodsproto.ColCollComments,
+	ColCollCreateTime: irodsproto.ColCollCreateTime, ColCollModifyTime: irodsproto.ColCollModifyTime,
+
+	ColMetaDataAttrName: irodsproto.ColMetaDataAttrName, ColMetaDataAttrValue: irodsproto.ColMetaDataAttrValue,
+	ColMetaDataAttrUnits: irodsproto.ColMetaDataAttrUnits,
+	ColMetaCollAttrName:  irodsproto.ColMetaCollAttrName, ColMetaCollAttrValue: irodsproto.ColMetaCollAttrValue,
+	ColMetaCollAttrUnits: irodsproto.ColMetaCollAttrUnits,
+	ColMetaRescAttrName:  irodsproto.ColMetaRescAttrName, ColMetaRescAttrValue: irodsproto.ColMetaRescAttrValue,
+	ColMetaRescAttrUnits: irodsproto.ColMetaRescAttrUnits,
+	ColMetaUserAttrName:  irodsproto.ColMetaUserAttrName, ColMetaUserAttrValue: irodsproto.ColMetaUserAttrValue,
+	ColMetaUserAttrUnits: irodsproto.ColMetaUserAttrUnits,
+
+	ColDataAccessType: irodsproto.ColDataAccessType, ColDataAccessName: irodsproto.ColDataAccessName,
+	ColDataAccessUserId: irodsproto.ColDataAccessUserId,
+	ColCollAccessType:   irodsproto.ColCollAccessType, ColCollAccessName: irodsproto.ColCollAccessName,
+	ColCollAccessUserId: irodsproto.ColCollAccessUserId,
+
+	ColTicketId: irodsproto.ColTicketId, ColTicketString: irodsproto.ColTicketString, ColTicketType: irodsproto.ColTicketType,
+	ColTicketObjectType: irodsproto.ColTicketObjectType, ColTicketOwnerName: irodsproto.ColTicketOwnerName,
+	ColTicketOwnerZone: irodsproto.ColTicketOwnerZone, ColTicketUsesCount: irodsproto.ColTicketUsesCount,
+	ColTicketUsesLimit: irodsproto.ColTicketUsesLimit, ColTicketWriteFileCount: irodsproto.ColTicketWriteFileCount,
+	ColTicketWriteFileLimit: irodsproto.ColTicketWriteFileLimit, ColTicketWriteByteCount: irodsproto.ColTicketWriteByteCount,
+	ColTicketWriteByteLimit: irodsproto.ColTicketWriteByteLimit, ColTicketExpiry: irodsproto.ColTicketExpiry,
+	ColTicketCreateTime: irodsproto.ColTicketCreateTime, ColTicketModifyTime: irodsproto.ColTicketModifyTime,
+	ColTicketDataName: irodsproto.ColTicketDataName, ColTicketDataCollName: irodsproto.ColTicketDataCollName,
+	ColTicketCollName:            irodsproto.ColTicketCollName,
+	ColTicketAllowedHostTicketId: irodsproto.ColTicketAllowedHostTicketId, ColTicketAllowedHost: irodsproto.ColTicketAllowedHost,
+	ColTicketAllowedUserTicketId: irodsproto.ColTicketAllowedUserTicketId, ColTicketAllowedUser: irodsproto.ColTicketAllowedUserName,
+	ColTicketAllowedGroupTicketId: irodsproto.ColTicketAllowedGroupTicketId, ColTicketAllowedGroup: irodsproto.ColTicketAllowedGroupName,
+
+	ColQuotaUserId: irodsproto.ColQuotaUserId, ColQuotaRescId: irodsproto.ColQuotaRescId, ColQuotaLimit: irodsproto.ColQuotaLimit,
+	ColQuotaOver: irodsproto.ColQuotaOver, ColQuotaModifyTime: irodsproto.ColQuotaModifyTime,
+	ColQuotaUsageUserId: irodsproto.ColQuotaUsageUserId, ColQuotaUsageRescId: irodsproto.ColQuotaUsageRescId,
+	ColQuotaUsage: irodsproto.ColQuotaUsage, ColQuotaUsageModifyTime: irodsproto.ColQuotaUsageModifyTime,
+	ColQuotaRescName: irodsproto.ColQuotaRescName, ColQuotaUserName: irodsproto.ColQuotaUserName,
+	ColQuotaUserZone: irodsproto.ColQuotaUserZone, ColQuotaUserType: irodsproto.ColQuotaUserType,
+}
+
+// GenQuery runs a validated *Query over the protocol, see genQuerier
+func (t *protoTransport) GenQuery(q *Query, zone string) (*QueryResult, error) {
+	gq := &irodsproto.GenQuery{
+		Zone:   zone,
+		Offset: q.RowOffset,
+		Limit:  q.RowLimit,
+	}
+
+	if q.NoDistinct {
+		gq.Options |= irodsproto.NoDistinct
+	}
+	if q.UpperCase {
+		gq.Options |= irodsproto.UpperCaseWhere
+	}
+
+	flags := make(map[Column]int)
+	for _, col := range q.Ascending {
+		flags[col] = irodsproto.OrderBy
+	}
+	for _, col := range q.Descending {
+		flags[col] = irodsproto.OrderByDesc
+	}
+
+	for _, col := range q.Columns {
+		gq.Select = append(gq.Select, irodsproto.Selection{Column: protoColumns[col], Flags: irodsproto.SelectNormal | flags[col]})
+	}
+
+	for _, cond := range q.Conditions {
+		gq.Where = append(gq.Where, irodsproto.Condition{Column: protoColumns[cond.Column], Expr: cond.expr()})
+	}
+
+	rows, err := t.conn.Query(gq)
+	if err != nil {
+		return nil, protoError(err)
+	}
+
+	return &QueryResult{Columns: q.Columns, Rows: rows}, nil
+}
+
+// GeneralAdmin runs an iadmin like operation, see generalAdminer
+func (t *protoTransport) GeneralAdmin(args ...string) error {
+	if err := t.conn.GeneralAdmin(args...); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+// AtomicApplyMetadata applies metadata operations to one object all at once, see metaBatcher
+func (t *protoTransport) AtomicApplyMetadata(input []byte) ([]byte, error) {
+	output, err := t.conn.AtomicApplyMetadata(input)
+	if err != nil {
+		return output, protoError(err)
+	}
+
+	return output, nil
+}
+
+// TicketAdmin runs an iticket like operation, see ticketAdminer
+func (t *protoTransport) TicketAdmin(args ...string) error {
+	if err := t.conn.TicketAdmin(args...); err != nil {
+		return protoError(err)
+	}
+
+	return nil
+}
+
+// VerifyChecksum has the server verify a replica against its registered checksum, see checksumVerifier
+func (t *protoTransport) VerifyChecksum(path string, replNum int) (string, error) {
+	sum, err := t.conn.VerifyChecksum(path, replNum)
+	if err != nil {
+		return "", protoError(err)
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,358 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestProtoColumns(t *testing.T) {
+	for col := range knownColumns {
+		if _, ok := protoColumns[col]; !ok {
+			t.Errorf("Column %v has no GenQuery column number for PureGo connections", col)
+		}
+	}
+}
+
+func TestConditionExpr(t *testing.T) {
+	cases := map[string]Condition{
+		"= 'foo'":                 {Column: ColDataName, Operator: "=", Values: []string{"foo"}},
+		"in ('a','b')":            {Column: ColDataName, Operator: "in", Values: []string{"a", "b"}},
+		"between '1' '2'":         {Column: ColDataSize, Operator: "between", Values: []string{"1", "2"}},
+		"like '/tempZone/home/%'": {Column: ColCollName, Operator: "like", Values: []string{"/tempZone/home/%"}},
+	}
+
+	for expected, cond := range cases {
+		if cond.expr() != expected {
+			t.Errorf("Expected %q, got %q", expected, cond.expr())
+		}
+		if cond.String() != string(cond.Column)+" "+expected {
+			t.Errorf("Expected String to prefix the column, got %q", cond.String())
+		}
+	}
+}
+
+func TestPureGoRefusesPAM(t *testing.T) {
+	if _, err := NewConnection(&ConnectionOptions{Type: UserDefined, AuthType: PAMAuth, PureGo: true}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestPureGoTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// The server accepts the connection, but never answers
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		buf := make([]byte, 1024)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	opts := &ConnectionOptions{
+		Type:     UserDefined,
+		PureGo:   true,
+		Host:     "127.0.0.1",
+		Port:     ln.Addr().(*net.TCPAddr).Port,
+		Zone:     "tempZone",
+		Username: "rods",
+		Password: "secret",
+		Timeout:  50 * time.Millisecond,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewConnection(opts)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("Expected a timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected NewConnection to time out on a server that never answers")
+	}
+}
+
+// protoPipe returns a protoTransport talking to a scripted server, which serve drives. The error serve returns is
+// sent on the channel, and the server end is closed after a failure so the client doesn't wait for a reply.
+func protoPipe(t *testing.T, serve func(s *irodsproto.Server) error) (*protoTransport, chan error) {
+	server, conn, err := irodsproto.Pipe(irodsproto.Config{User: "rods", Zone: "tempZone", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := serve(server)
+		if err != nil {
+			server.Close()
+		}
+		done <- err
+	}()
+
+	return &protoTransport{conn: conn, zone: "tempZone"}, done
+}
+
+// protoConnection returns a *Connection talking to the scripted server through transport
+func protoConnection(transport *protoTransport) *Connection {
+	return &Connection{Options: &ConnectionOptions{Zone: "tempZone", Username: "rods"}, transport: transport}
+}
+
+// expectQuery reads a GenQuery, and checks it has the conditions conds, a map of column number to expression
+func expectQuery(s *irodsproto.Server, conds map[int]string) (*irodsproto.GenQueryInp, error) {
+	inp := new(irodsproto.GenQueryInp)
+	if _, err := s.Expect(irodsproto.GenQueryAN, inp); err != nil {
+		return nil, err
+	}
+
+	if inp.Conditions.Len != len(conds) {
+		return nil, fmt.Errorf("Expected %v conditions, got %+v", len(conds), inp.Conditions)
+	}
+
+	for i, col := range inp.Conditions.Inx {
+		if conds[col] != inp.Conditions.Values[i] {
+			return nil, fmt.Errorf("Expected condition %q on column %v, got %q", conds[col], col, inp.Conditions.Values[i])
+		}
+	}
+
+	return inp, nil
+}
+
+func TestProtoList(t *testing.T) {
+	path := "/tempZone/home/rods"
+
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		var stat irodsproto.DataObjInp
+		if _, err := s.Expect(irodsproto.ObjStatAN, &stat); err != nil {
+			return err
+		}
+		if stat.ObjPath != path {
+			return fmt.Errorf("Expected to stat %v, got %v", path, stat.ObjPath)
+		}
+		if err := s.Reply(irodsproto.ObjStat{ObjType: irodsproto.CollObjT}, nil, 0); err != nil {
+			return err
+		}
+
+		inp, err := expectQuery(s, map[int]string{irodsproto.ColCollParentName: "= '" + path + "'"})
+		if err != nil {
+			return err
+		}
+		if err := s.ReplyRows(inp, [][]string{
+			{path + "/b", "rods", "tempZone", "01500000000", "01500000000"},
+			{path + "/a", "rods", "tempZone", "01500000000", "01500000000"},
+		}); err != nil {
+			return err
+		}
+
+		if inp, err = expectQuery(s, map[int]string{irodsproto.ColCollName: "= '" + path + "'"}); err != nil {
+			return err
+		}
+
+		return s.ReplyRows(inp, [][]string{
+			{path, "y.txt", "10020", "3", "0", "", "rods", "tempZone", "demoResc", "demoResc", "/var/lib/y.txt", "0", "1", "01500000000", "01500000000"},
+			{path, "x.txt", "10010", "5", "0", "", "rods", "tempZone", "otherResc", "otherResc", "/var/lib/x.txt", "1", "1", "01500000000", "01500000000"},
+			{path, "x.txt", "10010", "5", "0", "", "rods", "tempZone", "demoResc", "demoResc", "/var/lib/x.txt", "0", "1", "01500000000", "01500000000"},
+		})
+	})
+
+	entries, err := transport.List(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{path + "/a", path + "/b", path + "/x.txt", path + "/y.txt"}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %v entries, got %v", len(expected), len(entries))
+	}
+
+	for i, entry := range entries {
+		if entry.Path != expected[i] {
+			t.Errorf("Expected entry %v to be %v, got %v", i, expected[i], entry.Path)
+		}
+	}
+
+	if entries[0].Type != CollectionType || entries[2].Type != DataObjType {
+		t.Errorf("Expected collections then data objects, got %v and %v", entries[0].Type, entries[2].Type)
+	}
+	if entries[2].ReplNum != 0 || entries[2].Resource != "demoResc" || entries[2].Size != 5 || entries[2].DataId != "10010" {
+		t.Errorf("Expected the first replica of x.txt, got %+v", entries[2])
+	}
+}
+
+func TestProtoMeta(t *testing.T) {
+	path := "/tempZone/home/rods/x.txt"
+
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		inp, err := expectQuery(s, map[int]string{
+			irodsproto.ColCollName: "= '/tempZone/home/rods'",
+			irodsproto.ColDataName: "= 'x.txt'",
+		})
+		if err != nil {
+			return err
+		}
+		if inp.Selects.Len != 3 || inp.Selects.Inx[0] != irodsproto.ColMetaDataAttrName {
+			return fmt.Errorf("Expected the data object AVU columns, got %+v", inp.Selects)
+		}
+		if err := s.ReplyRows(inp, [][]string{{"color", "blue", ""}, {"size", "5", "MB"}}); err != nil {
+			return err
+		}
+
+		var avu irodsproto.ModAVUMetadataInp
+		if _, err := s.Expect(irodsproto.ModAVUMetadataAN, &avu); err != nil {
+			return err
+		}
+		if avu.Arg0 != "add" || avu.Arg1 != "-d" || avu.Arg2 != path || avu.Arg3 != "shape" || avu.Arg4 != "round" || avu.Arg5 != "cm" {
+			return fmt.Errorf("Unexpected AVU arguments %+v", avu)
+		}
+
+		return s.Reply(nil, nil, 0)
+	})
+
+	metas, err := transport.Meta(DataObjType, path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metas) != 2 || metas[1].Attribute != "size" || metas[1].Value != "5" || metas[1].Units != "MB" {
+		t.Errorf("Unexpected metadata %v", metas)
+	}
+
+	if err := transport.AddMeta(DataObjType, path, Meta{Attribute: "shape", Value: "round", Units: "cm"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProtoACL(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		inp, err := expectQuery(s, map[int]string{
+			irodsproto.ColDataAccessDataId:   "= '10010'",
+			irodsproto.ColDataTokenNamespace: "= 'access_type'",
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.ReplyRows(inp, [][]string{{"rods", "own", "rodsadmin"}}); err != nil {
+			return err
+		}
+
+		if inp, err = expectQuery(s, map[int]string{
+			irodsproto.ColCollName:           "= '/tempZone/home/rods'",
+			irodsproto.ColCollTokenNamespace: "= 'access_type'",
+		}); err != nil {
+			return err
+		}
+		if err := s.ReplyRows(inp, [][]string{{"rods", "own"}, {"public", "read object"}}); err != nil {
+			return err
+		}
+
+		if inp, err = expectQuery(s, map[int]string{irodsproto.ColUserName: "in ('rods','public')"}); err != nil {
+			return err
+		}
+
+		return s.ReplyRows(inp, [][]string{{"public", "rodsgroup"}, {"rods", "rodsadmin"}})
+	})
+
+	acls, err := transport.DataObjACL("10010", "tempZone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 1 || *acls[0] != (TransportACL{Name: "rods", Access: "own", Type: "rodsadmin"}) {
+		t.Errorf("Unexpected data object ACL %v", acls)
+	}
+
+	if acls, err = transport.CollectionACL("/tempZone/home/rods", "tempZone"); err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 2 || *acls[1] != (TransportACL{Name: "public", Access: "read object", Type: "rodsgroup"}) {
+		t.Errorf("Unexpected collection ACL %v", acls)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProtoReadWrite(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), protoChunkSize/10+10)
+
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		var received []byte
+
+		// Writes larger than protoChunkSize are split
+		for _, size := range []int{protoChunkSize, len(data) - protoChunkSize} {
+			var inp irodsproto.OpenedDataObjInp
+			bs, err := s.Expect(irodsproto.DataObjWriteAN, &inp)
+			if err != nil {
+				return err
+			}
+			if inp.L1descInx != 3 || inp.Len != size || len(bs) != size {
+				return fmt.Errorf("Expected to write %v bytes to 3, got %v bytes to %v", size, len(bs), inp.L1descInx)
+			}
+			received = append(received, bs...)
+
+			if err := s.Reply(nil, nil, len(bs)); err != nil {
+				return err
+			}
+		}
+
+		if !bytes.Equal(received, data) {
+			return fmt.Errorf("Expected the chunks to add up to the data written")
+		}
+
+		// A short read ends the read, without asking for the rest
+		var inp irodsproto.OpenedDataObjInp
+		if _, err := s.Expect(irodsproto.DataObjReadAN, &inp); err != nil {
+			return err
+		}
+		if inp.L1descInx != 3 || inp.Len != 20 {
+			return fmt.Errorf("Expected to read 20 bytes from 3, got %+v", inp)
+		}
+
+		return s.Reply(nil, []byte("012345"), 6)
+	})
+
+	if err := transport.Write(3, data); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := transport.Read(3, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(read) != "012345" {
+		t.Errorf("Expected to read %q, got %q", "012345", read)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
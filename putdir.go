@@ -0,0 +1,260 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+)
+
+// PutDirOptions control the behavior of Collection.PutDir.
+// Include and Exclude are lists of glob patterns (see filepath.Match), matched against both the file name
+// and the slash separated path relative to the local directory. If Include is set, only files matching
+// at least one of its patterns are uploaded. Files and directories matching any Exclude pattern are skipped.
+// Concurrency is the number of files uploaded at once, each over its own connection obtained from Pool
+// (see TransferOptions). Force, Resource and Verify are used when creating each data object, as in DataObjOptions.
+// Once Context is cancelled, files which haven't started uploading fail with the context's error.
+type PutDirOptions struct {
+	Include     []string
+	Exclude     []string
+	Concurrency int
+	Pool        *ConnectionPool
+	Force       bool
+	Resource    interface{}
+	Context     context.Context
+	Verify      bool
+}
+
+// PutDirResult is the outcome of uploading a single file (or creating a single collection) in Collection.PutDir.
+type PutDirResult struct {
+	LocalPath string
+	Path      string
+	Size      int64
+	IsDir     bool
+	Err       error
+}
+
+// PutDirReport holds the result of every file and collection handled by Collection.PutDir.
+type PutDirReport struct {
+	Results []*PutDirResult
+}
+
+// Failed returns the results of files and collections which couldn't be uploaded
+func (rep *PutDirReport) Failed() []*PutDirResult {
+	failed := make([]*PutDirResult, 0)
+
+	for _, res := range rep.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+// matchAny returns true if the name or relative path matches one of patterns
+func matchAny(patterns []string, name string, relPath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// putDirTarget is a directory walked by Collection.PutDir, and the collection it's uploaded to once it's created
+type putDirTarget struct {
+	res    *PutDirResult
+	name   string
+	parent *putDirTarget
+	col    *Collection
+}
+
+// PutDir recursively uploads the contents of localDir into the collection. Sub directories are created
+// as sub collections using CreateSubCollection (existing ones are reused), and files are uploaded concurrently.
+// When Include is set, only the sub collections leading to an included file are created.
+// Failures are recorded in the returned report instead of aborting the upload. The returned error is non-nil
+// if the options are invalid or anything failed.
+func (col *Collection) PutDir(localDir string, opts PutDirOptions) (*PutDirReport, error) {
+
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS PutDir Failed: Invalid pattern %q", pattern))
+		}
+	}
+
+	report := new(PutDirReport)
+	files := make([]*PutDirResult, 0)
+
+	// With Include set, collections are only created once a file to upload in them is found, so directories
+	// without included files don't leave empty collections behind
+	var ensure func(dir *putDirTarget) (*Collection, error)
+
+	ensure = func(dir *putDirTarget) (*Collection, error) {
+		if dir.col != nil || dir.res.Err != nil {
+			return dir.col, dir.res.Err
+		}
+
+		parent, err := ensure(dir.parent)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Results = append(report.Results, dir.res)
+
+		if dir.col = parent.Cd(dir.name); dir.col == nil {
+			dir.col, dir.res.Err = parent.CreateSubCollection(dir.name)
+		}
+
+		return dir.col, dir.res.Err
+	}
+
+	var walk func(dir string, relDir string, target *putDirTarget) error
+
+	walk = func(dir string, relDir string, target *putDirTarget) error {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS PutDir Failed: %v", err)).wrap(err)
+		}
+
+		for _, info := range infos {
+			localPath := filepath.Join(dir, info.Name())
+			relPath := info.Name()
+			if relDir != "" {
+				relPath = relDir + "/" + info.Name()
+			}
+
+			if matchAny(opts.Exclude, info.Name(), relPath) {
+				continue
+			}
+
+			res := &PutDirResult{
+				LocalPath: localPath,
+				Path:      target.res.Path + "/" + info.Name(),
+				IsDir:     info.IsDir(),
+			}
+
+			if info.IsDir() {
+				sub := &putDirTarget{res: res, name: info.Name(), parent: target}
+
+				if len(opts.Include) == 0 {
+					if _, er := ensure(sub); er != nil {
+						continue
+					}
+				}
+
+				if er := walk(localPath, relPath, sub); er != nil {
+					if sub.col == nil && sub.res.Err == nil {
+						report.Results = append(report.Results, res)
+					}
+					res.Err = er
+				}
+				continue
+			}
+
+			if !info.Mode().IsRegular() {
+				continue
+			}
+
+			if len(opts.Include) > 0 && !matchAny(opts.Include, info.Name(), relPath) {
+				continue
+			}
+
+			if _, er := ensure(target); er != nil {
+				continue
+			}
+
+			res.Size = info.Size()
+			files = append(files, res)
+			report.Results = append(report.Results, res)
+		}
+
+		return nil
+	}
+
+	if err := walk(localDir, "", &putDirTarget{col: col, res: &PutDirResult{Path: col.path}}); err != nil {
+		return report, err
+	}
+
+	dataOpts := DataObjOptions{
+		Force:    opts.Force,
+		Resource: opts.Resource,
+		Verify:   opts.Verify,
+	}
+
+	topts := TransferOptions{
+		Concurrency: opts.Concurrency,
+		Pool:        opts.Pool,
+		Context:     opts.Context,
+	}
+
+	ctx := topts.context()
+
+	put := func(res *PutDirResult, con *Connection) error {
+		if er := ctx.Err(); er != nil {
+			return er
+		}
+		return putDataObj(ctx, res.LocalPath, res.Path, dataOpts, con)
+	}
+
+	concurrency := topts.concurrency()
+	if concurrency > len(files) {
+		concurrency = len(files)
+	}
+
+	if concurrency == 1 {
+		for _, res := range files {
+			res.Err = put(res, col.con)
+		}
+	} else if concurrency > 1 {
+		var wg sync.WaitGroup
+
+		work := make(chan *PutDirResult)
+
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				con, err := topts.connection(col.con)
+				if err != nil {
+					for res := range work {
+						res.Err = err
+					}
+					return
+				}
+				defer topts.release(con)
+
+				for res := range work {
+					res.Err = put(res, con)
+				}
+			}()
+		}
+
+		for _, res := range files {
+			work <- res
+		}
+
+		close(work)
+		wg.Wait()
+	}
+
+	if err := col.Refresh(); err != nil {
+		return report, err
+	}
+
+	if failed := report.Failed(); len(failed) > 0 {
+		return report, newError(Fatal, -1, fmt.Sprintf("iRODS PutDir Failed: %v of %v uploads failed, first error: %v", len(failed), len(report.Results), failed[0].Err))
+	}
+
+	return report, nil
+}
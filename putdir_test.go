@@ -0,0 +1,105 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchAny(t *testing.T) {
+
+	patterns := []string{"*.bam", "logs/*"}
+
+	tests := []struct {
+		name     string
+		relPath  string
+		expected bool
+	}{
+		{"reads.bam", "reads.bam", true},
+		{"reads.bam", "run1/reads.bam", true},
+		{"out.log", "logs/out.log", true},
+		{"out.log", "run1/logs/out.log", false},
+		{"notes.txt", "notes.txt", false},
+	}
+
+	for _, test := range tests {
+		if matched := matchAny(patterns, test.name, test.relPath); matched != test.expected {
+			t.Errorf("Expected matchAny(%v) to be %v, got %v", test.relPath, test.expected, matched)
+		}
+	}
+
+	if matchAny(nil, "reads.bam", "reads.bam") {
+		t.Error("Expected no match with empty pattern list")
+	}
+}
+
+func TestPutDirInclude(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "gorods-putdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"top.bam", "run1/reads.bam", "run1/notes.txt", "run2/notes.txt", "run2/deep/x.txt"} {
+		localPath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(localPath), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(localPath, []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "empty"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := home.PutDir(dir, PutDirOptions{Include: []string{"*.bam"}, Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := make([]string, len(report.Results))
+	for i, res := range report.Results {
+		paths[i] = res.Path
+	}
+
+	expected := []string{home.Path() + "/run1", home.Path() + "/run1/reads.bam", home.Path() + "/top.bam"}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected the results %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Expected the results %v, got %v", expected, paths)
+			break
+		}
+	}
+
+	for _, name := range []string{"run2", "run2/deep", "empty"} {
+		if _, err := con.PathType(home.Path() + "/" + name); err == nil {
+			t.Errorf("Expected no collection to be created for %v", name)
+		}
+	}
+
+	// Without Include every directory is created, including empty ones
+	if _, err := home.PutDir(dir, PutDirOptions{Concurrency: 1, Force: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"run2/deep/x.txt", "empty"} {
+		if _, err := con.PathType(home.Path() + "/" + name); err != nil {
+			t.Errorf("Expected %v to be uploaded, got %v", name, err)
+		}
+	}
+}
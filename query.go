@@ -0,0 +1,650 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column is the name of an iRODS GenQuery column, as used by iquest
+type Column string
+
+// GenQuery columns which can be used with NewQuery
+const (
+	ColZoneId      Column = "ZONE_ID"
+	ColZoneName    Column = "ZONE_NAME"
+	ColZoneType    Column = "ZONE_TYPE"
+	ColZoneConnStr Column = "ZONE_CONNECTION"
+	ColZoneComment Column = "ZONE_COMMENT"
+
+	ColUserId      Column = "USER_ID"
+	ColUserName    Column = "USER_NAME"
+	ColUserType    Column = "USER_TYPE"
+	ColUserZone    Column = "USER_ZONE"
+	ColUserComment Column = "USER_COMMENT"
+	ColUserGroupId Column = "USER_GROUP_ID"
+	ColUserGroup   Column = "USER_GROUP_NAME"
+
+	ColRescId        Column = "RESC_ID"
+	ColRescName      Column = "RESC_NAME"
+	ColRescZoneName  Column = "RESC_ZONE_NAME"
+	ColRescTypeName  Column = "RESC_TYPE_NAME"
+	ColRescClassName Column = "RESC_CLASS_NAME"
+	ColRescLoc       Column = "RESC_LOC"
+	ColRescVaultPath Column = "RESC_VAULT_PATH"
+	ColRescFreeSpace Column = "RESC_FREE_SPACE"
+	ColRescComment   Column = "RESC_COMMENT"
+	ColRescStatus    Column = "RESC_STATUS"
+	ColRescChildren  Column = "RESC_CHILDREN"
+	ColRescContext   Column = "RESC_CONTEXT"
+	ColRescParent    Column = "RESC_PARENT"
+
+	ColDataId         Column = "DATA_ID"
+	ColDataName       Column = "DATA_NAME"
+	ColDataReplNum    Column = "DATA_REPL_NUM"
+	ColDataVersion    Column = "DATA_VERSION"
+	ColDataTypeName   Column = "DATA_TYPE_NAME"
+	ColDataSize       Column = "DATA_SIZE"
+	ColDataRescName   Column = "DATA_RESC_NAME"
+	ColDataRescHier   Column = "DATA_RESC_HIER"
+	ColDataPath       Column = "DATA_PATH"
+	ColDataOwnerName  Column = "DATA_OWNER_NAME"
+	ColDataOwnerZone  Column = "DATA_OWNER_ZONE"
+	ColDataReplStatus Column = "DATA_REPL_STATUS"
+	ColDataStatus     Column = "DATA_STATUS"
+	ColDataChecksum   Column = "DATA_CHECKSUM"
+	ColDataComments   Column = "DATA_COMMENTS"
+	ColDataCreateTime Column = "DATA_CREATE_TIME"
+	ColDataModifyTime Column = "DATA_MODIFY_TIME"
+	ColDataCollId     Column = "DATA_COLL_ID"
+
+	ColCollId          Column = "COLL_ID"
+	ColCollName        Column = "COLL_NAME"
+	ColCollParentName  Column = "COLL_PARENT_NAME"
+	ColCollOwnerName   Column = "COLL_OWNER_NAME"
+	ColCollOwnerZone   Column = "COLL_OWNER_ZONE"
+	ColCollInheritance Column = "COLL_INHERITANCE"
+	ColCollComments    Column = "COLL_COMMENTS"
+	ColCollCreateTime  Column = "COLL_CREATE_TIME"
+	ColCollModifyTime  Column = "COLL_MODIFY_TIME"
+
+	ColMetaDataAttrName  Column = "META_DATA_ATTR_NAME"
+	ColMetaDataAttrValue Column = "META_DATA_ATTR_VALUE"
+	ColMetaDataAttrUnits Column = "META_DATA_ATTR_UNITS"
+	ColMetaCollAttrName  Column = "META_COLL_ATTR_NAME"
+	ColMetaCollAttrValue Column = "META_COLL_ATTR_VALUE"
+	ColMetaCollAttrUnits Column = "META_COLL_ATTR_UNITS"
+	ColMetaRescAttrName  Column = "META_RESC_ATTR_NAME"
+	ColMetaRescAttrValue Column = "META_RESC_ATTR_VALUE"
+	ColMetaRescAttrUnits Column = "META_RESC_ATTR_UNITS"
+	ColMetaUserAttrName  Column = "META_USER_ATTR_NAME"
+	ColMetaUserAttrValue Column = "META_USER_ATTR_VALUE"
+	ColMetaUserAttrUnits Column = "META_USER_ATTR_UNITS"
+
+	ColDataAccessType   Column = "DATA_ACCESS_TYPE"
+	ColDataAccessName   Column = "DATA_ACCESS_NAME"
+	ColDataAccessUserId Column = "DATA_ACCESS_USER_ID"
+	ColCollAccessType   Column = "COLL_ACCESS_TYPE"
+	ColCollAccessName   Column = "COLL_ACCESS_NAME"
+	ColCollAccessUserId Column = "COLL_ACCESS_USER_ID"
+
+	ColQuotaUserId          Column = "QUOTA_USER_ID"
+	ColQuotaRescId          Column = "QUOTA_RESC_ID"
+	ColQuotaLimit           Column = "QUOTA_LIMIT"
+	ColQuotaOver            Column = "QUOTA_OVER"
+	ColQuotaModifyTime      Column = "QUOTA_MODIFY_TIME"
+	ColQuotaUsageUserId     Column = "QUOTA_USAGE_USER_ID"
+	ColQuotaUsageRescId     Column = "QUOTA_USAGE_RESC_ID"
+	ColQuotaUsage           Column = "QUOTA_USAGE"
+	ColQuotaUsageModifyTime Column = "QUOTA_USAGE_MODIFY_TIME"
+	ColQuotaRescName        Column = "QUOTA_RESC_NAME"
+	ColQuotaUserName        Column = "QUOTA_USER_NAME"
+	ColQuotaUserZone        Column = "QUOTA_USER_ZONE"
+	ColQuotaUserType        Column = "QUOTA_USER_TYPE"
+
+	ColTicketId                   Column = "TICKET_ID"
+	ColTicketString               Column = "TICKET_STRING"
+	ColTicketType                 Column = "TICKET_TYPE"
+	ColTicketObjectType           Column = "TICKET_OBJECT_TYPE"
+	ColTicketOwnerName            Column = "TICKET_OWNER_NAME"
+	ColTicketOwnerZone            Column = "TICKET_OWNER_ZONE"
+	ColTicketUsesCount            Column = "TICKET_USES_COUNT"
+	ColTicketUsesLimit            Column = "TICKET_USES_LIMIT"
+	ColTicketWriteFileCount       Column = "TICKET_WRITE_FILE_COUNT"
+	ColTicketWriteFileLimit       Column = "TICKET_WRITE_FILE_LIMIT"
+	ColTicketWriteByteCount       Column = "TICKET_WRITE_BYTE_COUNT"
+	ColTicketWriteByteLimit       Column = "TICKET_WRITE_BYTE_LIMIT"
+	ColTicketExpiry               Column = "TICKET_EXPIRY"
+	ColTicketCreateTime           Column = "TICKET_CREATE_TIME"
+	ColTicketModifyTime           Column = "TICKET_MODIFY_TIME"
+	ColTicketDataName             Column = "TICKET_DATA_NAME"
+	ColTicketDataCollName         Column = "TICKET_DATA_COLL_NAME"
+	ColTicketCollName             Column = "TICKET_COLL_NAME"
+	ColTicketAllowedHostTicketId  Column = "TICKET_ALLOWED_HOST_TICKET_ID"
+	ColTicketAllowedHost          Column = "TICKET_ALLOWED_HOST"
+	ColTicketAllowedUserTicketId  Column = "TICKET_ALLOWED_USER_TICKET_ID"
+	ColTicketAllowedUser          Column = "TICKET_ALLOWED_USER_NAME"
+	ColTicketAllowedGroupTicketId Column = "TICKET_ALLOWED_GROUP_TICKET_ID"
+	ColTicketAllowedGroup         Column = "TICKET_ALLOWED_GROUP_NAME"
+)
+
+var knownColumns = map[Column]bool{
+	ColZoneId: true, ColZoneName: true, ColZoneType: true, ColZoneConnStr: true, ColZoneComment: true,
+	ColUserId: true, ColUserName: true, ColUserType: true, ColUserZone: true, ColUserComment: true, ColUserGroupId: true, ColUserGroup: true,
+	ColRescId: true, ColRescName: true, ColRescZoneName: true, ColRescTypeName: true, ColRescClassName: true, ColRescLoc: true,
+	ColRescVaultPath: true, ColRescFreeSpace: true, ColRescComment: true, ColRescStatus: true, ColRescChildren: true, ColRescContext: true, ColRescParent: true,
+	ColDataId: true, ColDataName: true, ColDataReplNum: true, ColDataVersion: true, ColDataTypeName: true, ColDataSize: true, ColDataRescName: true,
+	ColDataRescHier: true, ColDataPath: true, ColDataOwnerName: true, ColDataOwnerZone: true, ColDataReplStatus: true, ColDataStatus: true,
+	ColDataChecksum: true, ColDataComments: true, ColDataCreateTime: true, ColDataModifyTime: true, ColDataCollId: true,
+	ColCollId: true, ColCollName: true, ColCollParentName: true, ColCollOwnerName: true, ColCollOwnerZone: true, ColCollInheritance: true,
+	ColCollComments: true, ColCollCreateTime: true, ColCollModifyTime: true,
+	ColMetaDataAttrName: true, ColMetaDataAttrValue: true, ColMetaDataAttrUnits: true,
+	ColMetaCollAttrName: true, ColMetaCollAttrValue: true, ColMetaCollAttrUnits: true,
+	ColMetaRescAttrName: true, ColMetaRescAttrValue: true, ColMetaRescAttrUnits: true,
+	ColMetaUserAttrName: true, ColMetaUserAttrValue: true, ColMetaUserAttrUnits: true,
+	ColDataAccessType: true, ColDataAccessName: true, ColDataAccessUserId: true,
+	ColCollAccessType: true, ColCollAccessName: true, ColCollAccessUserId: true,
+	ColTicketId: true, ColTicketString: true, ColTicketType: true, ColTicketObjectType: true, ColTicketOwnerName: true,
+	ColTicketOwnerZone: true, ColTicketUsesCount: true, ColTicketUsesLimit: true, ColTicketWriteFileCount: true,
+	ColTicketWriteFileLimit: true, ColTicketWriteByteCount: true, ColTicketWriteByteLimit: true, ColTicketExpiry: true,
+	ColTicketCreateTime: true, ColTicketModifyTime: true, ColTicketDataName: true, ColTicketDataCollName: true,
+	ColTicketCollName: true, ColTicketAllowedHostTicketId: true, ColTicketAllowedHost: true,
+	ColTicketAllowedUserTicketId: true, ColTicketAllowedUser: true, ColTicketAllowedGroupTicketId: true,
+	ColTicketAllowedGroup: true,
+
+	ColQuotaUserId: true, ColQuotaRescId: true, ColQuotaLimit: true, ColQuotaOver: true, ColQuotaModifyTime: true,
+	ColQuotaUsageUserId: true, ColQuotaUsageRescId: true, ColQuotaUsage: true, ColQuotaUsageModifyTime: true,
+	ColQuotaRescName: true, ColQuotaUserName: true, ColQuotaUserZone: true, ColQuotaUserType: true,
+}
+
+var knownOperators = map[string]bool{
+	"=": true, "<>": true, "<": true, ">": true, "<=": true, ">=": true,
+	"like": true, "not like": true, "in": true, "between": true,
+}
+
+// Condition is a single where clause of a *Query
+type Condition struct {
+	Column   Column
+	Operator string
+	Values   []string
+}
+
+// String renders the condition in iquest syntax
+func (cond Condition) String() string {
+	return fmt.Sprintf("%v %v", cond.Column, cond.expr())
+}
+
+// expr renders the condition without its column, e.g. "like '%.bam'"
+func (cond Condition) expr() string {
+	quoted := make([]string, len(cond.Values))
+	for i, v := range cond.Values {
+		quoted[i] = "'" + v + "'"
+	}
+
+	switch cond.Operator {
+	case "in":
+		return fmt.Sprintf("in (%v)", strings.Join(quoted, ","))
+	case "between":
+		return fmt.Sprintf("between %v", strings.Join(quoted, " "))
+	}
+
+	return fmt.Sprintf("%v %v", cond.Operator, strings.Join(quoted, " "))
+}
+
+// Query is a GenQuery built from typed columns. Create one with NewQuery, then chain Where, OrderBy, Limit and friends.
+// The query is validated by Connection.Query before it's sent to the server.
+type Query struct {
+	Columns    []Column
+	Conditions []Condition
+	Ascending  []Column
+	Descending []Column
+
+	RowLimit   int
+	RowOffset  int
+	NoDistinct bool
+	UpperCase  bool
+	ZoneHint   string
+}
+
+// NewQuery returns a *Query selecting columns
+func NewQuery(columns ...Column) *Query {
+	q := new(Query)
+	q.Columns = columns
+
+	return q
+}
+
+// Where adds a condition comparing col against value using op, which is one of =, <>, <, >, <=, >=, like, not like
+func (q *Query) Where(col Column, op string, value string) *Query {
+	q.Conditions = append(q.Conditions, Condition{col, strings.ToLower(op), []string{value}})
+	return q
+}
+
+// WhereIn adds a condition matching col against any of values
+func (q *Query) WhereIn(col Column, values ...string) *Query {
+	q.Conditions = append(q.Conditions, Condition{col, "in", values})
+	return q
+}
+
+// WhereBetween adds a condition matching col between low and high (inclusive)
+func (q *Query) WhereBetween(col Column, low string, high string) *Query {
+	q.Conditions = append(q.Conditions, Condition{col, "between", []string{low, high}})
+	return q
+}
+
+// OrderBy sorts results by col in ascending order. col must be one of the selected columns.
+func (q *Query) OrderBy(col Column) *Query {
+	q.Ascending = append(q.Ascending, col)
+	return q
+}
+
+// OrderByDesc sorts results by col in descending order. col must be one of the selected columns.
+func (q *Query) OrderByDesc(col Column) *Query {
+	q.Descending = append(q.Descending, col)
+	return q
+}
+
+// Limit sets the maximum number of rows returned. 0 means no limit.
+func (q *Query) Limit(n int) *Query {
+	q.RowLimit = n
+	return q
+}
+
+// Offset skips the first n rows of the result
+func (q *Query) Offset(n int) *Query {
+	q.RowOffset = n
+	return q
+}
+
+// Distinct controls whether duplicate rows are removed. GenQuery results are distinct by default.
+func (q *Query) Distinct(distinct bool) *Query {
+	q.NoDistinct = !distinct
+	return q
+}
+
+// CaseInsensitive makes the where conditions case insensitive
+func (q *Query) CaseInsensitive() *Query {
+	q.UpperCase = true
+	return q
+}
+
+// Zone sets the zone the query is sent to, for querying federated zones. Defaults to the zone of the collection
+// the query is restricted to (such as otherZone for COLL_NAME = '/otherZone/home/rods'), or the local zone.
+func (q *Query) Zone(zone string) *Query {
+	q.ZoneHint = zone
+	return q
+}
+
+// pathZone returns the zone of the paths matched by the query's COLL_NAME and COLL_PARENT_NAME conditions, or an
+// empty string if there are none or they span several zones
+func (q *Query) pathZone() string {
+	zone := ""
+
+	for _, cond := range q.Conditions {
+		if cond.Column != ColCollName && cond.Column != ColCollParentName {
+			continue
+		}
+
+		if cond.Operator != "=" && cond.Operator != "like" && cond.Operator != "in" {
+			continue
+		}
+
+		for _, value := range cond.Values {
+			z := PathZone(value)
+			if cond.Operator == "like" {
+				z = likeZone(z)
+			}
+
+			if z == "" || (zone != "" && zone != z) {
+				return ""
+			}
+
+			zone = z
+		}
+	}
+
+	return zone
+}
+
+// likeZone returns the zone matched by zone, the first element of a like pattern, or an empty string if it has a
+// % wildcard. Zone names often have underscores, so an _ is taken literally there, and a wildcard escaped with a
+// backslash is too.
+func likeZone(zone string) string {
+	name := make([]byte, 0, len(zone))
+
+	for i := 0; i < len(zone); i++ {
+		switch {
+		case zone[i] == '\\' && i+1 < len(zone):
+			i++
+			name = append(name, zone[i])
+		case zone[i] == '%':
+			return ""
+		default:
+			name = append(name, zone[i])
+		}
+	}
+
+	return string(name)
+}
+
+// Validate checks the query for unknown columns, unsupported operators and values that can't be expressed in GenQuery
+func (q *Query) Validate() error {
+	if len(q.Columns) == 0 {
+		return newError(Fatal, -1, "iRODS Query Failed: No columns selected")
+	}
+
+	selected := make(map[Column]bool)
+
+	for _, col := range q.Columns {
+		if !knownColumns[col] {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: Unknown column %v", col))
+		}
+		if selected[col] {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: Column %v selected twice", col))
+		}
+		selected[col] = true
+	}
+
+	for _, col := range append(append([]Column{}, q.Ascending...), q.Descending...) {
+		if !selected[col] {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: Can't order by %v, it isn't selected", col))
+		}
+	}
+
+	for _, cond := range q.Conditions {
+		if !knownColumns[cond.Column] {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: Unknown column %v", cond.Column))
+		}
+
+		if !knownOperators[cond.Operator] {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: Unsupported operator %q", cond.Operator))
+		}
+
+		if cond.Operator == "between" && len(cond.Values) != 2 {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: between on %v needs exactly 2 values", cond.Column))
+		}
+
+		if len(cond.Values) == 0 {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: No values for condition on %v", cond.Column))
+		}
+
+		for _, v := range cond.Values {
+			if strings.Contains(v, "'") {
+				return newError(Fatal, -1, fmt.Sprintf("iRODS Query Failed: Value %q for %v contains a single quote, which GenQuery can't escape", v, cond.Column))
+			}
+		}
+	}
+
+	if q.RowLimit < 0 || q.RowOffset < 0 {
+		return newError(Fatal, -1, "iRODS Query Failed: Limit and offset can't be negative")
+	}
+
+	return nil
+}
+
+// String renders the query in iquest syntax, e.g. "select COLL_NAME, order(DATA_NAME) where DATA_NAME like '%.bam'"
+func (q *Query) String() string {
+	ordering := make(map[Column]string)
+
+	for _, col := range q.Ascending {
+		ordering[col] = "order"
+	}
+	for _, col := range q.Descending {
+		ordering[col] = "order_desc"
+	}
+
+	selects := make([]string, len(q.Columns))
+
+	for i, col := range q.Columns {
+		if fn, ok := ordering[col]; ok {
+			selects[i] = fmt.Sprintf("%v(%v)", fn, col)
+		} else {
+			selects[i] = string(col)
+		}
+	}
+
+	str := "select " + strings.Join(selects, ", ")
+
+	if len(q.Conditions) > 0 {
+		conds := make([]string, len(q.Conditions))
+		for i, cond := range q.Conditions {
+			conds[i] = cond.String()
+		}
+
+		str += " where " + strings.Join(conds, " and ")
+	}
+
+	return str
+}
+
+// QueryResult holds the rows returned by Connection.Query. Values in each row are in the same order as Columns.
+type QueryResult struct {
+	Columns []Column
+	Rows    [][]string
+}
+
+// Len returns the number of rows
+func (res *QueryResult) Len() int {
+	return len(res.Rows)
+}
+
+// Get returns the value of col in row i, or an empty string if col wasn't selected
+func (res *QueryResult) Get(i int, col Column) string {
+	for n, c := range res.Columns {
+		if c == col {
+			return res.Rows[i][n]
+		}
+	}
+
+	return ""
+}
+
+// Maps returns the rows as maps of column name to value, like Connection.IQuest
+func (res *QueryResult) Maps() []map[string]string {
+	maps := make([]map[string]string, len(res.Rows))
+
+	for i, row := range res.Rows {
+		maps[i] = make(map[string]string)
+		for n, col := range res.Columns {
+			maps[i][string(col)] = row[n]
+		}
+	}
+
+	return maps
+}
+
+// Scan copies the rows into dest, which must be a pointer to a slice of structs (or struct pointers).
+// Struct fields are matched to columns with the `irods` tag, e.g. `irods:"DATA_NAME"`. Fields can be strings,
+// integers, unsigned integers, floats, bools or time.Time (parsed from iRODS' unix timestamp strings).
+func (res *QueryResult) Scan(dest interface{}) error {
+	slice := reflect.ValueOf(dest)
+
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return newError(Fatal, -1, "iRODS Query Scan Failed: dest must be a pointer to a slice")
+	}
+
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return newError(Fatal, -1, "iRODS Query Scan Failed: dest must be a slice of structs")
+	}
+
+	fields := fieldIndexes(elemType, res.Columns)
+
+	for _, row := range res.Rows {
+		elem := reflect.New(elemType).Elem()
+
+		if er := scanRow(elem, fields, res.Columns, row); er != nil {
+			return er
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem.Addr()))
+		} else {
+			slice.Set(reflect.Append(slice, elem))
+		}
+	}
+
+	return nil
+}
+
+// fieldIndexes maps the position of each column to the index of the struct field tagged with its name
+func fieldIndexes(structType reflect.Type, columns []Column) map[int]int {
+	fields := make(map[int]int)
+
+	for f := 0; f < structType.NumField(); f++ {
+		tag := structType.Field(f).Tag.Get("irods")
+		if tag == "" {
+			continue
+		}
+
+		for n, col := range columns {
+			if string(col) == tag {
+				fields[n] = f
+			}
+		}
+	}
+
+	return fields
+}
+
+// scanRow copies the values of row into the struct elem, using the mapping from fieldIndexes
+func scanRow(elem reflect.Value, fields map[int]int, columns []Column, row []string) error {
+	for n, f := range fields {
+		if er := setField(elem.Field(f), row[n]); er != nil {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Query Scan Failed: Column %v: %v", columns[n], er)).wrap(er)
+		}
+	}
+
+	return nil
+}
+
+// setField parses value into field, based on the field's type
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		if value == "" {
+			return nil
+		}
+
+		secs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.ValueOf(time.Unix(secs, 0)))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	case reflect.Bool:
+		field.SetBool(value == "1" || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes"))
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+
+	return nil
+}
+
+// ObjPath is a lightweight query result, identifying a data object or collection without fetching it from iRODS.
+// Type is either DataObjType or CollectionType. Use Connection.DataObject or Connection.Collection to load the full object.
+type ObjPath struct {
+	Path string
+	Type int
+}
+
+// String returns the path
+func (p *ObjPath) String() string {
+	return p.Path
+}
+
+// ObjPathFromRow builds an *ObjPath from a row containing COLL_NAME, and optionally DATA_NAME.
+// Rows with DATA_NAME are data objects, rows without are collections. Returns nil if COLL_NAME isn't selected.
+func ObjPathFromRow(columns []Column, row []string) *ObjPath {
+	var (
+		collName string
+		dataName string
+		hasColl  bool
+		hasData  bool
+	)
+
+	if row == nil {
+		return nil
+	}
+
+	for n, col := range columns {
+		switch col {
+		case ColCollName:
+			collName = row[n]
+			hasColl = true
+		case ColDataName:
+			dataName = row[n]
+			hasData = true
+		}
+	}
+
+	if !hasColl {
+		return nil
+	}
+
+	if hasData {
+		return &ObjPath{collName + "/" + dataName, DataObjType}
+	}
+
+	return &ObjPath{collName, CollectionType}
+}
+
+// NewDataObjMetaQuery returns a *Query selecting the path of every data object with an AVU named attr, whose value
+// compares to value using op. Use it with Connection.QueryCursor and QueryCursor.Path to page through matching paths.
+func NewDataObjMetaQuery(attr string, op string, value string) *Query {
+	return NewQuery(ColCollName, ColDataName).
+		Where(ColMetaDataAttrName, "=", attr).
+		Where(ColMetaDataAttrValue, op, value)
+}
+
+// NewCollMetaQuery returns a *Query selecting the path of every collection with an AVU named attr, whose value
+// compares to value using op. Use it with Connection.QueryCursor and QueryCursor.Path to page through matching paths.
+func NewCollMetaQuery(attr string, op string, value string) *Query {
+	return NewQuery(ColCollName).
+		Where(ColMetaCollAttrName, "=", attr).
+		Where(ColMetaCollAttrValue, op, value)
+}
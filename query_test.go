@@ -0,0 +1,141 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryString(t *testing.T) {
+
+	q := NewQuery(ColCollName, ColDataName, ColDataSize).
+		Where(ColMetaDataAttrName, "=", "project").
+		Where(ColDataName, "LIKE", "%.bam").
+		WhereIn(ColDataRescName, "demoResc", "archive").
+		WhereBetween(ColDataSize, "0", "1024").
+		OrderBy(ColCollName).
+		OrderByDesc(ColDataSize)
+
+	if err := q.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select order(COLL_NAME), DATA_NAME, order_desc(DATA_SIZE) where META_DATA_ATTR_NAME = 'project' and DATA_NAME like '%.bam' and DATA_RESC_NAME in ('demoResc','archive') and DATA_SIZE between '0' '1024'"
+
+	if str := q.String(); str != expected {
+		t.Errorf("Expected query %q, got %q", expected, str)
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+
+	invalid := map[string]*Query{
+		"no columns":       NewQuery(),
+		"unknown column":   NewQuery(Column("DATA_NAMEE")),
+		"unknown operator": NewQuery(ColDataName).Where(ColDataName, "~", "a"),
+		"unselected order": NewQuery(ColDataName).OrderBy(ColCollName),
+		"quote in value":   NewQuery(ColDataName).Where(ColDataName, "=", "it's"),
+		"negative limit":   NewQuery(ColDataName).Limit(-1),
+		"empty in":         NewQuery(ColDataName).WhereIn(ColDataName),
+	}
+
+	for name, q := range invalid {
+		if err := q.Validate(); err == nil {
+			t.Errorf("Expected validation error for %v", name)
+		}
+	}
+}
+
+func TestQueryResultScan(t *testing.T) {
+
+	type file struct {
+		Collection string    `irods:"COLL_NAME"`
+		Name       string    `irods:"DATA_NAME"`
+		Size       int64     `irods:"DATA_SIZE"`
+		Modified   time.Time `irods:"DATA_MODIFY_TIME"`
+		Ignored    string
+	}
+
+	res := &QueryResult{
+		Columns: []Column{ColCollName, ColDataName, ColDataSize, ColDataModifyTime},
+		Rows: [][]string{
+			{"/tempZone/home/rods", "a.bam", "1024", "01473969839"},
+			{"/tempZone/home/rods", "b.bam", "0", ""},
+		},
+	}
+
+	var files []file
+
+	if err := res.Scan(&files); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 rows, got %v", len(files))
+	}
+
+	if files[0].Name != "a.bam" || files[0].Size != 1024 || files[0].Modified.Unix() != 1473969839 {
+		t.Errorf("Unexpected scan result %+v", files[0])
+	}
+
+	if res.Get(1, ColDataName) != "b.bam" {
+		t.Errorf("Expected b.bam, got %v", res.Get(1, ColDataName))
+	}
+
+	var ptrs []*file
+
+	if err := res.Scan(&ptrs); err != nil || len(ptrs) != 2 || ptrs[1].Name != "b.bam" {
+		t.Errorf("Expected scan into pointer slice to work, got %v, %v", ptrs, err)
+	}
+
+	if err := res.Scan(files); err == nil {
+		t.Error("Expected error scanning into a non-pointer")
+	}
+}
+
+func TestObjPathFromRow(t *testing.T) {
+
+	cols := []Column{ColCollName, ColDataName}
+
+	if p := ObjPathFromRow(cols, []string{"/tempZone/home/rods", "hello.txt"}); p == nil || p.Path != "/tempZone/home/rods/hello.txt" || p.Type != DataObjType {
+		t.Errorf("Expected data object path, got %v", p)
+	}
+
+	if p := ObjPathFromRow([]Column{ColCollName}, []string{"/tempZone/home/rods"}); p == nil || p.Path != "/tempZone/home/rods" || p.Type != CollectionType {
+		t.Errorf("Expected collection path, got %v", p)
+	}
+
+	if p := ObjPathFromRow([]Column{ColDataName}, []string{"hello.txt"}); p != nil {
+		t.Errorf("Expected nil without COLL_NAME, got %v", p)
+	}
+
+	q := NewDataObjMetaQuery("wordCount", ">", "2")
+	expected := "select COLL_NAME, DATA_NAME where META_DATA_ATTR_NAME = 'wordCount' and META_DATA_ATTR_VALUE > '2'"
+
+	if str := q.String(); str != expected {
+		t.Errorf("Expected query %q, got %q", expected, str)
+	}
+}
+
+func TestConditionString(t *testing.T) {
+
+	tests := map[string]Condition{
+		"DATA_NAME in ('a','b')":         {Column: ColDataName, Operator: "in", Values: []string{"a", "b"}},
+		"DATA_NAME in ('a')":             {Column: ColDataName, Operator: "in", Values: []string{"a"}},
+		"DATA_SIZE between '1' '2'":      {Column: ColDataSize, Operator: "between", Values: []string{"1", "2"}},
+		"COLL_NAME like '/tempZone/%'":   {Column: ColCollName, Operator: "like", Values: []string{"/tempZone/%"}},
+		"META_DATA_ATTR_NAME = 'sample'": {Column: ColMetaDataAttrName, Operator: "=", Values: []string{"sample"}},
+	}
+
+	for expected, cond := range tests {
+		if str := cond.String(); str != expected {
+			t.Errorf("Expected %q, got %q", expected, str)
+		}
+	}
+
+	if str := NewQuery(ColDataName).WhereIn(ColDataRescName, "demoResc", "archive").String(); str != "select DATA_NAME where DATA_RESC_NAME in ('demoResc','archive')" {
+		t.Errorf("Expected the in condition without spaces between values, got %q", str)
+	}
+}
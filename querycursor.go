@@ -0,0 +1,281 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DefaultQueryPageSize is the number of rows fetched per round trip by Connection.QueryCursor, when no page size is given
+const DefaultQueryPageSize = 256
+
+// QueryCursor pages through the results of a *Query lazily, fetching one page of rows from the server at a time.
+// Iterate with Next, read the current row with Row, Get, Scan or Path, and check Err once Next returns false.
+// Close must be called if you stop early, so the server can release the statement. With several sessions (see
+// ConnectionOptions.Sessions), every page is fetched on the session the statement was opened on.
+//
+//	cur, err := con.QueryCursor(q, 0)
+//	if err != nil {
+//		return err
+//	}
+//	defer cur.Close()
+//
+//	for cur.Next() {
+//		fmt.Println(cur.Path())
+//	}
+//
+//	return cur.Err()
+type QueryCursor struct {
+	con     *Connection
+	query   *Query
+	columns []Column
+
+	cInp    *genQueryInp
+	handle  int
+	started bool
+
+	page  [][]string
+	index int
+	row   []string
+	count int
+
+	done   bool
+	err    error
+	mu     sync.Mutex
+	closed bool
+}
+
+// QueryCursor validates q and returns a *QueryCursor over its results. pageSize is the number of rows fetched
+// per round trip, 0 means DefaultQueryPageSize. Query.Limit and Query.Offset are honored.
+func (con *Connection) QueryCursor(q *Query, pageSize int) (*QueryCursor, error) {
+	if er := q.Validate(); er != nil {
+		return nil, er
+	}
+
+	if er := con.requireCcon("Query"); er != nil {
+		return nil, er
+	}
+
+	if pageSize <= 0 {
+		pageSize = DefaultQueryPageSize
+	}
+
+	if q.RowLimit > 0 && q.RowLimit < pageSize {
+		pageSize = q.RowLimit
+	}
+
+	zone, zErr := con.queryZone(q)
+	if zErr != nil {
+		return nil, zErr
+	}
+
+	cur := &QueryCursor{
+		con:     con,
+		query:   q,
+		columns: q.Columns,
+	}
+
+	er := con.retry(false, func() error {
+		ccon, er := con.getCcon()
+		if er != nil {
+			return er
+		}
+
+		defer con.returnCcon(ccon)
+
+		cInp, er := cGenQueryOpen(ccon, q, zone, pageSize)
+		if er != nil {
+			return transportError(er, fmt.Sprintf("iRODS Query Failed: %v", q))
+		}
+
+		// The statement only exists on the session it was opened on
+		cur.cInp = cInp
+		cur.handle = con.sessionHandle(ccon, 0)
+
+		return nil
+	})
+	if er != nil {
+		return nil, er
+	}
+
+	return cur, nil
+}
+
+// fetch reads the next page of rows from the server
+func (cur *QueryCursor) fetch(ctx context.Context) error {
+	cur.page = cur.page[:0]
+	cur.index = 0
+
+	ccon, _, cErr := cur.con.handleCconContext(ctx, cur.handle)
+	if cErr != nil {
+		return cErr
+	}
+
+	rows, more, er := cGenQueryNext(ccon, cur.cInp)
+	cur.con.unlockSession(ccon)
+
+	cur.started = true
+
+	// The server closed the statement, there's nothing left for Close to release
+	if er != nil || !more {
+		cGenQueryFree(cur.cInp)
+		cur.cInp = nil
+	}
+
+	if er != nil {
+		cur.done = true
+		if errors.Is(er, ErrNoRowsFound) {
+			return nil
+		}
+		return transportError(er, fmt.Sprintf("iRODS Query Failed: %v", cur.query))
+	}
+
+	cur.page = append(cur.page, rows...)
+	cur.done = !more
+
+	return nil
+}
+
+// Next advances to the next row, fetching another page from the server when needed. It returns false when
+// there are no more rows, the limit was reached, an error occurred or the cursor was closed.
+func (cur *QueryCursor) Next() bool {
+	return cur.NextContext(context.Background())
+}
+
+// NextContext is like Next, but stops waiting for the next page once ctx is cancelled or its deadline passes.
+// In that case it returns false, and Err returns ctx.Err(). The cursor must still be closed.
+func (cur *QueryCursor) NextContext(ctx context.Context) bool {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if cur.closed || cur.err != nil {
+		return false
+	}
+
+	if cur.query.RowLimit > 0 && cur.count >= cur.query.RowLimit {
+		cur.row = nil
+		return false
+	}
+
+	for cur.index >= len(cur.page) {
+		if cur.started && cur.done {
+			cur.row = nil
+			return false
+		}
+
+		if er := cur.fetch(ctx); er != nil {
+			cur.err = er
+			cur.row = nil
+			return false
+		}
+	}
+
+	cur.row = cur.page[cur.index]
+	cur.index++
+	cur.count++
+
+	return true
+}
+
+// Err returns the error that stopped iteration, if any
+func (cur *QueryCursor) Err() error {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	return cur.err
+}
+
+// Columns returns the selected columns, in the order values appear in Row
+func (cur *QueryCursor) Columns() []Column {
+	return cur.columns
+}
+
+// Row returns the values of the current row, in the order of the selected columns
+func (cur *QueryCursor) Row() []string {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	return cur.row
+}
+
+// Get returns the value of col in the current row, or an empty string if col wasn't selected
+func (cur *QueryCursor) Get(col Column) string {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	for n, c := range cur.columns {
+		if c == col && cur.row != nil {
+			return cur.row[n]
+		}
+	}
+
+	return ""
+}
+
+// Path returns the current row as a lightweight *ObjPath, without fetching the data object or collection from iRODS.
+// See ObjPathFromRow for the columns that need to be selected.
+func (cur *QueryCursor) Path() *ObjPath {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	return ObjPathFromRow(cur.columns, cur.row)
+}
+
+// Scan copies the current row into dest, which must be a pointer to a struct. See QueryResult.Scan for details.
+func (cur *QueryCursor) Scan(dest interface{}) error {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	elem := reflect.ValueOf(dest)
+
+	if elem.Kind() != reflect.Ptr || elem.Elem().Kind() != reflect.Struct {
+		return newError(Fatal, -1, "iRODS Query Scan Failed: dest must be a pointer to a struct")
+	}
+
+	if cur.row == nil {
+		return newError(Fatal, -1, "iRODS Query Scan Failed: No current row, call Next first")
+	}
+
+	elem = elem.Elem()
+
+	return scanRow(elem, fieldIndexes(elem.Type(), cur.columns), cur.columns, cur.row)
+}
+
+// Close stops the iteration early and releases the statement on the server, which is already done once the last page
+// was read or a page failed. It's safe to call Close more than once.
+func (cur *QueryCursor) Close() error {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if cur.closed {
+		return nil
+	}
+
+	cur.closed = true
+	cur.row = nil
+
+	if cur.cInp == nil {
+		return nil
+	}
+
+	cInp := cur.cInp
+	cur.cInp = nil
+
+	ccon, _, cErr := cur.con.handleCcon(cur.handle)
+	if cErr != nil {
+		cGenQueryFree(cInp)
+		return cErr
+	}
+	defer cur.con.unlockSession(ccon)
+
+	if er := cGenQueryClose(ccon, cInp); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS Query Close Failed: %v", cur.query))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+// cGenQueryOpen prepares q to be paged through pageSize rows at a time. Nothing is sent to the server until
+// cGenQueryNext is called.
+func cGenQueryOpen(ccon *rcComm, q *Query, zone string, pageSize int) (*genQueryInp, error) {
+	var (
+		err  *C.char
+		cInp *genQueryInp
+	)
+
+	cQueryString := C.CString(q.String())
+	cZoneName := C.CString(zone)
+	defer C.free(unsafe.Pointer(cZoneName))
+	defer C.free(unsafe.Pointer(cQueryString))
+
+	if status := C.gorods_gen_query_open(ccon, cQueryString, cBool(q.NoDistinct), cBool(q.UpperCase), cZoneName, C.int(q.RowOffset), C.int(pageSize), &cInp, &err); status != 0 {
+		return nil, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return cInp, nil
+}
+
+// cGenQueryNext fetches the next page of rows of cInp. more is false once the server has no rows left.
+func cGenQueryNext(ccon *rcComm, cInp *genQueryInp) (rows [][]string, more bool, er error) {
+	var (
+		result C.goRodsGenQueryResult_t
+		err    *C.char
+	)
+
+	result.rowSize = C.int(0)
+	result.attrSize = C.int(0)
+
+	defer C.gorods_free_gen_query_result(&result)
+
+	if status := C.gorods_gen_query_next(ccon, cInp, &result, &err); status != 0 {
+		return nil, false, NewTransportError(int(status), C.GoString(err))
+	}
+
+	return genQueryRows(&result), int(cInp.continueInx) > 0, nil
+}
+
+// cGenQueryClose releases the statement of cInp on the server if rows are still pending, then frees cInp
+func cGenQueryClose(ccon *rcComm, cInp *genQueryInp) error {
+	var err *C.char
+
+	if status := C.gorods_gen_query_close(ccon, cInp, &err); status != 0 {
+		return NewTransportError(int(status), C.GoString(err))
+	}
+
+	return nil
+}
+
+// cGenQueryFree frees cInp without contacting the server, once the statement is closed or its session is gone
+func cGenQueryFree(cInp *genQueryInp) {
+	C.gorods_gen_query_free(cInp)
+}
@@ -0,0 +1,319 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Quota is a storage limit set on a user or group, like iquota reports. Resource is empty for total quotas, which
+// count the data stored on all resources. Over is the number of bytes stored above Limit, and is negative while
+// there's room left. It's computed by the server when usage is calculated (see Connection.CalculateQuotaUsage), so
+// it can be out of date.
+type Quota struct {
+	Owner      string
+	OwnerZone  string
+	Group      bool
+	Resource   string
+	Limit      int64
+	Over       int64
+	ModifyTime time.Time
+}
+
+// IsTotal returns true if the quota counts the data on all resources
+func (q *Quota) IsTotal() bool {
+	return q.Resource == ""
+}
+
+// Exceeded returns true if more than Limit bytes were stored when usage was last calculated
+func (q *Quota) Exceeded() bool {
+	return q.Over > 0
+}
+
+// Usage returns the number of bytes counted against the quota when usage was last calculated
+func (q *Quota) Usage() int64 {
+	return q.Limit + q.Over
+}
+
+// Available returns the number of bytes that can still be stored before the quota is exceeded
+func (q *Quota) Available() int64 {
+	if q.Over >= 0 {
+		return 0
+	}
+
+	return -q.Over
+}
+
+// String returns the quota in a iquota like format
+func (q *Quota) String() string {
+	resource := q.Resource
+	if q.IsTotal() {
+		resource = "total"
+	}
+
+	return fmt.Sprintf("%v#%v:%v: limit %v, over %v", q.Owner, q.OwnerZone, resource, q.Limit, q.Over)
+}
+
+// Quotas is a slice of *Quota
+type Quotas []*Quota
+
+// Total returns the total quota of owner (a user or group name), or nil if there's none
+func (qs Quotas) Total(owner string) *Quota {
+	for _, q := range qs {
+		if q.Owner == owner && q.IsTotal() {
+			return q
+		}
+	}
+
+	return nil
+}
+
+// FindByResource returns the quota of owner (a user or group name) on resource, or nil if there's none
+func (qs Quotas) FindByResource(owner string, resource string) *Quota {
+	for _, q := range qs {
+		if q.Owner == owner && q.Resource == resource {
+			return q
+		}
+	}
+
+	return nil
+}
+
+// Exceeded returns the quotas that were exceeded when usage was last calculated
+func (qs Quotas) Exceeded() Quotas {
+	response := make(Quotas, 0)
+
+	for _, q := range qs {
+		if q.Exceeded() {
+			response = append(response, q)
+		}
+	}
+
+	return response
+}
+
+// WouldExceed returns the first quota that storing size more bytes on resource would exceed, or nil if they all
+// have room left. Total quotas apply to every resource. Use it to warn users before an upload is refused.
+func (qs Quotas) WouldExceed(resource string, size int64) *Quota {
+	for _, q := range qs {
+		if (q.IsTotal() || q.Resource == resource) && size > q.Available() {
+			return q
+		}
+	}
+
+	return nil
+}
+
+// QuotaUsage is the number of bytes a user stores on a resource, as counted by the last usage calculation
+type QuotaUsage struct {
+	Resource   string
+	Usage      int64
+	ModifyTime time.Time
+}
+
+// QuotaUsages is a slice of *QuotaUsage
+type QuotaUsages []*QuotaUsage
+
+// Total returns the number of bytes stored on all resources
+func (us QuotaUsages) Total() int64 {
+	var total int64
+
+	for _, u := range us {
+		total += u.Usage
+	}
+
+	return total
+}
+
+// Quotas returns every quota set in the zone, for users and groups.
+// You must have the proper rodsadmin privileges to see other users' quotas.
+func (con *Connection) Quotas() (Quotas, error) {
+	return con.fetchQuotas(NewQuery(quotaColumns...))
+}
+
+// CalculateQuotaUsage makes the server count the data stored by each user on each resource, and update how much
+// each quota is over its limit, like iadmin cu. You must have the proper rodsadmin privileges to use this function.
+func (con *Connection) CalculateQuotaUsage() error {
+	return con.generalAdmin("Calculate Quota Usage", "calculate-usage")
+}
+
+// Quotas returns the quotas that apply to the user: its own quotas, and those of the groups it's a member of
+func (usr *User) Quotas() (Quotas, error) {
+	grps, err := usr.Groups()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []string{usr.name}
+	for _, grp := range grps {
+		owners = append(owners, grp.Name())
+	}
+
+	return usr.con.fetchQuotas(NewQuery(quotaColumns...).WhereIn(ColQuotaUserName, owners...))
+}
+
+// QuotaUsage returns the number of bytes the user stores on each resource, as counted by the last usage
+// calculation (see Connection.CalculateQuotaUsage)
+func (usr *User) QuotaUsage() (QuotaUsages, error) {
+	id, err := usr.Id()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := usr.con.Query(NewQuery(ColQuotaUsageRescId, ColQuotaUsage, ColQuotaUsageModifyTime).
+		Where(ColQuotaUsageUserId, "=", strconv.Itoa(id)))
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(QuotaUsages, 0, res.Len())
+
+	for i := range res.Rows {
+		resource, err := usr.con.quotaResource(res.Get(i, ColQuotaUsageRescId))
+		if err != nil {
+			return nil, err
+		}
+
+		u := &QuotaUsage{
+			Resource:   resource,
+			ModifyTime: timeStringToTime(res.Get(i, ColQuotaUsageModifyTime)),
+		}
+
+		u.Usage, _ = strconv.ParseInt(res.Get(i, ColQuotaUsage), 10, 64)
+
+		response = append(response, u)
+	}
+
+	sort.Slice(response, func(i, j int) bool { return response[i].Resource < response[j].Resource })
+
+	return response, nil
+}
+
+// SetQuota limits the number of bytes the user can store on resource (a string or *Resource), or on all resources
+// when resource is nil or "", like iadmin suq. A limit of 0 removes the quota.
+// You must have the proper rodsadmin privileges to use this function.
+func (usr *User) SetQuota(resource interface{}, limit int64) error {
+	return usr.con.setQuota("user", usr.name, resource, limit)
+}
+
+// ClearQuota removes the user's quota on resource (a string or *Resource), or its total quota when resource is nil
+// or "". You must have the proper rodsadmin privileges to use this function.
+func (usr *User) ClearQuota(resource interface{}) error {
+	return usr.SetQuota(resource, 0)
+}
+
+// Quotas returns the quotas set on the group. Group quotas count the data stored by all its members.
+func (grp *Group) Quotas() (Quotas, error) {
+	return grp.con.fetchQuotas(NewQuery(quotaColumns...).Where(ColQuotaUserName, "=", grp.name))
+}
+
+// SetQuota limits the number of bytes the members of the group can store on resource (a string or *Resource), or
+// on all resources when resource is nil or "", like iadmin sgq. A limit of 0 removes the quota.
+// You must have the proper rodsadmin privileges to use this function.
+func (grp *Group) SetQuota(resource interface{}, limit int64) error {
+	return grp.con.setQuota("group", grp.name, resource, limit)
+}
+
+// ClearQuota removes the group's quota on resource (a string or *Resource), or its total quota when resource is
+// nil or "". You must have the proper rodsadmin privileges to use this function.
+func (grp *Group) ClearQuota(resource interface{}) error {
+	return grp.SetQuota(resource, 0)
+}
+
+// Quotas returns the quotas of users and groups on the resource. Total quotas aren't included.
+func (resc *Resource) Quotas() (Quotas, error) {
+	id, err := resc.Id()
+	if err != nil {
+		return nil, err
+	}
+
+	return resc.con.fetchQuotas(NewQuery(quotaColumns...).Where(ColQuotaRescId, "=", strconv.Itoa(id)))
+}
+
+// quotaColumns are selected by fetchQuotas. QUOTA_RESC_NAME isn't, as joining the resource table drops the total
+// quotas, whose resource id is 0.
+var quotaColumns = []Column{
+	ColQuotaUserName, ColQuotaUserZone, ColQuotaUserType, ColQuotaRescId, ColQuotaLimit, ColQuotaOver, ColQuotaModifyTime,
+}
+
+// fetchQuotas runs q, which selects quotaColumns, and returns the quotas sorted by owner, with total quotas first
+func (con *Connection) fetchQuotas(q *Query) (Quotas, error) {
+	res, err := con.Query(q)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(Quotas, 0, res.Len())
+
+	for i := range res.Rows {
+		resource, err := con.quotaResource(res.Get(i, ColQuotaRescId))
+		if err != nil {
+			return nil, err
+		}
+
+		q := &Quota{
+			Owner:      res.Get(i, ColQuotaUserName),
+			OwnerZone:  res.Get(i, ColQuotaUserZone),
+			Group:      res.Get(i, ColQuotaUserType) == "rodsgroup",
+			Resource:   resource,
+			ModifyTime: timeStringToTime(res.Get(i, ColQuotaModifyTime)),
+		}
+
+		q.Limit, _ = strconv.ParseInt(res.Get(i, ColQuotaLimit), 10, 64)
+		q.Over, _ = strconv.ParseInt(res.Get(i, ColQuotaOver), 10, 64)
+
+		response = append(response, q)
+	}
+
+	sort.SliceStable(response, func(i, j int) bool {
+		if response[i].Owner != response[j].Owner {
+			return response[i].Owner < response[j].Owner
+		}
+		if response[i].IsTotal() != response[j].IsTotal() {
+			return response[i].IsTotal()
+		}
+		return response[i].Resource < response[j].Resource
+	})
+
+	return response, nil
+}
+
+// quotaResource returns the name of the resource with the id rescId, or an empty string for total quotas (id 0)
+func (con *Connection) quotaResource(rescId string) (string, error) {
+	if rescId == "" || rescId == "0" {
+		return "", nil
+	}
+
+	resc, err := con.resourceByRef(rescId)
+	if err != nil {
+		return "", err
+	}
+
+	if resc == nil {
+		return rescId, nil
+	}
+
+	return resc.Name(), nil
+}
+
+// setQuota sets the quota of a user or group (typ), on a resource or in total
+func (con *Connection) setQuota(typ string, name string, resource interface{}, limit int64) error {
+	if limit < 0 {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Set Quota Failed: Invalid limit %v for %v", limit, name))
+	}
+
+	rescName, err := resourceName(resource)
+	if err != nil {
+		return err
+	}
+
+	if rescName == "" {
+		rescName = "total"
+	}
+
+	return con.generalAdmin("Set Quota", "set-quota", typ, name, rescName, strconv.FormatInt(limit, 10))
+}
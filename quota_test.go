@@ -0,0 +1,169 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestQuotas(t *testing.T) {
+	qs := Quotas{
+		{Owner: "alice", Limit: 1000, Over: -400},
+		{Owner: "alice", Resource: "demoResc", Limit: 500, Over: -50},
+		{Owner: "lab", Group: true, Resource: "archive", Limit: 100, Over: 20},
+	}
+
+	if q := qs.Total("alice"); q == nil || q.Usage() != 600 || q.Available() != 400 {
+		t.Errorf("Expected alice's total quota with 600 bytes used, got %v", q)
+	}
+
+	if q := qs.FindByResource("alice", "demoResc"); q == nil || q.Available() != 50 {
+		t.Errorf("Expected alice's quota on demoResc, got %v", q)
+	}
+
+	if exceeded := qs.Exceeded(); len(exceeded) != 1 || exceeded[0].Owner != "lab" || exceeded[0].Available() != 0 {
+		t.Errorf("Expected lab's quota to be exceeded, got %v", exceeded)
+	}
+
+	for _, c := range []struct {
+		resource string
+		size     int64
+		expected *Quota
+	}{
+		{"demoResc", 50, nil},
+		{"demoResc", 51, qs[1]},
+		{"otherResc", 400, nil},
+		{"otherResc", 401, qs[0]},
+		{"archive", 1, qs[2]},
+	} {
+		if q := qs.WouldExceed(c.resource, c.size); q != c.expected {
+			t.Errorf("Expected storing %v bytes on %v to exceed %v, got %v", c.size, c.resource, c.expected, q)
+		}
+	}
+
+	if s := qs[0].String(); s != "alice#:total: limit 1000, over -400" {
+		t.Errorf("Unexpected quota string %q", s)
+	}
+
+	if total := (QuotaUsages{{Resource: "a", Usage: 10}, {Resource: "b", Usage: 5}}).Total(); total != 15 {
+		t.Errorf("Expected a total usage of 15, got %v", total)
+	}
+}
+
+func TestQuotaAdmin(t *testing.T) {
+	con := memConnection(t)
+
+	usr, err := con.CreateUser("alice", UserType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := usr.SetQuota("demoResc", -1); err == nil || errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected a negative limit to be rejected, got %v", err)
+	}
+
+	if err := usr.SetQuota(nil, 1000); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+
+	if err := con.CalculateQuotaUsage(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+
+	if _, err := usr.Quotas(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+// adminTransport records the iadmin like operations it runs
+type adminTransport struct {
+	Transport
+	args [][]string
+}
+
+func (t *adminTransport) GeneralAdmin(args ...string) error {
+	t.args = append(t.args, args)
+	return nil
+}
+
+func TestQuotaAdminTransport(t *testing.T) {
+	con := memConnection(t)
+
+	admin := &adminTransport{Transport: con.transport}
+	con.transport = admin
+
+	if err := con.CalculateQuotaUsage(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(admin.args) != 1 || len(admin.args[0]) != 10 || admin.args[0][0] != "calculate-usage" {
+		t.Errorf("Expected the Transport to run calculate-usage, got %q", admin.args)
+	}
+}
+
+func TestQuotaAdminArgs(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		for _, args := range [][]string{
+			{"set-quota", "user", "alice", "demoResc", "1000"},
+			{"set-quota", "user", "alice", "total", "5000"},
+			{"set-quota", "group", "lab", "total", "0"},
+			{"calculate-usage"},
+		} {
+			if err := expectGeneralAdmin(s, args...); err != nil {
+				return err
+			}
+		}
+
+		selects := []int{2021, 2022, 2023, 2001, 2002, 2003, 2004}
+
+		for _, conds := range []map[int]string{{}, {2021: "= 'lab'"}} {
+			inp, err := expectQuery(s, conds)
+			if err != nil {
+				return err
+			}
+
+			if !reflect.DeepEqual(inp.Selects.Inx, selects) {
+				return fmt.Errorf("Expected the quota columns %v, got %v", selects, inp.Selects.Inx)
+			}
+
+			if err := s.ReplyRows(inp, [][]string{{"lab", "tempZone", "rodsgroup", "0", "1000", "-200", "01500000000"}}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	con := protoConnection(transport)
+	usr := &User{name: "alice", con: con}
+	grp := &Group{name: "lab", con: con}
+
+	for _, err := range []error{
+		usr.SetQuota("demoResc", 1000),
+		usr.SetQuota(nil, 5000),
+		grp.ClearQuota(""),
+		con.CalculateQuotaUsage(),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if qs, err := con.Quotas(); err != nil || len(qs) != 1 || !qs[0].Group || qs[0].Resource != "" || qs[0].Limit != 1000 || qs[0].Usage() != 800 {
+		t.Errorf("Expected lab's total quota, got %v (%v)", qs, err)
+	}
+
+	if qs, err := grp.Quotas(); err != nil || len(qs) != 1 || qs[0].Owner != "lab" {
+		t.Errorf("Expected lab's quota, got %v (%v)", qs, err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,326 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Replica statuses, as returned by Replica.Status. ReplicaIntermediate is used by iRODS 4.2.9 and later for
+// replicas being written.
+const (
+	ReplicaStale        = 0
+	ReplicaGood         = 1
+	ReplicaIntermediate = 2
+)
+
+// Replica describes one copy of a data object, stored on a resource. Use DataObj to read it.
+type Replica struct {
+	num        int
+	resource   string
+	rescHier   string
+	status     int
+	checksum   string
+	size       int64
+	phyPath    string
+	createTime time.Time
+	modifyTime time.Time
+
+	obj *DataObj
+}
+
+// Replicas is a slice of *Replica.
+type Replicas []*Replica
+
+// FindByNum returns the replica with the replica number num, or nil
+func (repls Replicas) FindByNum(num int) *Replica {
+	for _, r := range repls {
+		if r.num == num {
+			return r
+		}
+	}
+	return nil
+}
+
+// FindByResource returns the first good replica stored on the resource name, or nil. name can be the resource the
+// replica is registered with, or any resource of its hierarchy.
+func (repls Replicas) FindByResource(name string) *Replica {
+	var stale *Replica
+
+	for _, r := range repls {
+		if !r.OnResource(name) {
+			continue
+		}
+
+		if r.IsGood() {
+			return r
+		}
+
+		if stale == nil {
+			stale = r
+		}
+	}
+
+	return stale
+}
+
+// Good returns the replicas that are up to date
+func (repls Replicas) Good() Replicas {
+	response := make(Replicas, 0)
+
+	for _, r := range repls {
+		if r.IsGood() {
+			response = append(response, r)
+		}
+	}
+
+	return response
+}
+
+// String returns the path of the data object, the replica number and the resource hierarchy
+func (r *Replica) String() string {
+	return fmt.Sprintf("Replica: %v #%v (%v)", r.obj.path, r.num, r.rescHier)
+}
+
+// Num returns the replica number
+func (r *Replica) Num() int {
+	return r.num
+}
+
+// ResourceName returns the name of the resource the replica is registered with
+func (r *Replica) ResourceName() string {
+	return r.resource
+}
+
+// Resource returns the *Resource the replica is registered with, or nil if it isn't in Connection.Resources
+func (r *Replica) Resource() *Resource {
+	if rsrcs, err := r.obj.con.Resources(); err == nil {
+		return rsrcs.FindByName(r.resource)
+	}
+
+	return nil
+}
+
+// RescHier returns the resource hierarchy of the replica, from the root resource to the storage resource,
+// separated with ";"
+func (r *Replica) RescHier() string {
+	return r.rescHier
+}
+
+// OnResource returns true if the replica is registered with the resource name, or name is in its hierarchy
+func (r *Replica) OnResource(name string) bool {
+	if r.resource == name {
+		return true
+	}
+
+	for _, resc := range strings.Split(r.rescHier, ";") {
+		if resc == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Status returns ReplicaGood, ReplicaStale or ReplicaIntermediate
+func (r *Replica) Status() int {
+	return r.status
+}
+
+// IsGood returns true if the replica is up to date
+func (r *Replica) IsGood() bool {
+	return r.status == ReplicaGood
+}
+
+// Checksum returns the checksum registered for the replica, if any
+func (r *Replica) Checksum() string {
+	return r.checksum
+}
+
+// Size returns the size in bytes of the replica
+func (r *Replica) Size() int64 {
+	return r.size
+}
+
+// PhyPath returns the location of the replica on its storage resource
+func (r *Replica) PhyPath() string {
+	return r.phyPath
+}
+
+// CreateTime returns the create time of the replica
+func (r *Replica) CreateTime() time.Time {
+	return r.createTime
+}
+
+// ModifyTime returns the modify time of the replica
+func (r *Replica) ModifyTime() time.Time {
+	return r.modifyTime
+}
+
+// DataObj returns a copy of the data object bound to the replica. Its handle isn't opened yet, reading it (or
+// downloading it) uses the replica's data, and its ReplNum, RescHier, Checksum and Size are the replica's.
+func (r *Replica) DataObj() *DataObj {
+	w := r.obj.withConnection(r.obj.con)
+
+	w.replNum = r.num
+	w.rescHier = r.rescHier
+	w.replStatus = r.status
+	w.checksum = r.checksum
+	w.size = r.size
+	w.phyPath = r.phyPath
+	w.createTime = r.createTime
+	w.modifyTime = r.modifyTime
+
+	// The replica is opened on the root resource of its hierarchy
+	w.resource = nil
+	if rsrcs, err := r.obj.con.Resources(); err == nil {
+		w.resource = rsrcs.FindByName(strings.Split(r.rescHier, ";")[0])
+	}
+
+	return w
+}
+
+// replicaColumns are the columns Replicas selects
+var replicaColumns = []Column{
+	ColDataReplNum, ColDataRescName, ColDataRescHier, ColDataReplStatus, ColDataChecksum, ColDataSize, ColDataPath,
+	ColDataCreateTime, ColDataModifyTime,
+}
+
+// Replicas returns every replica of the data object, sorted by replica number
+func (obj *DataObj) Replicas() (Replicas, error) {
+	response, err := obj.fetchReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response) == 0 {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Replicas DataObject Failed: %v, no replicas found", obj.path)).withPath(obj.path).wrap(ErrNotFound)
+	}
+
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].num < response[j].num
+	})
+
+	return response, nil
+}
+
+// fetchReplicas queries the replicas of the data object. Transports without GenQuery list its collection instead.
+func (obj *DataObj) fetchReplicas() (Replicas, error) {
+	con := obj.con
+
+	if _, ok := unwrapTransport(con.transport).(genQuerier); !ok {
+		return obj.listReplicas()
+	}
+
+	res, err := con.Query(NewQuery(replicaColumns...).
+		Where(ColCollName, "=", filepath.Dir(obj.path)).
+		Where(ColDataName, "=", filepath.Base(obj.path)))
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(Replicas, 0, res.Len())
+
+	for i := range res.Rows {
+		r := &Replica{
+			resource:   res.Get(i, ColDataRescName),
+			rescHier:   res.Get(i, ColDataRescHier),
+			checksum:   res.Get(i, ColDataChecksum),
+			phyPath:    res.Get(i, ColDataPath),
+			createTime: timeStringToTime(res.Get(i, ColDataCreateTime)),
+			modifyTime: timeStringToTime(res.Get(i, ColDataModifyTime)),
+			obj:        obj,
+		}
+
+		r.num, _ = strconv.Atoi(res.Get(i, ColDataReplNum))
+		r.status, _ = strconv.Atoi(res.Get(i, ColDataReplStatus))
+		r.size, _ = strconv.ParseInt(res.Get(i, ColDataSize), 10, 64)
+
+		response = append(response, r)
+	}
+
+	return response, nil
+}
+
+// listReplicas picks the replicas of the data object out of the listing of its collection
+func (obj *DataObj) listReplicas() (Replicas, error) {
+	entries, err := obj.con.transport.List(filepath.Dir(obj.path), false)
+	if err != nil {
+		return nil, transportError(err, fmt.Sprintf("iRODS Replicas DataObject Failed: %v", obj.path)).withPath(obj.path)
+	}
+
+	response := make(Replicas, 0)
+
+	for _, entry := range entries {
+		if entry.Type != DataObjType || entry.Path != obj.path {
+			continue
+		}
+
+		response = append(response, &Replica{
+			num:        entry.ReplNum,
+			resource:   entry.Resource,
+			rescHier:   entry.RescHier,
+			status:     entry.ReplStatus,
+			checksum:   entry.Checksum,
+			size:       entry.Size,
+			phyPath:    entry.PhyPath,
+			createTime: entry.CreateTime,
+			modifyTime: entry.ModifyTime,
+			obj:        obj,
+		})
+	}
+
+	return response, nil
+}
+
+// OpenReplica returns a copy of the data object bound to the replica num (see Replica.DataObj), opened for reading
+func (obj *DataObj) OpenReplica(num int) (*DataObj, error) {
+	repls, err := obj.Replicas()
+	if err != nil {
+		return nil, err
+	}
+
+	r := repls.FindByNum(num)
+	if r == nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Open DataObject Failed: %v has no replica %v", obj.path, num)).withPath(obj.path).wrap(ErrNotFound)
+	}
+
+	return openReplica(r)
+}
+
+// OpenResource returns a copy of the data object bound to its replica on resource (see Replicas.FindByResource),
+// opened for reading. resource is a string or *Resource type.
+func (obj *DataObj) OpenResource(resource interface{}) (*DataObj, error) {
+	name, err := resourceName(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	repls, err := obj.Replicas()
+	if err != nil {
+		return nil, err
+	}
+
+	r := repls.FindByResource(name)
+	if r == nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Open DataObject Failed: %v has no replica on %v", obj.path, name)).withPath(obj.path).wrap(ErrNotFound)
+	}
+
+	return openReplica(r)
+}
+
+func openReplica(r *Replica) (*DataObj, error) {
+	w := r.DataObj()
+
+	if err := w.Open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
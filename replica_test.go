@@ -0,0 +1,131 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestReplicasFind(t *testing.T) {
+	repls := Replicas{
+		{num: 0, resource: "demoResc", rescHier: "demoResc", status: ReplicaGood},
+		{num: 1, resource: "replResc", rescHier: "replResc;child1", status: ReplicaStale},
+		{num: 2, resource: "replResc", rescHier: "replResc;child2", status: ReplicaGood},
+	}
+
+	if r := repls.FindByNum(1); r == nil || r.RescHier() != "replResc;child1" {
+		t.Errorf("Expected replica 1, got %v", r)
+	}
+
+	if r := repls.FindByResource("replResc"); r == nil || r.Num() != 2 {
+		t.Errorf("Expected the good replica on replResc, got %v", r)
+	}
+
+	if r := repls.FindByResource("child1"); r == nil || r.Num() != 1 || r.IsGood() {
+		t.Errorf("Expected the stale replica on child1, got %v", r)
+	}
+
+	if r := repls.FindByResource("missing"); r != nil {
+		t.Errorf("Expected no replica, got %v", r)
+	}
+
+	if good := repls.Good(); len(good) != 2 || good[0].Num() != 0 || good[1].Num() != 2 {
+		t.Errorf("Expected replicas 0 and 2 to be good, got %v", good)
+	}
+}
+
+func TestDataObjReplicas(t *testing.T) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.CreateDataObj(DataObjOptions{Name: "hello.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := obj.Write([]byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	obj.Close()
+
+	repls, err := obj.Replicas()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repls) != 1 || repls[0].Num() != 0 || !repls[0].IsGood() || repls[0].Size() != 11 || repls[0].ResourceName() != "demoResc" {
+		t.Fatalf("Expected a single good replica on demoResc, got %v", repls)
+	}
+
+	if repls[0].Resource() == nil || repls[0].PhyPath() == "" {
+		t.Errorf("Expected the replica's resource and physical path, got %v", repls[0])
+	}
+
+	repl, err := obj.OpenResource("demoResc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := repl.ReadBytes(6, 5); err != nil || string(data) != "world" {
+		t.Errorf("Expected to read the replica, got %q (%v)", data, err)
+	}
+	repl.Close()
+
+	if _, err := obj.OpenReplica(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound opening a missing replica, got %v", err)
+	}
+
+	if _, err := obj.OpenResource("otherResc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound opening a replica on another resource, got %v", err)
+	}
+}
+
+func TestDataObjReplicasQuery(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		inp, err := expectQuery(s, map[int]string{
+			irodsproto.ColCollName: "= '/tempZone/home/rods'",
+			irodsproto.ColDataName: "= 'hello.txt'",
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.ReplyRows(inp, [][]string{
+			{"1", "otherResc", "otherResc", "0", "", "11", "/var/lib/other/hello.txt", "01500000000", "01500000001"},
+			{"0", "demoResc", "demoResc", "1", "sha2:abc", "11", "/var/lib/irods/hello.txt", "01500000000", "01500000002"},
+		})
+	})
+
+	con := protoConnection(transport)
+	obj := &DataObj{path: "/tempZone/home/rods/hello.txt", con: con}
+
+	repls, err := obj.Replicas()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repls) != 2 || repls[0].Num() != 0 || repls[1].Num() != 1 {
+		t.Fatalf("Expected replicas 0 and 1, got %v", repls)
+	}
+
+	if r := repls[0]; !r.IsGood() || r.ResourceName() != "demoResc" || r.Checksum() != "sha2:abc" || r.Size() != 11 ||
+		r.PhyPath() != "/var/lib/irods/hello.txt" || r.ModifyTime().Unix() != 1500000002 {
+		t.Errorf("Expected the good replica on demoResc, got %+v", r)
+	}
+
+	if r := repls[1]; r.IsGood() || r.RescHier() != "otherResc" {
+		t.Errorf("Expected the stale replica on otherResc, got %+v", r)
+	}
+}
@@ -3,15 +3,10 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
-	"unsafe"
 )
 
 // Resource holds information about a resource server registered with iCAT.
@@ -165,8 +160,9 @@ func (resc *Resource) Class() (int, error) {
 	return resc.class, nil
 }
 
-// Children loads data from iCAT if needed, and returns the resources children attribute.
-func (resc *Resource) Children() (string, error) {
+// ChildrenStr loads data from iCAT if needed, and returns the resources children attribute. It's only set by
+// iRODS 4.1, as "name{context};name{context}". Use Children to get the child resources.
+func (resc *Resource) ChildrenStr() (string, error) {
 	if err := resc.init(); err != nil {
 		return resc.children, err
 	}
@@ -197,7 +193,8 @@ func (resc *Resource) Status() (string, error) {
 	return resc.status, nil
 }
 
-// ParentStr loads data from iCAT if needed, and returns the resources parentStr attribute.
+// ParentStr loads data from iCAT if needed, and returns the resources parentStr attribute. It's the name of the
+// parent resource with iRODS 4.1, and its id with later versions. Use Parent to get the parent resource.
 func (resc *Resource) ParentStr() (string, error) {
 	if err := resc.init(); err != nil {
 		return resc.parentStr, err
@@ -318,49 +315,9 @@ func (resc *Resource) RefreshInfo() error {
 
 // FetchInfo fetches fresh resource info from the iCAT server and returns it as a map
 func (resc *Resource) FetchInfo() (map[string]string, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
-
-	cResource := C.CString(resc.name)
-	defer C.free(unsafe.Pointer(cResource))
-
-	ccon := resc.con.GetCcon()
-
-	if status := C.gorods_get_resource(cResource, ccon, &result, &err); status != 0 {
-		resc.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Resource Info Failed: %v", C.GoString(err)))
-	}
-
-	resc.con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
-	response := make(map[string]string)
-
-	for _, resourceInfo := range slice {
-
-		resourceAttributes := strings.Split(strings.Trim(C.GoString(resourceInfo), " \n"), "\n")
-
-		for _, attr := range resourceAttributes {
-
-			split := strings.Split(attr, ": ")
-
-			attrName := split[0]
-			attrVal := split[1]
-
-			response[attrName] = attrVal
-
-		}
+	response, er := resc.con.transport.ResourceInfo(resc.name)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Resource Info Failed")
 	}
 
 	return response, nil
@@ -0,0 +1,180 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Resource statuses, used in ResourceModifyOptions.Status
+const (
+	ResourceUp   = "up"
+	ResourceDown = "down"
+)
+
+// ResourceOptions describe the resource created by Connection.CreateResource, like the arguments of iadmin mkresc.
+// Host and VaultPath are where storage resources keep their data, composite resources don't need them. Context is
+// a "key=value;key=value" string of plugin options.
+type ResourceOptions struct {
+	Name      string
+	Kind      ResourceKind
+	Host      string
+	VaultPath string
+	Context   string
+}
+
+// ResourceModifyOptions lists the attributes changed by Resource.Modify, like iadmin modresc. Empty fields are left
+// unchanged. Status is ResourceUp or ResourceDown. FreeSpace is a number of bytes, or "+n" / "-n" to adjust it.
+type ResourceModifyOptions struct {
+	Host      string
+	VaultPath string
+	Status    string
+	Context   string
+	Comment   string
+	Info      string
+	FreeSpace string
+}
+
+// modifications returns the iadmin modresc option and value of each attribute to change, in a stable order
+func (opts ResourceModifyOptions) modifications() ([][2]string, error) {
+	mods := make([][2]string, 0)
+
+	for _, m := range [][2]string{
+		{"host", opts.Host},
+		{"path", opts.VaultPath},
+		{"status", opts.Status},
+		{"context", opts.Context},
+		{"comment", opts.Comment},
+		{"info", opts.Info},
+		{"free_space", opts.FreeSpace},
+	} {
+		if m[1] != "" {
+			mods = append(mods, m)
+		}
+	}
+
+	if opts.Status != "" && opts.Status != ResourceUp && opts.Status != ResourceDown {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Modify Resource Failed: Status must be %q or %q, got %q", ResourceUp, ResourceDown, opts.Status))
+	}
+
+	if opts.FreeSpace != "" {
+		if _, err := strconv.ParseInt(opts.FreeSpace, 10, 64); err != nil {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Modify Resource Failed: Invalid FreeSpace %q", opts.FreeSpace)).wrap(err)
+		}
+	}
+
+	return mods, nil
+}
+
+// CreateResource creates a resource in the local zone, like iadmin mkresc, and returns it.
+// You must have the proper rodsadmin privileges to use this function.
+func (con *Connection) CreateResource(opts ResourceOptions) (*Resource, error) {
+	if opts.Name == "" || opts.Kind == "" {
+		return nil, newError(Fatal, -1, "iRODS Create Resource Failed: Name and Kind are required")
+	}
+
+	if (opts.Host == "") != (opts.VaultPath == "") {
+		return nil, newError(Fatal, -1, "iRODS Create Resource Failed: Host and VaultPath must be set together")
+	}
+
+	location := ""
+	if opts.Host != "" {
+		location = opts.Host + ":" + opts.VaultPath
+	}
+
+	// Like iadmin, the local zone is passed along
+	if err := con.generalAdmin("Create Resource", "add", "resource", opts.Name, string(opts.Kind), location, opts.Context, con.Options.Zone); err != nil {
+		return nil, err
+	}
+
+	if err := con.RefreshResources(); err != nil {
+		return nil, err
+	}
+
+	rsrcs, err := con.Resources()
+	if err != nil {
+		return nil, err
+	}
+
+	if resc := rsrcs.FindByName(opts.Name); resc != nil {
+		return resc, nil
+	}
+
+	return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Create Resource %v Failed: %v", opts.Name, "Unable to locate newly created resource in cache"))
+}
+
+// Modify changes the attributes set in opts, like iadmin modresc. The resource info is fetched again the next time
+// it's needed. You must have the proper rodsadmin privileges to use this function.
+func (resc *Resource) Modify(opts ResourceModifyOptions) error {
+	mods, err := opts.modifications()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range mods {
+		if err := resc.con.generalAdmin("Modify Resource", "modify", "resource", resc.name, m[0], m[1]); err != nil {
+			return err
+		}
+
+		resc.hasInit = false
+	}
+
+	return nil
+}
+
+// AddChild adds child (a string or *Resource) to the composite resource, like iadmin addchildtoresc. context is
+// passed to the parent for this child, such as "weight=2" for load balanced resources, and can be empty.
+func (resc *Resource) AddChild(child interface{}, context string) error {
+	name, err := resourceName(child)
+	if err != nil {
+		return err
+	}
+
+	if err := resc.con.generalAdmin("Add Child Resource", "add", "childtoresc", resc.name, name, context); err != nil {
+		return err
+	}
+
+	return resc.con.refreshResourceInfo(resc.name, name)
+}
+
+// RemoveChild removes child (a string or *Resource) from the composite resource, like iadmin rmchildfromresc
+func (resc *Resource) RemoveChild(child interface{}) error {
+	name, err := resourceName(child)
+	if err != nil {
+		return err
+	}
+
+	if err := resc.con.generalAdmin("Remove Child Resource", "rm", "childfromresc", resc.name, name); err != nil {
+		return err
+	}
+
+	return resc.con.refreshResourceInfo(resc.name, name)
+}
+
+// Delete removes the resource, like iadmin rmresc. It must not have children or hold data objects.
+// You must have the proper rodsadmin privileges to use this function.
+func (resc *Resource) Delete() error {
+	if err := resc.con.generalAdmin("Delete Resource", "rm", "resource", resc.name); err != nil {
+		return err
+	}
+
+	return resc.con.RefreshResources()
+}
+
+// refreshResourceInfo makes the resources named in names fetch their info again the next time it's needed
+func (con *Connection) refreshResourceInfo(names ...string) error {
+	rsrcs, err := con.Resources()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if resc := rsrcs.FindByName(name); resc != nil {
+			resc.hasInit = false
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestResourceModifyOptions(t *testing.T) {
+	mods, err := ResourceModifyOptions{Status: ResourceDown, Comment: "retired", FreeSpace: "+1024"}.modifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]string{{"status", "down"}, {"comment", "retired"}, {"free_space", "+1024"}}
+
+	if len(mods) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, mods)
+	}
+
+	for i := range expected {
+		if mods[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], mods[i])
+		}
+	}
+
+	for _, opts := range []ResourceModifyOptions{{Status: "offline"}, {FreeSpace: "lots"}} {
+		if _, err := opts.modifications(); err == nil {
+			t.Errorf("Expected %+v to be rejected", opts)
+		}
+	}
+}
+
+func TestCreateResourceValidation(t *testing.T) {
+	con := memConnection(t)
+
+	for _, opts := range []ResourceOptions{
+		{Kind: UnixFileSystemResource},
+		{Name: "newResc", Kind: UnixFileSystemResource, Host: "storage1"},
+	} {
+		if _, err := con.CreateResource(opts); err == nil || errors.Is(err, ErrNotSupported) {
+			t.Errorf("Expected %+v to be rejected, got %v", opts, err)
+		}
+	}
+
+	if _, err := con.CreateResource(ResourceOptions{Name: "repl", Kind: ReplicationResource}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+
+	rsrcs, err := con.Resources()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rsrcs.FindByName("demoResc").AddChild("other", ""); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+// expectGeneralAdmin reads an iadmin like operation, checks its arguments and answers it
+func expectGeneralAdmin(s *irodsproto.Server, args ...string) error {
+	var inp irodsproto.GeneralAdminInp
+	if _, err := s.Expect(irodsproto.GeneralAdminAN, &inp); err != nil {
+		return err
+	}
+
+	expected := make([]string, 10)
+	copy(expected, args)
+
+	got := []string{inp.Arg0, inp.Arg1, inp.Arg2, inp.Arg3, inp.Arg4, inp.Arg5, inp.Arg6, inp.Arg7, inp.Arg8, inp.Arg9}
+	if !reflect.DeepEqual(got, expected) {
+		return fmt.Errorf("Expected general admin arguments %q, got %q", expected, got)
+	}
+
+	return s.Reply(nil, nil, 0)
+}
+
+func TestResourceAdminArgs(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		// mkresc
+		if err := expectGeneralAdmin(s, "add", "resource", "newResc", "unixfilesystem", "storage1:/var/lib/irods/vault", "", "tempZone"); err != nil {
+			return err
+		}
+		if err := replyQuery(s, []string{"demoResc"}, []string{"newResc"}, []string{"replResc"}); err != nil {
+			return err
+		}
+
+		// modresc, one call per attribute
+		if err := expectGeneralAdmin(s, "modify", "resource", "newResc", "status", "down"); err != nil {
+			return err
+		}
+		if err := expectGeneralAdmin(s, "modify", "resource", "newResc", "comment", "retired"); err != nil {
+			return err
+		}
+
+		// addchildtoresc and rmchildfromresc
+		if err := expectGeneralAdmin(s, "add", "childtoresc", "replResc", "newResc", "weight=2"); err != nil {
+			return err
+		}
+		return expectGeneralAdmin(s, "rm", "childfromresc", "replResc", "newResc")
+	})
+
+	con := protoConnection(transport)
+
+	// The local zone is cached already, so the resources are listed without looking it up
+	con.Init = true
+	con.zones = Zones{&Zone{name: "tempZone"}}
+
+	resc, err := con.CreateResource(ResourceOptions{
+		Name:      "newResc",
+		Kind:      UnixFileSystemResource,
+		Host:      "storage1",
+		VaultPath: "/var/lib/irods/vault",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resc.Modify(ResourceModifyOptions{Status: ResourceDown, Comment: "retired"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rsrcs, err := con.Resources()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repl := rsrcs.FindByName("replResc")
+
+	if err := repl.AddChild(resc, "weight=2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repl.RemoveChild("newResc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
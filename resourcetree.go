@@ -0,0 +1,343 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResourceKind is the plugin type of a resource (resc_type_name), see Resource.Kind
+type ResourceKind string
+
+// Resource plugin types shipped with iRODS. The first ones are composite resources, which store data objects on
+// their children instead of storing them themselves.
+const (
+	ReplicationResource  ResourceKind = "replication"
+	PassthruResource     ResourceKind = "passthru"
+	CompoundResource     ResourceKind = "compound"
+	RandomResource       ResourceKind = "random"
+	RoundRobinResource   ResourceKind = "roundrobin"
+	LoadBalancedResource ResourceKind = "load_balanced"
+	DeferredResource     ResourceKind = "deferred"
+
+	UnixFileSystemResource ResourceKind = "unixfilesystem"
+	StructFileResource     ResourceKind = "structfile"
+	UnivMSSResource        ResourceKind = "univmss"
+	S3Resource             ResourceKind = "s3"
+	WOSResource            ResourceKind = "wos"
+	MockArchiveResource    ResourceKind = "mockarchive"
+)
+
+// IsComposite returns true for the composite resource types (replication, passthru, compound, random, round-robin,
+// load balanced and deferred)
+func (kind ResourceKind) IsComposite() bool {
+	switch kind {
+	case ReplicationResource, PassthruResource, CompoundResource, RandomResource, RoundRobinResource, LoadBalancedResource, DeferredResource:
+		return true
+	}
+
+	return false
+}
+
+// HierarchySeparator separates the resources of a resource hierarchy, as in "repl;a;b"
+const HierarchySeparator = ";"
+
+// SplitHierarchy returns the names of the resources in hier, from the root resource to the leaf
+func SplitHierarchy(hier string) []string {
+	if hier == "" {
+		return []string{}
+	}
+
+	return strings.Split(hier, HierarchySeparator)
+}
+
+// parseChildren returns the names of the resources listed by the resc_children attribute of iRODS 4.1
+// ("name{context};name{context}")
+func parseChildren(children string) []string {
+	names := make([]string, 0)
+
+	for _, child := range strings.Split(children, HierarchySeparator) {
+		if i := strings.IndexByte(child, '{'); i >= 0 {
+			child = child[:i]
+		}
+
+		if child = strings.TrimSpace(child); child != "" {
+			names = append(names, child)
+		}
+	}
+
+	return names
+}
+
+// ParseResourceContext parses a resource context string ("key=value;key=value") into a map. Entries without a
+// value, such as "read_only", are mapped to an empty string.
+func ParseResourceContext(context string) map[string]string {
+	response := make(map[string]string)
+
+	for _, entry := range strings.Split(context, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		split := strings.SplitN(entry, "=", 2)
+
+		if len(split) == 2 {
+			response[split[0]] = split[1]
+		} else {
+			response[split[0]] = ""
+		}
+	}
+
+	return response
+}
+
+// Kind loads data from iCAT if needed, and returns the plugin type of the resource
+func (resc *Resource) Kind() (ResourceKind, error) {
+	typ, err := resc.StorageType()
+
+	return ResourceKind(typ), err
+}
+
+// ContextMap loads data from iCAT if needed, and returns the resources context attribute parsed with
+// ParseResourceContext
+func (resc *Resource) ContextMap() (map[string]string, error) {
+	context, err := resc.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseResourceContext(context), nil
+}
+
+// Parent returns the parent resource, or nil if the resource is the root of its hierarchy
+func (resc *Resource) Parent() (*Resource, error) {
+	parentStr, err := resc.ParentStr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parentStr == "" {
+		return nil, nil
+	}
+
+	parent, err := resc.con.resourceByRef(parentStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent == nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Get Resource Parent Failed: Unable to locate parent %v of %v", parentStr, resc.name)).wrap(ErrNotFound)
+	}
+
+	return parent, nil
+}
+
+// Children returns the child resources of a composite resource
+func (resc *Resource) Children() (Resources, error) {
+	childrenStr, err := resc.ChildrenStr()
+	if err != nil {
+		return nil, err
+	}
+
+	rsrcs, err := resc.con.Resources()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(Resources, 0)
+
+	// iRODS 4.1 lists the children, later versions only store the parent of each resource
+	if childrenStr != "" {
+		for _, name := range parseChildren(childrenStr) {
+			if child := rsrcs.FindByName(name); child != nil {
+				response = append(response, child)
+			}
+		}
+
+		return response, nil
+	}
+
+	id, err := resc.Id()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range rsrcs {
+		parentStr, err := child.ParentStr()
+		if err != nil {
+			return nil, err
+		}
+
+		if parentStr != "" && (parentStr == resc.name || parentStr == strconv.Itoa(id)) {
+			response = append(response, child)
+		}
+	}
+
+	return response, nil
+}
+
+// IsLeaf returns true if the resource has no children, and stores data objects itself
+func (resc *Resource) IsLeaf() (bool, error) {
+	children, err := resc.Children()
+
+	return len(children) == 0, err
+}
+
+// Root returns the root resource of the hierarchy the resource belongs to, which is itself if it has no parent
+func (resc *Resource) Root() (*Resource, error) {
+	root := resc
+
+	for depth := 0; ; depth++ {
+		parent, err := root.Parent()
+		if err != nil {
+			return nil, err
+		}
+
+		if parent == nil {
+			return root, nil
+		}
+
+		if depth > len(resc.con.resources) {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Get Resource Root Failed: %v is in a parent loop", resc.name))
+		}
+
+		root = parent
+	}
+}
+
+// Hierarchy returns the full hierarchy string of the resource, from the root resource, as in "repl;a;b"
+func (resc *Resource) Hierarchy() (string, error) {
+	names := []string{resc.name}
+
+	for r := resc; ; {
+		parent, err := r.Parent()
+		if err != nil {
+			return "", err
+		}
+
+		if parent == nil {
+			return strings.Join(names, HierarchySeparator), nil
+		}
+
+		if len(names) > len(resc.con.resources) {
+			return "", newError(Fatal, -1, fmt.Sprintf("iRODS Get Resource Hierarchy Failed: %v is in a parent loop", resc.name))
+		}
+
+		names = append([]string{parent.name}, names...)
+		r = parent
+	}
+}
+
+// Leaves returns the leaf resources below the resource, or the resource itself if it's a leaf
+func (resc *Resource) Leaves() (Resources, error) {
+	children, err := resc.Children()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(children) == 0 {
+		return Resources{resc}, nil
+	}
+
+	response := make(Resources, 0)
+
+	for _, child := range children {
+		leaves, err := child.Leaves()
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, leaves...)
+	}
+
+	return response, nil
+}
+
+// resourceByRef returns the resource named ref, or with the id ref, or nil if there's none
+func (con *Connection) resourceByRef(ref string) (*Resource, error) {
+	rsrcs, err := con.Resources()
+	if err != nil {
+		return nil, err
+	}
+
+	if resc := rsrcs.FindByName(ref); resc != nil {
+		return resc, nil
+	}
+
+	id, err := strconv.Atoi(ref)
+	if err != nil {
+		return nil, nil
+	}
+
+	for _, resc := range rsrcs {
+		if rescId, err := resc.Id(); err != nil {
+			return nil, err
+		} else if rescId == id {
+			return resc, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ResolveHierarchy returns the leaf resource a resource hierarchy such as "repl;a;b" lands on, checking that each
+// resource is a child of the one before it. When the last resource has children, the hierarchy is followed down as
+// long as there's a single child (like below passthru resources), otherwise the leaf is ambiguous and an error is
+// returned.
+func (con *Connection) ResolveHierarchy(hier string) (*Resource, error) {
+	names := SplitHierarchy(hier)
+	if len(names) == 0 {
+		return nil, newError(Fatal, -1, "iRODS Resolve Hierarchy Failed: Empty hierarchy")
+	}
+
+	rsrcs, err := con.Resources()
+	if err != nil {
+		return nil, err
+	}
+
+	resc := rsrcs.FindByName(names[0])
+	if resc == nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Resolve Hierarchy Failed: %v: Unknown resource %v", hier, names[0])).wrap(ErrNotFound)
+	}
+
+	if parent, err := resc.Parent(); err != nil {
+		return nil, err
+	} else if parent != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Resolve Hierarchy Failed: %v: %v isn't a root resource, its parent is %v", hier, names[0], parent.name))
+	}
+
+	for _, name := range names[1:] {
+		children, err := resc.Children()
+		if err != nil {
+			return nil, err
+		}
+
+		child := children.FindByName(name)
+		if child == nil {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Resolve Hierarchy Failed: %v: %v isn't a child resource of %v", hier, name, resc.name)).wrap(ErrNotFound)
+		}
+
+		resc = child
+	}
+
+	for depth := 0; depth <= len(rsrcs); depth++ {
+		children, err := resc.Children()
+		if err != nil {
+			return nil, err
+		}
+
+		switch len(children) {
+		case 0:
+			return resc, nil
+		case 1:
+			resc = children[0]
+		default:
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Resolve Hierarchy Failed: %v: %v has %v children, the leaf is ambiguous", hier, resc.name, len(children)))
+		}
+	}
+
+	return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Resolve Hierarchy Failed: %v: Resource loop", hier))
+}
@@ -0,0 +1,120 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"testing"
+)
+
+// resourceTreeConnection has the hierarchies repl;a, repl;pt;b and demoResc
+func resourceTreeConnection(t *testing.T) *Connection {
+	mem := NewMemTransport("tempZone", "rods")
+
+	mem.AddResource("repl", "replication")
+	mem.AddResource("a", "unixfilesystem")
+	mem.AddResource("pt", "passthru")
+	mem.AddResource("b", "unixfilesystem")
+
+	mem.AddChildResource("repl", "a")
+	mem.AddChildResource("repl", "pt")
+	mem.AddChildResource("pt", "b")
+
+	con, err := NewConnection(memOptions(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return con
+}
+
+func TestParseResourceContext(t *testing.T) {
+	context := ParseResourceContext("minimum_free_space_for_create_in_bytes=1024;read_only; host_mode=archive_attached")
+
+	if len(context) != 3 || context["minimum_free_space_for_create_in_bytes"] != "1024" || context["host_mode"] != "archive_attached" {
+		t.Errorf("Unexpected context %v", context)
+	}
+
+	if v, ok := context["read_only"]; !ok || v != "" {
+		t.Errorf("Expected read_only without a value, got %q", v)
+	}
+
+	if names := parseChildren("a{};pt{weight=2}"); len(names) != 2 || names[0] != "a" || names[1] != "pt" {
+		t.Errorf("Expected children a and pt, got %v", names)
+	}
+
+	if !ReplicationResource.IsComposite() || UnixFileSystemResource.IsComposite() {
+		t.Error("Expected only composite resource types to be composite")
+	}
+}
+
+func TestResourceTree(t *testing.T) {
+	con := resourceTreeConnection(t)
+
+	rsrcs, err := con.Resources()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := rsrcs.FindByName("b")
+
+	if parent, err := b.Parent(); err != nil || parent == nil || parent.Name() != "pt" {
+		t.Errorf("Expected b's parent to be pt, got %v (%v)", parent, err)
+	}
+
+	if hier, err := b.Hierarchy(); err != nil || hier != "repl;pt;b" {
+		t.Errorf("Expected the hierarchy repl;pt;b, got %q (%v)", hier, err)
+	}
+
+	if root, err := b.Root(); err != nil || root.Name() != "repl" {
+		t.Errorf("Expected repl to be the root, got %v (%v)", root, err)
+	}
+
+	repl := rsrcs.FindByName("repl")
+
+	if kind, err := repl.Kind(); err != nil || kind != ReplicationResource {
+		t.Errorf("Expected a replication resource, got %v (%v)", kind, err)
+	}
+
+	if parent, err := repl.Parent(); err != nil || parent != nil {
+		t.Errorf("Expected repl to have no parent, got %v (%v)", parent, err)
+	}
+
+	if children, err := repl.Children(); err != nil || len(children) != 2 || children.FindByName("a") == nil || children.FindByName("pt") == nil {
+		t.Errorf("Expected repl's children to be a and pt, got %v (%v)", children, err)
+	}
+
+	if leaves, err := repl.Leaves(); err != nil || len(leaves) != 2 || leaves.FindByName("a") == nil || leaves.FindByName("b") == nil {
+		t.Errorf("Expected the leaves a and b, got %v (%v)", leaves, err)
+	}
+
+	if leaf, err := b.IsLeaf(); err != nil || !leaf {
+		t.Errorf("Expected b to be a leaf, got %v (%v)", leaf, err)
+	}
+}
+
+func TestResolveHierarchy(t *testing.T) {
+	con := resourceTreeConnection(t)
+
+	for hier, expected := range map[string]string{
+		"repl;pt;b": "b",
+		"repl;pt":   "b",
+		"repl;a":    "a",
+		"demoResc":  "demoResc",
+	} {
+		if leaf, err := con.ResolveHierarchy(hier); err != nil || leaf.Name() != expected {
+			t.Errorf("Expected %v to land on %v, got %v (%v)", hier, expected, leaf, err)
+		}
+	}
+
+	if _, err := con.ResolveHierarchy("repl;b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected b not to be a child of repl, got %v", err)
+	}
+
+	for _, hier := range []string{"", "repl", "pt;b"} {
+		if leaf, err := con.ResolveHierarchy(hier); err == nil {
+			t.Errorf("Expected %q not to resolve, got %v", hier, leaf)
+		}
+	}
+}
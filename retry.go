@@ -0,0 +1,818 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultRetryableCodes are the iRODS status codes retried when RetryPolicy.RetryableCodes is nil. They're all
+// socket and connection failures, raised when the server agent dies or the network drops.
+var DefaultRetryableCodes = []int{
+	sysSockOpenErr,
+	sysSockConnectErr,
+	sysSockReadErr,
+	sysSockReadTimedout,
+	sysHeaderReadLenErr,
+	sysHeaderWriteLenErr,
+	sysExceedConnectCnt,
+	userSockOpenErr,
+	userSockConnectErr,
+	userSockConnectTimedout,
+}
+
+// DefaultRetryPolicy tries operations 3 times, waiting half a second before the first retry and a second before the next
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// RetryPolicy configures how a connection recovers from transient failures, set it with ConnectionOptions.Retry.
+//
+// When an operation fails with a retryable error the connection waits for the backoff, reconnects and logs in
+// again (reusing the PAM token of PAMAuth connections), then retries the operation. Data objects opened read-only,
+// or opened read/write but not written to yet, are reopened at the same offset. Handles that were written to can't
+// be recovered safely, and fail with ErrHandleLost.
+//
+// Operations that change the catalog or data (Put, Remove, Write, AddMeta...) may have been carried out by the server
+// before the connection dropped, so they're only retried when RetryWrites is set. Otherwise the connection is still
+// re-established, and the error is returned.
+//
+// Operations outside of the Transport interface (Query, IQuest, tickets, resource, zone and user administration,
+// replication and trimming) are retried the same way. The pages of a QueryCursor aren't, as the statement is lost
+// with the session: Next fails, and the query has to be run again.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times an operation is tried, including the first one
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles with every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// RetryableCodes are the iRODS status codes to retry, DefaultRetryableCodes when it's nil. Socket errors of
+	// PureGo connections are always retryable.
+	RetryableCodes []int
+	// RetryWrites also retries operations that modify iRODS
+	RetryWrites bool
+}
+
+// ErrHandleLost is wrapped by errors of data object operations on a handle that couldn't be reopened after reconnecting
+var ErrHandleLost = errors.New("data object handle lost when reconnecting")
+
+// Retryable reports whether err is a transient failure that the policy retries
+func (p *RetryPolicy) Retryable(err error) bool {
+	// Giving up on a context isn't a failure of the connection
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var rodsErr *GoRodsError
+	if errors.As(err, &rodsErr) && rodsErr.Code < 0 {
+		codes := p.RetryableCodes
+		if codes == nil {
+			codes = DefaultRetryableCodes
+		}
+
+		// Strip the errno carried in the last three digits
+		code := rodsErr.Code / 1000 * 1000
+
+		for _, retryable := range codes {
+			if code == retryable/1000*1000 {
+				return true
+			}
+		}
+	}
+
+	var netErr net.Error
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// delay returns how long to wait before the retry number attempt (starting at 1)
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+// Reconnect replaces the connection's session with a new one, logging in again, for example after the server was
+// restarted. Unlike InitCon it keeps the opened collections and data objects. When ConnectionOptions.Retry is set,
+// their handles are reopened like they are after automatic reconnections.
+//
+// The sessions of the iRODS C API are replaced once the operations using them are done, so Reconnect can be called
+// while other goroutines use the connection. PureGo connections and custom Transports get a new Transport, which is
+// only swapped in safely when ConnectionOptions.Retry is set; otherwise don't call Reconnect while the connection
+// is in use.
+func (con *Connection) Reconnect() error {
+	if rt, ok := con.transport.(*retryTransport); ok {
+		rt.mu.Lock()
+		gen := rt.gen
+		rt.mu.Unlock()
+
+		return rt.reconnect(gen)
+	}
+
+	old := con.transport
+
+	t, err := con.reconnect()
+	if err != nil {
+		return err
+	}
+
+	if t != old {
+		con.transport = t
+		old.Disconnect()
+	}
+
+	return nil
+}
+
+// reconnect opens a new session with the options of the connection, and logs in again. The PAM token returned by the
+// first login is reused, so PAM isn't asked for the password again. The new sessions of the iRODS C API are adopted
+// by the connection, and its cTransport is returned. Otherwise the new Transport is returned, for the caller to swap
+// in before disconnecting the old one.
+func (con *Connection) reconnect() (Transport, error) {
+	opts := *con.Options
+	opts.FastInit = true
+
+	if opts.AuthType == PAMAuth && opts.PAMToken == "" && con.PAMToken != "" {
+		opts.PAMToken = con.PAMToken
+	}
+
+	// A custom Transport is connected again, rather than replaced
+	if con.Options.Transport != nil {
+		con.Options.Transport.Disconnect()
+	}
+
+	fresh := &Connection{Options: &opts}
+
+	if err := fresh.connect(); err != nil {
+		return nil, err
+	}
+
+	if !fresh.hasCcon() {
+		return fresh.transport, nil
+	}
+
+	if err := con.adoptSessions(fresh); err != nil {
+		fresh.transport.Disconnect()
+		return nil, err
+	}
+
+	return unwrapTransport(con.transport), nil
+}
+
+// retry runs fn, an operation outside of the Transport interface, with the connection's RetryPolicy like the
+// operations of the Transport. fn has to check out the session (or the PureGo client) itself, as it's replaced
+// when reconnecting. Operations that modify iRODS set modifies.
+func (con *Connection) retry(modifies bool, fn func() error) error {
+	return con.retryContext(context.Background(), modifies, fn)
+}
+
+// retryContext is like retry, but stops retrying once ctx is done. fn should pass ctx on when checking out the session.
+func (con *Connection) retryContext(ctx context.Context, modifies bool, fn func() error) error {
+	if rt, ok := con.transport.(*retryTransport); ok {
+		return rt.withContext(ctx).(*retryTransport).do(modifies, func(Transport) error {
+			return fn()
+		})
+	}
+
+	return fn()
+}
+
+// unwrapTransport returns the Transport behind t when it's a retryTransport
+func unwrapTransport(t Transport) Transport {
+	if rt, ok := t.(*retryTransport); ok {
+		rt.mu.Lock()
+		defer rt.mu.Unlock()
+
+		return rt.inner
+	}
+
+	return t
+}
+
+// retryHandle tracks a data object handle returned by retryTransport.Open, so it can be reopened after reconnecting
+type retryHandle struct {
+	inner   int
+	path    string
+	resc    string
+	replNum int
+	flags   int
+	offset  int64
+	written bool
+	lost    bool
+}
+
+// retryTransport wraps the Transport of a connection with a RetryPolicy. It hands out its own data object
+// handles, so they stay valid when the session (and the handles of the wrapped Transport) are replaced.
+type retryTransport struct {
+	*retryState
+
+	// ctx is passed on to the wrapped Transport and bounds the backoff, see withContext
+	ctx context.Context
+}
+
+// retryState is shared by a retryTransport and its copies bound to a context
+type retryState struct {
+	con    *Connection
+	policy RetryPolicy
+
+	// reconnectMu serializes reconnections, mu guards the fields below
+	reconnectMu sync.Mutex
+	mu          sync.Mutex
+	inner       Transport
+	gen         int
+	handles     map[int]*retryHandle
+	nextHandle  int
+}
+
+func newRetryTransport(con *Connection, inner Transport, policy RetryPolicy) *retryTransport {
+	return &retryTransport{retryState: &retryState{
+		con:     con,
+		policy:  policy,
+		inner:   inner,
+		handles: make(map[int]*retryHandle),
+	}}
+}
+
+// withContext returns a retryTransport sharing the state of t, which binds the wrapped Transport to ctx and stops
+// retrying once ctx is done
+func (t *retryTransport) withContext(ctx context.Context) Transport {
+	return &retryTransport{retryState: t.retryState, ctx: ctx}
+}
+
+// context returns the context the transport is bound to
+func (t *retryTransport) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+
+	return t.ctx
+}
+
+// current returns the wrapped Transport and its generation, which changes with every reconnection
+func (t *retryTransport) current() (Transport, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.inner, t.gen
+}
+
+// reconnect connects again, unless another goroutine already did since generation gen failed. The new Transport is
+// swapped in, then the old one is disconnected. Handles that are safe to reopen are reopened at their offset, the
+// others are marked lost.
+func (t *retryTransport) reconnect(gen int) error {
+	t.reconnectMu.Lock()
+	defer t.reconnectMu.Unlock()
+
+	t.mu.Lock()
+	old := t.inner
+	current := t.gen == gen
+	t.mu.Unlock()
+
+	if !current {
+		return nil
+	}
+
+	inner, err := t.con.reconnect()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+
+	t.inner = inner
+	t.gen++
+
+	for _, h := range t.handles {
+		if h.lost {
+			continue
+		}
+
+		if h.written {
+			h.lost = true
+			continue
+		}
+
+		handle, err := inner.Open(h.path, h.resc, h.replNum, h.flags)
+		if err != nil {
+			h.lost = true
+			continue
+		}
+
+		if h.offset > 0 {
+			if err := inner.Seek(handle, h.offset); err != nil {
+				inner.Close(handle)
+				h.lost = true
+				continue
+			}
+		}
+
+		h.inner = handle
+	}
+
+	t.mu.Unlock()
+
+	// Operations still running on the old Transport fail, and are retried on the new one
+	if inner != old {
+		old.Disconnect()
+	}
+
+	return nil
+}
+
+// do runs fn with the wrapped Transport, reconnecting and retrying according to the policy. Operations that
+// modify iRODS set modifies, and are only retried with RetryWrites.
+func (t *retryTransport) do(modifies bool, fn func(inner Transport) error) error {
+	ctx := t.context()
+	inner, gen := t.current()
+
+	err := fn(bindTransport(ctx, inner))
+
+	for attempt := 1; attempt < t.policy.MaxAttempts && t.policy.Retryable(err); attempt++ {
+		select {
+		case <-time.After(t.policy.delay(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+
+		if rErr := t.reconnect(gen); rErr != nil {
+			if t.policy.Retryable(rErr) {
+				continue
+			}
+
+			return rErr
+		}
+
+		if modifies && !t.policy.RetryWrites {
+			return err
+		}
+
+		inner, gen = t.current()
+		err = fn(bindTransport(ctx, inner))
+	}
+
+	return err
+}
+
+// handle returns the state of the handle h, or an error wrapping ErrHandleLost
+func (t *retryTransport) handle(h int) (*retryHandle, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rh, ok := t.handles[h]
+	if !ok {
+		return nil, NewTransportError(-1, fmt.Sprintf("unknown handle %v", h))
+	}
+
+	if rh.lost {
+		return nil, NewTransportError(-1, fmt.Sprintf("%v couldn't be reopened after reconnecting", rh.path)).wrap(ErrHandleLost)
+	}
+
+	return rh, nil
+}
+
+// innerHandle returns the handle of the wrapped Transport for h
+func (t *retryTransport) innerHandle(h int) (int, error) {
+	rh, err := t.handle(h)
+	if err != nil {
+		return -1, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return rh.inner, nil
+}
+
+func (t *retryTransport) Disconnect() error {
+	inner, _ := t.current()
+
+	t.mu.Lock()
+	t.handles = make(map[int]*retryHandle)
+	t.mu.Unlock()
+
+	return inner.Disconnect()
+}
+
+func (t *retryTransport) Ping() error {
+	return t.do(false, func(inner Transport) error {
+		return inner.Ping()
+	})
+}
+
+func (t *retryTransport) LocalZone() (zone string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		zone, er = inner.LocalZone()
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Stat(path string) (entry *TransportEntry, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		entry, er = inner.Stat(path)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) DataObj(path string) (entry *TransportEntry, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		entry, er = inner.DataObj(path)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) List(path string, trimRepls bool) (entries []*TransportEntry, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		entries, er = inner.List(path, trimRepls)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) ListPage(path string, trimRepls bool, offset int, limit int) (page *TransportPage, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		page, er = inner.ListPage(path, trimRepls, offset, limit)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) CreateCollection(path string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.CreateCollection(path)
+	})
+}
+
+func (t *retryTransport) CreateDataObj(path string, size int64, mode int, force bool, resource string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.CreateDataObj(path, size, mode, force, resource)
+	})
+}
+
+func (t *retryTransport) Put(localPath string, path string, size int64, mode int, force bool, resource string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.Put(localPath, path, size, mode, force, resource)
+	})
+}
+
+func (t *retryTransport) Remove(path string, isCollection bool, recursive bool, force bool, rmTrash bool) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.Remove(path, isCollection, recursive, force, rmTrash)
+	})
+}
+
+func (t *retryTransport) Move(src string, dst string, isCollection bool) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.Move(src, dst, isCollection)
+	})
+}
+
+func (t *retryTransport) Copy(src string, dst string, force bool, resource string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.Copy(src, dst, force, resource)
+	})
+}
+
+func (t *retryTransport) Checksum(path string) (sum string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		sum, er = inner.Checksum(path)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Open(path string, resource string, replNum int, flags int) (int, error) {
+	var handle int
+
+	err := t.do(false, func(inner Transport) (er error) {
+		handle, er = inner.Open(path, resource, replNum, flags)
+		return
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := t.nextHandle
+	t.nextHandle++
+
+	t.handles[h] = &retryHandle{inner: handle, path: path, resc: resource, replNum: replNum, flags: flags}
+
+	return h, nil
+}
+
+func (t *retryTransport) Read(handle int, length int64) ([]byte, error) {
+	return t.ReadContext(t.context(), handle, length)
+}
+
+// ReadContext is like Read, passing ctx on to the wrapped Transport
+func (t *retryTransport) ReadContext(ctx context.Context, handle int, length int64) (data []byte, err error) {
+	rh, err := t.handle(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading again after reconnecting is safe, the handle is reopened at the offset of the failed read
+	err = t.do(false, func(inner Transport) error {
+		h, er := t.innerHandle(handle)
+		if er != nil {
+			return er
+		}
+
+		data, er = readContext(ctx, inner, h, length)
+		return er
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	rh.offset += int64(len(data))
+	t.mu.Unlock()
+
+	return data, nil
+}
+
+func (t *retryTransport) Write(handle int, data []byte) error {
+	return t.WriteContext(t.context(), handle, data)
+}
+
+// WriteContext is like Write, passing ctx on to the wrapped Transport
+func (t *retryTransport) WriteContext(ctx context.Context, handle int, data []byte) error {
+	rh, err := t.handle(handle)
+	if err != nil {
+		return err
+	}
+
+	err = t.do(true, func(inner Transport) error {
+		h, er := t.innerHandle(handle)
+		if er != nil {
+			return er
+		}
+
+		er = writeContext(ctx, inner, h, data)
+
+		// Nothing was sent when giving up while waiting for the session
+		if er != nil && (errors.Is(er, context.Canceled) || errors.Is(er, context.DeadlineExceeded)) {
+			return er
+		}
+
+		// The server may have written part of the data when the write fails, so the handle can't be reopened anymore
+		t.mu.Lock()
+		rh.written = true
+		t.mu.Unlock()
+
+		return er
+	})
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	rh.offset += int64(len(data))
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *retryTransport) Seek(handle int, offset int64) error {
+	rh, err := t.handle(handle)
+	if err != nil {
+		return err
+	}
+
+	err = t.do(false, func(inner Transport) error {
+		h, er := t.innerHandle(handle)
+		if er != nil {
+			return er
+		}
+
+		return inner.Seek(h, offset)
+	})
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	rh.offset = offset
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *retryTransport) Close(handle int) error {
+	if _, err := t.handle(handle); err != nil {
+		t.mu.Lock()
+		delete(t.handles, handle)
+		t.mu.Unlock()
+
+		return err
+	}
+
+	err := t.do(false, func(inner Transport) error {
+		h, er := t.innerHandle(handle)
+		if er != nil {
+			return er
+		}
+
+		return inner.Close(h)
+	})
+
+	t.mu.Lock()
+	delete(t.handles, handle)
+	t.mu.Unlock()
+
+	return err
+}
+
+func (t *retryTransport) Meta(typ int, path string, zone string) (metas Metas, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		metas, er = inner.Meta(typ, path, zone)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) AddMeta(typ int, path string, m Meta) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.AddMeta(typ, path, m)
+	})
+}
+
+func (t *retryTransport) RemoveMeta(typ int, path string, m Meta) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.RemoveMeta(typ, path, m)
+	})
+}
+
+func (t *retryTransport) ModMeta(typ int, path string, old Meta, updated Meta) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.ModMeta(typ, path, old, updated)
+	})
+}
+
+func (t *retryTransport) DataObjACL(dataId string, zone string) (acls []*TransportACL, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		acls, er = inner.DataObjACL(dataId, zone)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) CollectionACL(path string, zone string) (acls []*TransportACL, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		acls, er = inner.CollectionACL(path, zone)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Chmod(path string, zone string, user string, access string, recursive bool) error {
+	// Setting the same access level twice is harmless
+	return t.do(false, func(inner Transport) error {
+		return inner.Chmod(path, zone, user, access, recursive)
+	})
+}
+
+func (t *retryTransport) Inheritance(path string) (inherit bool, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		inherit, er = inner.Inheritance(path)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Users() (names []string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		names, er = inner.Users()
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Groups() (names []string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		names, er = inner.Groups()
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Zones() (names []string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		names, er = inner.Zones()
+		return
+	})
+	return
+}
+
+func (t *retryTransport) Resources() (names []string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		names, er = inner.Resources()
+		return
+	})
+	return
+}
+
+func (t *retryTransport) UserInfo(name string) (info map[string]string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		info, er = inner.UserInfo(name)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) UserGroups(user string) (names []string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		names, er = inner.UserGroups(user)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) GroupMembers(group string) (names []string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		names, er = inner.GroupMembers(group)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) CreateUser(name string, zone string, typ string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.CreateUser(name, zone, typ)
+	})
+}
+
+func (t *retryTransport) DeleteUser(name string, zone string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.DeleteUser(name, zone)
+	})
+}
+
+func (t *retryTransport) ChangePassword(user string, newPass string, myPass string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.ChangePassword(user, newPass, myPass)
+	})
+}
+
+func (t *retryTransport) CreateGroup(name string, zone string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.CreateGroup(name, zone)
+	})
+}
+
+func (t *retryTransport) DeleteGroup(name string, zone string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.DeleteGroup(name, zone)
+	})
+}
+
+func (t *retryTransport) AddToGroup(user string, zone string, group string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.AddToGroup(user, zone, group)
+	})
+}
+
+func (t *retryTransport) RemoveFromGroup(user string, zone string, group string) error {
+	return t.do(true, func(inner Transport) error {
+		return inner.RemoveFromGroup(user, zone, group)
+	})
+}
+
+func (t *retryTransport) ZoneInfo(name string) (info map[string]string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		info, er = inner.ZoneInfo(name)
+		return
+	})
+	return
+}
+
+func (t *retryTransport) ResourceInfo(name string) (info map[string]string, err error) {
+	err = t.do(false, func(inner Transport) (er error) {
+		info, er = inner.ResourceInfo(name)
+		return
+	})
+	return
+}
@@ -0,0 +1,291 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// flakyTransport fails the next failures calls with a socket error, and invalidates open handles when disconnected
+type flakyTransport struct {
+	Transport
+	failures    int
+	calls       int
+	disconnects int
+	handles     []int
+	dead        map[int]bool
+}
+
+func (t *flakyTransport) fail() error {
+	t.calls++
+
+	if t.failures > 0 {
+		t.failures--
+		return NewTransportError(DefaultRetryableCodes[0], "socket dropped")
+	}
+
+	return nil
+}
+
+func (t *flakyTransport) Disconnect() error {
+	t.disconnects++
+
+	for _, h := range t.handles {
+		t.dead[h] = true
+	}
+
+	return t.Transport.Disconnect()
+}
+
+func (t *flakyTransport) Stat(path string) (*TransportEntry, error) {
+	if err := t.fail(); err != nil {
+		return nil, err
+	}
+	return t.Transport.Stat(path)
+}
+
+func (t *flakyTransport) Put(localPath string, path string, size int64, mode int, force bool, resource string) error {
+	if err := t.fail(); err != nil {
+		return err
+	}
+	return t.Transport.Put(localPath, path, size, mode, force, resource)
+}
+
+func (t *flakyTransport) Open(path string, resource string, replNum int, flags int) (int, error) {
+	if err := t.fail(); err != nil {
+		return -1, err
+	}
+
+	h, err := t.Transport.Open(path, resource, replNum, flags)
+	if err == nil {
+		t.handles = append(t.handles, h)
+	}
+
+	return h, err
+}
+
+func (t *flakyTransport) Read(handle int, length int64) ([]byte, error) {
+	if err := t.fail(); err != nil {
+		return nil, err
+	}
+	if t.dead[handle] {
+		return nil, NewTransportError(-1, fmt.Sprintf("bad handle %v", handle))
+	}
+	return t.Transport.Read(handle, length)
+}
+
+func (t *flakyTransport) Write(handle int, data []byte) error {
+	if err := t.fail(); err != nil {
+		return err
+	}
+	if t.dead[handle] {
+		return NewTransportError(-1, fmt.Sprintf("bad handle %v", handle))
+	}
+	return t.Transport.Write(handle, data)
+}
+
+func flakyConnection(t *testing.T, policy RetryPolicy) (*Connection, *flakyTransport) {
+	flaky := &flakyTransport{Transport: NewMemTransport("tempZone", "rods"), dead: make(map[int]bool)}
+
+	opts := memOptions(flaky)
+	opts.Retry = &policy
+
+	con, err := NewConnection(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := home.CreateDataObj(DataObjOptions{Name: "hello.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := obj.Write([]byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+
+	flaky.disconnects = 0
+	flaky.calls = 0
+
+	return con, flaky
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	p := DefaultRetryPolicy
+
+	if !p.Retryable(transportError(NewTransportError(DefaultRetryableCodes[0]-104, "reset"), "iRODS Stat Failed")) {
+		t.Error("Expected a socket error carrying an errno to be retryable")
+	}
+
+	if p.Retryable(NewTransportError(-310000, "missing")) || p.Retryable(nil) {
+		t.Error("Expected other errors not to be retryable")
+	}
+
+	if !p.Retryable(NewTransportError(-1, "EOF").wrap(io.EOF)) {
+		t.Error("Expected io.EOF from the PureGo client to be retryable")
+	}
+
+	p.RetryableCodes = []int{-310000}
+	if !p.Retryable(NewTransportError(-310000, "missing")) || p.Retryable(NewTransportError(DefaultRetryableCodes[0], "reset")) {
+		t.Error("Expected RetryableCodes to replace the defaults")
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}
+
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		if d := p.delay(attempt + 1); d != expected {
+			t.Errorf("Expected a delay of %v before retry %v, got %v", expected, attempt+1, d)
+		}
+	}
+}
+
+func TestRetryReconnects(t *testing.T) {
+	con, flaky := flakyConnection(t, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	flaky.failures = 2
+	if _, err := con.transport.Stat("/tempZone/home/rods/hello.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if flaky.disconnects != 2 {
+		t.Errorf("Expected to reconnect twice, reconnected %v times", flaky.disconnects)
+	}
+
+	flaky.failures = 3
+	if _, err := con.transport.Stat("/tempZone/home/rods/hello.txt"); !DefaultRetryPolicy.Retryable(err) {
+		t.Errorf("Expected the socket error after 3 attempts, got %v", err)
+	}
+}
+
+func TestRetryReopensHandles(t *testing.T) {
+	con, flaky := flakyConnection(t, RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	h, err := con.transport.Open("/tempZone/home/rods/hello.txt", "", 0, os.O_RDONLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := con.transport.Read(h, 5); err != nil || string(data) != "hello" {
+		t.Fatalf("Expected hello, got %q (%v)", data, err)
+	}
+
+	flaky.failures = 1
+	if data, err := con.transport.Read(h, 6); err != nil || string(data) != " world" {
+		t.Errorf("Expected to carry on reading after reconnecting, got %q (%v)", data, err)
+	}
+
+	rw, err := con.transport.Open("/tempZone/home/rods/hello.txt", "", 0, os.O_RDWR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := con.transport.Write(rw, []byte("HELLO")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := con.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := con.transport.Write(rw, []byte(" WORLD")); !errors.Is(err, ErrHandleLost) {
+		t.Errorf("Expected a handle that was written to be lost, got %v", err)
+	}
+
+	if err := con.transport.Close(rw); !errors.Is(err, ErrHandleLost) {
+		t.Errorf("Expected closing a lost handle to report it, got %v", err)
+	}
+
+	if err := con.transport.Close(h); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRetryWrites(t *testing.T) {
+	con, flaky := flakyConnection(t, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	flaky.failures = 1
+	if err := con.transport.Put("/dev/null", "/tempZone/home/rods/null.txt", 0, 0644, true, ""); !DefaultRetryPolicy.Retryable(err) {
+		t.Errorf("Expected Put not to be retried, got %v", err)
+	}
+
+	if flaky.disconnects != 1 || flaky.calls != 1 {
+		t.Errorf("Expected to reconnect once without retrying, got %v reconnections and %v calls", flaky.disconnects, flaky.calls)
+	}
+
+	con.transport.(*retryTransport).policy.RetryWrites = true
+
+	flaky.failures = 1
+	if err := con.transport.Put("/dev/null", "/tempZone/home/rods/null.txt", 0, 0644, true, ""); err != nil {
+		t.Errorf("Expected Put to be retried with RetryWrites, got %v", err)
+	}
+}
+
+func TestRetryAbandonedWrite(t *testing.T) {
+	con, _ := flakyConnection(t, RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+	rw, err := con.transport.Open("/tempZone/home/rods/hello.txt", "", 0, os.O_RDWR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := con.transport.(*retryTransport).WriteContext(ctx, rw, []byte("HELLO")); err != context.Canceled {
+		t.Fatalf("Expected the write to be abandoned, got %v", err)
+	}
+
+	if err := con.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing was written, so the handle is reopened
+	if err := con.transport.Write(rw, []byte("HELLO")); err != nil {
+		t.Errorf("Expected a handle that wasn't written to to be reopened, got %v", err)
+	}
+
+	if err := con.transport.Close(rw); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRetryConnectionOperations(t *testing.T) {
+	con, flaky := flakyConnection(t, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	calls := 0
+	err := con.retry(false, func() error {
+		calls++
+		if calls == 1 {
+			return NewTransportError(DefaultRetryableCodes[0], "socket dropped")
+		}
+		return nil
+	})
+
+	if err != nil || calls != 2 || flaky.disconnects != 1 {
+		t.Errorf("Expected to reconnect and call again, got %v after %v calls and %v reconnections", err, calls, flaky.disconnects)
+	}
+
+	calls = 0
+	err = con.retry(true, func() error {
+		calls++
+		return NewTransportError(DefaultRetryableCodes[0], "socket dropped")
+	})
+
+	if !DefaultRetryPolicy.Retryable(err) || calls != 1 {
+		t.Errorf("Expected an operation modifying the zone not to be called again, got %v after %v calls", err, calls)
+	}
+}
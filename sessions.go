@@ -0,0 +1,188 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+)
+
+// sessionHandleShift is where the session index is stored in the data object handles returned by cTransport.Open.
+// iRODS handles (L1 descriptors) stay well below it, so handles of the first session are left unchanged.
+const sessionHandleShift = 16
+
+// session is one of the rcComm_t handles of a connection, see ConnectionOptions.Sessions. lock is held by the
+// goroutine using it, whether it was checked out with getCcon or for one of its data object handles. It's a
+// semaphore rather than a mutex, so waiting for the session can be given up when a context is cancelled.
+type session struct {
+	ccon *rcComm
+	lock chan struct{}
+}
+
+func newSession(ccon *rcComm) *session {
+	return &session{ccon: ccon, lock: make(chan struct{}, 1)}
+}
+
+// acquire waits for the session to be free, or for ctx to be done
+func (s *session) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case s.lock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *session) release() {
+	<-s.lock
+}
+
+// sessionCount returns the number of sessions to open, at least one
+func (conOpts *ConnectionOptions) sessionCount() int {
+	if conOpts.Sessions < 1 {
+		return 1
+	}
+
+	return conOpts.Sessions
+}
+
+// Sessions returns the number of iRODS sessions (rcComm_t handles) the connection spreads its operations over
+func (con *Connection) Sessions() int {
+	return len(con.sessions)
+}
+
+// session returns the session of ccon, or nil if it isn't one of the connection's
+func (con *Connection) session(ccon *rcComm) *session {
+	for _, s := range con.sessions {
+		if s.ccon == ccon {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// lockSession waits for the goroutine using the data object handles of ccon's session, if any, or for ctx to be done
+func (con *Connection) lockSession(ctx context.Context, ccon *rcComm) error {
+	if s := con.session(ccon); s != nil {
+		return s.acquire(ctx)
+	}
+
+	return nil
+}
+
+// unlockSession releases ccon's session, locked by lockSession or handleCcon
+func (con *Connection) unlockSession(ccon *rcComm) {
+	if s := con.session(ccon); s != nil {
+		s.release()
+	}
+}
+
+// sessionHandle returns the handle cTransport.Open returns for the iRODS handle opened on ccon
+func (con *Connection) sessionHandle(ccon *rcComm, handle int) int {
+	for i, s := range con.sessions {
+		if s.ccon == ccon {
+			return i<<sessionHandleShift | handle
+		}
+	}
+
+	return handle
+}
+
+// handleCcon waits for the session a data object handle was opened on, and returns it along with the iRODS handle.
+// Release it with unlockSession.
+func (con *Connection) handleCcon(handle int) (*rcComm, int, error) {
+	return con.handleCconContext(context.Background(), handle)
+}
+
+// handleCconContext is like handleCcon, but gives up waiting for the session when ctx is done
+func (con *Connection) handleCconContext(ctx context.Context, handle int) (*rcComm, int, error) {
+	i := handle >> sessionHandleShift
+
+	if handle < 0 || i >= len(con.sessions) {
+		return nil, -1, NewTransportError(-1, fmt.Sprintf("invalid data object handle %v", handle))
+	}
+
+	s := con.sessions[i]
+	if err := s.acquire(ctx); err != nil {
+		return nil, -1, err
+	}
+
+	return s.ccon, handle & (1<<sessionHandleShift - 1), nil
+}
+
+// eachSession checks out every session of the connection, then runs fn on each of them, for settings that belong
+// to the session like tickets. Calls are serialized, so two of them can't each hold part of the sessions.
+func (con *Connection) eachSession(fn func(ccon *rcComm) error) error {
+	con.allSessions.Lock()
+	defer con.allSessions.Unlock()
+
+	cconns := make([]*rcComm, 0, len(con.sessions))
+
+	defer func() {
+		for _, ccon := range cconns {
+			con.returnCcon(ccon)
+		}
+	}()
+
+	for range con.sessions {
+		ccon, err := con.getCcon()
+		if err != nil {
+			return err
+		}
+
+		cconns = append(cconns, ccon)
+	}
+
+	for _, ccon := range cconns {
+		if err := fn(ccon); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// adoptSessions replaces the rcComm_t handles of the connection's sessions with the ones of fresh, a connection made
+// with the same options, then disconnects the old handles. Every session is checked out meanwhile, so operations
+// still using the old handles finish first, and nothing uses them afterwards.
+func (con *Connection) adoptSessions(fresh *Connection) error {
+	con.allSessions.Lock()
+	defer con.allSessions.Unlock()
+
+	old := make([]*rcComm, 0, len(con.sessions))
+
+	for range con.sessions {
+		ccon, err := con.getCcon()
+		if err != nil {
+			for _, ccon := range old {
+				con.returnCcon(ccon)
+			}
+
+			return err
+		}
+
+		old = append(old, ccon)
+	}
+
+	for i, s := range con.sessions {
+		s.ccon = fresh.sessions[i].ccon
+	}
+	con.ccon = fresh.ccon
+
+	// The old sessions are most likely dead already
+	for _, ccon := range old {
+		cDisconnect(ccon)
+	}
+
+	for _, s := range con.sessions {
+		con.returnCcon(s.ccon)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// openSessions connects and logs in the sessions after the first one, until there are ConnectionOptions.Sessions.
+// password is the one the first session logged in with (the PAM token for PAMAuth connections).
+func (con *Connection) openSessions(password *C.char) error {
+	host := C.CString(con.Options.Host)
+	port := C.int(con.Options.Port)
+	username := C.CString(con.Options.Username)
+	zone := C.CString(con.Options.Zone)
+
+	defer C.free(unsafe.Pointer(host))
+	defer C.free(unsafe.Pointer(username))
+	defer C.free(unsafe.Pointer(zone))
+
+	for len(con.sessions) < con.Options.sessionCount() {
+		var (
+			ccon   *rcComm
+			status C.int
+			errMsg *C.char
+		)
+
+		withSSLEnv(con.Options, func() {
+			status = C.gorods_connect_env(&ccon, host, port, username, zone, &errMsg)
+		})
+
+		if status != 0 {
+			return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: Session %v: %v", len(con.sessions)+1, C.GoString(errMsg)))
+		}
+
+		if status = C.clientLoginWithPassword(ccon, password); status != 0 {
+			C.rcDisconnect(ccon)
+			return newError(Fatal, int(status), fmt.Sprintf("iRODS Connect Failed: Session %v: clientLoginWithPassword error", len(con.sessions)+1))
+		}
+
+		con.sessions = append(con.sessions, newSession(ccon))
+		con.cconBuffer <- ccon
+	}
+
+	return nil
+}
+
+// cDisconnect ends the session of ccon
+func cDisconnect(ccon *rcComm) error {
+	if status := C.rcDisconnect(ccon); status < 0 {
+		return NewTransportError(int(status), "rcDisconnect")
+	}
+
+	return nil
+}
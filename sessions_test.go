@@ -0,0 +1,104 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionCount(t *testing.T) {
+	for sessions, expected := range map[int]int{-1: 1, 0: 1, 1: 1, 4: 4} {
+		if count := (&ConnectionOptions{Sessions: sessions}).sessionCount(); count != expected {
+			t.Errorf("Expected %v sessions for Sessions: %v, got %v", expected, sessions, count)
+		}
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	opts := testCreds
+	opts.Sessions = 3
+
+	irods, err := NewConnection(&opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer irods.Disconnect()
+
+	if irods.Sessions() != 3 {
+		t.Fatalf("Expected 3 sessions, got %v", irods.Sessions())
+	}
+
+	home := fmt.Sprintf("/%v/home/%v", opts.Zone, opts.Username)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := irods.PathType(home); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestQueryCursorSession(t *testing.T) {
+	first, second := new(rcComm), new(rcComm)
+	con := &Connection{cconBuffer: make(chan *rcComm, 2), sessions: []*session{newSession(first), newSession(second)}}
+	con.cconBuffer <- first
+	con.cconBuffer <- second
+
+	cur := &QueryCursor{con: con, query: &Query{}, handle: con.sessionHandle(second, 0)}
+
+	// The statement was opened on the second session, which is busy with a data object handle
+	if _, _, err := con.handleCcon(cur.handle); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if cur.NextContext(ctx) || cur.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected the next page to wait for the second session, got %v", cur.Err())
+	}
+
+	// The first session was left alone
+	if ccon, err := con.getCconContext(context.Background()); err != nil || ccon != first {
+		t.Errorf("Expected the first session to be free, got %v", err)
+	}
+}
+
+func TestQueryCursorCloseFinished(t *testing.T) {
+	ccon := new(rcComm)
+	con := &Connection{cconBuffer: make(chan *rcComm, 1), sessions: []*session{newSession(ccon)}}
+	con.cconBuffer <- ccon
+
+	// The iteration ended, so the statement was already released, while the session is busy with another handle
+	cur := &QueryCursor{con: con, query: &Query{}, handle: con.sessionHandle(ccon, 0), started: true, done: true}
+
+	if _, _, err := con.handleCcon(cur.handle); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- cur.Close() }()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close not to wait for the session once the iteration ended")
+	}
+}
@@ -0,0 +1,121 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+// Client-server negotiation policies for ConnectionOptions.ClientServerPolicy
+const (
+	CSNegRequire  = irodsproto.CSNegRequire
+	CSNegRefuse   = irodsproto.CSNegRefuse
+	CSNegDontCare = irodsproto.CSNegDontCare
+)
+
+// Server verification modes for ConnectionOptions.SSLVerifyServer
+const (
+	SSLVerifyHostname = irodsproto.VerifyHostname
+	SSLVerifyCert     = irodsproto.VerifyCert
+	SSLVerifyNone     = irodsproto.VerifyNone
+)
+
+// sslEnvMu serializes every connection made with the iRODS C API. The environment variables of the SSL options are
+// process wide, so a connection without SSL options would otherwise pick up the ones set for another connection.
+var sslEnvMu sync.Mutex
+
+// validateSSL checks the SSL related options
+func (conOpts *ConnectionOptions) validateSSL() error {
+	switch conOpts.ClientServerPolicy {
+	case "", CSNegRequire, CSNegRefuse, CSNegDontCare:
+	default:
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Unknown ClientServerPolicy %q", conOpts.ClientServerPolicy))
+	}
+
+	switch conOpts.SSLVerifyServer {
+	case "", SSLVerifyHostname, SSLVerifyCert, SSLVerifyNone:
+	default:
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Connect Failed: Unknown SSLVerifyServer %q", conOpts.SSLVerifyServer))
+	}
+
+	if conOpts.EncryptionKeySize < 0 || conOpts.EncryptionSaltSize < 0 || conOpts.EncryptionNumHashRounds < 0 {
+		return newError(Fatal, -1, "iRODS Connect Failed: Encryption parameters can't be negative")
+	}
+
+	return nil
+}
+
+// sslEnv returns the iRODS environment variables matching the SSL options that are set. The iRODS C API reads
+// them when connecting, and they take precedence over irods_environment.json.
+func (conOpts *ConnectionOptions) sslEnv() map[string]string {
+	env := make(map[string]string)
+
+	if conOpts.ClientServerPolicy != "" {
+		env["IRODS_CLIENT_SERVER_NEGOTIATION"] = "request_server_negotiation"
+		env["IRODS_CLIENT_SERVER_POLICY"] = conOpts.ClientServerPolicy
+	}
+
+	if conOpts.SSLCACertificateFile != "" {
+		env["IRODS_SSL_CA_CERTIFICATE_FILE"] = conOpts.SSLCACertificateFile
+	}
+
+	if conOpts.SSLVerifyServer != "" {
+		env["IRODS_SSL_VERIFY_SERVER"] = conOpts.SSLVerifyServer
+	}
+
+	if conOpts.EncryptionAlgorithm != "" {
+		env["IRODS_ENCRYPTION_ALGORITHM"] = conOpts.EncryptionAlgorithm
+	}
+
+	if conOpts.EncryptionKeySize > 0 {
+		env["IRODS_ENCRYPTION_KEY_SIZE"] = strconv.Itoa(conOpts.EncryptionKeySize)
+	}
+
+	if conOpts.EncryptionSaltSize > 0 {
+		env["IRODS_ENCRYPTION_SALT_SIZE"] = strconv.Itoa(conOpts.EncryptionSaltSize)
+	}
+
+	if conOpts.EncryptionNumHashRounds > 0 {
+		env["IRODS_ENCRYPTION_NUM_HASH_ROUNDS"] = strconv.Itoa(conOpts.EncryptionNumHashRounds)
+	}
+
+	return env
+}
+
+// sslConfig returns the SSL options for the pure-Go client
+func (conOpts *ConnectionOptions) sslConfig() irodsproto.SSLConfig {
+	return irodsproto.SSLConfig{
+		ClientServerPolicy:      conOpts.ClientServerPolicy,
+		CACertificateFile:       conOpts.SSLCACertificateFile,
+		VerifyServer:            conOpts.SSLVerifyServer,
+		EncryptionAlgorithm:     conOpts.EncryptionAlgorithm,
+		EncryptionKeySize:       conOpts.EncryptionKeySize,
+		EncryptionSaltSize:      conOpts.EncryptionSaltSize,
+		EncryptionNumHashRounds: conOpts.EncryptionNumHashRounds,
+	}
+}
+
+// withSSLEnv runs connect with the environment variables of the SSL options set, and restores them afterwards.
+// It must wrap every C API connect, including the ones of connections without SSL options, see sslEnvMu.
+func withSSLEnv(conOpts *ConnectionOptions, connect func()) {
+	sslEnvMu.Lock()
+	defer sslEnvMu.Unlock()
+
+	for key, value := range conOpts.sslEnv() {
+		if old, ok := os.LookupEnv(key); ok {
+			defer os.Setenv(key, old)
+		} else {
+			defer os.Unsetenv(key)
+		}
+
+		os.Setenv(key, value)
+	}
+
+	connect()
+}
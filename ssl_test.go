@@ -0,0 +1,101 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestValidateSSL(t *testing.T) {
+	if err := (&ConnectionOptions{ClientServerPolicy: "CS_NEG_MAYBE"}).validateSSL(); err == nil {
+		t.Error("Expected an unknown ClientServerPolicy to be rejected")
+	}
+
+	if err := (&ConnectionOptions{SSLVerifyServer: "always"}).validateSSL(); err == nil {
+		t.Error("Expected an unknown SSLVerifyServer to be rejected")
+	}
+
+	if err := (&ConnectionOptions{EncryptionKeySize: -1}).validateSSL(); err == nil {
+		t.Error("Expected a negative key size to be rejected")
+	}
+
+	if err := (&ConnectionOptions{ClientServerPolicy: CSNegRequire, SSLVerifyServer: SSLVerifyCert}).validateSSL(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSSLEnv(t *testing.T) {
+	opts := &ConnectionOptions{
+		ClientServerPolicy:   CSNegRequire,
+		SSLCACertificateFile: "/etc/irods/ca.pem",
+		EncryptionKeySize:    32,
+	}
+
+	env := opts.sslEnv()
+
+	expected := map[string]string{
+		"IRODS_CLIENT_SERVER_NEGOTIATION": "request_server_negotiation",
+		"IRODS_CLIENT_SERVER_POLICY":      "CS_NEG_REQUIRE",
+		"IRODS_SSL_CA_CERTIFICATE_FILE":   "/etc/irods/ca.pem",
+		"IRODS_ENCRYPTION_KEY_SIZE":       "32",
+	}
+
+	if len(env) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("Expected %v=%v, got %q", key, value, env[key])
+		}
+	}
+
+	if len((&ConnectionOptions{}).sslEnv()) != 0 {
+		t.Error("Expected no variables without SSL options")
+	}
+}
+
+func TestWithSSLEnv(t *testing.T) {
+	os.Setenv("IRODS_SSL_VERIFY_SERVER", "cert")
+	os.Unsetenv("IRODS_CLIENT_SERVER_POLICY")
+	defer os.Unsetenv("IRODS_SSL_VERIFY_SERVER")
+
+	withSSLEnv(&ConnectionOptions{ClientServerPolicy: CSNegDontCare, SSLVerifyServer: SSLVerifyNone}, func() {
+		if os.Getenv("IRODS_CLIENT_SERVER_POLICY") != CSNegDontCare || os.Getenv("IRODS_SSL_VERIFY_SERVER") != SSLVerifyNone {
+			t.Error("Expected the SSL options to be set while connecting")
+		}
+	})
+
+	if _, ok := os.LookupEnv("IRODS_CLIENT_SERVER_POLICY"); ok {
+		t.Error("Expected IRODS_CLIENT_SERVER_POLICY to be unset again")
+	}
+
+	if os.Getenv("IRODS_SSL_VERIFY_SERVER") != "cert" {
+		t.Error("Expected IRODS_SSL_VERIFY_SERVER to be restored")
+	}
+}
+
+func TestWithSSLEnvSerialized(t *testing.T) {
+	sslEnvMu.Lock()
+
+	connected := make(chan struct{})
+	go withSSLEnv(&ConnectionOptions{}, func() { close(connected) })
+
+	// Another connection is setting its SSL options
+	select {
+	case <-connected:
+		t.Error("Expected a connection without SSL options to wait for the other connections")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	sslEnvMu.Unlock()
+
+	select {
+	case <-connected:
+	case <-time.After(time.Second):
+		t.Error("Expected to connect once the other connections are done")
+	}
+}
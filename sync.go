@@ -0,0 +1,510 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Sync directions used in SyncOptions
+const (
+	SyncToCollection = iota
+	SyncToLocal
+)
+
+// Sync operations, used in SyncAction
+const (
+	SyncPut = iota
+	SyncGet
+	SyncCreateCollection
+	SyncCreateDir
+	SyncDeleteRemote
+	SyncDeleteLocal
+)
+
+// SyncOptions control the behavior of Collection.Sync.
+// Direction is either SyncToCollection (upload) or SyncToLocal (download).
+// Files are compared by size and modification time, or by size and checksum if Checksum is true.
+// If Delete is true, files and directories that only exist at the destination are removed.
+// If DryRun is true, the planned actions are returned without being carried out.
+// Resource is used when creating data objects, and Transfer controls how files are moved (see TransferOptions).
+// Once Transfer.Context is cancelled, the remaining actions fail with the context's error.
+type SyncOptions struct {
+	Direction int
+	Checksum  bool
+	Delete    bool
+	DryRun    bool
+	Resource  interface{}
+	Transfer  TransferOptions
+}
+
+// SyncAction describes a single step of a sync: the operation, the local and iRODS paths involved,
+// why it was needed, and the error encountered when carrying it out (if any).
+type SyncAction struct {
+	Op        int
+	LocalPath string
+	Path      string
+	Reason    string
+	Err       error
+
+	obj IRodsObj
+}
+
+// String returns a one line summary of the action, similar to the output of irsync -v
+func (act *SyncAction) String() string {
+	var str string
+
+	switch act.Op {
+	case SyncPut:
+		str = fmt.Sprintf("put %v -> %v", act.LocalPath, act.Path)
+	case SyncGet:
+		str = fmt.Sprintf("get %v -> %v", act.Path, act.LocalPath)
+	case SyncCreateCollection:
+		str = fmt.Sprintf("mkdir %v", act.Path)
+	case SyncCreateDir:
+		str = fmt.Sprintf("mkdir %v", act.LocalPath)
+	case SyncDeleteRemote:
+		str = fmt.Sprintf("rm %v", act.Path)
+	case SyncDeleteLocal:
+		str = fmt.Sprintf("rm %v", act.LocalPath)
+	}
+
+	if act.Reason != "" {
+		str += " (" + act.Reason + ")"
+	}
+
+	if act.Err != nil {
+		str += ": " + act.Err.Error()
+	}
+
+	return str
+}
+
+// SyncReport holds every action planned (and carried out, unless DryRun was set) by Collection.Sync, in order.
+type SyncReport struct {
+	Actions []*SyncAction
+}
+
+// Failed returns the actions which couldn't be carried out
+func (rep *SyncReport) Failed() []*SyncAction {
+	failed := make([]*SyncAction, 0)
+
+	for _, act := range rep.Actions {
+		if act.Err != nil {
+			failed = append(failed, act)
+		}
+	}
+
+	return failed
+}
+
+// String returns the summary of every action, one per line
+func (rep *SyncReport) String() string {
+	var str string
+
+	for _, act := range rep.Actions {
+		str += act.String() + "\n"
+	}
+
+	return str
+}
+
+type syncer struct {
+	opts    SyncOptions
+	con     *Connection
+	cols    map[string]*Collection
+	actions []*SyncAction
+}
+
+// Sync compares the local directory localDir with the collection, similar to irsync -r.
+// Only files that are missing or differ are transferred, in the direction set by opts.Direction.
+// Errors encountered while transferring individual files are recorded in the report, and don't stop the sync.
+// The returned error is non-nil if either side couldn't be listed, or any action failed.
+func (col *Collection) Sync(localDir string, opts SyncOptions) (*SyncReport, error) {
+
+	s := &syncer{
+		opts: opts,
+		con:  col.con,
+		cols: map[string]*Collection{col.path: col},
+	}
+
+	var err error
+
+	switch opts.Direction {
+	case SyncToCollection:
+		if info, er := os.Stat(localDir); er != nil || !info.IsDir() {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v doesn't exist or isn't a directory", localDir))
+		}
+		err = s.planUpload(localDir, col.path, col)
+	case SyncToLocal:
+		if info, er := os.Stat(localDir); er != nil {
+			s.add(&SyncAction{Op: SyncCreateDir, LocalPath: localDir, Path: col.path, Reason: "missing"})
+		} else if !info.IsDir() {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v isn't a directory", localDir))
+		}
+		err = s.planDownload(col, localDir)
+	default:
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: Unknown direction %v", opts.Direction))
+	}
+
+	report := &SyncReport{s.actions}
+
+	if err != nil {
+		return report, err
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	ctx := opts.Transfer.context()
+
+	for _, act := range s.actions {
+		if er := ctx.Err(); er != nil {
+			act.Err = er
+			continue
+		}
+
+		act.Err = s.run(act)
+	}
+
+	if failed := report.Failed(); len(failed) > 0 {
+		return report, newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v of %v actions failed, first error: %v", len(failed), len(report.Actions), failed[0].Err))
+	}
+
+	return report, nil
+}
+
+func (s *syncer) add(act *SyncAction) {
+	s.actions = append(s.actions, act)
+}
+
+// remoteEntries lists the contents of col by name. A nil col is treated as empty.
+func remoteEntries(col *Collection) (map[string]IRodsObj, error) {
+	entries := make(map[string]IRodsObj)
+
+	if col == nil {
+		return entries, nil
+	}
+
+	objs, err := col.All()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objs {
+		entries[obj.Name()] = obj
+	}
+
+	return entries, nil
+}
+
+// remoteNames returns the names of entries in sorted order
+func remoteNames(entries map[string]IRodsObj) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// localEntries lists the contents of dir by name. A missing dir is treated as empty.
+func localEntries(dir string) (map[string]os.FileInfo, error) {
+	entries := make(map[string]os.FileInfo)
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v", err)).wrap(err)
+	}
+
+	for _, info := range infos {
+		entries[info.Name()] = info
+	}
+
+	return entries, nil
+}
+
+// localNames returns the names of entries in sorted order
+func localNames(entries map[string]os.FileInfo) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// planUpload compares localDir with the collection at colPath. col is nil if the collection doesn't exist yet.
+func (s *syncer) planUpload(localDir string, colPath string, col *Collection) error {
+
+	local, err := localEntries(localDir)
+	if err != nil {
+		return err
+	}
+
+	remote, err := remoteEntries(col)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range localNames(local) {
+		info := local[name]
+		localPath := filepath.Join(localDir, name)
+		objPath := colPath + "/" + name
+
+		obj, exists := remote[name]
+
+		if info.IsDir() {
+			var sub *Collection
+
+			if exists && obj.Type() == CollectionType {
+				sub = obj.(*Collection)
+			} else {
+				if exists {
+					s.add(&SyncAction{Op: SyncDeleteRemote, LocalPath: localPath, Path: objPath, Reason: "replaced by directory", obj: obj})
+				}
+				s.add(&SyncAction{Op: SyncCreateCollection, LocalPath: localPath, Path: objPath, Reason: "missing"})
+			}
+
+			if er := s.planUpload(localPath, objPath, sub); er != nil {
+				return er
+			}
+			continue
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		if !exists {
+			s.add(&SyncAction{Op: SyncPut, LocalPath: localPath, Path: objPath, Reason: "missing"})
+			continue
+		}
+
+		if obj.Type() != DataObjType {
+			s.add(&SyncAction{Op: SyncDeleteRemote, LocalPath: localPath, Path: objPath, Reason: "replaced by file", obj: obj})
+			s.add(&SyncAction{Op: SyncPut, LocalPath: localPath, Path: objPath, Reason: "missing"})
+			continue
+		}
+
+		reason, er := s.differs(localPath, info, obj.(*DataObj), true)
+		if er != nil {
+			return er
+		}
+
+		if reason != "" {
+			s.add(&SyncAction{Op: SyncPut, LocalPath: localPath, Path: objPath, Reason: reason, obj: obj})
+		}
+	}
+
+	if s.opts.Delete {
+		for _, name := range remoteNames(remote) {
+			if _, ok := local[name]; !ok {
+				s.add(&SyncAction{Op: SyncDeleteRemote, LocalPath: filepath.Join(localDir, name), Path: colPath + "/" + name, Reason: "not in source", obj: remote[name]})
+			}
+		}
+	}
+
+	return nil
+}
+
+// planDownload compares col with the local directory localDir, which might not exist yet.
+func (s *syncer) planDownload(col *Collection, localDir string) error {
+
+	local, err := localEntries(localDir)
+	if err != nil {
+		return err
+	}
+
+	remote, err := remoteEntries(col)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range remoteNames(remote) {
+		obj := remote[name]
+		localPath := filepath.Join(localDir, name)
+
+		info, exists := local[name]
+
+		if obj.Type() == CollectionType {
+			if !exists || !info.IsDir() {
+				if exists {
+					s.add(&SyncAction{Op: SyncDeleteLocal, LocalPath: localPath, Path: obj.Path(), Reason: "replaced by collection"})
+				}
+				s.add(&SyncAction{Op: SyncCreateDir, LocalPath: localPath, Path: obj.Path(), Reason: "missing"})
+			}
+
+			if er := s.planDownload(obj.(*Collection), localPath); er != nil {
+				return er
+			}
+			continue
+		}
+
+		if !exists {
+			s.add(&SyncAction{Op: SyncGet, LocalPath: localPath, Path: obj.Path(), Reason: "missing", obj: obj})
+			continue
+		}
+
+		if !info.Mode().IsRegular() {
+			s.add(&SyncAction{Op: SyncDeleteLocal, LocalPath: localPath, Path: obj.Path(), Reason: "replaced by data object"})
+			s.add(&SyncAction{Op: SyncGet, LocalPath: localPath, Path: obj.Path(), Reason: "missing", obj: obj})
+			continue
+		}
+
+		reason, er := s.differs(localPath, info, obj.(*DataObj), false)
+		if er != nil {
+			return er
+		}
+
+		if reason != "" {
+			s.add(&SyncAction{Op: SyncGet, LocalPath: localPath, Path: obj.Path(), Reason: reason, obj: obj})
+		}
+	}
+
+	if s.opts.Delete {
+		for _, name := range localNames(local) {
+			if _, ok := remote[name]; !ok {
+				s.add(&SyncAction{Op: SyncDeleteLocal, LocalPath: filepath.Join(localDir, name), Path: col.path + "/" + name, Reason: "not in source"})
+			}
+		}
+	}
+
+	return nil
+}
+
+// differs returns the reason the local file and data object need syncing, or an empty string if they match
+func (s *syncer) differs(localPath string, info os.FileInfo, obj *DataObj, upload bool) (string, error) {
+
+	if info.Size() != obj.Size() {
+		return "size differs", nil
+	}
+
+	if s.opts.Checksum {
+		remote, err := obj.Chksum()
+		if err != nil {
+			return "", err
+		}
+
+		local, err := localChecksum(localPath, remote)
+		if err != nil {
+			return "", err
+		}
+
+		if local != remote {
+			return "checksum differs", nil
+		}
+
+		return "", nil
+	}
+
+	// iRODS stores modify times with second precision
+	localTime := info.ModTime().Truncate(time.Second)
+
+	if upload && localTime.After(obj.ModifyTime()) {
+		return "local file is newer", nil
+	}
+
+	if !upload && obj.ModifyTime().After(localTime) {
+		return "data object is newer", nil
+	}
+
+	return "", nil
+}
+
+// run carries out a single action
+func (s *syncer) run(act *SyncAction) error {
+	switch act.Op {
+	case SyncPut:
+		col, err := s.collection(path.Dir(act.Path))
+		if err != nil {
+			return err
+		}
+
+		_, err = col.PutParallel(act.LocalPath, DataObjOptions{
+			Name:     path.Base(act.Path),
+			Force:    true,
+			Resource: s.opts.Resource,
+		}, s.opts.Transfer)
+
+		return err
+
+	case SyncGet:
+		obj := act.obj.(*DataObj)
+
+		if err := obj.DownloadToParallel(act.LocalPath, s.opts.Transfer); err != nil {
+			return err
+		}
+
+		// Keep the local modification time in line with iRODS, so the next sync sees the file as unchanged
+		if err := os.Chtimes(act.LocalPath, time.Now(), obj.ModifyTime()); err != nil {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v", err)).wrap(err)
+		}
+
+		return nil
+
+	case SyncCreateCollection:
+		parent, err := s.collection(path.Dir(act.Path))
+		if err != nil {
+			return err
+		}
+
+		col, err := parent.CreateSubCollection(path.Base(act.Path))
+		if err != nil {
+			return err
+		}
+
+		s.cols[act.Path] = col
+
+		return nil
+
+	case SyncCreateDir:
+		if err := os.MkdirAll(act.LocalPath, 0777); err != nil {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v", err)).wrap(err)
+		}
+
+		return nil
+
+	case SyncDeleteRemote:
+		return act.obj.Delete(true)
+
+	case SyncDeleteLocal:
+		if err := os.RemoveAll(act.LocalPath); err != nil {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: %v", err)).wrap(err)
+		}
+
+		return nil
+	}
+
+	return newError(Fatal, -1, fmt.Sprintf("iRODS Sync Failed: Unknown operation %v", act.Op))
+}
+
+// collection returns the collection at colPath, opening it if it wasn't seen during planning
+func (s *syncer) collection(colPath string) (*Collection, error) {
+	if col, ok := s.cols[colPath]; ok {
+		return col, nil
+	}
+
+	col, err := s.con.Collection(CollectionOptions{
+		Path:      colPath,
+		SkipCache: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cols[colPath] = col
+
+	return col, nil
+}
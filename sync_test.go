@@ -0,0 +1,208 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLocalChecksum(t *testing.T) {
+
+	f, err := ioutil.TempFile("", "gorods-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString("hello world\n")
+	f.Close()
+
+	tests := map[string]string{
+		"":             "6f5902ac237024bdd0c176cb93063dc4",
+		"md5:abc":      "md5:6f5902ac237024bdd0c176cb93063dc4",
+		"sha2:abc":     "sha2:qUiQTy8PR5uPgZdpSzAYSw0u0cHNKh7A+4XSmaGSpEc=",
+		"0123456789ab": "6f5902ac237024bdd0c176cb93063dc4",
+	}
+
+	for remote, expected := range tests {
+		if sum, er := localChecksum(f.Name(), remote); er != nil {
+			t.Error(er)
+		} else if sum != expected {
+			t.Errorf("Expected checksum %v for remote format %q, got %v", expected, remote, sum)
+		}
+	}
+}
+
+// syncFixture returns a collection holding same.txt, changed.txt, newer.txt and extra.txt, and a local directory
+// where same.txt is unchanged, changed.txt has another size, newer.txt was modified later and sub/new.txt was added
+func syncFixture(t *testing.T) (*Connection, *Collection, string) {
+	con := memConnection(t)
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	col, err := home.CreateSubCollection("sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{"same.txt": "same", "changed.txt": "old", "newer.txt": "abc", "extra.txt": "x"} {
+		obj, err := col.CreateDataObj(DataObjOptions{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := obj.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "gorods-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	for name, content := range map[string]string{"same.txt": "same", "changed.txt": "changed", "newer.txt": "xyz", "sub/new.txt": "new"} {
+		localPath := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(localPath, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		mtime := past
+		if name == "newer.txt" {
+			mtime = future
+		}
+		if err := os.Chtimes(localPath, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Open the collection again so it lists the data objects written above
+	col, err = con.Collection(CollectionOptions{Path: col.Path(), SkipCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return con, col, dir
+}
+
+// memContent returns the content of the data object at p, or an error if it doesn't exist
+func memContent(con *Connection, p string) (string, error) {
+	h, err := con.transport.Open(p, "", -1, 0)
+	if err != nil {
+		return "", err
+	}
+	defer con.transport.Close(h)
+
+	data, err := con.transport.Read(h, 1024)
+
+	return string(data), err
+}
+
+func TestSyncToCollection(t *testing.T) {
+	con, col, dir := syncFixture(t)
+	defer os.RemoveAll(dir)
+
+	expected := []SyncAction{
+		{Op: SyncPut, Path: col.Path() + "/changed.txt", Reason: "size differs"},
+		{Op: SyncPut, Path: col.Path() + "/newer.txt", Reason: "local file is newer"},
+		{Op: SyncCreateCollection, Path: col.Path() + "/sub", Reason: "missing"},
+		{Op: SyncPut, Path: col.Path() + "/sub/new.txt", Reason: "missing"},
+		{Op: SyncDeleteRemote, Path: col.Path() + "/extra.txt", Reason: "not in source"},
+	}
+
+	checkActions := func(report *SyncReport) {
+		if len(report.Actions) != len(expected) {
+			t.Fatalf("Expected %v actions, got:\n%v", len(expected), report)
+		}
+
+		for i, act := range report.Actions {
+			if act.Op != expected[i].Op || act.Path != expected[i].Path || act.Reason != expected[i].Reason || act.Err != nil {
+				t.Errorf("Expected action %v to be %+v, got %v", i, expected[i], act)
+			}
+		}
+	}
+
+	report, err := col.Sync(dir, SyncOptions{Direction: SyncToCollection, Delete: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkActions(report)
+
+	if content, err := memContent(con, col.Path()+"/changed.txt"); err != nil || content != "old" {
+		t.Errorf("Expected the dry run to leave changed.txt alone, got %q (%v)", content, err)
+	}
+
+	if _, err := memContent(con, col.Path()+"/extra.txt"); err != nil {
+		t.Errorf("Expected the dry run to leave extra.txt alone, got %v", err)
+	}
+
+	report, err = col.Sync(dir, SyncOptions{Direction: SyncToCollection, Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkActions(report)
+
+	for name, expected := range map[string]string{"same.txt": "same", "changed.txt": "changed", "newer.txt": "xyz", "sub/new.txt": "new"} {
+		if content, err := memContent(con, col.Path()+"/"+name); err != nil || content != expected {
+			t.Errorf("Expected %v to hold %q, got %q (%v)", name, expected, content, err)
+		}
+	}
+
+	if _, err := memContent(con, col.Path()+"/extra.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected extra.txt to be deleted, got %v", err)
+	}
+}
+
+func TestSyncToLocal(t *testing.T) {
+	_, col, dir := syncFixture(t)
+	defer os.RemoveAll(dir)
+
+	report, err := col.Sync(dir, SyncOptions{Direction: SyncToLocal, Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newer.txt has the same size and a later local modification time so it's left alone, the local same.txt is older
+	expected := []SyncAction{
+		{Op: SyncGet, LocalPath: filepath.Join(dir, "changed.txt"), Reason: "size differs"},
+		{Op: SyncGet, LocalPath: filepath.Join(dir, "extra.txt"), Reason: "missing"},
+		{Op: SyncGet, LocalPath: filepath.Join(dir, "same.txt"), Reason: "data object is newer"},
+		{Op: SyncDeleteLocal, LocalPath: filepath.Join(dir, "sub"), Reason: "not in source"},
+	}
+
+	if len(report.Actions) != len(expected) {
+		t.Fatalf("Expected %v actions, got:\n%v", len(expected), report)
+	}
+
+	for i, act := range report.Actions {
+		if act.Op != expected[i].Op || act.LocalPath != expected[i].LocalPath || act.Reason != expected[i].Reason || act.Err != nil {
+			t.Errorf("Expected action %v to be %+v, got %v", i, expected[i], act)
+		}
+	}
+
+	for name, expected := range map[string]string{"same.txt": "same", "changed.txt": "old", "newer.txt": "xyz", "extra.txt": "x"} {
+		if content, err := ioutil.ReadFile(filepath.Join(dir, name)); err != nil || string(content) != expected {
+			t.Errorf("Expected %v to hold %q, got %q (%v)", name, expected, content, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Errorf("Expected sub to be deleted, got %v", err)
+	}
+}
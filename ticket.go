@@ -0,0 +1,536 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ticket types, used by TicketOptions.Type
+const (
+	TicketRead  = "read"
+	TicketWrite = "write"
+)
+
+// ticketChars are used to generate ticket strings, like iticket does
+const ticketChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// TicketOptions is used when creating tickets. Limits left at zero (and a zero Expiry) mean no limit.
+type TicketOptions struct {
+	// Name is the ticket string, one is generated when it's empty
+	Name string
+	// Type is TicketRead (the default) or TicketWrite
+	Type string
+
+	Expiry         time.Time
+	UsesLimit      int
+	WriteFileLimit int
+	WriteByteLimit int64
+
+	// Hosts, Users and Groups restrict who can use the ticket
+	Hosts  []string
+	Users  []string
+	Groups []string
+}
+
+// Ticket contains information representing an iRODS ticket, which grants access to a data object or collection
+// to whoever presents its string (see Connection.SetTicket).
+type Ticket struct {
+	id      string
+	name    string
+	typ     string
+	objType int
+	path    string
+
+	ownerName string
+	ownerZone string
+
+	usesCount      int
+	usesLimit      int
+	writeFileCount int
+	writeFileLimit int
+	writeByteCount int64
+	writeByteLimit int64
+
+	expiry     time.Time
+	createTime time.Time
+	modifyTime time.Time
+
+	hosts  []string
+	users  []string
+	groups []string
+
+	con *Connection
+}
+
+// Tickets is a slice of *Ticket.
+type Tickets []*Ticket
+
+// FindByName returns the ticket with the string name, or nil if it isn't in the slice.
+func (tkts Tickets) FindByName(name string) *Ticket {
+	for _, tkt := range tkts {
+		if tkt.name == name {
+			return tkt
+		}
+	}
+
+	return nil
+}
+
+// String returns the ticket type, string and path.
+func (tkt *Ticket) String() string {
+	return fmt.Sprintf("%v:%v %v", tkt.typ, tkt.name, tkt.path)
+}
+
+// Id returns the ticket's id.
+func (tkt *Ticket) Id() string {
+	return tkt.id
+}
+
+// Name returns the ticket string, which is passed to Connection.SetTicket to use it.
+func (tkt *Ticket) Name() string {
+	return tkt.name
+}
+
+// Type returns TicketRead or TicketWrite.
+func (tkt *Ticket) Type() string {
+	return tkt.typ
+}
+
+// ObjType returns DataObjType or CollectionType, depending on what the ticket grants access to.
+func (tkt *Ticket) ObjType() int {
+	return tkt.objType
+}
+
+// Path returns the path of the data object or collection the ticket grants access to.
+func (tkt *Ticket) Path() string {
+	return tkt.path
+}
+
+// OwnerName returns the name of the user who created the ticket.
+func (tkt *Ticket) OwnerName() string {
+	return tkt.ownerName
+}
+
+// OwnerZone returns the zone of the user who created the ticket.
+func (tkt *Ticket) OwnerZone() string {
+	return tkt.ownerZone
+}
+
+// UsesCount returns the number of times the ticket was used.
+func (tkt *Ticket) UsesCount() int {
+	return tkt.usesCount
+}
+
+// UsesLimit returns the number of times the ticket can be used, 0 means no limit.
+func (tkt *Ticket) UsesLimit() int {
+	return tkt.usesLimit
+}
+
+// WriteFileCount returns the number of files written with the ticket.
+func (tkt *Ticket) WriteFileCount() int {
+	return tkt.writeFileCount
+}
+
+// WriteFileLimit returns the number of files that can be written with the ticket, 0 means no limit.
+func (tkt *Ticket) WriteFileLimit() int {
+	return tkt.writeFileLimit
+}
+
+// WriteByteCount returns the number of bytes written with the ticket.
+func (tkt *Ticket) WriteByteCount() int64 {
+	return tkt.writeByteCount
+}
+
+// WriteByteLimit returns the number of bytes that can be written with the ticket, 0 means no limit.
+func (tkt *Ticket) WriteByteLimit() int64 {
+	return tkt.writeByteLimit
+}
+
+// Expiry returns the time the ticket expires, the zero time means it doesn't.
+func (tkt *Ticket) Expiry() time.Time {
+	return tkt.expiry
+}
+
+// CreateTime returns the time the ticket was created.
+func (tkt *Ticket) CreateTime() time.Time {
+	return tkt.createTime
+}
+
+// ModifyTime returns the time the ticket was last modified.
+func (tkt *Ticket) ModifyTime() time.Time {
+	return tkt.modifyTime
+}
+
+// Hosts returns the hosts allowed to use the ticket, any host can when it's empty.
+func (tkt *Ticket) Hosts() []string {
+	return tkt.hosts
+}
+
+// Users returns the users allowed to use the ticket, any user can when it's empty.
+func (tkt *Ticket) Users() []string {
+	return tkt.users
+}
+
+// Groups returns the groups whose members are allowed to use the ticket, any group can when it's empty.
+func (tkt *Ticket) Groups() []string {
+	return tkt.groups
+}
+
+// Refresh pulls fresh info about the ticket from the iCAT server.
+func (tkt *Ticket) Refresh() error {
+	fresh, err := tkt.con.Ticket(tkt.name)
+	if err != nil {
+		return err
+	}
+
+	*tkt = *fresh
+
+	return nil
+}
+
+// SetUsesLimit sets the number of times the ticket can be used, 0 removes the limit.
+func (tkt *Ticket) SetUsesLimit(limit int) error {
+	if err := tkt.con.ticketAdmin("Modify Ticket", "mod", tkt.name, "uses", strconv.Itoa(limit)); err != nil {
+		return err
+	}
+
+	tkt.usesLimit = limit
+
+	return nil
+}
+
+// SetWriteFileLimit sets the number of files that can be written with the ticket, 0 removes the limit.
+func (tkt *Ticket) SetWriteFileLimit(limit int) error {
+	if err := tkt.con.ticketAdmin("Modify Ticket", "mod", tkt.name, "write-file", strconv.Itoa(limit)); err != nil {
+		return err
+	}
+
+	tkt.writeFileLimit = limit
+
+	return nil
+}
+
+// SetWriteByteLimit sets the number of bytes that can be written with the ticket, 0 removes the limit.
+func (tkt *Ticket) SetWriteByteLimit(limit int64) error {
+	if err := tkt.con.ticketAdmin("Modify Ticket", "mod", tkt.name, "write-bytes", strconv.FormatInt(limit, 10)); err != nil {
+		return err
+	}
+
+	tkt.writeByteLimit = limit
+
+	return nil
+}
+
+// SetExpiry sets the time the ticket expires, the zero time removes the expiry.
+func (tkt *Ticket) SetExpiry(expiry time.Time) error {
+	if err := tkt.con.ticketAdmin("Modify Ticket", "mod", tkt.name, "expire", ticketTime(expiry)); err != nil {
+		return err
+	}
+
+	tkt.expiry = expiry
+
+	return nil
+}
+
+// AddHost allows host to use the ticket.
+func (tkt *Ticket) AddHost(host string) error {
+	return tkt.modRestriction("add", "host", host, &tkt.hosts)
+}
+
+// RemoveHost removes host from the hosts allowed to use the ticket.
+func (tkt *Ticket) RemoveHost(host string) error {
+	return tkt.modRestriction("remove", "host", host, &tkt.hosts)
+}
+
+// AddUser allows user to use the ticket.
+func (tkt *Ticket) AddUser(user string) error {
+	return tkt.modRestriction("add", "user", user, &tkt.users)
+}
+
+// RemoveUser removes user from the users allowed to use the ticket.
+func (tkt *Ticket) RemoveUser(user string) error {
+	return tkt.modRestriction("remove", "user", user, &tkt.users)
+}
+
+// AddGroup allows the members of group to use the ticket.
+func (tkt *Ticket) AddGroup(group string) error {
+	return tkt.modRestriction("add", "group", group, &tkt.groups)
+}
+
+// RemoveGroup removes group from the groups allowed to use the ticket.
+func (tkt *Ticket) RemoveGroup(group string) error {
+	return tkt.modRestriction("remove", "group", group, &tkt.groups)
+}
+
+func (tkt *Ticket) modRestriction(op string, kind string, value string, list *[]string) error {
+	if err := tkt.con.ticketAdmin("Modify Ticket", "mod", tkt.name, op, kind, value); err != nil {
+		return err
+	}
+
+	updated := make([]string, 0, len(*list)+1)
+	for _, v := range *list {
+		if v != value {
+			updated = append(updated, v)
+		}
+	}
+
+	if op == "add" {
+		updated = append(updated, value)
+	}
+
+	*list = updated
+
+	return nil
+}
+
+// Delete removes the ticket from iRODS.
+func (tkt *Ticket) Delete() error {
+	return tkt.con.DeleteTicket(tkt.name)
+}
+
+// ticketTime formats t for ticket expiry, "0" removes the expiry
+func ticketTime(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// newTicketName returns a random ticket string
+func newTicketName() (string, error) {
+	name := make([]byte, 15)
+	max := big.NewInt(int64(len(ticketChars)))
+
+	for i := range name {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		name[i] = ticketChars[n.Int64()]
+	}
+
+	return string(name), nil
+}
+
+// CreateTicket creates a ticket granting access to the data object or collection at path, with the limits and
+// restrictions of opts. If a limit or restriction can't be set, the ticket is deleted again.
+func (con *Connection) CreateTicket(path string, opts TicketOptions) (*Ticket, error) {
+	if opts.Type == "" {
+		opts.Type = TicketRead
+	}
+
+	if opts.Type != TicketRead && opts.Type != TicketWrite {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Create Ticket Failed: Unknown ticket type %q", opts.Type))
+	}
+
+	if opts.Name == "" {
+		name, err := newTicketName()
+		if err != nil {
+			return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Create Ticket Failed: %v", err))
+		}
+		opts.Name = name
+	}
+
+	if err := con.ticketAdmin("Create Ticket", "create", opts.Name, opts.Type, path, opts.Name); err != nil {
+		return nil, err
+	}
+
+	mods := make([][]string, 0)
+
+	if !opts.Expiry.IsZero() {
+		mods = append(mods, []string{"expire", ticketTime(opts.Expiry)})
+	}
+	if opts.UsesLimit > 0 {
+		mods = append(mods, []string{"uses", strconv.Itoa(opts.UsesLimit)})
+	}
+	if opts.WriteFileLimit > 0 {
+		mods = append(mods, []string{"write-file", strconv.Itoa(opts.WriteFileLimit)})
+	}
+	if opts.WriteByteLimit > 0 {
+		mods = append(mods, []string{"write-bytes", strconv.FormatInt(opts.WriteByteLimit, 10)})
+	}
+	for _, host := range opts.Hosts {
+		mods = append(mods, []string{"add", "host", host})
+	}
+	for _, user := range opts.Users {
+		mods = append(mods, []string{"add", "user", user})
+	}
+	for _, group := range opts.Groups {
+		mods = append(mods, []string{"add", "group", group})
+	}
+
+	for _, mod := range mods {
+		if err := con.ticketAdmin("Create Ticket", append([]string{"mod", opts.Name}, mod...)...); err != nil {
+			con.ticketAdmin("Delete Ticket", "delete", opts.Name)
+			return nil, err
+		}
+	}
+
+	return con.Ticket(opts.Name)
+}
+
+// CreateTicket creates a ticket granting access to the data object, see Connection.CreateTicket.
+func (obj *DataObj) CreateTicket(opts TicketOptions) (*Ticket, error) {
+	return obj.con.CreateTicket(obj.path, opts)
+}
+
+// CreateTicket creates a ticket granting access to the collection and its contents, see Connection.CreateTicket.
+func (col *Collection) CreateTicket(opts TicketOptions) (*Ticket, error) {
+	return col.con.CreateTicket(col.path, opts)
+}
+
+// DeleteTicket removes the ticket with the string name.
+func (con *Connection) DeleteTicket(name string) error {
+	if err := con.ticketAdmin("Delete Ticket", "delete", name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Ticket returns the ticket with the string name. The error matches ErrNotFound if there's no such ticket.
+func (con *Connection) Ticket(name string) (*Ticket, error) {
+	tkts, err := con.fetchTickets(ColTicketString, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tkts) == 0 {
+		return nil, newError(Fatal, catNoRowsFound, fmt.Sprintf("iRODS Get Ticket Failed: Ticket %v doesn't exist", name))
+	}
+
+	return tkts[0], nil
+}
+
+// Tickets returns the tickets owned by the connected user.
+func (con *Connection) Tickets() (Tickets, error) {
+	return con.fetchTickets(ColTicketOwnerName, con.Options.Username)
+}
+
+// fetchTickets queries the tickets where col equals value, then their paths and restrictions
+func (con *Connection) fetchTickets(col Column, value string) (Tickets, error) {
+	res, err := con.Query(NewQuery(
+		ColTicketId, ColTicketString, ColTicketType, ColTicketObjectType, ColTicketOwnerName, ColTicketOwnerZone,
+		ColTicketUsesCount, ColTicketUsesLimit, ColTicketWriteFileCount, ColTicketWriteFileLimit,
+		ColTicketWriteByteCount, ColTicketWriteByteLimit, ColTicketExpiry, ColTicketCreateTime, ColTicketModifyTime,
+	).Where(col, "=", value))
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(Tickets, 0, res.Len())
+	byId := make(map[string]*Ticket)
+
+	for i := range res.Rows {
+		tkt := &Ticket{
+			id:        res.Get(i, ColTicketId),
+			name:      res.Get(i, ColTicketString),
+			typ:       res.Get(i, ColTicketType),
+			objType:   DataObjType,
+			ownerName: res.Get(i, ColTicketOwnerName),
+			ownerZone: res.Get(i, ColTicketOwnerZone),
+			con:       con,
+		}
+
+		if res.Get(i, ColTicketObjectType) == "collection" {
+			tkt.objType = CollectionType
+		}
+
+		tkt.usesCount, _ = strconv.Atoi(res.Get(i, ColTicketUsesCount))
+		tkt.usesLimit, _ = strconv.Atoi(res.Get(i, ColTicketUsesLimit))
+		tkt.writeFileCount, _ = strconv.Atoi(res.Get(i, ColTicketWriteFileCount))
+		tkt.writeFileLimit, _ = strconv.Atoi(res.Get(i, ColTicketWriteFileLimit))
+		tkt.writeByteCount, _ = strconv.ParseInt(res.Get(i, ColTicketWriteByteCount), 10, 64)
+		tkt.writeByteLimit, _ = strconv.ParseInt(res.Get(i, ColTicketWriteByteLimit), 10, 64)
+
+		if expiry := res.Get(i, ColTicketExpiry); expiry != "" && expiry != "0" {
+			tkt.expiry = timeStringToTime(expiry)
+		}
+
+		tkt.createTime = timeStringToTime(res.Get(i, ColTicketCreateTime))
+		tkt.modifyTime = timeStringToTime(res.Get(i, ColTicketModifyTime))
+
+		response = append(response, tkt)
+		byId[tkt.id] = tkt
+	}
+
+	if len(byId) == 0 {
+		return response, nil
+	}
+
+	ids := make([]string, 0, len(byId))
+	for id := range byId {
+		ids = append(ids, id)
+	}
+
+	// Data object and collection tickets join with different tables, so their paths are fetched separately
+	if res, err = con.Query(NewQuery(ColTicketId, ColTicketDataCollName, ColTicketDataName).WhereIn(ColTicketId, ids...)); err != nil {
+		return nil, err
+	}
+	for i := range res.Rows {
+		if tkt, ok := byId[res.Get(i, ColTicketId)]; ok {
+			tkt.path = strings.TrimRight(res.Get(i, ColTicketDataCollName), "/") + "/" + res.Get(i, ColTicketDataName)
+		}
+	}
+
+	if res, err = con.Query(NewQuery(ColTicketId, ColTicketCollName).WhereIn(ColTicketId, ids...)); err != nil {
+		return nil, err
+	}
+	for i := range res.Rows {
+		if tkt, ok := byId[res.Get(i, ColTicketId)]; ok && tkt.objType == CollectionType {
+			tkt.path = res.Get(i, ColTicketCollName)
+		}
+	}
+
+	restrictions := []struct {
+		idCol, valueCol Column
+		list            func(*Ticket) *[]string
+	}{
+		{ColTicketAllowedHostTicketId, ColTicketAllowedHost, func(t *Ticket) *[]string { return &t.hosts }},
+		{ColTicketAllowedUserTicketId, ColTicketAllowedUser, func(t *Ticket) *[]string { return &t.users }},
+		{ColTicketAllowedGroupTicketId, ColTicketAllowedGroup, func(t *Ticket) *[]string { return &t.groups }},
+	}
+
+	for _, r := range restrictions {
+		if res, err = con.Query(NewQuery(r.idCol, r.valueCol).WhereIn(r.idCol, ids...)); err != nil {
+			return nil, err
+		}
+
+		for i := range res.Rows {
+			if tkt, ok := byId[res.Get(i, r.idCol)]; ok {
+				list := r.list(tkt)
+				*list = append(*list, res.Get(i, r.valueCol))
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// ticketAdmin runs an iticket like operation, op names it in errors ("iRODS <op> Failed")
+func (con *Connection) ticketAdmin(op string, args ...string) error {
+	padded := make([]string, 6)
+	copy(padded, args)
+
+	return con.retry(true, func() error {
+		ta, ok := con.innerTransport(context.Background()).(ticketAdminer)
+		if !ok {
+			return con.notSupported(op)
+		}
+
+		if err := ta.TicketAdmin(padded...); err != nil {
+			return transportError(err, fmt.Sprintf("iRODS %v Failed", op))
+		}
+
+		return nil
+	})
+}
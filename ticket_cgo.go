@@ -0,0 +1,50 @@
+//go:build cgo
+// +build cgo
+
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+// #include "wrapper.h"
+import "C"
+
+import (
+	"unsafe"
+)
+
+// TicketAdmin runs an iticket like operation, see ticketAdminer
+func (t *cTransport) TicketAdmin(args ...string) error {
+	ccon, er := t.getCcon()
+	if er != nil {
+		return er
+	}
+
+	defer t.con.returnCcon(ccon)
+
+	return cTicketAdmin(ccon, args)
+}
+
+// cTicketAdmin sends the 6 arguments of an iticket like operation to the server
+func cTicketAdmin(ccon *rcComm, args []string) error {
+	arg1 := C.CString(args[0])
+	arg2 := C.CString(args[1])
+	arg3 := C.CString(args[2])
+	arg4 := C.CString(args[3])
+	arg5 := C.CString(args[4])
+	arg6 := C.CString(args[5])
+	defer C.free(unsafe.Pointer(arg1))
+	defer C.free(unsafe.Pointer(arg2))
+	defer C.free(unsafe.Pointer(arg3))
+	defer C.free(unsafe.Pointer(arg4))
+	defer C.free(unsafe.Pointer(arg5))
+	defer C.free(unsafe.Pointer(arg6))
+
+	var errMsg *C.char
+
+	if status := C.gorods_ticket_admin(ccon, arg1, arg2, arg3, arg4, arg5, arg6, &errMsg); status < 0 {
+		return NewTransportError(int(status), C.GoString(errMsg))
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestNewTicketName(t *testing.T) {
+	a, err := newTicketName()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := newTicketName()
+
+	if len(a) != 15 || a == b {
+		t.Errorf("Expected distinct 15 character ticket strings, got %q and %q", a, b)
+	}
+
+	for _, c := range a {
+		if !strings.ContainsRune(ticketChars, c) {
+			t.Errorf("Unexpected character %q in ticket string %q", c, a)
+		}
+	}
+}
+
+func TestTicketTime(t *testing.T) {
+	if ticketTime(time.Time{}) != "0" {
+		t.Errorf("Expected the zero time to remove the expiry, got %v", ticketTime(time.Time{}))
+	}
+
+	if ticketTime(time.Unix(1500000000, 0)) != "1500000000" {
+		t.Errorf("Expected seconds since the epoch, got %v", ticketTime(time.Unix(1500000000, 0)))
+	}
+}
+
+func TestCreateTicketValidation(t *testing.T) {
+	con := memConnection(t)
+
+	if _, err := con.CreateTicket("/tempZone/home/rods", TicketOptions{Type: "execute"}); err == nil {
+		t.Error("Expected an unknown ticket type to be rejected")
+	}
+
+	if _, err := con.CreateTicket("/tempZone/home/rods", TicketOptions{}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported without the C API, got %v", err)
+	}
+}
+
+func TestTicketsFindByName(t *testing.T) {
+	tkts := Tickets{&Ticket{name: "abc"}, &Ticket{name: "def"}}
+
+	if tkt := tkts.FindByName("def"); tkt == nil || tkt.Name() != "def" {
+		t.Errorf("Expected to find ticket def, got %v", tkt)
+	}
+
+	if tkts.FindByName("xyz") != nil {
+		t.Error("Expected no ticket named xyz")
+	}
+}
+
+func TestTicketModRestriction(t *testing.T) {
+	tkt := &Ticket{name: "abc", hosts: []string{"a.example.org"}, con: memConnection(t)}
+
+	if err := tkt.AddHost("b.example.org"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported without the C API, got %v", err)
+	}
+
+	if len(tkt.Hosts()) != 1 {
+		t.Errorf("Expected hosts to be unchanged after a failure, got %v", tkt.Hosts())
+	}
+}
+
+// ticketTransport records the iticket like operations it runs, and fails the one whose second argument is failOn
+type ticketTransport struct {
+	Transport
+	args   [][]string
+	failOn string
+}
+
+func (t *ticketTransport) TicketAdmin(args ...string) error {
+	t.args = append(t.args, args)
+
+	if t.failOn != "" && args[2] == t.failOn {
+		return NewTransportError(-893000, "no such user")
+	}
+
+	return nil
+}
+
+func TestTicketAdminTransport(t *testing.T) {
+	con := memConnection(t)
+
+	tickets := &ticketTransport{Transport: con.transport, failOn: "add"}
+	con.transport = tickets
+
+	tkt := &Ticket{name: "abc", con: con}
+
+	if err := tkt.SetUsesLimit(5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tkt.AddHost("b.example.org"); err == nil || len(tkt.Hosts()) != 0 {
+		t.Errorf("Expected the failed restriction to be reported and not recorded, got %v (%v)", tkt.Hosts(), err)
+	}
+
+	if _, err := con.CreateTicket("/tempZone/home/rods", TicketOptions{Name: "abc", Users: []string{"nobody"}}); err == nil {
+		t.Error("Expected the failed restriction to be reported")
+	}
+
+	expected := [][]string{
+		{"mod", "abc", "uses", "5", "", ""},
+		{"mod", "abc", "add", "host", "b.example.org", ""},
+		{"create", "abc", "read", "/tempZone/home/rods", "abc", ""},
+		{"mod", "abc", "add", "user", "nobody", ""},
+		{"delete", "abc", "", "", "", ""},
+	}
+
+	if !reflect.DeepEqual(tickets.args, expected) {
+		t.Errorf("Expected the ticket operations %q, got %q", expected, tickets.args)
+	}
+}
+
+// expectTicketAdmin reads an iticket like operation, checks its arguments and answers it with status
+func expectTicketAdmin(s *irodsproto.Server, status int, args ...string) error {
+	var inp irodsproto.TicketAdminInp
+	if _, err := s.Expect(irodsproto.TicketAdminAN, &inp); err != nil {
+		return err
+	}
+
+	expected := make([]string, 6)
+	copy(expected, args)
+
+	if got := []string{inp.Arg1, inp.Arg2, inp.Arg3, inp.Arg4, inp.Arg5, inp.Arg6}; !reflect.DeepEqual(got, expected) {
+		return fmt.Errorf("Expected ticket admin arguments %q, got %q", expected, got)
+	}
+
+	return s.Reply(nil, nil, status)
+}
+
+// replyQuery reads a GenQuery and answers it with rows
+func replyQuery(s *irodsproto.Server, rows ...[]string) error {
+	inp := new(irodsproto.GenQueryInp)
+	if _, err := s.Expect(irodsproto.GenQueryAN, inp); err != nil {
+		return err
+	}
+
+	return s.ReplyRows(inp, rows)
+}
+
+func TestCreateTicketArgs(t *testing.T) {
+	path := "/tempZone/home/rods/hello.txt"
+
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		for _, args := range [][]string{
+			{"create", "abc", "write", path, "abc"},
+			{"mod", "abc", "expire", "1500000000"},
+			{"mod", "abc", "uses", "10"},
+			{"mod", "abc", "write-file", "2"},
+			{"mod", "abc", "add", "host", "a.example.org"},
+			{"mod", "abc", "add", "user", "alice"},
+		} {
+			if err := expectTicketAdmin(s, 0, args...); err != nil {
+				return err
+			}
+		}
+
+		inp, err := expectQuery(s, map[int]string{irodsproto.ColTicketString: "= 'abc'"})
+		if err != nil {
+			return err
+		}
+
+		selects := []int{
+			irodsproto.ColTicketId, irodsproto.ColTicketString, irodsproto.ColTicketType, 2205, irodsproto.ColTicketOwnerName,
+			irodsproto.ColTicketOwnerZone, 2207, 2206, 2211, 2212, 2213, 2214, 2208, 2209, 2210,
+		}
+		if !reflect.DeepEqual(inp.Selects.Inx, selects) {
+			return fmt.Errorf("Expected the ticket columns %v, got %v", selects, inp.Selects.Inx)
+		}
+
+		if err := s.ReplyRows(inp, [][]string{{
+			"1", "abc", "write", "data", "rods", "tempZone", "0", "10", "0", "2", "0", "0", "1500000000", "01400000000", "01400000001",
+		}}); err != nil {
+			return err
+		}
+
+		for _, rows := range [][][]string{
+			{{"1", "/tempZone/home/rods", "hello.txt"}},
+			nil,
+			{{"1", "a.example.org"}},
+			{{"1", "alice"}},
+			nil,
+		} {
+			if err := replyQuery(s, rows...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	con := protoConnection(transport)
+
+	tkt, err := con.CreateTicket(path, TicketOptions{
+		Name:           "abc",
+		Type:           TicketWrite,
+		Expiry:         time.Unix(1500000000, 0),
+		UsesLimit:      10,
+		WriteFileLimit: 2,
+		Hosts:          []string{"a.example.org"},
+		Users:          []string{"alice"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if tkt.Path() != path || tkt.Type() != TicketWrite || tkt.UsesLimit() != 10 || tkt.WriteFileLimit() != 2 || tkt.Expiry().Unix() != 1500000000 {
+		t.Errorf("Expected the write ticket on %v, got %+v", path, tkt)
+	}
+
+	if !reflect.DeepEqual(tkt.Hosts(), []string{"a.example.org"}) || !reflect.DeepEqual(tkt.Users(), []string{"alice"}) || len(tkt.Groups()) != 0 {
+		t.Errorf("Expected the host and user restrictions, got %v, %v and %v", tkt.Hosts(), tkt.Users(), tkt.Groups())
+	}
+}
+
+func TestCreateTicketRollback(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		if err := expectTicketAdmin(s, 0, "create", "abc", "read", "/tempZone/home/rods", "abc"); err != nil {
+			return err
+		}
+		if err := expectTicketAdmin(s, -893000, "mod", "abc", "add", "user", "nobody"); err != nil {
+			return err
+		}
+		return expectTicketAdmin(s, 0, "delete", "abc")
+	})
+
+	con := protoConnection(transport)
+
+	if _, err := con.CreateTicket("/tempZone/home/rods", TicketOptions{Name: "abc", Users: []string{"nobody"}}); err == nil {
+		t.Error("Expected the failed restriction to be reported")
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTicketModArgs(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		for _, args := range [][]string{
+			{"mod", "abc", "uses", "5"},
+			{"mod", "abc", "expire", "0"},
+			{"mod", "abc", "write-bytes", "1024"},
+			{"mod", "abc", "remove", "host", "a.example.org"},
+			{"mod", "abc", "add", "group", "public"},
+			{"delete", "abc"},
+		} {
+			if err := expectTicketAdmin(s, 0, args...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	con := protoConnection(transport)
+	tkt := &Ticket{name: "abc", hosts: []string{"a.example.org"}, expiry: time.Unix(1500000000, 0), con: con}
+
+	for _, err := range []error{
+		tkt.SetUsesLimit(5),
+		tkt.SetExpiry(time.Time{}),
+		tkt.SetWriteByteLimit(1024),
+		tkt.RemoveHost("a.example.org"),
+		tkt.AddGroup("public"),
+		tkt.Delete(),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if tkt.UsesLimit() != 5 || !tkt.Expiry().IsZero() || tkt.WriteByteLimit() != 1024 || len(tkt.Hosts()) != 0 || !reflect.DeepEqual(tkt.Groups(), []string{"public"}) {
+		t.Errorf("Expected the ticket to be updated, got %+v", tkt)
+	}
+}
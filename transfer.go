@@ -0,0 +1,415 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Default values used when TransferOptions fields are left empty
+const (
+	DefaultTransferPartSize    = 32 * 1024 * 1024
+	DefaultTransferBufferSize  = 4 * 1024 * 1024
+	DefaultTransferConcurrency = 4
+)
+
+// TransferOptions control how data is moved by Collection.PutParallel and DataObj.DownloadToParallel.
+// The data object is split into ranges of PartSize bytes, which are moved over Concurrency parallel
+// connections. Each read or write call against iRODS moves at most BufferSize bytes.
+// Extra connections are checked out from Pool if it's set, otherwise they're opened using the options
+// of the data object's connection and disconnected when the transfer finishes. When using a Pool, make sure
+// PoolOptions.MaxOpen leaves room for Concurrency connections beside the ones you already hold.
+// If Context is set, the transfer stops between buffers once it's cancelled or its deadline passes, returning ctx.Err().
+// When Verify is set, the checksum of the local file is computed as the ranges are moved, then compared with the
+// checksum computed by the server once the transfer is done. A difference is reported with an error matching
+// ErrChecksumMismatch.
+type TransferOptions struct {
+	PartSize    int64
+	BufferSize  int
+	Concurrency int
+	Pool        *ConnectionPool
+	Context     context.Context
+	Verify      bool
+}
+
+// ByteRange describes a section of a data object, used to split up transfers.
+type ByteRange struct {
+	Offset int64
+	Length int64
+}
+
+func (topts TransferOptions) partSize() int64 {
+	if topts.PartSize > 0 {
+		return topts.PartSize
+	}
+	return DefaultTransferPartSize
+}
+
+func (topts TransferOptions) bufferSize() int {
+	if topts.BufferSize > 0 {
+		return topts.BufferSize
+	}
+	return DefaultTransferBufferSize
+}
+
+func (topts TransferOptions) context() context.Context {
+	if topts.Context != nil {
+		return topts.Context
+	}
+	return context.Background()
+}
+
+func (topts TransferOptions) concurrency() int {
+	if topts.Concurrency > 0 {
+		return topts.Concurrency
+	}
+	return DefaultTransferConcurrency
+}
+
+// connection returns an extra connection for a transfer worker, using the pool if one was provided.
+func (topts TransferOptions) connection(con *Connection) (*Connection, error) {
+	if topts.Pool != nil {
+		return topts.Pool.GetContext(topts.context())
+	}
+
+	opts := *con.Options
+	opts.FastInit = true
+	opts.Sessions = 1 // Each worker only uses one
+
+	// Reuse the PAM token so workers don't authenticate with PAM again
+	if con.PAMToken != "" {
+		opts.PAMToken = con.PAMToken
+	}
+
+	return NewConnection(&opts)
+}
+
+func (topts TransferOptions) release(con *Connection) error {
+	if topts.Pool != nil {
+		return topts.Pool.Put(con)
+	}
+
+	return con.Disconnect()
+}
+
+// splitRanges divides size bytes into ranges of at most partSize bytes
+func splitRanges(size int64, partSize int64) []ByteRange {
+	ranges := make([]ByteRange, 0)
+
+	for offset := int64(0); offset < size; offset += partSize {
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+
+		ranges = append(ranges, ByteRange{offset, length})
+	}
+
+	return ranges
+}
+
+// withConnection returns a copy of the data object bound to con, with no open handle.
+func (obj *DataObj) withConnection(con *Connection) *DataObj {
+	w := *obj
+
+	w.con = con
+	w.handle = -1
+	w.openedAs = -1
+	w.offset = 0
+	w.metaCol = nil
+
+	return &w
+}
+
+// transferRanges runs fn for each range. When more than one range and worker is requested, every worker gets its own
+// connection and data object handle. Otherwise the ranges are processed in order using obj itself.
+// The first error encountered stops the transfer and is returned.
+func (obj *DataObj) transferRanges(ranges []ByteRange, topts TransferOptions, write bool, fn func(*DataObj, ByteRange) error) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	open := func(w *DataObj) error {
+		if write {
+			if w.openedAs != os.O_RDWR && w.openedAs != os.O_WRONLY {
+				if er := w.Close(); er != nil {
+					return er
+				}
+			}
+			return w.initRW()
+		}
+		return w.init()
+	}
+
+	ctx := topts.context()
+
+	if er := ctx.Err(); er != nil {
+		return er
+	}
+
+	concurrency := topts.concurrency()
+	if concurrency > len(ranges) {
+		concurrency = len(ranges)
+	}
+
+	if concurrency == 1 {
+		if er := open(obj); er != nil {
+			return er
+		}
+
+		for _, r := range ranges {
+			if er := ctx.Err(); er != nil {
+				obj.Close()
+				return er
+			}
+
+			if er := fn(obj, r); er != nil {
+				obj.Close()
+				return er
+			}
+		}
+
+		return obj.Close()
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	work := make(chan ByteRange)
+	done := make(chan struct{})
+
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			close(done)
+		})
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			con, err := topts.connection(obj.con)
+			if err != nil {
+				fail(err)
+				return
+			}
+			defer topts.release(con)
+
+			w := obj.withConnection(con)
+			if er := open(w); er != nil {
+				fail(er)
+				return
+			}
+
+			for r := range work {
+				if er := fn(w, r); er != nil {
+					w.Close()
+					fail(er)
+					return
+				}
+			}
+
+			if er := w.Close(); er != nil {
+				fail(er)
+			}
+		}()
+	}
+
+feed:
+	for _, r := range ranges {
+		select {
+		case work <- r:
+		case <-done:
+			break feed
+		case <-ctx.Done():
+			fail(ctx.Err())
+			break feed
+		}
+	}
+
+	close(work)
+	wg.Wait()
+
+	return firstErr
+}
+
+// downloadRange copies a range of the data object into the same range of f, checking ctx between buffers.
+// The data is added to sums, which can be nil.
+func (obj *DataObj) downloadRange(ctx context.Context, f *os.File, r ByteRange, bufSize int, sums *rangeChecksummer) error {
+	if er := obj.LSeek(r.Offset); er != nil {
+		return er
+	}
+
+	end := r.Offset + r.Length
+
+	for pos := r.Offset; pos < end; {
+		if er := ctx.Err(); er != nil {
+			return er
+		}
+
+		n := end - pos
+		if n > int64(bufSize) {
+			n = int64(bufSize)
+		}
+
+		data, err := obj.readNextContext(ctx, int(n))
+		if err != nil {
+			return err
+		}
+
+		if len(data) == 0 {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, unexpected end of data at offset %v", obj.path, pos)).withPath(obj.path)
+		}
+
+		if _, er := f.WriteAt(data, pos); er != nil {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, er)).withPath(obj.path).wrap(er)
+		}
+
+		sums.add(pos, data)
+		pos += int64(len(data))
+	}
+
+	return nil
+}
+
+// uploadRange copies a range of f into the same range of the data object, checking ctx between buffers.
+// The data is added to sums, which can be nil.
+func (obj *DataObj) uploadRange(ctx context.Context, f *os.File, r ByteRange, bufSize int, sums *rangeChecksummer) error {
+	if er := obj.LSeek(r.Offset); er != nil {
+		return er
+	}
+
+	buf := make([]byte, bufSize)
+	end := r.Offset + r.Length
+
+	for pos := r.Offset; pos < end; {
+		if er := ctx.Err(); er != nil {
+			return er
+		}
+
+		n := end - pos
+		if n > int64(bufSize) {
+			n = int64(bufSize)
+		}
+
+		read, er := f.ReadAt(buf[:n], pos)
+		if int64(read) != n {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Put DataObject Failed: %v, short read from %v at offset %v: %v", obj.path, f.Name(), pos, er)).withPath(obj.path).wrap(er)
+		}
+
+		if err := obj.writeNextContext(ctx, buf[:n]); err != nil {
+			return err
+		}
+
+		sums.add(pos, buf[:n])
+		pos += n
+	}
+
+	return nil
+}
+
+// DownloadToParallel downloads the data object to localPath, splitting it into ranges that are read over
+// parallel connections and written directly to their position in the local file. See TransferOptions for details.
+func (obj *DataObj) DownloadToParallel(localPath string, topts TransferOptions) error {
+
+	// Opened for reading too, ranges received out of order are read back to checksum them
+	f, err := os.OpenFile(localPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, err)).withPath(obj.path).wrap(err)
+	}
+	defer f.Close()
+
+	if er := f.Truncate(obj.size); er != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, er)).withPath(obj.path).wrap(er)
+	}
+
+	bufSize := topts.bufferSize()
+	ctx := topts.context()
+
+	var sums *rangeChecksummer
+	if topts.Verify {
+		sums = newRangeChecksummer(f)
+	}
+
+	if er := obj.transferRanges(splitRanges(obj.size, topts.partSize()), topts, false, func(w *DataObj, r ByteRange) error {
+		return w.downloadRange(ctx, f, r, bufSize, sums)
+	}); er != nil {
+		return er
+	}
+
+	if er := f.Close(); er != nil {
+		return newError(Fatal, -1, fmt.Sprintf("iRODS Download DataObject Failed: %v, %v", obj.path, er)).withPath(obj.path).wrap(er)
+	}
+
+	if topts.Verify {
+		return obj.verifyTransfer(localPath, obj.size, sums)
+	}
+
+	return nil
+}
+
+// PutParallel uploads the file at localPath into the collection, splitting it into ranges that are written
+// over parallel connections. opts is used to create the data object, as in Put. See TransferOptions for details,
+// the upload is verified when either opts.Verify or topts.Verify is set.
+func (col *Collection) PutParallel(localPath string, opts DataObjOptions, topts TransferOptions) (*DataObj, error) {
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Put DataObject Failed: %v", err)).wrap(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Put DataObject Failed: %v", err)).wrap(err)
+	}
+
+	if opts.Name == "" {
+		opts.Name = filepath.Base(localPath)
+	}
+
+	opts.Size = info.Size()
+
+	obj, err := CreateDataObj(opts, col)
+	if err != nil {
+		return nil, err
+	}
+
+	bufSize := topts.bufferSize()
+	ctx := topts.context()
+
+	var sums *rangeChecksummer
+	if opts.Verify || topts.Verify {
+		sums = newRangeChecksummer(f)
+	}
+
+	if er := obj.transferRanges(splitRanges(opts.Size, topts.partSize()), topts, true, func(w *DataObj, r ByteRange) error {
+		return w.uploadRange(ctx, f, r, bufSize, sums)
+	}); er != nil {
+		return nil, er
+	}
+
+	if sums != nil {
+		// obj was created empty, its size is the one of the file
+		if er := obj.verifyTransfer(localPath, opts.Size, sums); er != nil {
+			return nil, er
+		}
+	}
+
+	if err := col.refreshContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return getDataObj(ctx, obj.path, col.con)
+}
@@ -0,0 +1,197 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSplitRanges(t *testing.T) {
+
+	ranges := splitRanges(10, 4)
+
+	expected := []ByteRange{{0, 4}, {4, 4}, {8, 2}}
+
+	if len(ranges) != len(expected) {
+		t.Fatalf("Expected %v ranges, got %v", len(expected), len(ranges))
+	}
+
+	for i := range expected {
+		if ranges[i] != expected[i] {
+			t.Errorf("Expected range %v, got %v", expected[i], ranges[i])
+		}
+	}
+
+	if r := splitRanges(0, 4); len(r) != 0 {
+		t.Errorf("Expected no ranges for empty object, got %v", r)
+	}
+}
+
+// rangeFaultTransport is a MemTransport whose reads and writes of the range starting at failAt fail, or call hook
+// and carry on when it's set
+type rangeFaultTransport struct {
+	*MemTransport
+
+	mu      sync.Mutex
+	offsets map[int]int64
+	failAt  int64
+	hook    func()
+}
+
+func (t *rangeFaultTransport) Seek(handle int, offset int64) error {
+	t.mu.Lock()
+	t.offsets[handle] = offset
+	t.mu.Unlock()
+
+	return t.MemTransport.Seek(handle, offset)
+}
+
+// fault returns the error of an operation on handle, if it's in the faulty range
+func (t *rangeFaultTransport) fault(handle int) error {
+	t.mu.Lock()
+	offset, ok := t.offsets[handle]
+	t.mu.Unlock()
+
+	if !ok || offset != t.failAt {
+		return nil
+	}
+
+	if t.hook != nil {
+		t.hook()
+		return nil
+	}
+
+	return NewTransportError(-1, "connection reset")
+}
+
+func (t *rangeFaultTransport) Read(handle int, length int64) ([]byte, error) {
+	if er := t.fault(handle); er != nil {
+		return nil, er
+	}
+	return t.MemTransport.Read(handle, length)
+}
+
+func (t *rangeFaultTransport) Write(handle int, data []byte) error {
+	if er := t.fault(handle); er != nil {
+		return er
+	}
+	return t.MemTransport.Write(handle, data)
+}
+
+// transferFixture returns the home collection of a connection over transport, and the path of a local file of
+// 1000 bytes which can't be mistaken for one another at different offsets
+func transferFixture(t *testing.T, transport Transport) (*Collection, string, []byte) {
+	con, err := NewConnection(memOptions(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home, err := con.Collection(CollectionOptions{Path: "/tempZone/home/rods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "gorods-transfer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+
+	localPath := filepath.Join(dir, "big.bin")
+	if err := ioutil.WriteFile(localPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return home, localPath, data
+}
+
+func TestTransferParallel(t *testing.T) {
+	mem := NewMemTransport("tempZone", "rods")
+
+	home, localPath, data := transferFixture(t, mem)
+	defer os.RemoveAll(filepath.Dir(localPath))
+
+	// Ranges of 100 bytes moved 30 bytes at a time, by more workers than needed to send them in order
+	topts := TransferOptions{PartSize: 100, BufferSize: 30, Concurrency: 4}
+
+	obj, err := home.PutParallel(localPath, DataObjOptions{}, topts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remote := mem.objs[obj.Path()].data; !bytes.Equal(remote, data) {
+		t.Errorf("Expected the uploaded ranges to be reassembled in order, got %v", remote)
+	}
+
+	downloaded := localPath + ".download"
+
+	if err := obj.DownloadToParallel(downloaded, topts); err != nil {
+		t.Fatal(err)
+	}
+
+	if local, err := ioutil.ReadFile(downloaded); err != nil || !bytes.Equal(local, data) {
+		t.Errorf("Expected the downloaded ranges to be reassembled in order, got %v (%v)", local, err)
+	}
+}
+
+func TestTransferParallelRangeError(t *testing.T) {
+	transport := &rangeFaultTransport{MemTransport: NewMemTransport("tempZone", "rods"), offsets: make(map[int]int64), failAt: 500}
+
+	home, localPath, _ := transferFixture(t, transport)
+	defer os.RemoveAll(filepath.Dir(localPath))
+
+	topts := TransferOptions{PartSize: 100, BufferSize: 30, Concurrency: 4}
+
+	if _, err := home.PutParallel(localPath, DataObjOptions{}, topts); err == nil {
+		t.Error("Expected the upload to fail with its range at offset 500")
+	}
+
+	// Upload it in one piece so there's something to download
+	transport.failAt = -1
+
+	obj, err := home.PutParallel(localPath, DataObjOptions{Force: true}, TransferOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport.failAt = 500
+
+	if err := obj.DownloadToParallel(localPath+".download", topts); err == nil {
+		t.Error("Expected the download to fail with its range at offset 500")
+	}
+}
+
+func TestTransferParallelCancel(t *testing.T) {
+	transport := &rangeFaultTransport{MemTransport: NewMemTransport("tempZone", "rods"), offsets: make(map[int]int64), failAt: 200}
+
+	home, localPath, _ := transferFixture(t, transport)
+	defer os.RemoveAll(filepath.Dir(localPath))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel the transfer once it reaches the third range
+	transport.hook = cancel
+
+	topts := TransferOptions{PartSize: 100, BufferSize: 30, Concurrency: 2, Context: ctx}
+
+	if _, err := home.PutParallel(localPath, DataObjOptions{}, topts); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the upload to be cancelled, got %v", err)
+	}
+
+	if remote := transport.objs[home.Path()+"/big.bin"].data; len(remote) == 1000 {
+		t.Error("Expected the cancelled upload to stop before the last range")
+	}
+}
@@ -0,0 +1,275 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Transport carries out the iRODS operations used by Connection, Collection, DataObj, MetaCollection, User and Group.
+// By default connections use the iRODS C API. Set ConnectionOptions.Transport to use another implementation instead,
+// such as MemTransport, which keeps everything in memory so code can be tested without a live iCAT server.
+//
+// Errors should be *GoRodsError values carrying the iRODS status code (see NewTransportError), so that errors.Is
+// checks against ErrNotFound, ErrAlreadyExists, etc. keep working. Their Message is used as the detail of the
+// "iRODS <Op> Failed" error returned to the caller.
+//
+// Some operations aren't part of the interface. A Transport supports GenQuery, resource and zone administration
+// and replica checksum verification by implementing the optional GenQuery, GeneralAdmin and VerifyChecksum
+// methods below (the iRODS C API's and PureGo's do), and these operations return ErrNotSupported otherwise. Ticket
+// management needs the optional TicketAdmin method, and the ticket listings GenQuery as well. Atomic metadata
+// batches need the optional AtomicApplyMetadata method, which MemTransport implements too. Connecting with a
+// ticket, replication, trimming, registration and PAM still require the iRODS C API.
+type Transport interface {
+	// Disconnect ends the session with the server.
+	Disconnect() error
+	// Ping verifies that the session is still usable.
+	Ping() error
+	// LocalZone returns the name of the zone the session is connected to.
+	LocalZone() (string, error)
+
+	// Stat returns the system metadata of the data object or collection at path.
+	Stat(path string) (*TransportEntry, error)
+	// DataObj returns the catalog entry of the data object at path, including its replica and resource.
+	DataObj(path string) (*TransportEntry, error)
+	// List returns the sub collections, followed by the data objects, found directly inside the collection at path.
+	// When trimRepls is false every replica of a data object gets its own entry.
+	List(path string, trimRepls bool) ([]*TransportEntry, error)
+	// ListPage is like List, but returns up to limit entries starting at offset, along with the totals.
+	ListPage(path string, trimRepls bool, offset int, limit int) (*TransportPage, error)
+
+	// CreateCollection creates the collection at path. The parent collection must exist.
+	CreateCollection(path string) error
+	// CreateDataObj creates an empty data object at path, on resource if it's not empty.
+	CreateDataObj(path string, size int64, mode int, force bool, resource string) error
+	// Put uploads the local file at localPath to the data object at path, on resource if it's not empty.
+	Put(localPath string, path string, size int64, mode int, force bool, resource string) error
+	// Remove deletes the data object or collection at path. Unless force is set, it's moved to the trash.
+	// When rmTrash is set, path is in the trash and is removed permanently.
+	Remove(path string, isCollection bool, recursive bool, force bool, rmTrash bool) error
+	// Move renames the data object or collection at src to dst.
+	Move(src string, dst string, isCollection bool) error
+	// Copy copies the data object at src to dst, on resource if it's not empty.
+	Copy(src string, dst string, force bool, resource string) error
+	// Checksum computes (if needed) and returns the checksum of the data object at path.
+	Checksum(path string) (string, error)
+
+	// Open opens the data object at path and returns a handle for Read, Write, Seek and Close.
+	// flags is os.O_RDONLY or os.O_RDWR.
+	Open(path string, resource string, replNum int, flags int) (int, error)
+	// Read reads up to length bytes from the current offset of handle, and advances it.
+	// A short (or empty) result means the end of the data object was reached.
+	Read(handle int, length int64) ([]byte, error)
+	// Write writes data at the current offset of handle, and advances it.
+	Write(handle int, data []byte) error
+	// Seek sets the offset of handle.
+	Seek(handle int, offset int64) error
+	// Close releases handle.
+	Close(handle int) error
+
+	// Meta returns the AVUs of the object of type typ (DataObjType, CollectionType, UserType, GroupType...) named path.
+	// For users and groups path is the user or group name, and zone is the zone it belongs to.
+	Meta(typ int, path string, zone string) (Metas, error)
+	// AddMeta adds m to the object of type typ named path.
+	AddMeta(typ int, path string, m Meta) error
+	// RemoveMeta removes m from the object of type typ named path.
+	RemoveMeta(typ int, path string, m Meta) error
+	// ModMeta replaces the AVU old with updated on the object of type typ named path.
+	ModMeta(typ int, path string, old Meta, updated Meta) error
+
+	// DataObjACL returns the access control list of the data object with the id dataId.
+	DataObjACL(dataId string, zone string) ([]*TransportACL, error)
+	// CollectionACL returns the access control list of the collection at path.
+	CollectionACL(path string, zone string) ([]*TransportACL, error)
+	// Chmod sets the access level of user on path. access is one of "null", "read", "write", "own", or
+	// "inherit" and "noinherit" for collections.
+	Chmod(path string, zone string, user string, access string, recursive bool) error
+	// Inheritance returns the inheritance setting of the collection at path.
+	Inheritance(path string) (bool, error)
+
+	// Users returns every user, as "name#zone" strings.
+	Users() ([]string, error)
+	// Groups returns the names of every group.
+	Groups() ([]string, error)
+	// Zones returns the names of every zone.
+	Zones() ([]string, error)
+	// Resources returns the names of every resource.
+	Resources() ([]string, error)
+
+	// UserInfo returns the catalog information of the user or group name, using the iCAT column names as keys
+	// (user_id, user_name, user_type_name, zone_name, user_info, r_comment, create_ts, modify_ts).
+	UserInfo(name string) (map[string]string, error)
+	// UserGroups returns the names of the groups user belongs to.
+	UserGroups(user string) ([]string, error)
+	// GroupMembers returns the members of group, as "name#zone" strings.
+	GroupMembers(group string) ([]string, error)
+	// CreateUser creates the user name in zone, typ is "rodsuser", "rodsadmin" or "groupadmin".
+	CreateUser(name string, zone string, typ string) error
+	// DeleteUser removes the user name from zone.
+	DeleteUser(name string, zone string) error
+	// ChangePassword sets the password of user to newPass, myPass is the password of the connected user.
+	ChangePassword(user string, newPass string, myPass string) error
+	// CreateGroup creates the group name in zone.
+	CreateGroup(name string, zone string) error
+	// DeleteGroup removes the group name from zone.
+	DeleteGroup(name string, zone string) error
+	// AddToGroup adds the user of zone to group.
+	AddToGroup(user string, zone string, group string) error
+	// RemoveFromGroup removes the user of zone from group.
+	RemoveFromGroup(user string, zone string, group string) error
+
+	// ZoneInfo returns the catalog information of the zone name (zone_id, zone_name, zone_type_name,
+	// zone_conn_string, r_comment, create_ts, modify_ts).
+	ZoneInfo(name string) (map[string]string, error)
+	// ResourceInfo returns the catalog information of the resource name, using the iCAT column names as keys
+	// (resc_id, resc_name, zone_name, resc_type_name, resc_class_name, resc_net, resc_def_path, ...).
+	ResourceInfo(name string) (map[string]string, error)
+}
+
+// TransportEntry describes a data object or collection, as returned by a Transport.
+// Replica and resource fields are only set for data objects.
+type TransportEntry struct {
+	Type       int
+	Path       string
+	Size       int64
+	Mode       int
+	DataId     string
+	Checksum   string
+	OwnerName  string
+	OwnerZone  string
+	Resource   string
+	RescHier   string
+	PhyPath    string
+	ReplNum    int
+	ReplStatus int
+	CreateTime time.Time
+	ModifyTime time.Time
+}
+
+// TransportPage is one page of a collection listing, as returned by Transport.ListPage.
+// ColTotal and ObjTotal are the number of sub collections and data objects in the whole collection.
+type TransportPage struct {
+	Collections []*TransportEntry
+	DataObjs    []*TransportEntry
+	ColTotal    int
+	ObjTotal    int
+}
+
+// TransportACL is a single access control list entry, as returned by a Transport.
+// Type is "rodsuser", "rodsadmin", "groupadmin" or "rodsgroup", and Access is "own", "modify object" or "read object".
+type TransportACL struct {
+	Name   string
+	Type   string
+	Access string
+}
+
+// contextTransport is implemented by Transports whose reads and writes may wait for a session used by another
+// goroutine, like the iRODS C API's. They give up waiting and return ctx.Err() once ctx is done, but a read or
+// write that was sent to the server always completes.
+type contextTransport interface {
+	ReadContext(ctx context.Context, handle int, length int64) ([]byte, error)
+	WriteContext(ctx context.Context, handle int, data []byte) error
+}
+
+// boundTransport is implemented by Transports whose calls may wait for a session used by another goroutine, like
+// the iRODS C API's. withContext returns a Transport making the same calls, which give up waiting and return
+// ctx.Err() once ctx is done. A call that was sent to the server always completes.
+type boundTransport interface {
+	withContext(ctx context.Context) Transport
+}
+
+// genQuerier is implemented by Transports that run GenQueries, see Connection.Query. q is validated, and finding
+// no rows isn't an error.
+type genQuerier interface {
+	GenQuery(q *Query, zone string) (*QueryResult, error)
+}
+
+// generalAdminer is implemented by Transports that run iadmin like operations, such as resource and zone
+// administration. args are the 10 arguments of the GeneralAdmin API, padded with empty strings.
+type generalAdminer interface {
+	GeneralAdmin(args ...string) error
+}
+
+// checksumVerifier is implemented by Transports that have the server verify replica replNum of the data object at
+// path against its registered checksum. The checksum is returned, errors match ErrChecksumMismatch on a mismatch.
+type checksumVerifier interface {
+	VerifyChecksum(path string, replNum int) (string, error)
+}
+
+// ticketAdminer is implemented by Transports that run iticket like operations. args are the 6 arguments of the
+// TicketAdmin API, padded with empty strings.
+type ticketAdminer interface {
+	TicketAdmin(args ...string) error
+}
+
+// metaBatcher is implemented by Transports that apply metadata operations to one object all at once. input and the
+// returned output are the JSON documents of the atomic metadata API, when an operation fails none is applied and
+// output reports its index.
+type metaBatcher interface {
+	AtomicApplyMetadata(input []byte) ([]byte, error)
+}
+
+// bindTransport returns t bound to ctx when t supports it, and ctx can be cancelled
+func bindTransport(ctx context.Context, t Transport) Transport {
+	if bt, ok := t.(boundTransport); ok && ctx.Done() != nil {
+		return bt.withContext(ctx)
+	}
+
+	return t
+}
+
+// readContext reads from handle with t, waiting for it only as long as ctx allows when t supports it
+func readContext(ctx context.Context, t Transport, handle int, length int64) ([]byte, error) {
+	if ct, ok := t.(contextTransport); ok {
+		return ct.ReadContext(ctx, handle, length)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return t.Read(handle, length)
+}
+
+// writeContext writes to handle with t, waiting for it only as long as ctx allows when t supports it
+func writeContext(ctx context.Context, t Transport, handle int, data []byte) error {
+	if ct, ok := t.(contextTransport); ok {
+		return ct.WriteContext(ctx, handle, data)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return t.Write(handle, data)
+}
+
+// NewTransportError returns an error for a Transport to return, carrying the iRODS status code (or -1) and a detail message.
+func NewTransportError(status int, detail string) *GoRodsError {
+	return newError(Fatal, status, detail)
+}
+
+// transportError returns the "iRODS <Op> Failed" error for an operation that failed in the Transport.
+// message is formatted like the other GoRODS errors, and the detail of cause is appended to it.
+// The iRODS status code of cause is kept, and cause is wrapped.
+func transportError(cause error, message string) *GoRodsError {
+	status := -1
+	detail := cause.Error()
+
+	if rodsErr, ok := cause.(*GoRodsError); ok {
+		detail = rodsErr.Message
+		if rodsErr.Code != 0 {
+			status = rodsErr.Code
+		}
+	}
+
+	return newError(Fatal, status, fmt.Sprintf("%v: %v", message, detail)).wrap(cause)
+}
+
+// timeToString formats t like the iCAT does with its create_ts and modify_ts columns
+func timeToString(t time.Time) string {
+	return fmt.Sprintf("%011d", t.Unix())
+}
@@ -3,15 +3,11 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
+	"context"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
-	"unsafe"
 )
 
 // User contains information relating to an iRODS user
@@ -138,11 +134,16 @@ func (usr *User) Groups() (Groups, error) {
 
 // Delete deletes the user from the iCAT server
 func (usr *User) Delete() error {
-	if err := deleteUser(usr.Name(), usr.Zone(), usr.con); err != nil {
+	return usr.deleteContext(context.Background())
+}
+
+// deleteContext is like Delete, but gives up waiting for a session once ctx is done
+func (usr *User) deleteContext(ctx context.Context) error {
+	if err := deleteUser(ctx, usr.Name(), usr.Zone(), usr.con); err != nil {
 		return err
 	}
 
-	if err := usr.con.RefreshUsers(); err != nil {
+	if err := usr.con.refreshUsersContext(ctx); err != nil {
 		return err
 	}
 
@@ -250,39 +251,15 @@ func (usr *User) String() string {
 
 // FetchGroups fetches and returns fresh data about the user's groups from the iCAT server.
 func (usr *User) FetchGroups() (Groups, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	cName := C.CString(usr.name)
-	defer C.free(unsafe.Pointer(cName))
-
-	result.size = C.int(0)
-
-	ccon := usr.con.GetCcon()
-
-	if status := C.gorods_get_user_groups(ccon, cName, &result, &err); status != 0 {
-		usr.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Groups Failed: %v", C.GoString(err)))
+	groupNames, er := usr.con.transport.UserGroups(usr.name)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Groups Failed")
 	}
 
-	usr.con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
 	if grps, err := usr.con.Groups(); err == nil {
 		response := make(Groups, 0)
 
-		for _, groupName := range slice {
-
-			gName := C.GoString(groupName)
+		for _, gName := range groupNames {
 
 			if gName != usr.name {
 				grp := grps.FindByName(gName, usr.con)
@@ -304,51 +281,14 @@ func (usr *User) FetchGroups() (Groups, error) {
 
 // FetchInfo fetches fresh user info from the iCAT server, and returns it as a map.
 func (usr *User) FetchInfo() (map[string]string, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
-
-	cUser := C.CString(usr.name)
-	defer C.free(unsafe.Pointer(cUser))
-
-	ccon := usr.con.GetCcon()
-
-	if status := C.gorods_get_user(cUser, ccon, &result, &err); status != 0 {
-		usr.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Users Failed: %v", C.GoString(err)))
-	}
-
-	usr.con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
-	//response := make(Users, 0)
-	response := make(map[string]string)
-
-	for _, userInfo := range slice {
-
-		userAttributes := strings.Split(strings.Trim(C.GoString(userInfo), " \n"), "\n")
-
-		for _, attr := range userAttributes {
-
-			split := strings.Split(attr, ": ")
-
-			attrName := split[0]
-			attrVal := split[1]
-
-			response[attrName] = attrVal
-
-		}
+	return usr.fetchInfoContext(context.Background())
+}
 
+// fetchInfoContext is like FetchInfo, but gives up waiting for a session once ctx is done
+func (usr *User) fetchInfoContext(ctx context.Context) (map[string]string, error) {
+	response, er := usr.con.transportContext(ctx).UserInfo(usr.name)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Users Failed")
 	}
 
 	return response, nil
@@ -386,22 +326,8 @@ func (usr *User) RemoveFromGroup(grp interface{}) error {
 // ChangePassword changes the user's password.
 // You will need to be a rodsadmin for this to succeed (I think).
 func (usr *User) ChangePassword(newPass string) error {
-	var (
-		err *C.char
-	)
-
-	cUserName := C.CString(usr.Name())
-	cNewPass := C.CString(newPass)
-	cMyPass := C.CString(usr.Con().Options.Password)
-	defer C.free(unsafe.Pointer(cUserName))
-	defer C.free(unsafe.Pointer(cNewPass))
-	defer C.free(unsafe.Pointer(cMyPass))
-
-	ccon := usr.Con().GetCcon()
-	defer usr.Con().ReturnCcon(ccon)
-
-	if status := C.gorods_change_user_password(cUserName, cNewPass, cMyPass, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS ChangePassword Failed: %v", C.GoString(err)))
+	if er := usr.con.transport.ChangePassword(usr.Name(), newPass, usr.Con().Options.Password); er != nil {
+		return transportError(er, "iRODS ChangePassword Failed")
 	}
 
 	return nil
@@ -442,7 +368,7 @@ func (usr *User) DeleteMeta(attr string) (*MetaCollection, error) {
 func (usr *User) Meta() (*MetaCollection, error) {
 
 	if usr.metaCol == nil {
-		if mc, err := newMetaCollection(usr); err == nil {
+		if mc, err := newMetaCollection(context.Background(), usr); err == nil {
 			usr.metaCol = mc
 		} else {
 			return nil, err
@@ -452,54 +378,30 @@ func (usr *User) Meta() (*MetaCollection, error) {
 	return usr.metaCol, nil
 }
 
-func deleteUser(userName string, zone *Zone, con *Connection) error {
-	var (
-		err *C.char
-	)
-
-	cZoneName := C.CString(zone.Name())
-	cUserName := C.CString(userName)
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cUserName))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status := C.gorods_delete_user(cUserName, cZoneName, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS DeleteUser %v Failed: %v", userName, C.GoString(err)))
+func deleteUser(ctx context.Context, userName string, zone *Zone, con *Connection) error {
+	if er := con.transportContext(ctx).DeleteUser(userName, zone.Name()); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS DeleteUser %v Failed", userName))
 	}
 
 	return nil
 }
 
-func createUser(userName string, zoneName string, typ int, con *Connection) error {
-	var (
-		err   *C.char
-		cType *C.char
-	)
+func createUser(ctx context.Context, userName string, zoneName string, typ int, con *Connection) error {
+	var userType string
 
 	switch typ {
 	case AdminType:
-		cType = C.CString("rodsadmin")
+		userType = "rodsadmin"
 	case UserType:
-		cType = C.CString("rodsuser")
+		userType = "rodsuser"
 	case GroupAdminType:
-		cType = C.CString("groupadmin")
+		userType = "groupadmin"
 	default:
 		return newError(Fatal, -1, fmt.Sprintf("iRODS CreateUser Failed: Unknown user type passed"))
 	}
 
-	cZoneName := C.CString(zoneName)
-	cUserName := C.CString(userName)
-	defer C.free(unsafe.Pointer(cZoneName))
-	defer C.free(unsafe.Pointer(cUserName))
-	defer C.free(unsafe.Pointer(cType))
-
-	ccon := con.GetCcon()
-	defer con.ReturnCcon(ccon)
-
-	if status := C.gorods_create_user(cUserName, cZoneName, cType, ccon, &err); status != 0 {
-		return newError(Fatal, status, fmt.Sprintf("iRODS CreateUser %v Failed: %v", userName, C.GoString(err)))
+	if er := con.transportContext(ctx).CreateUser(userName, zoneName, userType); er != nil {
+		return transportError(er, fmt.Sprintf("iRODS CreateUser %v Failed", userName))
 	}
 
 	return nil
@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 /*** Copyright (c) 2016, University of Florida Research Foundation, Inc. and The BioTeam, Inc.  ***
  *** For more information please refer to the LICENSE.md file                                   ***/
 
@@ -54,6 +57,20 @@ int gorods_connect_env(rcComm_t** conn, char* host, int port, char* username, ch
     return 0;
 }
 
+int gorods_ping(rcComm_t* conn, char** err) {
+    miscSvrInfo_t *outSvrInfo = NULL;
+
+    int status = rcGetMiscSvrInfo(conn, &outSvrInfo);
+    if ( status < 0 ) {
+        *err = "rcGetMiscSvrInfo failed";
+        return status;
+    }
+
+    free(outSvrInfo);
+
+    return 0;
+}
+
 void display_mallinfo(void) {
     struct mallinfo mi;
 
@@ -151,6 +168,28 @@ int gorods_set_session_ticket(rcComm_t *myConn, char *ticket, char** err) {
     return status;
 }
 
+int gorods_ticket_admin(rcComm_t *myConn, char* arg1, char* arg2, char* arg3, char* arg4, char* arg5, char* arg6, char** err) {
+    ticketAdminInp_t ticketAdminInp;
+    int status;
+
+    memset(&ticketAdminInp, 0, sizeof(ticketAdminInp));
+
+    ticketAdminInp.arg1 = arg1;
+    ticketAdminInp.arg2 = arg2;
+    ticketAdminInp.arg3 = arg3;
+    ticketAdminInp.arg4 = arg4;
+    ticketAdminInp.arg5 = arg5;
+    ticketAdminInp.arg6 = arg6;
+
+    status = rcTicketAdmin( myConn, &ticketAdminInp );
+
+    if ( status < 0 ) {
+        *err = "rcTicketAdmin failed";
+    }
+
+    return status;
+}
+
 int gorods_iuserinfo(rcComm_t *myConn, char *name, userInfo_t* outInfo, char** err) {
     genQueryInp_t genQueryInp;
     genQueryOut_t *genQueryOut;
@@ -1668,6 +1707,160 @@ int gorods_exec_specific_query(rcComm_t *conn, char *sql, char *args[], int args
 
 }
 
+int gorods_gen_query(rcComm_t *conn, char *selectConditionString, int noDistinctFlag, int upperCaseFlag, char *zoneName, int rowOffset, int limit, goRodsGenQueryResult_t* result, char** err) {
+    int status;
+    genQueryInp_t genQueryInp;
+    genQueryOut_t *genQueryOut = NULL;
+
+    memset(&genQueryInp, 0, sizeof(genQueryInp_t));
+
+    status = fillGenQueryInpFromStrCond(selectConditionString, &genQueryInp);
+    if ( status < 0 ) {
+        clearGenQueryInp(&genQueryInp);
+        *err = "fillGenQueryInpFromStrCond failed";
+        return status;
+    }
+
+    if ( noDistinctFlag ) {
+        genQueryInp.options |= NO_DISTINCT;
+    }
+
+    if ( upperCaseFlag ) {
+        genQueryInp.options |= UPPER_CASE_WHERE;
+    }
+
+    if ( zoneName != 0 && zoneName[0] != '\0' ) {
+        addKeyVal(&genQueryInp.condInput, ZONE_KW, zoneName);
+    }
+
+    genQueryInp.maxRows = MAX_SQL_ROWS;
+    if ( limit > 0 && limit < MAX_SQL_ROWS ) {
+        genQueryInp.maxRows = limit;
+    }
+
+    genQueryInp.rowOffset = rowOffset;
+    genQueryInp.continueInx = 0;
+
+    status = rcGenQuery(conn, &genQueryInp, &genQueryOut);
+
+    while ( status >= 0 ) {
+        gorods_build_iquest_spec_result(genQueryOut, result);
+
+        if ( genQueryOut->continueInx <= 0 || (limit > 0 && result->rowSize >= limit) ) {
+            break;
+        }
+
+        genQueryInp.continueInx = genQueryOut->continueInx;
+        freeGenQueryOut(&genQueryOut);
+
+        status = rcGenQuery(conn, &genQueryInp, &genQueryOut);
+    }
+
+    // We stopped before the end of the results, let the server close the statement
+    if ( status >= 0 && genQueryOut != NULL && genQueryOut->continueInx > 0 ) {
+        genQueryInp.continueInx = genQueryOut->continueInx;
+        genQueryInp.maxRows = 0;
+        freeGenQueryOut(&genQueryOut);
+
+        rcGenQuery(conn, &genQueryInp, &genQueryOut);
+    }
+
+    freeGenQueryOut(&genQueryOut);
+    clearGenQueryInp(&genQueryInp);
+
+    if ( status < 0 ) {
+        *err = "rcGenQuery failed";
+        return status;
+    }
+
+    return 0;
+}
+
+int gorods_gen_query_open(rcComm_t *conn, char *selectConditionString, int noDistinctFlag, int upperCaseFlag, char *zoneName, int rowOffset, int pageSize, genQueryInp_t** inp, char** err) {
+    int status;
+    genQueryInp_t *genQueryInp = gorods_malloc(sizeof(genQueryInp_t));
+
+    memset(genQueryInp, 0, sizeof(genQueryInp_t));
+
+    status = fillGenQueryInpFromStrCond(selectConditionString, genQueryInp);
+    if ( status < 0 ) {
+        gorods_gen_query_free(genQueryInp);
+        *err = "fillGenQueryInpFromStrCond failed";
+        return status;
+    }
+
+    if ( noDistinctFlag ) {
+        genQueryInp->options |= NO_DISTINCT;
+    }
+
+    if ( upperCaseFlag ) {
+        genQueryInp->options |= UPPER_CASE_WHERE;
+    }
+
+    if ( zoneName != 0 && zoneName[0] != '\0' ) {
+        addKeyVal(&genQueryInp->condInput, ZONE_KW, zoneName);
+    }
+
+    genQueryInp->maxRows = MAX_SQL_ROWS;
+    if ( pageSize > 0 && pageSize < MAX_SQL_ROWS ) {
+        genQueryInp->maxRows = pageSize;
+    }
+
+    genQueryInp->rowOffset = rowOffset;
+    genQueryInp->continueInx = 0;
+
+    *inp = genQueryInp;
+
+    return 0;
+}
+
+int gorods_gen_query_next(rcComm_t *conn, genQueryInp_t* inp, goRodsGenQueryResult_t* result, char** err) {
+    int status;
+    genQueryOut_t *genQueryOut = NULL;
+
+    status = rcGenQuery(conn, inp, &genQueryOut);
+    if ( status < 0 ) {
+        freeGenQueryOut(&genQueryOut);
+        inp->continueInx = 0;
+        *err = "rcGenQuery failed";
+        return status;
+    }
+
+    gorods_build_iquest_spec_result(genQueryOut, result);
+
+    inp->continueInx = genQueryOut->continueInx;
+    freeGenQueryOut(&genQueryOut);
+
+    return 0;
+}
+
+int gorods_gen_query_close(rcComm_t *conn, genQueryInp_t* inp, char** err) {
+    int status = 0;
+    genQueryOut_t *genQueryOut = NULL;
+
+    // Results are still pending on the server, close the statement
+    if ( inp->continueInx > 0 ) {
+        inp->maxRows = 0;
+        status = rcGenQuery(conn, inp, &genQueryOut);
+        freeGenQueryOut(&genQueryOut);
+    }
+
+    gorods_gen_query_free(inp);
+
+    if ( status < 0 && status != CAT_NO_ROWS_FOUND ) {
+        *err = "rcGenQuery failed";
+        return status;
+    }
+
+    return 0;
+}
+
+void gorods_gen_query_free(genQueryInp_t* inp) {
+    // Frees the select, condition and keyword lists filled in by fillGenQueryInpFromStrCond too
+    clearGenQueryInp(inp);
+    free(inp);
+}
+
 int gorods_build_iquest_result(genQueryOut_t * genQueryOut, goRodsHashResult_t* result, char** err) {
     int i = 0, n = 0, j = 0;
     sqlResult_t *v[MAX_SQL_ATTR];
@@ -2221,6 +2414,47 @@ int gorods_checksum_dataobject(char* path, char** outChksum, rcComm_t* conn, cha
 	return 0;
 }
 
+int gorods_verify_checksum_dataobject(char* path, int replNum, char** outChksum, rcComm_t* conn, char** err) {
+
+	dataObjInp_t dataObjInp;
+	char replNumStr[NAME_LEN];
+
+	bzero(&dataObjInp, sizeof(dataObjInp));
+	rstrcpy(dataObjInp.objPath, path, MAX_NAME_LEN);
+
+	snprintf(replNumStr, NAME_LEN, "%d", replNum);
+
+	addKeyVal(&dataObjInp.condInput, VERIFY_CHKSUM_KW, "");
+	addKeyVal(&dataObjInp.condInput, REPL_NUM_KW, replNumStr);
+
+	int status = rcDataObjChksum(conn, &dataObjInp, outChksum);
+	clearKeyVal(&dataObjInp.condInput);
+
+	if ( status < 0 ) {
+		*err = "rcDataObjChksum failed";
+		return status;
+	}
+
+	return 0;
+}
+
+int gorods_atomic_apply_metadata_operations(char* jsonInput, char** jsonOutput, rcComm_t* conn, char** err) {
+
+#if defined(IRODS_VERSION_INTEGER) && IRODS_VERSION_INTEGER >= 4002008
+	int status = rc_atomic_apply_metadata_operations(conn, jsonInput, jsonOutput);
+
+	if ( status < 0 ) {
+		*err = "rc_atomic_apply_metadata_operations failed";
+		return status;
+	}
+
+	return 0;
+#else
+	*err = "Atomic metadata operations require iRODS 4.2.8 or later";
+	return SYS_NOT_SUPPORTED;
+#endif
+}
+
 
 const char NON_ROOT_COLL_CHECK_STR[] = "<>'/'";
 
@@ -3275,8 +3509,12 @@ int gorods_rm(char* path, int isCollection, int recursive, int force, int trash,
 
 }
 
-int gorods_meta_dataobj(char *name, char *cwd, goRodsMetaResult_t* result, rcComm_t* conn, char** err) {
+int gorods_meta_dataobj(char *name, char *cwd, char *zoneHint, goRodsMetaResult_t* result, rcComm_t* conn, char** err) {
     char zoneArgument[MAX_NAME_LEN + 2] = "";
+
+    if ( zoneHint != NULL ) {
+        rstrcpy(zoneArgument, zoneHint, MAX_NAME_LEN);
+    }
     char *attrName = ""; // Get all attributes?
     // End global vars
     
@@ -3405,10 +3643,14 @@ int gorods_meta_dataobj(char *name, char *cwd, goRodsMetaResult_t* result, rcCom
     return 0;
 }
 
-int gorods_meta_collection(char *name, char *cwd, goRodsMetaResult_t* result, rcComm_t* conn, char** err) {
+int gorods_meta_collection(char *name, char *cwd, char *zoneHint, goRodsMetaResult_t* result, rcComm_t* conn, char** err) {
 	char *attrName = ""; // Get all attributes?
     char zoneArgument[MAX_NAME_LEN + 2] = "";
 
+    if ( zoneHint != NULL ) {
+        rstrcpy(zoneArgument, zoneHint, MAX_NAME_LEN);
+    }
+
     genQueryInp_t genQueryInp;
 	genQueryOut_t *genQueryOut;
 	int i1a[10];
@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 /*** Copyright (c) 2016, University of Florida Research Foundation, Inc. and The BioTeam, Inc.  ***
  *** For more information please refer to the LICENSE.md file                                   ***/
 
@@ -14,6 +17,10 @@
 #include "dataObjChksum.h"
 #include "dataObjClose.h"
 #include "lsUtil.h"
+
+#if defined(IRODS_VERSION_INTEGER) && IRODS_VERSION_INTEGER >= 4002008
+#include "atomic_apply_metadata_operations.h"
+#endif
 #include <malloc.h>
 
 typedef struct {
@@ -90,6 +97,7 @@ void* gorods_malloc(size_t size);
 int gorods_connect(rcComm_t** conn, char** host, int* port, char** username, char** zone, char** err);
 int gorods_connect_env(rcComm_t** conn, char* host, int port, char* username, char* zone, char** err);
 int gorods_clientLoginPam(rcComm_t* conn, char* password, int ttl, char** pamPass, char** err) ;
+int gorods_ping(rcComm_t* conn, char** err);
 
 int gorods_iuserinfo(rcComm_t *myConn, char *name, userInfo_t* outInfo, char** err);
 
@@ -104,6 +112,11 @@ int gorods_iquest_general(rcComm_t *conn, char *selectConditionString, int noDis
 void gorods_free_map_result(goRodsHashResult_t* result);
 int gorods_exec_specific_query(rcComm_t*, char*, char *args[], int, char*, goRodsGenQueryResult_t*, char**);
 void gorods_free_gen_query_result(goRodsGenQueryResult_t* result);
+int gorods_gen_query(rcComm_t *conn, char *selectConditionString, int noDistinctFlag, int upperCaseFlag, char *zoneName, int rowOffset, int limit, goRodsGenQueryResult_t* result, char** err);
+int gorods_gen_query_open(rcComm_t *conn, char *selectConditionString, int noDistinctFlag, int upperCaseFlag, char *zoneName, int rowOffset, int pageSize, genQueryInp_t** inp, char** err);
+int gorods_gen_query_next(rcComm_t *conn, genQueryInp_t* inp, goRodsGenQueryResult_t* result, char** err);
+int gorods_gen_query_close(rcComm_t *conn, genQueryInp_t* inp, char** err);
+void gorods_gen_query_free(genQueryInp_t* inp);
 
 int gorods_get_users(rcComm_t* conn, goRodsStringResult_t* result, char** err);
 int gorods_get_user(char *user, rcComm_t* conn, goRodsStringResult_t* result, char** err);
@@ -166,6 +179,8 @@ int gorods_copy_dataobject(char* source, char* destination, int force, char* res
 int gorods_move_dataobject(char* source, char* destination, int objType, rcComm_t* conn, char** err);
 int gorods_unlink_dataobject(char* path, int force, rcComm_t* conn, char** err);
 int gorods_checksum_dataobject(char* path, char** outChksum, rcComm_t* conn, char** err);
+int gorods_verify_checksum_dataobject(char* path, int replNum, char** outChksum, rcComm_t* conn, char** err);
+int gorods_atomic_apply_metadata_operations(char* jsonInput, char** jsonOutput, rcComm_t* conn, char** err);
 int gorods_rm(char* path, int isCollection, int recursive, int force, int trash, rcComm_t* conn, char** err);
 int gorods_get_dataobject_acl(rcComm_t* conn, char* dataId, goRodsACLResult_t* result, char* zoneHint, char** err);
 void gorods_free_acl_result(goRodsACLResult_t* result);
@@ -178,12 +193,13 @@ void freeGoRodsMetaResult(goRodsMetaResult_t* result);
 goRodsMeta_t* expandGoRodsMetaResult(goRodsMetaResult_t* result, int length);
 
 int gorods_meta_user(char *name, char *zone, goRodsMetaResult_t* result, rcComm_t* conn, char** err);
-int gorods_meta_dataobj(char *name, char *cwd, goRodsMetaResult_t* result, rcComm_t* conn, char** err);
-int gorods_meta_collection(char *name, char *cwd, goRodsMetaResult_t* result, rcComm_t* conn, char** err);
+int gorods_meta_dataobj(char *name, char *cwd, char *zoneHint, goRodsMetaResult_t* result, rcComm_t* conn, char** err);
+int gorods_meta_collection(char *name, char *cwd, char *zoneHint, goRodsMetaResult_t* result, rcComm_t* conn, char** err);
 int gorods_mod_meta(char* type, char* path, char* oa, char* ov, char* ou, char* na, char* nv, char* nu, rcComm_t* conn, char** err);
 int gorods_add_meta(char* type, char* path, char* na, char* nv, char* nu, rcComm_t* conn, char** err);
 int gorods_rm_meta(char* type, char* path, char* oa, char* ov, char* ou, rcComm_t* conn, char** err);
 int gorods_set_session_ticket(rcComm_t *myConn, char *ticket, char** err);
+int gorods_ticket_admin(rcComm_t *myConn, char* arg1, char* arg2, char* arg3, char* arg4, char* arg5, char* arg6, char** err);
 
 int gorods_query_collection(rcComm_t* conn, char* query, goRodsPathResult_t* result, char** err);
 int gorods_query_dataobj(rcComm_t* conn, char* query, goRodsPathResult_t* result, char** err);
@@ -3,17 +3,74 @@
 
 package gorods
 
-// #include "wrapper.h"
-import "C"
-
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
-	"unsafe"
 )
 
+// DefaultZonePort is the port used for remote zones whose connection string doesn't include one
+const DefaultZonePort = 1247
+
+// ZoneConnection is the iRODS server a remote zone is reached through, parsed from the zone's connection string
+type ZoneConnection struct {
+	Host string
+	Port int
+}
+
+// String returns the connection string ("host:port") of the zone connection
+func (conn ZoneConnection) String() string {
+	if conn.Host == "" {
+		return ""
+	}
+
+	return net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port))
+}
+
+// ParseZoneConString parses a zone connection string ("host:port" or "host") into a ZoneConnection. An empty
+// string, as used by the local zone, returns an empty ZoneConnection.
+func ParseZoneConString(conString string) (ZoneConnection, error) {
+	conString = strings.TrimSpace(conString)
+	if conString == "" {
+		return ZoneConnection{}, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(conString)
+	if err != nil {
+		// No port, or an IPv6 address without brackets
+		if strings.Count(conString, ":") == 1 {
+			return ZoneConnection{}, newError(Fatal, -1, fmt.Sprintf("iRODS Parse Zone Connection Failed: Invalid connection string %q", conString)).wrap(err)
+		}
+
+		return ZoneConnection{Host: strings.Trim(conString, "[]"), Port: DefaultZonePort}, nil
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 || host == "" {
+		return ZoneConnection{}, newError(Fatal, -1, fmt.Sprintf("iRODS Parse Zone Connection Failed: Invalid connection string %q", conString))
+	}
+
+	return ZoneConnection{Host: host, Port: port}, nil
+}
+
+// PathZone returns the zone an absolute iRODS path belongs to, which is its first element ("otherZone" for
+// "/otherZone/home/rods"), or an empty string for relative paths
+func PathZone(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return ""
+	}
+
+	path = strings.TrimLeft(path, "/")
+
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+
+	return path
+}
+
 // Zone contains information representing an iRODS zone.
 type Zone struct {
 	name string
@@ -145,6 +202,24 @@ func (zne *Zone) ConString() (string, error) {
 	return zne.conString, nil
 }
 
+// Connection loads data from iRODS if needed, and returns the zone's connection string parsed with
+// ParseZoneConString. It's empty for the local zone.
+func (zne *Zone) Connection() (ZoneConnection, error) {
+	conString, err := zne.ConString()
+	if err != nil {
+		return ZoneConnection{}, err
+	}
+
+	return ParseZoneConString(conString)
+}
+
+// IsRemote loads data from iRODS if needed, and returns true if the zone is a federated (remote) zone
+func (zne *Zone) IsRemote() (bool, error) {
+	typ, err := zne.Type()
+
+	return typ == Remote, err
+}
+
 // RefreshInfo pulls fresh info from the iCAT server, and sets it's zone fields based on the data.
 func (zne *Zone) RefreshInfo() error {
 
@@ -177,49 +252,9 @@ func (zne *Zone) RefreshInfo() error {
 
 // FetchInfo returns a map of fresh zone info from the iCAT server.
 func (zne *Zone) FetchInfo() (map[string]string, error) {
-	var (
-		result C.goRodsStringResult_t
-		err    *C.char
-	)
-
-	result.size = C.int(0)
-
-	cZone := C.CString(zne.name)
-	defer C.free(unsafe.Pointer(cZone))
-
-	ccon := zne.con.GetCcon()
-
-	if status := C.gorods_get_zone(cZone, ccon, &result, &err); status != 0 {
-		zne.con.ReturnCcon(ccon)
-		return nil, newError(Fatal, status, fmt.Sprintf("iRODS Get Zone Info Failed: %v", C.GoString(err)))
-	}
-
-	zne.con.ReturnCcon(ccon)
-
-	defer C.gorods_free_string_result(&result)
-
-	unsafeArr := unsafe.Pointer(result.strArr)
-	arrLen := int(result.size)
-
-	// Convert C array to slice, backed by arr *C.char
-	slice := (*[1 << 30]*C.char)(unsafeArr)[:arrLen:arrLen]
-
-	response := make(map[string]string)
-
-	for _, zoneInfo := range slice {
-
-		zoneAttributes := strings.Split(strings.Trim(C.GoString(zoneInfo), " \n"), "\n")
-
-		for _, attr := range zoneAttributes {
-
-			split := strings.Split(attr, ": ")
-
-			attrName := split[0]
-			attrVal := split[1]
-
-			response[attrName] = attrVal
-
-		}
+	response, er := zne.con.transport.ZoneInfo(zne.name)
+	if er != nil {
+		return nil, transportError(er, "iRODS Get Zone Info Failed")
 	}
 
 	return response, nil
@@ -0,0 +1,252 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jjacquay712/GoRODS/irodsproto"
+)
+
+func TestParseZoneConString(t *testing.T) {
+	for conString, expected := range map[string]ZoneConnection{
+		"":                       {},
+		"irods.example.org:1247": {Host: "irods.example.org", Port: 1247},
+		"irods.example.org":      {Host: "irods.example.org", Port: DefaultZonePort},
+		"[::1]:2247":             {Host: "::1", Port: 2247},
+	} {
+		if conn, err := ParseZoneConString(conString); err != nil || conn != expected {
+			t.Errorf("Expected %q to parse as %+v, got %+v (%v)", conString, expected, conn, err)
+		}
+	}
+
+	for _, conString := range []string{"irods.example.org:", "irods.example.org:port", ":1247", "irods.example.org:70000"} {
+		if conn, err := ParseZoneConString(conString); err == nil {
+			t.Errorf("Expected %q to be rejected, got %+v", conString, conn)
+		}
+	}
+
+	if s := (ZoneConnection{Host: "irods.example.org", Port: 1247}).String(); s != "irods.example.org:1247" {
+		t.Errorf("Unexpected connection string %q", s)
+	}
+}
+
+func TestPathZone(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/otherZone/home/rods": "otherZone",
+		"/otherZone":           "otherZone",
+		"//tempZone/trash":     "tempZone",
+		"home/rods":            "",
+		"":                     "",
+	} {
+		if zone := PathZone(path); zone != expected {
+			t.Errorf("Expected %q to be in zone %q, got %q", path, expected, zone)
+		}
+	}
+}
+
+func TestQueryPathZone(t *testing.T) {
+	for expected, q := range map[string]*Query{
+		"otherZone": NewQuery(ColDataName).Where(ColCollName, "=", "/otherZone/home/rods"),
+		"my_zone":   NewQuery(ColDataName).Where(ColCollName, "=", "/my_zone/home/rods"),
+		"fed_zone":  NewQuery(ColDataName).Where(ColCollName, "like", "/fed_zone/home/%"),
+		"fed_zone2": NewQuery(ColDataName).Where(ColCollName, "like", "/fed\\_zone2/home/user_%"),
+		"":          NewQuery(ColDataName).Where(ColDataName, "=", "hello.txt"),
+	} {
+		if zone := q.pathZone(); zone != expected {
+			t.Errorf("Expected %v to be sent to %q, got %q", q, expected, zone)
+		}
+	}
+
+	for _, q := range []*Query{
+		NewQuery(ColDataName).Where(ColCollName, "like", "/%/home/rods"),
+		NewQuery(ColDataName).Where(ColCollName, "like", "/fed%/home/rods"),
+		NewQuery(ColDataName).WhereIn(ColCollName, "/tempZone/home", "/otherZone/home"),
+	} {
+		if zone := q.pathZone(); zone != "" {
+			t.Errorf("Expected %v not to have a zone, got %q", q, zone)
+		}
+	}
+
+	con := memConnection(t)
+
+	if zone, err := con.queryZone(NewQuery(ColDataName).Where(ColCollParentName, "=", "/otherZone/home")); err != nil || zone != "otherZone" {
+		t.Errorf("Expected the query to be sent to otherZone, got %q (%v)", zone, err)
+	}
+
+	if zone, err := con.queryZone(NewQuery(ColDataName).Where(ColCollName, "=", "/otherZone/home").Zone("thirdZone")); err != nil || zone != "thirdZone" {
+		t.Errorf("Expected the zone hint to win, got %q (%v)", zone, err)
+	}
+
+	if zone, err := con.queryZone(NewQuery(ColDataName)); err != nil || zone != "tempZone" {
+		t.Errorf("Expected the local zone, got %q (%v)", zone, err)
+	}
+}
+
+func TestRemoteZone(t *testing.T) {
+	mem := NewMemTransport("tempZone", "rods")
+	mem.AddZone("otherZone", "irods.other.org:1247")
+
+	con, err := NewConnection(memOptions(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zne, err := con.ZoneOf("/otherZone/home/rods")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remote, err := zne.IsRemote(); err != nil || !remote {
+		t.Errorf("Expected otherZone to be remote, got %v (%v)", remote, err)
+	}
+
+	if conn, err := zne.Connection(); err != nil || conn.Host != "irods.other.org" || conn.Port != 1247 {
+		t.Errorf("Expected irods.other.org:1247, got %+v (%v)", conn, err)
+	}
+
+	local, err := con.ZoneOf("home/rods")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remote, err := local.IsRemote(); err != nil || remote || local.Name() != "tempZone" {
+		t.Errorf("Expected the local zone tempZone, got %v (%v)", local, err)
+	}
+
+	if err := zne.Modify(ZoneModifyOptions{Comment: "partner institute"}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+
+	if err := zne.Delete(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestCreateZoneValidation(t *testing.T) {
+	con := memConnection(t)
+
+	for _, opts := range []ZoneOptions{
+		{Connection: ZoneConnection{Host: "irods.other.org"}},
+		{Name: "otherZone"},
+	} {
+		if _, err := con.CreateZone(opts); err == nil || errors.Is(err, ErrNotSupported) {
+			t.Errorf("Expected %+v to be rejected, got %v", opts, err)
+		}
+	}
+
+	if _, err := con.CreateZone(ZoneOptions{Name: "otherZone", Connection: ZoneConnection{Host: "irods.other.org"}}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestZoneAdminArgs(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		// mkzone, then the zones are listed again
+		if err := expectGeneralAdmin(s, "add", "zone", "otherZone", "remote", "irods.other.org:1247", "partner institute"); err != nil {
+			return err
+		}
+		if err := replyQuery(s, []string{"tempZone"}, []string{"otherZone"}); err != nil {
+			return err
+		}
+
+		// The current connection string is needed to change the port only
+		inp, err := expectQuery(s, map[int]string{irodsproto.ColZoneName: "= 'otherZone'"})
+		if err != nil {
+			return err
+		}
+		if err := s.ReplyRows(inp, [][]string{{"10001", "otherZone", "remote", "irods.other.org:1247", "partner institute", "01500000000", "01500000000"}}); err != nil {
+			return err
+		}
+
+		// modzone, one call per attribute
+		for _, args := range [][]string{
+			{"modify", "zone", "otherZone", "conn", "irods.other.org:2247"},
+			{"modify", "zone", "otherZone", "comment", "federated"},
+			{"modify", "zone", "otherZone", "name", "newZone"},
+			{"rm", "zone", "newZone"},
+		} {
+			if err := expectGeneralAdmin(s, args...); err != nil {
+				return err
+			}
+		}
+
+		return replyQuery(s, []string{"tempZone"})
+	})
+
+	con := protoConnection(transport)
+	con.Init = true
+
+	zne, err := con.CreateZone(ZoneOptions{Name: "otherZone", Connection: ZoneConnection{Host: "irods.other.org"}, Comment: "partner institute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zne.Modify(ZoneModifyOptions{Connection: ZoneConnection{Port: 2247}, Comment: "federated", Name: "newZone"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if zne.Name() != "newZone" || con.Options.Zone != "tempZone" {
+		t.Errorf("Expected the remote zone to be renamed only, got %v (local zone %v)", zne.Name(), con.Options.Zone)
+	}
+
+	if err := zne.Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoteZoneQuery(t *testing.T) {
+	transport, done := protoPipe(t, func(s *irodsproto.Server) error {
+		for _, zone := range []string{"otherZone", "thirdZone", ""} {
+			inp := new(irodsproto.GenQueryInp)
+			if _, err := s.Expect(irodsproto.GenQueryAN, inp); err != nil {
+				return err
+			}
+
+			got := ""
+			for i, key := range inp.KeyVals.Keys {
+				if key == irodsproto.ZoneKW {
+					got = inp.KeyVals.Values[i]
+				}
+			}
+
+			// Queries without a zone hint or a collection go to the local zone
+			expected := zone
+			if zone == "" {
+				expected = "tempZone"
+			}
+			if got != expected {
+				return fmt.Errorf("Expected the query to be sent to %q, got %q", expected, got)
+			}
+
+			if err := s.ReplyRows(inp, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	con := protoConnection(transport)
+
+	for _, q := range []*Query{
+		NewQuery(ColDataName).Where(ColCollName, "=", "/otherZone/home/rods"),
+		NewQuery(ColDataName).Where(ColCollName, "=", "/otherZone/home/rods").Zone("thirdZone"),
+		NewQuery(ColDataName),
+	} {
+		if _, err := con.Query(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,176 @@
+/*** Copyright (c) 2016, The BioTeam, Inc.                     ***
+ *** For more information please refer to the LICENSE.md file  ***/
+
+package gorods
+
+import (
+	"fmt"
+)
+
+// ZoneOptions describe the remote zone created by Connection.CreateZone, like the arguments of iadmin mkzone.
+// Connection is the iRODS server of the other zone, its Port defaults to DefaultZonePort.
+type ZoneOptions struct {
+	Name       string
+	Connection ZoneConnection
+	Comment    string
+}
+
+// ZoneModifyOptions lists the attributes changed by Zone.Modify, like iadmin modzone. Empty fields are left
+// unchanged. When only the Host or the Port of Connection is set, the other one is kept.
+type ZoneModifyOptions struct {
+	Name       string
+	Connection ZoneConnection
+	Comment    string
+}
+
+// CreateZone registers a remote zone for federation, like iadmin mkzone, and returns it.
+// You must have the proper rodsadmin privileges to use this function.
+func (con *Connection) CreateZone(opts ZoneOptions) (*Zone, error) {
+	if opts.Name == "" || opts.Connection.Host == "" {
+		return nil, newError(Fatal, -1, "iRODS Create Zone Failed: Name and Connection.Host are required")
+	}
+
+	if opts.Connection.Port == 0 {
+		opts.Connection.Port = DefaultZonePort
+	}
+
+	if err := con.generalAdmin("Create Zone", "add", "zone", opts.Name, "remote", opts.Connection.String(), opts.Comment); err != nil {
+		return nil, err
+	}
+
+	if err := con.RefreshZones(); err != nil {
+		return nil, err
+	}
+
+	znes, err := con.Zones()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zne := range znes {
+		if zne.name == opts.Name {
+			return zne, nil
+		}
+	}
+
+	return nil, newError(Fatal, -1, fmt.Sprintf("iRODS Create Zone %v Failed: %v", opts.Name, "Unable to locate newly created zone in cache"))
+}
+
+// Modify changes the attributes set in opts, like iadmin modzone. The zone info is fetched again the next time
+// it's needed. You must have the proper rodsadmin privileges to use this function.
+func (zne *Zone) Modify(opts ZoneModifyOptions) error {
+	if opts.Connection.Host != "" || opts.Connection.Port != 0 {
+		conn, err := zne.Connection()
+		if err != nil {
+			return err
+		}
+
+		if opts.Connection.Host != "" {
+			conn.Host = opts.Connection.Host
+		}
+
+		if opts.Connection.Port != 0 {
+			conn.Port = opts.Connection.Port
+		}
+
+		if conn.Host == "" {
+			return newError(Fatal, -1, fmt.Sprintf("iRODS Modify Zone Failed: %v has no connection host to change the port of", zne.name))
+		}
+
+		if conn.Port == 0 {
+			conn.Port = DefaultZonePort
+		}
+
+		if err := zne.con.generalAdmin("Modify Zone", "modify", "zone", zne.name, "conn", conn.String()); err != nil {
+			return err
+		}
+
+		zne.hasInit = false
+	}
+
+	if opts.Comment != "" {
+		if err := zne.con.generalAdmin("Modify Zone", "modify", "zone", zne.name, "comment", opts.Comment); err != nil {
+			return err
+		}
+
+		zne.hasInit = false
+	}
+
+	if opts.Name != "" && opts.Name != zne.name {
+		if err := zne.con.generalAdmin("Modify Zone", "modify", "zone", zne.name, "name", opts.Name); err != nil {
+			return err
+		}
+
+		if zne.con.Options.Zone == zne.name {
+			zne.con.Options.Zone = opts.Name
+		}
+
+		zne.name = opts.Name
+		zne.hasInit = false
+	}
+
+	return nil
+}
+
+// Delete removes the remote zone, like iadmin rmzone. The local zone can't be removed.
+// You must have the proper rodsadmin privileges to use this function.
+func (zne *Zone) Delete() error {
+	if err := zne.con.generalAdmin("Delete Zone", "rm", "zone", zne.name); err != nil {
+		return err
+	}
+
+	return zne.con.RefreshZones()
+}
+
+// ZoneOf returns the *Zone an absolute iRODS path belongs to, such as the remote zone otherZone for
+// "/otherZone/home/rods". Relative paths belong to the local zone.
+func (con *Connection) ZoneOf(path string) (*Zone, error) {
+	name, err := con.pathZone(path)
+	if err != nil {
+		return nil, err
+	}
+
+	znes, err := con.Zones()
+	if err != nil {
+		return nil, err
+	}
+
+	return znes.FindByName(name, con), nil
+}
+
+// pathZone returns the name of the zone path belongs to, falling back to the local zone for relative paths
+func (con *Connection) pathZone(path string) (string, error) {
+	if zone := PathZone(path); zone != "" {
+		return zone, nil
+	}
+
+	z, err := con.LocalZone()
+	if err != nil {
+		return "", err
+	}
+
+	return z.Name(), nil
+}
+
+// queryZone returns the zone q is sent to: its ZoneHint, or the zone of the collection it's restricted to, or the
+// local zone
+func (con *Connection) queryZone(q *Query) (string, error) {
+	if q.ZoneHint != "" {
+		return q.ZoneHint, nil
+	}
+
+	if zone := q.pathZone(); zone != "" {
+		return zone, nil
+	}
+
+	if con.Options.Zone != "" {
+		return con.Options.Zone, nil
+	}
+
+	z, err := con.LocalZone()
+	if err != nil {
+		return "", err
+	}
+
+	return z.Name(), nil
+}